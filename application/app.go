@@ -1,7 +1,6 @@
 package application
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -11,6 +10,16 @@ import (
 
 type CommandSet map[string]commands.Command
 
+var mutatingCommands = map[string]bool{
+	commands.UpCommand:           true,
+	commands.DestroyCommand:      true,
+	commands.CreateLBsCommand:    true,
+	commands.UpdateLBsCommand:    true,
+	commands.DeleteLBsCommand:    true,
+	commands.ImportCommand:       true,
+	commands.RestoreStateCommand: true,
+}
+
 type usage interface {
 	Print()
 	PrintCommandUsage(command, message string)
@@ -20,15 +29,17 @@ type App struct {
 	commands      CommandSet
 	configuration Configuration
 	stateStore    stateStore
+	stateLocker   stateLocker
 	usage         usage
 }
 
 func New(commands CommandSet, configuration Configuration, stateStore stateStore,
-	usage usage) App {
+	stateLocker stateLocker, usage usage) App {
 	return App{
 		commands:      commands,
 		configuration: configuration,
 		stateStore:    stateStore,
+		stateLocker:   stateLocker,
 		usage:         usage,
 	}
 }
@@ -51,7 +62,7 @@ func (a App) getCommand(commandString string) (commands.Command, error) {
 	return command, nil
 }
 
-func (a App) execute() error {
+func (a App) execute() (err error) {
 	command, err := a.getCommand(a.configuration.Command)
 	if err != nil {
 		return err
@@ -81,15 +92,43 @@ func (a App) execute() error {
 		return versionCommand.Execute([]string{}, storage.State{})
 	}
 
-	err = command.Execute(a.configuration.SubcommandFlags, a.configuration.State)
+	state := a.configuration.State
+	if mutatingCommands[a.configuration.Command] {
+		err = a.stateLocker.Lock(a.configuration.Command, a.configuration.Global.LockTimeout)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			unlockErr := a.stateLocker.Unlock()
+			if err == nil {
+				err = unlockErr
+			}
+		}()
+
+		if !commandsWithoutState[a.configuration.Command] {
+			err = a.stateStore.Migrate()
+			if err != nil {
+				return err
+			}
+
+			state, err = a.stateStore.Get()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = command.Execute(a.configuration.SubcommandFlags, state)
 	if err != nil {
 		switch err.(type) {
 		case awserr.RequestFailure:
 			requestFailure := err.(awserr.RequestFailure)
 			if requestFailure.StatusCode() == 403 {
-				return errors.New(fmt.Sprintf(
-					"The AWS credentials provided have insufficient permissions to perform the operation `bbl %s`.\nPlease refer to the bbl README:\nhttps://github.com/cloudfoundry/bosh-bootloader#configure-aws.\nOriginal error message from AWS:\n\n%s",
-					a.configuration.Command, requestFailure.Message()))
+				return InsufficientPermissionsError{
+					Command: a.configuration.Command,
+					Message: requestFailure.Message(),
+				}
 			}
 			return err
 		default:
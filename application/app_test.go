@@ -2,6 +2,7 @@ package application_test
 
 import (
 	"errors"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/cloudfoundry/bosh-bootloader/application"
@@ -29,13 +30,16 @@ func (snkp setNewKeyPairName) Usage() string { return "" }
 
 var _ = Describe("App", func() {
 	var (
-		app        application.App
-		helpCmd    *fakes.Command
-		versionCmd *fakes.Command
-		someCmd    *fakes.Command
-		errorCmd   *fakes.Command
-		usage      *fakes.Usage
-		stateStore *fakes.StateStore
+		app             application.App
+		helpCmd         *fakes.Command
+		versionCmd      *fakes.Command
+		someCmd         *fakes.Command
+		errorCmd        *fakes.Command
+		upCmd           *fakes.Command
+		restoreStateCmd *fakes.Command
+		usage           *fakes.Usage
+		stateStore      *fakes.StateStore
+		stateLocker     *fakes.StateLocker
 	)
 
 	var NewAppWithConfiguration = func(configuration application.Configuration) application.App {
@@ -45,9 +49,12 @@ var _ = Describe("App", func() {
 			"some":                 someCmd,
 			"error":                errorCmd,
 			"set-new-keypair-name": setNewKeyPairName{},
+			"up":                   upCmd,
+			"restore-state":        restoreStateCmd,
 		},
 			configuration,
 			stateStore,
+			stateLocker,
 			usage,
 		)
 	}
@@ -60,8 +67,14 @@ var _ = Describe("App", func() {
 		someCmd = &fakes.Command{}
 		someCmd.ExecuteCall.PassState = true
 
+		restoreStateCmd = &fakes.Command{}
+
+		upCmd = &fakes.Command{}
+		upCmd.ExecuteCall.PassState = true
+
 		usage = &fakes.Usage{}
 		stateStore = &fakes.StateStore{}
+		stateLocker = &fakes.StateLocker{}
 
 		app = NewAppWithConfiguration(application.Configuration{})
 	})
@@ -98,6 +111,126 @@ var _ = Describe("App", func() {
 			})
 		})
 
+		Context("locking the state", func() {
+			var configuration application.Configuration
+
+			BeforeEach(func() {
+				configuration = application.Configuration{
+					Command: "up",
+					Global: application.GlobalConfiguration{
+						LockTimeout: 5 * time.Minute,
+					},
+					State: storage.State{
+						EnvID: "some-stale-env-id",
+					},
+				}
+
+				stateStore.GetCall.Returns.State = storage.State{
+					EnvID: "some-env-id",
+				}
+			})
+
+			It("locks the state and executes the command with the latest state", func() {
+				app = NewAppWithConfiguration(configuration)
+
+				Expect(app.Run()).To(Succeed())
+
+				Expect(stateLocker.LockCall.CallCount).To(Equal(1))
+				Expect(stateLocker.LockCall.Receives.Command).To(Equal("up"))
+				Expect(stateLocker.LockCall.Receives.Timeout).To(Equal(5 * time.Minute))
+
+				Expect(stateStore.MigrateCall.CallCount).To(Equal(1))
+				Expect(stateStore.GetCall.CallCount).To(Equal(1))
+				Expect(upCmd.ExecuteCall.Receives.State).To(Equal(storage.State{
+					EnvID: "some-env-id",
+				}))
+
+				Expect(stateLocker.UnlockCall.CallCount).To(Equal(1))
+			})
+
+			It("does not lock the state for commands that do not modify it", func() {
+				app = NewAppWithConfiguration(application.Configuration{
+					Command: "some",
+				})
+
+				Expect(app.Run()).To(Succeed())
+
+				Expect(someCmd.ExecuteCall.CallCount).To(Equal(1))
+				Expect(stateLocker.LockCall.CallCount).To(Equal(0))
+				Expect(stateStore.MigrateCall.CallCount).To(Equal(0))
+				Expect(stateStore.GetCall.CallCount).To(Equal(0))
+			})
+
+			It("does not read the state for commands that do not need it", func() {
+				app = NewAppWithConfiguration(application.Configuration{
+					Command:         "restore-state",
+					SubcommandFlags: []string{"1"},
+				})
+
+				Expect(app.Run()).To(Succeed())
+
+				Expect(stateLocker.LockCall.CallCount).To(Equal(1))
+				Expect(stateLocker.LockCall.Receives.Command).To(Equal("restore-state"))
+				Expect(stateStore.MigrateCall.CallCount).To(Equal(0))
+				Expect(stateStore.GetCall.CallCount).To(Equal(0))
+				Expect(restoreStateCmd.ExecuteCall.CallCount).To(Equal(1))
+				Expect(stateLocker.UnlockCall.CallCount).To(Equal(1))
+			})
+
+			It("unlocks the state when the command fails", func() {
+				upCmd.ExecuteCall.Returns.Error = errors.New("failed to execute")
+				app = NewAppWithConfiguration(configuration)
+
+				err := app.Run()
+				Expect(err).To(MatchError("failed to execute"))
+
+				Expect(stateLocker.UnlockCall.CallCount).To(Equal(1))
+			})
+
+			Context("failure cases", func() {
+				It("returns an error and does not execute the command when the state cannot be locked", func() {
+					stateLocker.LockCall.Returns.Error = errors.New("bbl-state.json is locked")
+					app = NewAppWithConfiguration(configuration)
+
+					err := app.Run()
+					Expect(err).To(MatchError("bbl-state.json is locked"))
+
+					Expect(upCmd.ExecuteCall.CallCount).To(Equal(0))
+					Expect(stateLocker.UnlockCall.CallCount).To(Equal(0))
+				})
+
+				It("returns an error when the state cannot be migrated after locking", func() {
+					stateStore.MigrateCall.Returns.Error = errors.New("failed to migrate state")
+					app = NewAppWithConfiguration(configuration)
+
+					err := app.Run()
+					Expect(err).To(MatchError("failed to migrate state"))
+
+					Expect(upCmd.ExecuteCall.CallCount).To(Equal(0))
+					Expect(stateLocker.UnlockCall.CallCount).To(Equal(1))
+				})
+
+				It("returns an error when the state cannot be read after locking", func() {
+					stateStore.GetCall.Returns.Error = errors.New("failed to get state")
+					app = NewAppWithConfiguration(configuration)
+
+					err := app.Run()
+					Expect(err).To(MatchError("failed to get state"))
+
+					Expect(upCmd.ExecuteCall.CallCount).To(Equal(0))
+					Expect(stateLocker.UnlockCall.CallCount).To(Equal(1))
+				})
+
+				It("returns an error when the state cannot be unlocked", func() {
+					stateLocker.UnlockCall.Returns.Error = errors.New("failed to unlock")
+					app = NewAppWithConfiguration(configuration)
+
+					err := app.Run()
+					Expect(err).To(MatchError("failed to unlock"))
+				})
+			})
+		})
+
 		Context("when subcommand flags contains help", func() {
 			DescribeTable("prints command specific usage when help subcommand flag is provided", func(helpFlag string) {
 				someCmd.UsageCall.Returns.Usage = "some usage message"
@@ -175,7 +308,7 @@ var _ = Describe("App", func() {
 					}, application.Configuration{
 						Command:         "some",
 						SubcommandFlags: []string{"-v"},
-					}, storage.Store{}, usage)
+					}, storage.Store{}, stateLocker, usage)
 
 					err := app.Run()
 					Expect(err).To(MatchError("unknown command: version"))
@@ -2,6 +2,23 @@ package application
 
 import "strings"
 
+var globalFlagsWithValues = map[string]bool{
+	"--state-dir":      true,
+	"-state-dir":       true,
+	"--state-key-file": true,
+	"-state-key-file":  true,
+	"--state-backend":  true,
+	"-state-backend":   true,
+	"--lock-timeout":   true,
+	"-lock-timeout":    true,
+	"--state-backups":  true,
+	"-state-backups":   true,
+	"--secret-store":   true,
+	"-secret-store":    true,
+	"--output":         true,
+	"-output":          true,
+}
+
 type CommandFinderResult struct {
 	GlobalFlags []string
 	Command     string
@@ -27,7 +44,7 @@ func (finder commandFinder) FindCommand(input []string) CommandFinderResult {
 	commandFound := false
 	for index, word := range input {
 		if !strings.HasPrefix(word, "-") {
-			if previousCommand != "--state-dir" && previousCommand != "-state-dir" {
+			if !globalFlagsWithValues[previousCommand] {
 				commandIndex = index
 				commandFound = true
 				break
@@ -25,6 +25,21 @@ var _ = Describe("CommandFinder", func() {
 		Entry("parses the first non-hyphenated word as the attempted command if --state-dir=x is provided",
 			[]string{"--state-dir=some-dir", "help", "--other-flag"},
 			application.CommandFinderResult{GlobalFlags: []string{"--state-dir=some-dir"}, Command: "help", OtherArgs: []string{"--other-flag"}}),
+		Entry("parses the first non-hyphenated word as the state-key-file if it directly follows state-key-file",
+			[]string{"--state-key-file", "help", "delete-errthing", "--other-flag"},
+			application.CommandFinderResult{GlobalFlags: []string{"--state-key-file", "help"}, Command: "delete-errthing", OtherArgs: []string{"--other-flag"}}),
+		Entry("parses the first non-hyphenated word as the state-backend if it directly follows state-backend",
+			[]string{"--state-backend", "local", "delete-errthing", "--other-flag"},
+			application.CommandFinderResult{GlobalFlags: []string{"--state-backend", "local"}, Command: "delete-errthing", OtherArgs: []string{"--other-flag"}}),
+		Entry("parses the first non-hyphenated word as the lock-timeout if it directly follows lock-timeout",
+			[]string{"--lock-timeout", "5m", "delete-errthing", "--other-flag"},
+			application.CommandFinderResult{GlobalFlags: []string{"--lock-timeout", "5m"}, Command: "delete-errthing", OtherArgs: []string{"--other-flag"}}),
+		Entry("parses the first non-hyphenated word as the output if it directly follows output",
+			[]string{"--output", "json", "delete-errthing", "--other-flag"},
+			application.CommandFinderResult{GlobalFlags: []string{"--output", "json"}, Command: "delete-errthing", OtherArgs: []string{"--other-flag"}}),
+		Entry("parses the first non-hyphenated word as the secret-store if it directly follows secret-store",
+			[]string{"--secret-store", "https://vault/secret/bbl", "delete-errthing", "--other-flag"},
+			application.CommandFinderResult{GlobalFlags: []string{"--secret-store", "https://vault/secret/bbl"}, Command: "delete-errthing", OtherArgs: []string{"--other-flag"}}),
 		Entry("parses correctly if no global flags given",
 			[]string{"help", "foo", "--other-flag"},
 			application.CommandFinderResult{GlobalFlags: []string{}, Command: "help", OtherArgs: []string{"foo", "--other-flag"}}),
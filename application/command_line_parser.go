@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/flags"
 )
 
@@ -16,6 +18,12 @@ type CommandLineConfiguration struct {
 	SubcommandFlags  []string
 	EndpointOverride string
 	StateDir         string
+	StateKeyFile     string
+	StateBackend     string
+	LockTimeout      time.Duration
+	StateBackups     int
+	SecretStore      string
+	Output           string
 
 	help    bool
 	version bool
@@ -54,7 +62,7 @@ func (p CommandLineParser) Parse(arguments []string) (CommandLineConfiguration,
 	commandLineConfiguration, _, err = p.parseGlobalFlags(commandLineConfiguration, commandFinderResult.GlobalFlags)
 	if err != nil && commandNotFoundError == nil {
 		p.usage()
-		return CommandLineConfiguration{}, err
+		return CommandLineConfiguration{}, UsageError{err}
 	}
 
 	commandLineConfiguration.Command = commandFinderResult.Command
@@ -65,8 +73,10 @@ func (p CommandLineParser) Parse(arguments []string) (CommandLineConfiguration,
 		}
 	}
 	if commandNotFoundError != nil {
-		p.usage()
-		return CommandLineConfiguration{}, commandNotFoundError
+		if commandLineConfiguration.Output != commands.JSONOutput {
+			p.usage()
+		}
+		return CommandLineConfiguration{Output: commandLineConfiguration.Output}, UsageError{commandNotFoundError}
 	}
 
 	commandLineConfiguration, err = p.setDefaultStateDirectory(commandLineConfiguration)
@@ -86,6 +96,12 @@ func (c CommandLineParser) parseGlobalFlags(commandLineConfiguration CommandLine
 
 	globalFlags.String(&commandLineConfiguration.EndpointOverride, "endpoint-override", "")
 	globalFlags.String(&commandLineConfiguration.StateDir, "state-dir", "")
+	globalFlags.String(&commandLineConfiguration.StateKeyFile, "state-key-file", "")
+	globalFlags.String(&commandLineConfiguration.StateBackend, "state-backend", "")
+	globalFlags.Duration(&commandLineConfiguration.LockTimeout, "lock-timeout", 0)
+	globalFlags.Int(&commandLineConfiguration.StateBackups, "state-backups", 0)
+	globalFlags.String(&commandLineConfiguration.SecretStore, "secret-store", "")
+	globalFlags.String(&commandLineConfiguration.Output, "output", commands.TextOutput)
 
 	globalFlags.Bool(&commandLineConfiguration.help, "h", "help", false)
 	globalFlags.Bool(&commandLineConfiguration.version, "v", "version", false)
@@ -95,6 +111,10 @@ func (c CommandLineParser) parseGlobalFlags(commandLineConfiguration CommandLine
 		return CommandLineConfiguration{}, []string{}, err
 	}
 
+	if commandLineConfiguration.Output != commands.TextOutput && commandLineConfiguration.Output != commands.JSONOutput {
+		return CommandLineConfiguration{}, []string{}, fmt.Errorf("%q is an invalid output format, supported values are: [text, json]", commandLineConfiguration.Output)
+	}
+
 	return commandLineConfiguration, globalFlags.Args(), nil
 }
 
@@ -3,6 +3,7 @@ package application_test
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/cloudfoundry/bosh-bootloader/application"
 	"github.com/cloudfoundry/bosh-bootloader/commands"
@@ -36,6 +37,12 @@ var _ = Describe("CommandLineParser", func() {
 			args := []string{
 				"--endpoint-override=some-endpoint-override",
 				"--state-dir", "some/state/dir",
+				"--state-key-file", "some/state/key/file",
+				"--state-backend", "s3://some-bucket/some-path",
+				"--lock-timeout", "5m",
+				"--state-backups", "20",
+				"--secret-store", "https://vault.example.com/secret/bbl",
+				"--output", "json",
 				"up",
 				"--subcommand-flag", "some-value",
 			}
@@ -44,6 +51,13 @@ var _ = Describe("CommandLineParser", func() {
 
 			Expect(commandLineConfiguration.EndpointOverride).To(Equal("some-endpoint-override"))
 			Expect(commandLineConfiguration.StateDir).To(Equal("some/state/dir"))
+			Expect(commandLineConfiguration.StateKeyFile).To(Equal("some/state/key/file"))
+			Expect(commandLineConfiguration.StateBackend).To(Equal("s3://some-bucket/some-path"))
+			Expect(commandLineConfiguration.LockTimeout).To(Equal(5 * time.Minute))
+			Expect(commandLineConfiguration.StateBackups).To(Equal(20))
+			Expect(commandLineConfiguration.SecretStore).To(Equal("https://vault.example.com/secret/bbl"))
+			Expect(commandLineConfiguration.Output).To(Equal("json"))
+			Expect(commandLineConfiguration.Command).To(Equal("up"))
 		})
 
 		It("returns a command line configuration with correct command with subcommand flags based on arguments passed in", func() {
@@ -135,7 +149,7 @@ var _ = Describe("CommandLineParser", func() {
 					"up",
 				})
 
-				Expect(err).To(Equal(errors.New("flag provided but not defined: -invalid-flag")))
+				Expect(err).To(Equal(application.UsageError{Err: errors.New("flag provided but not defined: -invalid-flag")}))
 				Expect(usageCallCount).To(Equal(1))
 			})
 
@@ -145,10 +159,31 @@ var _ = Describe("CommandLineParser", func() {
 					"badcmd",
 				})
 
-				Expect(err).To(Equal(errors.New("Unrecognized command 'badcmd'")))
+				Expect(err).To(Equal(application.UsageError{Err: errors.New("Unrecognized command 'badcmd'")}))
 				Expect(usageCallCount).To(Equal(1))
 			})
 
+			It("returns an error when the output format is not supported", func() {
+				_, err := commandLineParser.Parse([]string{
+					"--output", "yaml",
+					"up",
+				})
+
+				Expect(err).To(MatchError(`"yaml" is an invalid output format, supported values are: [text, json]`))
+				Expect(err).To(BeAssignableToTypeOf(application.UsageError{}))
+			})
+
+			It("does not print usage for an unknown command in json mode", func() {
+				commandLineConfiguration, err := commandLineParser.Parse([]string{
+					"--output", "json",
+					"badcmd",
+				})
+
+				Expect(err).To(MatchError("Unrecognized command 'badcmd'"))
+				Expect(commandLineConfiguration.Output).To(Equal("json"))
+				Expect(usageCallCount).To(Equal(0))
+			})
+
 			It("returns an error when it cannot get working directory", func() {
 				application.SetGetwd(func() (string, error) {
 					return "", errors.New("failed to get working directory")
@@ -166,7 +201,7 @@ var _ = Describe("CommandLineParser", func() {
 					"--badflag", "x", "help", "delete-lbs", "--other-flag",
 				})
 
-				Expect(err).To(Equal(errors.New("Unrecognized command 'x'")))
+				Expect(err).To(Equal(application.UsageError{Err: errors.New("Unrecognized command 'x'")}))
 				Expect(usageCallCount).To(Equal(1))
 			})
 		})
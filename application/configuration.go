@@ -1,10 +1,21 @@
 package application
 
-import "github.com/cloudfoundry/bosh-bootloader/storage"
+import (
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
 
 type GlobalConfiguration struct {
 	EndpointOverride string
 	StateDir         string
+	StateKey         string
+	StateBackend     string
+	LockTimeout      time.Duration
+	StateBackups     int
+	SecretStore      string
+	SecretStoreToken string
+	Output           string
 }
 
 type StringSlice []string
@@ -1,15 +1,44 @@
 package application
 
-import "github.com/cloudfoundry/bosh-bootloader/storage"
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
-var getState func(string) (storage.State, error) = storage.GetState
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+var (
+	getState       func(storage.Backend, string) (storage.State, error) = storage.GetState
+	newBackend     func(string, string) (storage.Backend, error)        = storage.NewBackend
+	newSecretStore func(string, string) (storage.SecretStore, error)    = storage.NewSecretStore
+	getenv         func(string) string                                  = os.Getenv
+	readFile       func(string) ([]byte, error)                         = ioutil.ReadFile
+)
+
+var commandsWithoutState = map[string]bool{
+	commands.ForceUnlockCommand:  true,
+	commands.StateHistoryCommand: true,
+	commands.RestoreStateCommand: true,
+}
 
 type commandLineParser interface {
 	Parse(arguments []string) (CommandLineConfiguration, error)
 }
 
 type stateStore interface {
+	Get() (storage.State, error)
 	Set(state storage.State) error
+	Migrate() error
+}
+
+type stateLocker interface {
+	Lock(command string, timeout time.Duration) error
+	Unlock() error
 }
 
 type ConfigurationParser struct {
@@ -25,12 +54,16 @@ func NewConfigurationParser(commandLineParser commandLineParser) ConfigurationPa
 func (p ConfigurationParser) Parse(arguments []string) (Configuration, error) {
 	commandLineConfiguration, err := p.commandLineParser.Parse(arguments)
 	if err != nil {
-		return Configuration{}, err
+		return Configuration{Global: GlobalConfiguration{Output: commandLineConfiguration.Output}}, err
 	}
 
 	configuration := Configuration{
 		Global: GlobalConfiguration{
 			StateDir:         commandLineConfiguration.StateDir,
+			StateBackend:     commandLineConfiguration.StateBackend,
+			LockTimeout:      commandLineConfiguration.LockTimeout,
+			SecretStore:      commandLineConfiguration.SecretStore,
+			Output:           commandLineConfiguration.Output,
 			EndpointOverride: commandLineConfiguration.EndpointOverride,
 		},
 		Command:         commandLineConfiguration.Command,
@@ -38,16 +71,90 @@ func (p ConfigurationParser) Parse(arguments []string) (Configuration, error) {
 		State:           storage.State{},
 	}
 
-	if !p.isHelpOrVersion(configuration.Command, configuration.SubcommandFlags) {
-		configuration.State, err = getState(configuration.Global.StateDir)
+	configuration.Global.StateKey, err = p.stateKey(commandLineConfiguration.StateKeyFile)
+	if err != nil {
+		return Configuration{Global: GlobalConfiguration{Output: configuration.Global.Output}}, err
+	}
+
+	configuration.Global.StateBackups, err = p.stateBackups(commandLineConfiguration.StateBackups)
+	if err != nil {
+		return Configuration{Global: GlobalConfiguration{Output: configuration.Global.Output}}, err
+	}
+
+	if configuration.Global.StateBackend == "" {
+		configuration.Global.StateBackend = getenv("BBL_STATE_BACKEND")
+	}
+
+	if configuration.Global.SecretStore == "" {
+		configuration.Global.SecretStore = getenv("BBL_SECRET_STORE")
+	}
+	configuration.Global.SecretStoreToken = getenv("VAULT_TOKEN")
+
+	if !p.isHelpOrVersion(configuration.Command, configuration.SubcommandFlags) && !commandsWithoutState[configuration.Command] {
+		backend, err := newBackend(configuration.Global.StateDir, configuration.Global.StateBackend)
 		if err != nil {
-			return Configuration{}, err
+			return Configuration{Global: GlobalConfiguration{Output: configuration.Global.Output}}, err
+		}
+
+		configuration.State, err = getState(backend, configuration.Global.StateKey)
+		if err != nil {
+			return Configuration{Global: GlobalConfiguration{Output: configuration.Global.Output}}, err
+		}
+
+		if configuration.Global.SecretStore == "" {
+			configuration.Global.SecretStore = configuration.State.SecretStore
+		}
+
+		secretStore, err := newSecretStore(configuration.Global.SecretStore, configuration.Global.SecretStoreToken)
+		if err != nil {
+			return Configuration{Global: GlobalConfiguration{Output: configuration.Global.Output}}, err
+		}
+
+		configuration.State, err = storage.ResolveSecrets(configuration.State, secretStore)
+		if err != nil {
+			return Configuration{Global: GlobalConfiguration{Output: configuration.Global.Output}}, err
 		}
 	}
 
 	return configuration, nil
 }
 
+func (ConfigurationParser) stateKey(stateKeyFile string) (string, error) {
+	if stateKeyFile == "" {
+		stateKeyFile = getenv("BBL_STATE_KEY_FILE")
+	}
+
+	if stateKeyFile == "" {
+		return getenv("BBL_STATE_KEY"), nil
+	}
+
+	key, err := readFile(stateKeyFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading state key file: %v", err)
+	}
+
+	return strings.TrimSpace(string(key)), nil
+}
+
+func (ConfigurationParser) stateBackups(stateBackups int) (int, error) {
+	if stateBackups == 0 && getenv("BBL_STATE_BACKUPS") != "" {
+		var err error
+		stateBackups, err = strconv.Atoi(getenv("BBL_STATE_BACKUPS"))
+		if err != nil {
+			return 0, fmt.Errorf("BBL_STATE_BACKUPS must be a number: %s", err)
+		}
+	}
+
+	switch {
+	case stateBackups == 0:
+		return storage.DefaultStateBackups, nil
+	case stateBackups < 0:
+		return 0, fmt.Errorf("the number of state backups must be at least 1, got %d", stateBackups)
+	}
+
+	return stateBackups, nil
+}
+
 func (ConfigurationParser) isHelpOrVersion(command string, subcommandFlags StringSlice) bool {
 	if command == "help" || command == "version" {
 		return true
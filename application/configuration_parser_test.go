@@ -2,6 +2,8 @@ package application_test
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/cloudfoundry/bosh-bootloader/application"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
@@ -20,7 +22,7 @@ var _ = Describe("ConfigurationParser", func() {
 		commandLineParser = &fakes.CommandLineParser{}
 		configurationParser = application.NewConfigurationParser(commandLineParser)
 
-		application.SetGetState(func(dir string) (storage.State, error) {
+		application.SetGetState(func(backend storage.Backend, stateKey string) (storage.State, error) {
 			return storage.State{Version: 1}, nil
 		})
 	})
@@ -37,6 +39,9 @@ var _ = Describe("ConfigurationParser", func() {
 				SubcommandFlags:  []string{"--some-flag", "some-value"},
 				StateDir:         "some/state/dir",
 				EndpointOverride: "some-endpoint-override",
+				LockTimeout:      5 * time.Minute,
+				StateBackups:     20,
+				Output:           "json",
 			}
 			configuration, err := configurationParser.Parse([]string{"up"})
 			Expect(err).NotTo(HaveOccurred())
@@ -46,11 +51,63 @@ var _ = Describe("ConfigurationParser", func() {
 			Expect(configuration.Global).To(Equal(application.GlobalConfiguration{
 				EndpointOverride: "some-endpoint-override",
 				StateDir:         "some/state/dir",
+				LockTimeout:      5 * time.Minute,
+				StateBackups:     20,
+				Output:           "json",
 			}))
 
 			Expect(commandLineParser.ParseCall.Receives.Arguments).To(Equal([]string{"up"}))
 		})
 
+		Describe("state backups", func() {
+			It("defaults to ten backups", func() {
+				commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+					Command: "up",
+				}
+				configuration, err := configurationParser.Parse([]string{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(configuration.Global.StateBackups).To(Equal(10))
+			})
+
+			It("falls back to the BBL_STATE_BACKUPS environment variable", func() {
+				application.SetGetenv(func(name string) string {
+					return map[string]string{"BBL_STATE_BACKUPS": "3"}[name]
+				})
+				defer application.ResetGetenv()
+
+				commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+					Command: "up",
+				}
+				configuration, err := configurationParser.Parse([]string{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(configuration.Global.StateBackups).To(Equal(3))
+			})
+
+			It("returns an error when BBL_STATE_BACKUPS is not a number", func() {
+				application.SetGetenv(func(name string) string {
+					return map[string]string{"BBL_STATE_BACKUPS": "some-number"}[name]
+				})
+				defer application.ResetGetenv()
+
+				commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+					Command: "up",
+				}
+				_, err := configurationParser.Parse([]string{})
+				Expect(err).To(MatchError(ContainSubstring("BBL_STATE_BACKUPS must be a number")))
+			})
+
+			It("returns an error when the number of backups is negative", func() {
+				commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+					Command:      "up",
+					StateBackups: -1,
+				}
+				_, err := configurationParser.Parse([]string{})
+				Expect(err).To(MatchError("the number of state backups must be at least 1, got -1"))
+			})
+		})
+
 		Describe("state management", func() {
 			It("returns a configuration with the state from the state store", func() {
 				commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
@@ -65,13 +122,237 @@ var _ = Describe("ConfigurationParser", func() {
 				}))
 			})
 
+			Context("when a state key file is provided", func() {
+				BeforeEach(func() {
+					application.SetReadFile(func(path string) ([]byte, error) {
+						return []byte(fmt.Sprintf("key-from-%s\n", path)), nil
+					})
+				})
+
+				AfterEach(func() {
+					application.ResetReadFile()
+				})
+
+				It("reads the state with the key from the file", func() {
+					var receivedStateKey string
+					application.SetGetState(func(backend storage.Backend, stateKey string) (storage.State, error) {
+						receivedStateKey = stateKey
+						return storage.State{}, nil
+					})
+
+					commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+						Command:      "up",
+						StateKeyFile: "some-state-key-file",
+					}
+					configuration, err := configurationParser.Parse([]string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(configuration.Global.StateKey).To(Equal("key-from-some-state-key-file"))
+					Expect(receivedStateKey).To(Equal("key-from-some-state-key-file"))
+				})
+
+				It("falls back to the BBL_STATE_KEY_FILE environment variable", func() {
+					application.SetGetenv(func(name string) string {
+						return map[string]string{"BBL_STATE_KEY_FILE": "some-env-state-key-file"}[name]
+					})
+					defer application.ResetGetenv()
+
+					commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+						Command: "up",
+					}
+					configuration, err := configurationParser.Parse([]string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(configuration.Global.StateKey).To(Equal("key-from-some-env-state-key-file"))
+				})
+
+				It("returns an error when the state key file cannot be read", func() {
+					application.SetReadFile(func(path string) ([]byte, error) {
+						return nil, errors.New("failed to read file")
+					})
+
+					commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+						Command:      "up",
+						StateKeyFile: "some-state-key-file",
+					}
+					_, err := configurationParser.Parse([]string{})
+					Expect(err).To(MatchError("error reading state key file: failed to read file"))
+				})
+			})
+
+			It("reads the state from the backend for the state dir and state backend", func() {
+				var (
+					receivedStateDir     string
+					receivedStateBackend string
+					receivedBackend      storage.Backend
+				)
+				application.SetNewBackend(func(stateDir, stateBackend string) (storage.Backend, error) {
+					receivedStateDir = stateDir
+					receivedStateBackend = stateBackend
+					return storage.NewLocalBackend("some-backend-dir"), nil
+				})
+				defer application.ResetNewBackend()
+
+				application.SetGetState(func(backend storage.Backend, stateKey string) (storage.State, error) {
+					receivedBackend = backend
+					return storage.State{}, nil
+				})
+
+				commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+					Command:      "up",
+					StateDir:     "some/state/dir",
+					StateBackend: "s3://some-bucket/some-path",
+				}
+				configuration, err := configurationParser.Parse([]string{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(configuration.Global.StateBackend).To(Equal("s3://some-bucket/some-path"))
+				Expect(receivedStateDir).To(Equal("some/state/dir"))
+				Expect(receivedStateBackend).To(Equal("s3://some-bucket/some-path"))
+				Expect(receivedBackend).To(Equal(storage.NewLocalBackend("some-backend-dir")))
+			})
+
+			It("falls back to the BBL_STATE_BACKEND environment variable", func() {
+				application.SetGetenv(func(name string) string {
+					return map[string]string{"BBL_STATE_BACKEND": "s3://some-env-bucket"}[name]
+				})
+				defer application.ResetGetenv()
+
+				commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+					Command: "up",
+				}
+				configuration, err := configurationParser.Parse([]string{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(configuration.Global.StateBackend).To(Equal("s3://some-env-bucket"))
+			})
+
+			It("uses the key from the BBL_STATE_KEY environment variable", func() {
+				application.SetGetenv(func(name string) string {
+					return map[string]string{"BBL_STATE_KEY": "some-env-state-key"}[name]
+				})
+				defer application.ResetGetenv()
+
+				commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+					Command: "up",
+				}
+				configuration, err := configurationParser.Parse([]string{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(configuration.Global.StateKey).To(Equal("some-env-state-key"))
+			})
+
+			Describe("secret store", func() {
+				var (
+					secretStore              *fakes.SecretStore
+					receivedSecretStore      string
+					receivedSecretStoreToken string
+				)
+
+				BeforeEach(func() {
+					secretStore = &fakes.SecretStore{}
+					secretStore.ReadCall.Returns.Secrets = map[string]string{
+						"directorPassword": "some-director-password",
+					}
+
+					application.SetNewSecretStore(func(location, token string) (storage.SecretStore, error) {
+						receivedSecretStore = location
+						receivedSecretStoreToken = token
+						return secretStore, nil
+					})
+
+					application.SetGetState(func(backend storage.Backend, stateKey string) (storage.State, error) {
+						return storage.State{
+							SecretStore: "https://vault/secret/from-state",
+							BOSH: storage.BOSH{
+								DirectorPassword: "secret-store:directorPassword",
+							},
+						}, nil
+					})
+
+					application.SetGetenv(func(name string) string {
+						return map[string]string{
+							"BBL_SECRET_STORE": "https://vault/secret/from-env",
+							"VAULT_TOKEN":      "some-vault-token",
+						}[name]
+					})
+				})
+
+				AfterEach(func() {
+					application.ResetNewSecretStore()
+					application.ResetGetenv()
+				})
+
+				It("resolves secret references in the state", func() {
+					commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+						Command:     "director-password",
+						SecretStore: "https://vault/secret/from-flag",
+					}
+					configuration, err := configurationParser.Parse([]string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(configuration.Global.SecretStore).To(Equal("https://vault/secret/from-flag"))
+					Expect(configuration.Global.SecretStoreToken).To(Equal("some-vault-token"))
+					Expect(receivedSecretStore).To(Equal("https://vault/secret/from-flag"))
+					Expect(receivedSecretStoreToken).To(Equal("some-vault-token"))
+					Expect(configuration.State.BOSH.DirectorPassword).To(Equal("some-director-password"))
+				})
+
+				It("falls back to the BBL_SECRET_STORE environment variable", func() {
+					commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+						Command: "director-password",
+					}
+					configuration, err := configurationParser.Parse([]string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(configuration.Global.SecretStore).To(Equal("https://vault/secret/from-env"))
+				})
+
+				It("falls back to the secret store recorded in the state", func() {
+					application.SetGetenv(func(name string) string {
+						return map[string]string{"VAULT_TOKEN": "some-vault-token"}[name]
+					})
+
+					commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+						Command: "director-password",
+					}
+					configuration, err := configurationParser.Parse([]string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(configuration.Global.SecretStore).To(Equal("https://vault/secret/from-state"))
+					Expect(configuration.State.BOSH.DirectorPassword).To(Equal("some-director-password"))
+				})
+
+				It("returns an error when the secret store is invalid", func() {
+					application.SetNewSecretStore(func(string, string) (storage.SecretStore, error) {
+						return nil, errors.New("invalid secret store")
+					})
+
+					commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+						Command: "director-password",
+					}
+					_, err := configurationParser.Parse([]string{})
+					Expect(err).To(MatchError("invalid secret store"))
+				})
+
+				It("returns an error when the secrets cannot be resolved", func() {
+					secretStore.ReadCall.Returns.Error = errors.New("failed to read secrets")
+
+					commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+						Command: "director-password",
+					}
+					_, err := configurationParser.Parse([]string{})
+					Expect(err).To(MatchError("failed to read secrets"))
+				})
+			})
+
 			DescribeTable("help, version, help flags does not try parse state", func(command string, subcommandFlags []string) {
 				commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
 					Command:         command,
 					SubcommandFlags: application.StringSlice(subcommandFlags),
 				}
 
-				application.SetGetState(func(dir string) (storage.State, error) {
+				application.SetGetState(func(backend storage.Backend, stateKey string) (storage.State, error) {
 					return storage.State{}, errors.New("State Error")
 				})
 
@@ -84,6 +365,9 @@ var _ = Describe("ConfigurationParser", func() {
 				Entry("version", "version", []string{}),
 				Entry("--version", "some-command", []string{"--version"}),
 				Entry("-v", "some-command", []string{"-v"}),
+				Entry("force-unlock", "force-unlock", []string{}),
+				Entry("state-history", "state-history", []string{}),
+				Entry("restore-state", "restore-state", []string{"1"}),
 			)
 		})
 
@@ -95,8 +379,34 @@ var _ = Describe("ConfigurationParser", func() {
 				Expect(err).To(MatchError("failed to parse command line"))
 			})
 
+			It("keeps the output format when returning an error", func() {
+				commandLineParser.ParseCall.Returns.CommandLineConfiguration = application.CommandLineConfiguration{
+					Command: "some-command",
+					Output:  "json",
+				}
+				application.SetGetState(func(backend storage.Backend, stateKey string) (storage.State, error) {
+					return storage.State{}, errors.New("failed to read state")
+				})
+
+				configuration, err := configurationParser.Parse([]string{"some-command"})
+
+				Expect(err).To(MatchError("failed to read state"))
+				Expect(configuration.Global.Output).To(Equal("json"))
+			})
+
+			It("returns an error when the state backend is invalid", func() {
+				application.SetNewBackend(func(string, string) (storage.Backend, error) {
+					return nil, errors.New("invalid state backend")
+				})
+				defer application.ResetNewBackend()
+
+				_, err := configurationParser.Parse([]string{"some-command"})
+
+				Expect(err).To(MatchError("invalid state backend"))
+			})
+
 			It("returns an error when the state cannot be read", func() {
-				application.SetGetState(func(dir string) (storage.State, error) {
+				application.SetGetState(func(backend storage.Backend, stateKey string) (storage.State, error) {
 					return storage.State{}, errors.New("failed to read state")
 				})
 
@@ -12,16 +12,24 @@ func NewCredentialValidator(configuration Configuration) CredentialValidator {
 	}
 }
 
+// ValidateAWS allows the access keys to be left out, in which case the
+// credentials come from the profile or the default AWS credential chain.
 func (c CredentialValidator) ValidateAWS() error {
-	if c.configuration.State.AWS.AccessKeyID == "" {
+	aws := c.configuration.State.AWS
+
+	if aws.AccessKeyID == "" && aws.SecretAccessKey != "" {
 		return errors.New("AWS access key ID must be provided")
 	}
 
-	if c.configuration.State.AWS.SecretAccessKey == "" {
+	if aws.SecretAccessKey == "" && aws.AccessKeyID != "" {
 		return errors.New("AWS secret access key must be provided")
 	}
 
-	if c.configuration.State.AWS.Region == "" {
+	if aws.ExternalID != "" && aws.AssumeRoleARN == "" {
+		return errors.New("AWS external ID can only be used with an assume role ARN")
+	}
+
+	if aws.Region == "" {
 		return errors.New("AWS region must be provided")
 	}
 
@@ -25,6 +25,31 @@ var _ = Describe("CredentialValidator", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("allows the aws access keys to come from a profile or the environment", func() {
+			credentialValidator = application.NewCredentialValidator(application.Configuration{
+				State: storage.State{
+					AWS: storage.AWS{
+						Profile:       "some-profile",
+						AssumeRoleARN: "some-role-arn",
+						ExternalID:    "some-external-id",
+						Region:        "some-region",
+					},
+				},
+			})
+			err := credentialValidator.ValidateAWS()
+			Expect(err).NotTo(HaveOccurred())
+
+			credentialValidator = application.NewCredentialValidator(application.Configuration{
+				State: storage.State{
+					AWS: storage.AWS{
+						Region: "some-region",
+					},
+				},
+			})
+			err = credentialValidator.ValidateAWS()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		It("validates that the gcp credentials have been set", func() {
 			credentialValidator = application.NewCredentialValidator(application.Configuration{
 				State: storage.State{
@@ -77,6 +102,18 @@ var _ = Describe("CredentialValidator", func() {
 					})
 					Expect(credentialValidator.ValidateAWS()).To(MatchError("AWS region must be provided"))
 				})
+
+				It("returns an error when the external id is provided without a role arn", func() {
+					credentialValidator = application.NewCredentialValidator(application.Configuration{
+						State: storage.State{
+							AWS: storage.AWS{
+								ExternalID: "some-external-id",
+								Region:     "some-region",
+							},
+						},
+					})
+					Expect(credentialValidator.ValidateAWS()).To(MatchError("AWS external ID can only be used with an assume role ARN"))
+				})
 			})
 
 			Context("gcp validator", func() {
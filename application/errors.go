@@ -0,0 +1,95 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	ExitCodeError                   = 1
+	ExitCodeUsage                   = 2
+	ExitCodeNotFound                = 3
+	ExitCodeStateLocked             = 4
+	ExitCodeInsufficientPermissions = 5
+)
+
+type UsageError struct {
+	Err error
+}
+
+func (e UsageError) Error() string {
+	return e.Err.Error()
+}
+
+type StateNotFoundError struct {
+	Location string
+}
+
+func (e StateNotFoundError) Error() string {
+	return fmt.Sprintf("bbl-state.json not found in %q, ensure you're running this command in the proper state directory or create a new environment with bbl up", e.Location)
+}
+
+type InsufficientPermissionsError struct {
+	Command string
+	Message string
+}
+
+func (e InsufficientPermissionsError) Error() string {
+	return fmt.Sprintf(
+		"The AWS credentials provided have insufficient permissions to perform the operation `bbl %s`.\nPlease refer to the bbl README:\nhttps://github.com/cloudfoundry/bosh-bootloader#configure-aws.\nOriginal error message from AWS:\n\n%s",
+		e.Command, e.Message)
+}
+
+type jsonError struct {
+	Error jsonErrorDetails `json:"error"`
+}
+
+type jsonErrorDetails struct {
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	ExitCode int    `json:"exitCode"`
+}
+
+func ClassifyError(err error) (string, int) {
+	switch err.(type) {
+	case UsageError:
+		return "usage", ExitCodeUsage
+	case StateNotFoundError:
+		return "state_not_found", ExitCodeNotFound
+	case storage.LockedError:
+		return "state_locked", ExitCodeStateLocked
+	case InsufficientPermissionsError:
+		return "insufficient_permissions", ExitCodeInsufficientPermissions
+	}
+
+	switch err {
+	case commands.BBLNotFound:
+		return "bbl_not_found", ExitCodeNotFound
+	case commands.LBNotFound:
+		return "lb_not_found", ExitCodeNotFound
+	}
+
+	return "error", ExitCodeError
+}
+
+func PrintError(w io.Writer, err error, output string) int {
+	if output != commands.JSONOutput {
+		fmt.Fprintf(w, "\n\n%s\n", err)
+		return ExitCodeError
+	}
+
+	code, exitCode := ClassifyError(err)
+	json.NewEncoder(w).Encode(jsonError{
+		Error: jsonErrorDetails{
+			Code:     code,
+			Message:  err.Error(),
+			ExitCode: exitCode,
+		},
+	})
+
+	return exitCode
+}
@@ -0,0 +1,55 @@
+package application_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/application"
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Errors", func() {
+	Describe("PrintError", func() {
+		var stderr *bytes.Buffer
+
+		BeforeEach(func() {
+			stderr = &bytes.Buffer{}
+		})
+
+		It("prints the error as text and exits 1 in text mode", func() {
+			exitCode := application.PrintError(stderr, application.StateNotFoundError{Location: "some-dir"}, commands.TextOutput)
+
+			Expect(exitCode).To(Equal(1))
+			Expect(stderr.String()).To(Equal("\n\nbbl-state.json not found in \"some-dir\", ensure you're running this command in the proper state directory or create a new environment with bbl up\n"))
+		})
+
+		DescribeTable("prints a json error with a distinct exit code in json mode", func(err error, code string, exitCode int) {
+			Expect(application.PrintError(stderr, err, commands.JSONOutput)).To(Equal(exitCode))
+			var printed struct {
+				Error struct {
+					Code     string `json:"code"`
+					Message  string `json:"message"`
+					ExitCode int    `json:"exitCode"`
+				} `json:"error"`
+			}
+			Expect(json.Unmarshal(stderr.Bytes(), &printed)).To(Succeed())
+			Expect(printed.Error.Code).To(Equal(code))
+			Expect(printed.Error.Message).To(Equal(err.Error()))
+			Expect(printed.Error.ExitCode).To(Equal(exitCode))
+		},
+			Entry("generic errors", errors.New("some-error"), "error", 1),
+			Entry("usage errors", application.UsageError{Err: errors.New("some-usage-error")}, "usage", 2),
+			Entry("missing state", application.StateNotFoundError{Location: "some-dir"}, "state_not_found", 3),
+			Entry("missing environment", commands.BBLNotFound, "bbl_not_found", 3),
+			Entry("missing load balancers", commands.LBNotFound, "lb_not_found", 3),
+			Entry("locked state", storage.LockedError{Lock: storage.Lock{Holder: "some-user"}}, "state_locked", 4),
+			Entry("insufficient permissions", application.InsufficientPermissionsError{Command: "up", Message: "some-message"}, "insufficient_permissions", 5),
+		)
+	})
+})
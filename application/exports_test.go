@@ -1,6 +1,7 @@
 package application
 
 import (
+	"io/ioutil"
 	"os"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
@@ -14,10 +15,42 @@ func ResetGetwd() {
 	getwd = os.Getwd
 }
 
-func SetGetState(f func(string) (storage.State, error)) {
+func SetGetState(f func(storage.Backend, string) (storage.State, error)) {
 	getState = f
 }
 
 func ResetGetState() {
 	getState = storage.GetState
 }
+
+func SetNewBackend(f func(string, string) (storage.Backend, error)) {
+	newBackend = f
+}
+
+func ResetNewBackend() {
+	newBackend = storage.NewBackend
+}
+
+func SetNewSecretStore(f func(string, string) (storage.SecretStore, error)) {
+	newSecretStore = f
+}
+
+func ResetNewSecretStore() {
+	newSecretStore = storage.NewSecretStore
+}
+
+func SetGetenv(f func(string) string) {
+	getenv = f
+}
+
+func ResetGetenv() {
+	getenv = os.Getenv
+}
+
+func SetReadFile(f func(string) ([]byte, error)) {
+	readFile = f
+}
+
+func ResetReadFile() {
+	readFile = ioutil.ReadFile
+}
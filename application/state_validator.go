@@ -1,26 +1,26 @@
 package application
 
-import (
-	"fmt"
-	"os"
-	"path/filepath"
-)
+import "github.com/cloudfoundry/bosh-bootloader/storage"
 
 type StateValidator struct {
-	stateDir string
+	backend storage.Backend
 }
 
-func NewStateValidator(stateDir string) StateValidator {
-	return StateValidator{stateDir: stateDir}
+func NewStateValidator(backend storage.Backend) StateValidator {
+	return StateValidator{backend: backend}
 }
 
 func (s StateValidator) Validate() error {
-	_, err := os.Stat(filepath.Join(s.stateDir, "bbl-state.json"))
-	if os.IsNotExist(err) {
-		return fmt.Errorf("bbl-state.json not found in %q, ensure you're running this command in the proper state directory or create a new environment with bbl up", s.stateDir)
-	}
-	if err != nil {
-		return err
+	for _, name := range []string{storage.StateFileName, storage.LegacyStateFileName} {
+		exists, err := s.backend.Exists(name)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return nil
+		}
 	}
-	return nil
+
+	return StateNotFoundError{Location: s.backend.Location()}
 }
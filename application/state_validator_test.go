@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/cloudfoundry/bosh-bootloader/application"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -23,7 +24,7 @@ var _ = Describe("StateValidator", func() {
 		tempDirectory, err = ioutil.TempDir("", "")
 		Expect(err).NotTo(HaveOccurred())
 
-		stateValidator = application.NewStateValidator(tempDirectory)
+		stateValidator = application.NewStateValidator(storage.NewLocalBackend(tempDirectory))
 	})
 
 	It("returns no error when state file exists", func() {
@@ -34,10 +35,19 @@ var _ = Describe("StateValidator", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("returns no error when only the state.json of an older bbl exists", func() {
+		err := ioutil.WriteFile(filepath.Join(tempDirectory, "state.json"), []byte(""), os.ModePerm)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = stateValidator.Validate()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
 	It("returns an error when state file cannot be found", func() {
 		err := stateValidator.Validate()
 		expectedError := fmt.Errorf("bbl-state.json not found in %q, ensure you're running this command in the proper state directory or create a new environment with bbl up", tempDirectory)
-		Expect(err).To(MatchError(expectedError))
+		Expect(err).To(MatchError(expectedError.Error()))
+		Expect(err).To(Equal(application.StateNotFoundError{Location: tempDirectory}))
 	})
 
 	Context("failure cases", func() {
@@ -13,6 +13,9 @@ type Client interface {
 	DescribeStacks(input *awscloudformation.DescribeStacksInput) (*awscloudformation.DescribeStacksOutput, error)
 	DeleteStack(input *awscloudformation.DeleteStackInput) (*awscloudformation.DeleteStackOutput, error)
 	DescribeStackResource(input *awscloudformation.DescribeStackResourceInput) (*awscloudformation.DescribeStackResourceOutput, error)
+	CreateChangeSet(input *awscloudformation.CreateChangeSetInput) (*awscloudformation.CreateChangeSetOutput, error)
+	DescribeChangeSet(input *awscloudformation.DescribeChangeSetInput) (*awscloudformation.DescribeChangeSetOutput, error)
+	DeleteChangeSet(input *awscloudformation.DeleteChangeSetInput) (*awscloudformation.DeleteChangeSetOutput, error)
 }
 
 func NewClient(config aws.Config) Client {
@@ -0,0 +1,11 @@
+package cloudformation
+
+import "time"
+
+func SetChangeSetTimeout(timeout time.Duration) {
+	changeSetTimeout = timeout
+}
+
+func ResetChangeSetTimeout() {
+	changeSetTimeout = 10 * time.Minute
+}
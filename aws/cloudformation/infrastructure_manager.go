@@ -11,7 +11,7 @@ import (
 const bblTagKey = "bbl-env-id"
 
 type templateBuilder interface {
-	Build(keypairName string, numberOfAvailabilityZones int, lbType string, lbCertificateARN string, iamUserName string, envID string) templates.Template
+	Build(keypairName string, numberOfAvailabilityZones int, lbType string, lbCertificateARN string, iamUserName string, envID string, network templates.Network) templates.Template
 }
 
 type stackManager interface {
@@ -21,6 +21,7 @@ type stackManager interface {
 	Describe(stackName string) (Stack, error)
 	Delete(stackName string) error
 	GetPhysicalIDForResource(stackName string, logicalResourceID string) (string, error)
+	PlanChanges(stackName string, template templates.Template, tags Tags, sleepInterval time.Duration) ([]ResourceChange, error)
 }
 
 type InfrastructureManager struct {
@@ -36,7 +37,7 @@ func NewInfrastructureManager(builder templateBuilder, stackManager stackManager
 }
 
 func (m InfrastructureManager) Create(keyPairName string, numberOfAvailabilityZones int, stackName,
-	lbType, lbCertificateARN, envID string) (Stack, error) {
+	lbType, lbCertificateARN, envID string, network templates.Network, patches []templates.Patch) (Stack, error) {
 
 	iamUserName := generateIAMUserName(envID)
 
@@ -52,7 +53,11 @@ func (m InfrastructureManager) Create(keyPairName string, numberOfAvailabilityZo
 		}
 	}
 
-	template := m.templateBuilder.Build(keyPairName, numberOfAvailabilityZones, lbType, lbCertificateARN, iamUserName, envID)
+	template, err := m.templateBuilder.Build(keyPairName, numberOfAvailabilityZones, lbType, lbCertificateARN, iamUserName, envID, network).Patch(patches...)
+	if err != nil {
+		return Stack{}, err
+	}
+
 	tags := Tags{
 		{
 			Key:   bblTagKey,
@@ -72,14 +77,17 @@ func (m InfrastructureManager) Create(keyPairName string, numberOfAvailabilityZo
 }
 
 func (m InfrastructureManager) Update(keyPairName string, numberOfAvailabilityZones int, stackName, lbType,
-	lbCertificateARN, envID string) (Stack, error) {
+	lbCertificateARN, envID string, network templates.Network, patches []templates.Patch) (Stack, error) {
 
 	iamUserName, err := m.stackManager.GetPhysicalIDForResource(stackName, "BOSHUser")
 	if err != nil {
 		return Stack{}, err
 	}
 
-	template := m.templateBuilder.Build(keyPairName, numberOfAvailabilityZones, lbType, lbCertificateARN, iamUserName, envID)
+	template, err := m.templateBuilder.Build(keyPairName, numberOfAvailabilityZones, lbType, lbCertificateARN, iamUserName, envID, network).Patch(patches...)
+	if err != nil {
+		return Stack{}, err
+	}
 
 	if err := m.stackManager.Update(stackName, template, Tags{{Key: bblTagKey, Value: envID}}); err != nil {
 		return Stack{}, err
@@ -92,6 +100,31 @@ func (m InfrastructureManager) Update(keyPairName string, numberOfAvailabilityZo
 	return m.stackManager.Describe(stackName)
 }
 
+func (m InfrastructureManager) Plan(keyPairName string, numberOfAvailabilityZones int, stackName,
+	lbType, lbCertificateARN, envID string, network templates.Network, patches []templates.Patch) ([]ResourceChange, error) {
+
+	iamUserName := generateIAMUserName(envID)
+
+	stackExists, err := m.Exists(stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	if stackExists {
+		iamUserName, err = m.stackManager.GetPhysicalIDForResource(stackName, "BOSHUser")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	template, err := m.templateBuilder.Build(keyPairName, numberOfAvailabilityZones, lbType, lbCertificateARN, iamUserName, envID, network).Patch(patches...)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.stackManager.PlanChanges(stackName, template, Tags{{Key: bblTagKey, Value: envID}}, 5*time.Second)
+}
+
 func (m InfrastructureManager) Exists(stackName string) (bool, error) {
 	_, err := m.stackManager.Describe(stackName)
 
@@ -17,6 +17,8 @@ var _ = Describe("InfrastructureManager", func() {
 		builder               *fakes.TemplateBuilder
 		stackManager          *fakes.StackManager
 		infrastructureManager cloudformation.InfrastructureManager
+		patch                 templates.Patch
+		network               templates.Network
 	)
 
 	BeforeEach(func() {
@@ -30,6 +32,15 @@ var _ = Describe("InfrastructureManager", func() {
 		stackManager = &fakes.StackManager{}
 
 		infrastructureManager = cloudformation.NewInfrastructureManager(builder, stackManager)
+
+		network = templates.Network{CIDR: "some-cidr", NATIP: "some-nat-ip"}
+
+		patch = templates.Patch{
+			Name: "flow-logs.json",
+			Resources: map[string]*templates.Resource{
+				"FlowLogs": {Type: "AWS::EC2::FlowLog"},
+			},
+		}
 	})
 
 	Describe("Create", func() {
@@ -48,7 +59,7 @@ var _ = Describe("InfrastructureManager", func() {
 			}
 
 			stack, err := infrastructureManager.Create("some-key-pair-name", 2, "some-stack-name",
-				"some-lb-type", "some-lb-certificate-arn", "some-env-id-time-stamp")
+				"some-lb-type", "some-lb-certificate-arn", "some-env-id-time-stamp", network, nil)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(stack).To(Equal(cloudformation.Stack{Name: "some-stack-name"}))
@@ -58,6 +69,7 @@ var _ = Describe("InfrastructureManager", func() {
 			Expect(builder.BuildCall.Receives.LBCertificateARN).To(Equal("some-lb-certificate-arn"))
 			Expect(builder.BuildCall.Receives.IAMUserName).To(Equal("bosh-iam-user-some-env-id-time-stamp"))
 			Expect(builder.BuildCall.Receives.EnvID).To(Equal("some-env-id-time-stamp"))
+			Expect(builder.BuildCall.Receives.Network).To(Equal(network))
 
 			Expect(stackManager.CreateOrUpdateCall.Receives.StackName).To(Equal("some-stack-name"))
 			Expect(stackManager.CreateOrUpdateCall.Receives.Template).To(Equal(templates.Template{
@@ -82,7 +94,7 @@ var _ = Describe("InfrastructureManager", func() {
 			stackManager.GetPhysicalIDForResourceCall.Returns.PhysicalResourceID = "some-bosh-user-id"
 
 			_, err := infrastructureManager.Create("some-key-pair-name", 2, "some-stack-name",
-				"some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp")
+				"some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp", network, nil)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(stackManager.GetPhysicalIDForResourceCall.Receives.StackName).To(Equal("some-stack-name"))
@@ -91,18 +103,36 @@ var _ = Describe("InfrastructureManager", func() {
 			Expect(builder.BuildCall.Receives.IAMUserName).To(Equal("some-bosh-user-id"))
 		})
 
+		It("applies the cloudformation patches to the template", func() {
+			_, err := infrastructureManager.Create("some-key-pair-name", 2, "some-stack-name",
+				"some-lb-type", "some-lb-certificate-arn", "some-env-id", network, []templates.Patch{patch})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stackManager.CreateOrUpdateCall.Receives.Template.Resources).To(Equal(map[string]templates.Resource{
+				"FlowLogs": {Type: "AWS::EC2::FlowLog"},
+			}))
+		})
+
 		Context("failure cases", func() {
+			It("returns an error when a patch cannot be applied", func() {
+				patch.Resources = map[string]*templates.Resource{"NATInstance": nil}
+
+				_, err := infrastructureManager.Create("some-key-pair-name", 0, "some-stack-name", "", "", "", network, []templates.Patch{patch})
+				Expect(err).To(MatchError(`cloudformation patch "flow-logs.json" removes resource "NATInstance", which is not in the template`))
+				Expect(stackManager.CreateOrUpdateCall.Receives.StackName).To(BeEmpty())
+			})
+
 			It("returns an error when stack can't be created or updated", func() {
 				stackManager.CreateOrUpdateCall.Returns.Error = errors.New("stack create or update failed")
 
-				_, err := infrastructureManager.Create("some-key-pair-name", 0, "some-stack-name", "", "", "")
+				_, err := infrastructureManager.Create("some-key-pair-name", 0, "some-stack-name", "", "", "", network, nil)
 				Expect(err).To(MatchError("stack create or update failed"))
 			})
 
 			It("returns an error when waiting for stack completion fails", func() {
 				stackManager.WaitForCompletionCall.Returns.Error = errors.New("stack wait for completion failed")
 
-				_, err := infrastructureManager.Create("some-key-pair-name", 0, "some-stack-name", "", "", "")
+				_, err := infrastructureManager.Create("some-key-pair-name", 0, "some-stack-name", "", "", "", network, nil)
 				Expect(err).To(MatchError("stack wait for completion failed"))
 			})
 
@@ -110,7 +140,7 @@ var _ = Describe("InfrastructureManager", func() {
 				stackManager.GetPhysicalIDForResourceCall.Returns.Error = errors.New("get physical id for resource failed")
 
 				_, err := infrastructureManager.Create("some-key-pair-name", 2, "some-stack-name",
-					"some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp")
+					"some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp", network, nil)
 				Expect(err).To(MatchError("get physical id for resource failed"))
 
 			})
@@ -119,7 +149,7 @@ var _ = Describe("InfrastructureManager", func() {
 				It("returns an error when describing the stack fails", func() {
 					stackManager.DescribeCall.Returns.Error = errors.New("stack describe failed")
 
-					_, err := infrastructureManager.Create("some-key-pair-name", 0, "some-stack-name", "", "", "")
+					_, err := infrastructureManager.Create("some-key-pair-name", 0, "some-stack-name", "", "", "", network, nil)
 					Expect(err).To(MatchError("stack describe failed"))
 				})
 			})
@@ -135,7 +165,7 @@ var _ = Describe("InfrastructureManager", func() {
 						return cloudformation.Stack{}, errors.New("stack describe failed")
 					}
 
-					_, err := infrastructureManager.Create("some-key-pair-name", 0, "some-stack-name", "", "", "")
+					_, err := infrastructureManager.Create("some-key-pair-name", 0, "some-stack-name", "", "", "", network, nil)
 					Expect(err).To(MatchError("stack describe failed"))
 				})
 			})
@@ -150,7 +180,7 @@ var _ = Describe("InfrastructureManager", func() {
 		It("updates the stack and returns the stack", func() {
 			stackManager.GetPhysicalIDForResourceCall.Returns.PhysicalResourceID = "some-bosh-user-id"
 
-			stack, err := infrastructureManager.Update("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp")
+			stack, err := infrastructureManager.Update("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp", network, nil)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(stackManager.GetPhysicalIDForResourceCall.Receives.StackName).To(Equal("some-stack-name"))
@@ -163,6 +193,7 @@ var _ = Describe("InfrastructureManager", func() {
 			Expect(builder.BuildCall.Receives.LBCertificateARN).To(Equal("some-lb-certificate-arn"))
 			Expect(builder.BuildCall.Receives.IAMUserName).To(Equal("some-bosh-user-id"))
 			Expect(builder.BuildCall.Receives.EnvID).To(Equal("some-env-id-time:stamp"))
+			Expect(builder.BuildCall.Receives.Network).To(Equal(network))
 
 			Expect(stackManager.UpdateCall.Receives.StackName).To(Equal("some-stack-name"))
 			Expect(stackManager.UpdateCall.Receives.Template).To(Equal(templates.Template{
@@ -183,30 +214,116 @@ var _ = Describe("InfrastructureManager", func() {
 			Expect(stackManager.DescribeCall.Receives.StackName).To(Equal("some-stack-name"))
 		})
 
+		It("applies the cloudformation patches to the template", func() {
+			_, err := infrastructureManager.Update("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id", network, []templates.Patch{patch})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stackManager.UpdateCall.Receives.Template.Resources).To(HaveKey("FlowLogs"))
+		})
+
 		Context("failure cases", func() {
 			It("returns an error when it cannot get physical id for BOSHUser", func() {
 				stackManager.GetPhysicalIDForResourceCall.Returns.Error = errors.New("failed to get physical id for resource")
 
-				_, err := infrastructureManager.Update("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp")
+				_, err := infrastructureManager.Update("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp", network, nil)
 				Expect(err).To(MatchError("failed to get physical id for resource"))
 			})
 
 			It("returns an error when the update stack call fails", func() {
 				stackManager.UpdateCall.Returns.Error = errors.New("stack update call failed")
 
-				_, err := infrastructureManager.Update("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp")
+				_, err := infrastructureManager.Update("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp", network, nil)
 				Expect(err).To(MatchError("stack update call failed"))
 			})
 
 			It("returns an error when the wait for completion call fails", func() {
 				stackManager.WaitForCompletionCall.Returns.Error = errors.New("failed to wait for completion")
 
-				_, err := infrastructureManager.Update("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp")
+				_, err := infrastructureManager.Update("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id-time:stamp", network, nil)
 				Expect(err).To(MatchError("failed to wait for completion"))
 			})
 		})
 	})
 
+	Describe("Plan", func() {
+		BeforeEach(func() {
+			stackManager.PlanChangesCall.Returns.Changes = []cloudformation.ResourceChange{
+				{Action: "Add", LogicalID: "some-logical-id", ResourceType: "some-resource-type"},
+			}
+		})
+
+		It("plans the changes to the stack without applying them", func() {
+			stackManager.GetPhysicalIDForResourceCall.Returns.PhysicalResourceID = "some-bosh-user-id"
+
+			changes, err := infrastructureManager.Plan("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id", network, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(changes).To(Equal([]cloudformation.ResourceChange{
+				{Action: "Add", LogicalID: "some-logical-id", ResourceType: "some-resource-type"},
+			}))
+
+			Expect(builder.BuildCall.Receives.KeyPairName).To(Equal("some-key-pair-name"))
+			Expect(builder.BuildCall.Receives.NumberOfAZs).To(Equal(2))
+			Expect(builder.BuildCall.Receives.LBType).To(Equal("some-lb-type"))
+			Expect(builder.BuildCall.Receives.LBCertificateARN).To(Equal("some-lb-certificate-arn"))
+			Expect(builder.BuildCall.Receives.IAMUserName).To(Equal("some-bosh-user-id"))
+			Expect(builder.BuildCall.Receives.EnvID).To(Equal("some-env-id"))
+			Expect(builder.BuildCall.Receives.Network).To(Equal(network))
+
+			Expect(stackManager.PlanChangesCall.Receives.StackName).To(Equal("some-stack-name"))
+			Expect(stackManager.PlanChangesCall.Receives.Template).To(Equal(templates.Template{
+				AWSTemplateFormatVersion: "some-template-version",
+				Description:              "some-description",
+			}))
+			Expect(stackManager.PlanChangesCall.Receives.Tags).To(Equal(cloudformation.Tags{
+				{Key: "bbl-env-id", Value: "some-env-id"},
+			}))
+			Expect(stackManager.PlanChangesCall.Receives.SleepInterval).To(Equal(5 * time.Second))
+
+			Expect(stackManager.CreateOrUpdateCall.Receives.StackName).To(BeEmpty())
+			Expect(stackManager.UpdateCall.Receives.StackName).To(BeEmpty())
+		})
+
+		It("plans the changes with the cloudformation patches applied", func() {
+			_, err := infrastructureManager.Plan("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id", network, []templates.Patch{patch})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stackManager.PlanChangesCall.Receives.Template.Resources).To(HaveKey("FlowLogs"))
+		})
+
+		It("uses a generated iam user name when the stack does not exist", func() {
+			stackManager.DescribeCall.Returns.Error = cloudformation.StackNotFound
+
+			_, err := infrastructureManager.Plan("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id", network, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(builder.BuildCall.Receives.IAMUserName).To(Equal("bosh-iam-user-some-env-id"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the stack cannot be described", func() {
+				stackManager.DescribeCall.Returns.Error = errors.New("failed to describe stack")
+
+				_, err := infrastructureManager.Plan("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id", network, nil)
+				Expect(err).To(MatchError("failed to describe stack"))
+			})
+
+			It("returns an error when it cannot get physical id for BOSHUser", func() {
+				stackManager.GetPhysicalIDForResourceCall.Returns.Error = errors.New("failed to get physical id for resource")
+
+				_, err := infrastructureManager.Plan("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id", network, nil)
+				Expect(err).To(MatchError("failed to get physical id for resource"))
+			})
+
+			It("returns an error when the changes cannot be planned", func() {
+				stackManager.PlanChangesCall.Returns.Error = errors.New("failed to plan changes")
+
+				_, err := infrastructureManager.Plan("some-key-pair-name", 2, "some-stack-name", "some-lb-type", "some-lb-certificate-arn", "some-env-id", network, nil)
+				Expect(err).To(MatchError("failed to plan changes"))
+			})
+		})
+	})
+
 	Describe("Exists", func() {
 		It("returns true when the stack exists", func() {
 			stackManager.DescribeCall.Returns.Stack = cloudformation.Stack{}
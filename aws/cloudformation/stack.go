@@ -5,3 +5,10 @@ type Stack struct {
 	Status  string
 	Outputs map[string]string
 }
+
+type ResourceChange struct {
+	Action       string
+	LogicalID    string
+	ResourceType string
+	Replacement  string
+}
@@ -1,19 +1,25 @@
 package cloudformation
 
 import (
+	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
+	"github.com/cloudfoundry/bosh-bootloader/helpers"
 )
 
 var StackNotFound error = errors.New("stack not found")
 
+var changeSetTimeout = 10 * time.Minute
+
 type logger interface {
 	Step(message string, a ...interface{})
 	Dot()
@@ -224,6 +230,135 @@ func (s StackManager) Update(name string, template templates.Template, tags Tags
 	return nil
 }
 
+func (s StackManager) PlanChanges(name string, template templates.Template, tags Tags, sleepInterval time.Duration) (changes []ResourceChange, err error) {
+	_, err = s.Describe(name)
+	switch err {
+	case StackNotFound:
+		return templateResourceChanges(template), nil
+	case nil:
+	default:
+		return nil, err
+	}
+
+	s.logger.Step("creating cloudformation change set")
+
+	templateJson, err := json.Marshal(&template)
+	if err != nil {
+		return nil, err
+	}
+
+	changeSetName, err := helpers.NewStringGenerator(rand.Reader).Generate(fmt.Sprintf("bbl-plan-%d-", time.Now().Unix()), 8)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.cloudFormationClient().CreateChangeSet(&cloudformation.CreateChangeSetInput{
+		StackName:     aws.String(name),
+		ChangeSetName: aws.String(changeSetName),
+		Capabilities:  []*string{aws.String("CAPABILITY_IAM"), aws.String("CAPABILITY_NAMED_IAM")},
+		TemplateBody:  aws.String(string(templateJson)),
+		Tags:          tags.toAWSTags(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_, deleteErr := s.cloudFormationClient().DeleteChangeSet(&cloudformation.DeleteChangeSetInput{
+			StackName:     aws.String(name),
+			ChangeSetName: aws.String(changeSetName),
+		})
+		if deleteErr == nil {
+			return
+		}
+
+		deleteErr = fmt.Errorf("failed to delete change set %q of stack %q: %s", changeSetName, name, deleteErr)
+		if err == nil {
+			changes, err = nil, deleteErr
+			return
+		}
+
+		errorList := helpers.Errors{}
+		errorList.Add(err)
+		errorList.Add(deleteErr)
+		err = errorList
+	}()
+
+	return s.describeChangeSet(name, changeSetName, sleepInterval)
+}
+
+func (s StackManager) describeChangeSet(name, changeSetName string, sleepInterval time.Duration) ([]ResourceChange, error) {
+	changes := []ResourceChange{}
+	deadline := time.Now().Add(changeSetTimeout)
+
+	var nextToken *string
+	for {
+		output, err := s.cloudFormationClient().DescribeChangeSet(&cloudformation.DescribeChangeSetInput{
+			StackName:     aws.String(name),
+			ChangeSetName: aws.String(changeSetName),
+			NextToken:     nextToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		switch aws.StringValue(output.Status) {
+		case cloudformation.ChangeSetStatusCreateComplete:
+		case cloudformation.ChangeSetStatusFailed:
+			reason := aws.StringValue(output.StatusReason)
+			if strings.Contains(reason, "didn't contain changes") || strings.Contains(reason, "No updates are to be performed") {
+				return changes, nil
+			}
+			return nil, fmt.Errorf("failed to create change set for stack %q: %s", name, reason)
+		default:
+			if time.Now().After(deadline) {
+				return nil, fmt.Errorf("timed out after %s waiting for the change set of stack %q, its status is %s", changeSetTimeout, name, aws.StringValue(output.Status))
+			}
+
+			s.logger.Dot()
+			time.Sleep(sleepInterval)
+			continue
+		}
+
+		for _, change := range output.Changes {
+			if change.ResourceChange == nil {
+				continue
+			}
+
+			changes = append(changes, ResourceChange{
+				Action:       aws.StringValue(change.ResourceChange.Action),
+				LogicalID:    aws.StringValue(change.ResourceChange.LogicalResourceId),
+				ResourceType: aws.StringValue(change.ResourceChange.ResourceType),
+				Replacement:  aws.StringValue(change.ResourceChange.Replacement),
+			})
+		}
+
+		if output.NextToken == nil {
+			return changes, nil
+		}
+		nextToken = output.NextToken
+	}
+}
+
+func templateResourceChanges(template templates.Template) []ResourceChange {
+	logicalIDs := []string{}
+	for logicalID := range template.Resources {
+		logicalIDs = append(logicalIDs, logicalID)
+	}
+	sort.Strings(logicalIDs)
+
+	changes := []ResourceChange{}
+	for _, logicalID := range logicalIDs {
+		changes = append(changes, ResourceChange{
+			Action:       "Add",
+			LogicalID:    logicalID,
+			ResourceType: template.Resources[logicalID].Type,
+		})
+	}
+
+	return changes
+}
+
 func (s StackManager) GetPhysicalIDForResource(stackName string, logicalResourceID string) (string, error) {
 	describeStackResourceOutput, err := s.cloudFormationClient().DescribeStackResource(&cloudformation.DescribeStackResourceInput{
 		StackName:         aws.String(stackName),
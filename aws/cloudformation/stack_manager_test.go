@@ -553,6 +553,202 @@ and/or open a GitHub issue at https://github.com/cloudfoundry/bosh-bootloader/is
 		})
 	})
 
+	Describe("PlanChanges", func() {
+		var template templates.Template
+
+		BeforeEach(func() {
+			template = templates.Template{
+				Description: "some-description",
+				Resources: map[string]templates.Resource{
+					"VPC":      {Type: "AWS::EC2::VPC"},
+					"BOSHUser": {Type: "AWS::IAM::User"},
+				},
+			}
+		})
+
+		Context("when the stack does not exist", func() {
+			It("returns every resource in the template as an addition without creating a change set", func() {
+				cloudFormationClient.DescribeStacksCall.Returns.Error = awserr.NewRequestFailure(awserr.New("ValidationError", "Stack with id some-stack-name does not exist", errors.New("")), 400, "0")
+
+				changes, err := manager.PlanChanges("some-stack-name", template, cloudformation.Tags{}, 0)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(changes).To(Equal([]cloudformation.ResourceChange{
+					{Action: "Add", LogicalID: "BOSHUser", ResourceType: "AWS::IAM::User"},
+					{Action: "Add", LogicalID: "VPC", ResourceType: "AWS::EC2::VPC"},
+				}))
+				Expect(cloudFormationClient.CreateChangeSetCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when the stack exists", func() {
+			BeforeEach(func() {
+				cloudFormationClient.DescribeStacksCall.Returns.Output = &awscloudformation.DescribeStacksOutput{
+					Stacks: []*awscloudformation.Stack{{
+						StackName:   aws.String("some-stack-name"),
+						StackStatus: aws.String(awscloudformation.StackStatusUpdateComplete),
+					}},
+				}
+			})
+
+			It("creates a change set, returns its resource changes and deletes it", func() {
+				cloudFormationClient.DescribeChangeSetCall.Stub = func(input *awscloudformation.DescribeChangeSetInput) (*awscloudformation.DescribeChangeSetOutput, error) {
+					switch cloudFormationClient.DescribeChangeSetCall.CallCount {
+					case 1:
+						return &awscloudformation.DescribeChangeSetOutput{
+							Status: aws.String(awscloudformation.ChangeSetStatusCreatePending),
+						}, nil
+					case 2:
+						return &awscloudformation.DescribeChangeSetOutput{
+							Status: aws.String(awscloudformation.ChangeSetStatusCreateComplete),
+							Changes: []*awscloudformation.Change{{
+								ResourceChange: &awscloudformation.ResourceChange{
+									Action:            aws.String("Modify"),
+									LogicalResourceId: aws.String("VPC"),
+									ResourceType:      aws.String("AWS::EC2::VPC"),
+									Replacement:       aws.String("True"),
+								},
+							}},
+							NextToken: aws.String("some-next-token"),
+						}, nil
+					default:
+						Expect(input.NextToken).To(Equal(aws.String("some-next-token")))
+						return &awscloudformation.DescribeChangeSetOutput{
+							Status: aws.String(awscloudformation.ChangeSetStatusCreateComplete),
+							Changes: []*awscloudformation.Change{{
+								ResourceChange: &awscloudformation.ResourceChange{
+									Action:            aws.String("Remove"),
+									LogicalResourceId: aws.String("NATInstance"),
+									ResourceType:      aws.String("AWS::EC2::Instance"),
+								},
+							}},
+						}, nil
+					}
+				}
+
+				changes, err := manager.PlanChanges("some-stack-name", template, cloudformation.Tags{{Key: "bbl-env-id", Value: "some-env-id"}}, 0)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(changes).To(Equal([]cloudformation.ResourceChange{
+					{Action: "Modify", LogicalID: "VPC", ResourceType: "AWS::EC2::VPC", Replacement: "True"},
+					{Action: "Remove", LogicalID: "NATInstance", ResourceType: "AWS::EC2::Instance"},
+				}))
+
+				templateJson, err := json.Marshal(&template)
+				Expect(err).NotTo(HaveOccurred())
+
+				input := cloudFormationClient.CreateChangeSetCall.Receives.Input
+				Expect(input.StackName).To(Equal(aws.String("some-stack-name")))
+				Expect(aws.StringValue(input.ChangeSetName)).To(MatchRegexp(`^bbl-plan-\d+-[a-zA-Z0-9]{8}$`))
+				Expect(input.TemplateBody).To(Equal(aws.String(string(templateJson))))
+				Expect(input.Capabilities).To(Equal([]*string{aws.String("CAPABILITY_IAM"), aws.String("CAPABILITY_NAMED_IAM")}))
+				Expect(input.Tags).To(Equal([]*awscloudformation.Tag{{Key: aws.String("bbl-env-id"), Value: aws.String("some-env-id")}}))
+
+				Expect(cloudFormationClient.DescribeChangeSetCall.CallCount).To(Equal(3))
+				Expect(cloudFormationClient.DeleteChangeSetCall.Receives.Input).To(Equal(&awscloudformation.DeleteChangeSetInput{
+					StackName:     aws.String("some-stack-name"),
+					ChangeSetName: input.ChangeSetName,
+				}))
+				Expect(logger.StepCall.Messages).To(ContainElement("creating cloudformation change set"))
+			})
+
+			It("returns no changes when the change set has nothing to do", func() {
+				cloudFormationClient.DescribeChangeSetCall.Returns.Output = &awscloudformation.DescribeChangeSetOutput{
+					Status:       aws.String(awscloudformation.ChangeSetStatusFailed),
+					StatusReason: aws.String("The submitted information didn't contain changes. Submit different information to create a change set."),
+				}
+
+				changes, err := manager.PlanChanges("some-stack-name", template, cloudformation.Tags{}, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(changes).To(BeEmpty())
+				Expect(cloudFormationClient.DeleteChangeSetCall.CallCount).To(Equal(1))
+			})
+
+			It("gives every change set a unique name", func() {
+				cloudFormationClient.DescribeChangeSetCall.Returns.Output = &awscloudformation.DescribeChangeSetOutput{
+					Status: aws.String(awscloudformation.ChangeSetStatusCreateComplete),
+				}
+
+				_, err := manager.PlanChanges("some-stack-name", template, cloudformation.Tags{}, 0)
+				Expect(err).NotTo(HaveOccurred())
+				firstName := aws.StringValue(cloudFormationClient.CreateChangeSetCall.Receives.Input.ChangeSetName)
+
+				_, err = manager.PlanChanges("some-stack-name", template, cloudformation.Tags{}, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(aws.StringValue(cloudFormationClient.CreateChangeSetCall.Receives.Input.ChangeSetName)).NotTo(Equal(firstName))
+			})
+
+			Context("failure cases", func() {
+				AfterEach(func() {
+					cloudformation.ResetChangeSetTimeout()
+				})
+
+				It("returns an error when the change set cannot be deleted", func() {
+					cloudFormationClient.DescribeChangeSetCall.Returns.Output = &awscloudformation.DescribeChangeSetOutput{
+						Status: aws.String(awscloudformation.ChangeSetStatusCreateComplete),
+					}
+					cloudFormationClient.DeleteChangeSetCall.Returns.Error = errors.New("failed to delete change set")
+
+					changes, err := manager.PlanChanges("some-stack-name", template, cloudformation.Tags{}, 0)
+					Expect(err).To(MatchError(MatchRegexp(`failed to delete change set "bbl-plan-\d+-[a-zA-Z0-9]{8}" of stack "some-stack-name": failed to delete change set`)))
+					Expect(changes).To(BeNil())
+				})
+
+				It("returns both errors when the change set fails and cannot be deleted", func() {
+					cloudFormationClient.DescribeChangeSetCall.Returns.Error = errors.New("failed to describe change set")
+					cloudFormationClient.DeleteChangeSetCall.Returns.Error = errors.New("failed to delete change set")
+
+					_, err := manager.PlanChanges("some-stack-name", template, cloudformation.Tags{}, 0)
+					Expect(err).To(MatchError(ContainSubstring("failed to describe change set")))
+					Expect(err).To(MatchError(ContainSubstring("of stack \"some-stack-name\": failed to delete change set")))
+				})
+
+				It("returns an error when the change set is not created in time", func() {
+					cloudformation.SetChangeSetTimeout(time.Millisecond)
+					cloudFormationClient.DescribeChangeSetCall.Returns.Output = &awscloudformation.DescribeChangeSetOutput{
+						Status: aws.String(awscloudformation.ChangeSetStatusCreateInProgress),
+					}
+
+					_, err := manager.PlanChanges("some-stack-name", template, cloudformation.Tags{}, time.Millisecond)
+					Expect(err).To(MatchError(`timed out after 1ms waiting for the change set of stack "some-stack-name", its status is CREATE_IN_PROGRESS`))
+					Expect(cloudFormationClient.DeleteChangeSetCall.CallCount).To(Equal(1))
+				})
+
+				It("returns an error when the change set cannot be created", func() {
+					cloudFormationClient.CreateChangeSetCall.Returns.Error = errors.New("failed to create change set")
+
+					_, err := manager.PlanChanges("some-stack-name", template, cloudformation.Tags{}, 0)
+					Expect(err).To(MatchError("failed to create change set"))
+				})
+
+				It("returns an error when the change set fails", func() {
+					cloudFormationClient.DescribeChangeSetCall.Returns.Output = &awscloudformation.DescribeChangeSetOutput{
+						Status:       aws.String(awscloudformation.ChangeSetStatusFailed),
+						StatusReason: aws.String("something bad happened"),
+					}
+
+					_, err := manager.PlanChanges("some-stack-name", template, cloudformation.Tags{}, 0)
+					Expect(err).To(MatchError(`failed to create change set for stack "some-stack-name": something bad happened`))
+					Expect(cloudFormationClient.DeleteChangeSetCall.CallCount).To(Equal(1))
+				})
+
+				It("returns an error when the change set cannot be described", func() {
+					cloudFormationClient.DescribeChangeSetCall.Returns.Error = errors.New("failed to describe change set")
+
+					_, err := manager.PlanChanges("some-stack-name", template, cloudformation.Tags{}, 0)
+					Expect(err).To(MatchError("failed to describe change set"))
+				})
+			})
+		})
+
+		It("returns an error when the stack cannot be described", func() {
+			cloudFormationClient.DescribeStacksCall.Returns.Error = errors.New("failed to describe stack")
+
+			_, err := manager.PlanChanges("some-stack-name", template, cloudformation.Tags{}, 0)
+			Expect(err).To(MatchError("failed to describe stack"))
+		})
+	})
+
 	Describe("GetPhysicalIDForResource", func() {
 		It("gets the physical resource id for the given stack resource", func() {
 			cloudFormationClient.DescribeStackResourceCall.Returns.Output = &awscloudformation.DescribeStackResourceOutput{
@@ -6,13 +6,13 @@ func NewBOSHSubnetTemplateBuilder() BOSHSubnetTemplateBuilder {
 	return BOSHSubnetTemplateBuilder{}
 }
 
-func (BOSHSubnetTemplateBuilder) BOSHSubnet() Template {
+func (BOSHSubnetTemplateBuilder) BOSHSubnet(cidr string) Template {
 	return Template{
 		Parameters: map[string]Parameter{
 			"BOSHSubnetCIDR": Parameter{
 				Description: "CIDR block for the BOSH subnet.",
 				Type:        "String",
-				Default:     "10.0.0.0/24",
+				Default:     cidr,
 			},
 		},
 		Resources: map[string]Resource{
@@ -15,7 +15,7 @@ var _ = Describe("BOSHSubnetTemplateBuilder", func() {
 
 	Describe("BOSHSubnet", func() {
 		It("returns a template with all fields for the BOSH subnet", func() {
-			subnet := builder.BOSHSubnet()
+			subnet := builder.BOSHSubnet("10.0.0.0/24")
 
 			Expect(subnet.Resources).To(HaveLen(4))
 			Expect(subnet.Resources).To(HaveKeyWithValue("BOSHSubnet", templates.Resource{
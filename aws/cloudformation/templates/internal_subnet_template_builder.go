@@ -8,13 +8,38 @@ func NewInternalSubnetTemplateBuilder() InternalSubnetTemplateBuilder {
 	return InternalSubnetTemplateBuilder{}
 }
 
-func (s InternalSubnetTemplateBuilder) InternalSubnet(azIndex int, suffix, cidrBlock string) Template {
+func (s InternalSubnetTemplateBuilder) InternalSubnet(azIndex int, suffix, cidrBlock, natType string) Template {
 	subnetName := fmt.Sprintf("InternalSubnet%s", suffix)
 	subnetTag := fmt.Sprintf("Internal%s", suffix)
 	subnetCIDRName := fmt.Sprintf("%sCIDR", subnetName)
 	cidrDescription := fmt.Sprintf("CIDR block for %s.", subnetName)
 	subnetRouteTableAssociationName := fmt.Sprintf("%sRouteTableAssociation", subnetName)
 
+	routeTableName := "InternalRouteTable"
+	routeName := "InternalRoute"
+	route := Resource{
+		Type:      "AWS::EC2::Route",
+		DependsOn: "NATInstance",
+		Properties: Route{
+			DestinationCidrBlock: "0.0.0.0/0",
+			RouteTableId:         Ref{routeTableName},
+			InstanceId:           Ref{"NATInstance"},
+		},
+	}
+
+	if natType == NATTypeGateway {
+		routeTableName = fmt.Sprintf("InternalRouteTable%s", suffix)
+		routeName = fmt.Sprintf("InternalRoute%s", suffix)
+		route = Resource{
+			Type: "AWS::EC2::Route",
+			Properties: Route{
+				DestinationCidrBlock: "0.0.0.0/0",
+				RouteTableId:         Ref{routeTableName},
+				NatGatewayId:         Ref{fmt.Sprintf("NATGateway%s", suffix)},
+			},
+		}
+	}
+
 	return Template{
 		Outputs: map[string]Output{
 			fmt.Sprintf("%sName", subnetName): Output{
@@ -61,25 +86,17 @@ func (s InternalSubnetTemplateBuilder) InternalSubnet(azIndex int, suffix, cidrB
 					},
 				},
 			},
-			"InternalRouteTable": {
+			routeTableName: {
 				Type: "AWS::EC2::RouteTable",
 				Properties: RouteTable{
 					VpcId: Ref{"VPC"},
 				},
 			},
-			"InternalRoute": {
-				Type:      "AWS::EC2::Route",
-				DependsOn: "NATInstance",
-				Properties: Route{
-					DestinationCidrBlock: "0.0.0.0/0",
-					RouteTableId:         Ref{"InternalRouteTable"},
-					InstanceId:           Ref{"NATInstance"},
-				},
-			},
+			routeName: route,
 			subnetRouteTableAssociationName: Resource{
 				Type: "AWS::EC2::SubnetRouteTableAssociation",
 				Properties: SubnetRouteTableAssociation{
-					RouteTableId: Ref{"InternalRouteTable"},
+					RouteTableId: Ref{routeTableName},
 					SubnetId:     Ref{subnetName},
 				},
 			},
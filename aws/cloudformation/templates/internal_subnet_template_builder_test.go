@@ -15,7 +15,7 @@ var _ = Describe("InternalSubnetTemplateBuilder", func() {
 
 	Describe("InternalSubnet", func() {
 		It("returns a template with parameters for the internal subnet", func() {
-			subnet := builder.InternalSubnet(0, "1", "10.0.16.0/20")
+			subnet := builder.InternalSubnet(0, "1", "10.0.16.0/20", templates.NATTypeInstance)
 
 			Expect(subnet.Parameters).To(HaveLen(1))
 			Expect(subnet.Parameters).To(HaveKeyWithValue("InternalSubnet1CIDR", templates.Parameter{
@@ -26,7 +26,7 @@ var _ = Describe("InternalSubnetTemplateBuilder", func() {
 		})
 
 		It("returns a template with resources for the internal subnet", func() {
-			subnet := builder.InternalSubnet(0, "1", "10.0.16.0/20")
+			subnet := builder.InternalSubnet(0, "1", "10.0.16.0/20", templates.NATTypeInstance)
 
 			Expect(subnet.Resources).To(HaveLen(4))
 			Expect(subnet.Resources).To(HaveKeyWithValue("InternalSubnet1", templates.Resource{
@@ -77,8 +77,38 @@ var _ = Describe("InternalSubnetTemplateBuilder", func() {
 			}))
 		})
 
+		It("routes through the NAT gateway of the availability zone when the NAT type is gateway", func() {
+			subnet := builder.InternalSubnet(1, "2", "10.0.32.0/20", templates.NATTypeGateway)
+
+			Expect(subnet.Resources).To(HaveLen(4))
+			Expect(subnet.Resources).NotTo(HaveKey("InternalRouteTable"))
+			Expect(subnet.Resources).To(HaveKeyWithValue("InternalRouteTable2", templates.Resource{
+				Type: "AWS::EC2::RouteTable",
+				Properties: templates.RouteTable{
+					VpcId: templates.Ref{"VPC"},
+				},
+			}))
+
+			Expect(subnet.Resources).To(HaveKeyWithValue("InternalRoute2", templates.Resource{
+				Type: "AWS::EC2::Route",
+				Properties: templates.Route{
+					DestinationCidrBlock: "0.0.0.0/0",
+					RouteTableId:         templates.Ref{"InternalRouteTable2"},
+					NatGatewayId:         templates.Ref{"NATGateway2"},
+				},
+			}))
+
+			Expect(subnet.Resources).To(HaveKeyWithValue("InternalSubnet2RouteTableAssociation", templates.Resource{
+				Type: "AWS::EC2::SubnetRouteTableAssociation",
+				Properties: templates.SubnetRouteTableAssociation{
+					RouteTableId: templates.Ref{"InternalRouteTable2"},
+					SubnetId:     templates.Ref{"InternalSubnet2"},
+				},
+			}))
+		})
+
 		It("returns a template with outputs for the internal subnet", func() {
-			subnet := builder.InternalSubnet(0, "1", "10.0.16.0/20")
+			subnet := builder.InternalSubnet(0, "1", "10.0.16.0/20", templates.NATTypeInstance)
 
 			Expect(subnet.Outputs).To(HaveLen(3))
 			Expect(subnet.Outputs).To(HaveKeyWithValue("InternalSubnet1CIDR", templates.Output{
@@ -8,15 +8,16 @@ func NewInternalSubnetsTemplateBuilder() InternalSubnetsTemplateBuilder {
 	return InternalSubnetsTemplateBuilder{}
 }
 
-func (InternalSubnetsTemplateBuilder) InternalSubnets(numberOfAvailabilityZones int) Template {
+func (InternalSubnetsTemplateBuilder) InternalSubnets(cidrs []string, natType string) Template {
 	internalSubnetTemplateBuilder := NewInternalSubnetTemplateBuilder()
 
 	template := Template{}
-	for index := 1; index <= numberOfAvailabilityZones; index++ {
+	for index, cidr := range cidrs {
 		template = template.Merge(internalSubnetTemplateBuilder.InternalSubnet(
-			index-1,
-			fmt.Sprintf("%d", index),
-			fmt.Sprintf("10.0.%d.0/20", 16*(index)),
+			index,
+			fmt.Sprintf("%d", index+1),
+			cidr,
+			natType,
 		))
 	}
 
@@ -19,7 +19,7 @@ var _ = Describe("InternalSubnetsTemplateBuilder", func() {
 
 	Describe("InternalSubnets", func() {
 		It("creates internal subnets for each availability zone", func() {
-			template := internalSubnetsTemplateBuilder.InternalSubnets(2)
+			template := internalSubnetsTemplateBuilder.InternalSubnets([]string{"10.0.16.0/20", "10.0.32.0/20"}, templates.NATTypeInstance)
 
 			Expect(template.Parameters).To(HaveLen(2))
 			Expect(template.Parameters["InternalSubnet1CIDR"].Default).To(Equal("10.0.16.0/20"))
@@ -28,6 +28,15 @@ var _ = Describe("InternalSubnetsTemplateBuilder", func() {
 			Expect(HasSubnetWithAvailabilityZoneIndex(template, 0)).To(BeTrue())
 			Expect(HasSubnetWithAvailabilityZoneIndex(template, 1)).To(BeTrue())
 		})
+
+		It("creates a route table for each availability zone when the NAT type is gateway", func() {
+			template := internalSubnetsTemplateBuilder.InternalSubnets([]string{"10.0.16.0/20", "10.0.32.0/20"}, templates.NATTypeGateway)
+
+			Expect(template.Resources).To(HaveKey("InternalRouteTable1"))
+			Expect(template.Resources).To(HaveKey("InternalRouteTable2"))
+			Expect(template.Resources["InternalRoute1"].Properties.(templates.Route).NatGatewayId).To(Equal(templates.Ref{"NATGateway1"}))
+			Expect(template.Resources["InternalRoute2"].Properties.(templates.Route).NatGatewayId).To(Equal(templates.Ref{"NATGateway2"}))
+		})
 	})
 })
 
@@ -8,15 +8,15 @@ func NewLoadBalancerSubnetsTemplateBuilder() LoadBalancerSubnetsTemplateBuilder
 	return LoadBalancerSubnetsTemplateBuilder{}
 }
 
-func (LoadBalancerSubnetsTemplateBuilder) LoadBalancerSubnets(azCount int) Template {
+func (LoadBalancerSubnetsTemplateBuilder) LoadBalancerSubnets(cidrs []string) Template {
 	loadBalancerSubnetTemplateBuilder := NewLoadBalancerSubnetTemplateBuilder()
 
 	template := Template{}
-	for index := 1; index <= azCount; index++ {
+	for index, cidr := range cidrs {
 		template = template.Merge(loadBalancerSubnetTemplateBuilder.LoadBalancerSubnet(
-			index-1,
-			fmt.Sprintf("%d", index),
-			fmt.Sprintf("10.0.%d.0/24", index+1),
+			index,
+			fmt.Sprintf("%d", index+1),
+			cidr,
 		))
 	}
 	return template
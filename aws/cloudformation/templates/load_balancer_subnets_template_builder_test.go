@@ -19,7 +19,7 @@ var _ = Describe("LoadBalancerSubnetsTemplateBuilder", func() {
 
 	Describe("LoadBalancerSubnets", func() {
 		It("creates load balancer subnets for each availability zone", func() {
-			template := loadBalancerSubnetsTemplateBuilder.LoadBalancerSubnets(2)
+			template := loadBalancerSubnetsTemplateBuilder.LoadBalancerSubnets([]string{"10.0.2.0/24", "10.0.3.0/24"})
 
 			Expect(template.Parameters).To(HaveLen(2))
 			Expect(template.Parameters["LoadBalancerSubnet1CIDR"].Default).To(Equal("10.0.2.0/24"))
@@ -1,27 +1,36 @@
 package templates
 
+import "fmt"
+
+const (
+	NATTypeInstance = "instance"
+	NATTypeGateway  = "gateway"
+)
+
+// LegacyNATAMIs are the NAT AMIs that the template used to look up by region.
+// Stacks created before the NAT AMI was recorded in the state keep using them,
+// so that upgrading bbl does not replace their NAT instance.
+var LegacyNATAMIs = map[string]string{
+	"us-east-1":      "ami-68115b02",
+	"us-west-1":      "ami-ef1a718f",
+	"us-west-2":      "ami-77a4b816",
+	"eu-west-1":      "ami-c0993ab3",
+	"eu-central-1":   "ami-0b322e67",
+	"ap-southeast-1": "ami-e2fc3f81",
+	"ap-southeast-2": "ami-e3217a80",
+	"ap-northeast-1": "ami-f885ae96",
+	"ap-northeast-2": "ami-4118d72f",
+	"sa-east-1":      "ami-8631b5ea",
+}
+
 type NATTemplateBuilder struct{}
 
 func NewNATTemplateBuilder() NATTemplateBuilder {
 	return NATTemplateBuilder{}
 }
 
-func (t NATTemplateBuilder) NAT() Template {
+func (t NATTemplateBuilder) NAT(privateIP, amiID string) Template {
 	return Template{
-		Mappings: map[string]interface{}{
-			"AWSNATAMI": map[string]AMI{
-				"us-east-1":      {"ami-68115b02"},
-				"us-west-1":      {"ami-ef1a718f"},
-				"us-west-2":      {"ami-77a4b816"},
-				"eu-west-1":      {"ami-c0993ab3"},
-				"eu-central-1":   {"ami-0b322e67"},
-				"ap-southeast-1": {"ami-e2fc3f81"},
-				"ap-southeast-2": {"ami-e3217a80"},
-				"ap-northeast-1": {"ami-f885ae96"},
-				"ap-northeast-2": {"ami-4118d72f"},
-				"sa-east-1":      {"ami-8631b5ea"},
-			},
-		},
 		Resources: map[string]Resource{
 			"NATSecurityGroup": Resource{
 				Type: "AWS::EC2::SecurityGroup",
@@ -48,18 +57,12 @@ func (t NATTemplateBuilder) NAT() Template {
 			"NATInstance": Resource{
 				Type: "AWS::EC2::Instance",
 				Properties: Instance{
-					PrivateIpAddress: "10.0.0.7",
+					PrivateIpAddress: privateIP,
 					InstanceType:     "t2.medium",
 					SubnetId:         Ref{"BOSHSubnet"},
 					SourceDestCheck:  false,
-					ImageId: map[string]interface{}{
-						"Fn::FindInMap": []interface{}{
-							"AWSNATAMI",
-							Ref{"AWS::Region"},
-							"AMI",
-						},
-					},
-					KeyName: Ref{"SSHKeyPairName"},
+					ImageId:          amiID,
+					KeyName:          Ref{"SSHKeyPairName"},
 					SecurityGroupIds: []interface{}{
 						Ref{"NATSecurityGroup"},
 					},
@@ -82,3 +85,74 @@ func (t NATTemplateBuilder) NAT() Template {
 		},
 	}
 }
+
+// NATGateways creates a managed NAT gateway in each availability zone. Each
+// gateway sits in its own small public subnet, carved out of cidrs.
+func (t NATTemplateBuilder) NATGateways(cidrs []string) Template {
+	template := Template{
+		Parameters: map[string]Parameter{},
+		Resources:  map[string]Resource{},
+	}
+
+	for index, cidr := range cidrs {
+		suffix := fmt.Sprintf("%d", index+1)
+		subnetName := fmt.Sprintf("NATSubnet%s", suffix)
+		subnetCIDRName := fmt.Sprintf("%sCIDR", subnetName)
+		gatewayName := fmt.Sprintf("NATGateway%s", suffix)
+		eipName := fmt.Sprintf("%sEIP", gatewayName)
+
+		template.Parameters[subnetCIDRName] = Parameter{
+			Description: fmt.Sprintf("CIDR block for %s.", subnetName),
+			Type:        "String",
+			Default:     cidr,
+		}
+
+		template.Resources[subnetName] = Resource{
+			Type: "AWS::EC2::Subnet",
+			Properties: Subnet{
+				AvailabilityZone: map[string]interface{}{
+					"Fn::Select": []interface{}{
+						fmt.Sprintf("%d", index),
+						map[string]Ref{
+							"Fn::GetAZs": Ref{"AWS::Region"},
+						},
+					},
+				},
+				CidrBlock: Ref{subnetCIDRName},
+				VpcId:     Ref{"VPC"},
+				Tags: []Tag{
+					{
+						Key:   "Name",
+						Value: fmt.Sprintf("NAT%s", suffix),
+					},
+				},
+			},
+		}
+
+		template.Resources[fmt.Sprintf("%sRouteTableAssociation", subnetName)] = Resource{
+			Type: "AWS::EC2::SubnetRouteTableAssociation",
+			Properties: SubnetRouteTableAssociation{
+				RouteTableId: Ref{"BOSHRouteTable"},
+				SubnetId:     Ref{subnetName},
+			},
+		}
+
+		template.Resources[eipName] = Resource{
+			DependsOn: "VPCGatewayAttachment",
+			Type:      "AWS::EC2::EIP",
+			Properties: EIP{
+				Domain: "vpc",
+			},
+		}
+
+		template.Resources[gatewayName] = Resource{
+			Type: "AWS::EC2::NatGateway",
+			Properties: NatGateway{
+				AllocationId: FnGetAtt{[]string{eipName, "AllocationId"}},
+				SubnetId:     Ref{subnetName},
+			},
+		}
+	}
+
+	return template
+}
@@ -16,21 +16,9 @@ var _ = Describe("NATTemplateBuilder", func() {
 
 	Describe("NAT", func() {
 		It("returns a template containing all of the NAT fields", func() {
-			nat := builder.NAT()
+			nat := builder.NAT("10.0.0.7", "ami-some-nat-ami")
 
-			Expect(nat.Mappings).To(HaveLen(1))
-			Expect(nat.Mappings).To(HaveKeyWithValue("AWSNATAMI", map[string]templates.AMI{
-				"us-east-1":      {"ami-68115b02"},
-				"us-west-1":      {"ami-ef1a718f"},
-				"us-west-2":      {"ami-77a4b816"},
-				"eu-west-1":      {"ami-c0993ab3"},
-				"eu-central-1":   {"ami-0b322e67"},
-				"ap-southeast-1": {"ami-e2fc3f81"},
-				"ap-southeast-2": {"ami-e3217a80"},
-				"ap-northeast-1": {"ami-f885ae96"},
-				"ap-northeast-2": {"ami-4118d72f"},
-				"sa-east-1":      {"ami-8631b5ea"},
-			}))
+			Expect(nat.Mappings).To(BeEmpty())
 
 			Expect(nat.Resources).To(HaveLen(3))
 			Expect(nat.Resources).To(HaveKeyWithValue("NATSecurityGroup", templates.Resource{
@@ -63,14 +51,8 @@ var _ = Describe("NATTemplateBuilder", func() {
 					SubnetId:         templates.Ref{"BOSHSubnet"},
 					SourceDestCheck:  false,
 					PrivateIpAddress: "10.0.0.7",
-					ImageId: map[string]interface{}{
-						"Fn::FindInMap": []interface{}{
-							"AWSNATAMI",
-							templates.Ref{"AWS::Region"},
-							"AMI",
-						},
-					},
-					KeyName: templates.Ref{"SSHKeyPairName"},
+					ImageId:          "ami-some-nat-ami",
+					KeyName:          templates.Ref{"SSHKeyPairName"},
 					SecurityGroupIds: []interface{}{
 						templates.Ref{"NATSecurityGroup"},
 					},
@@ -92,4 +74,63 @@ var _ = Describe("NATTemplateBuilder", func() {
 			}))
 		})
 	})
+
+	Describe("NATGateways", func() {
+		It("returns a template with a NAT gateway in a public subnet for each availability zone", func() {
+			nat := builder.NATGateways([]string{"10.0.1.0/28", "10.0.1.16/28"})
+
+			Expect(nat.Mappings).To(BeEmpty())
+			Expect(nat.Parameters).To(HaveLen(2))
+			Expect(nat.Parameters).To(HaveKeyWithValue("NATSubnet2CIDR", templates.Parameter{
+				Description: "CIDR block for NATSubnet2.",
+				Type:        "String",
+				Default:     "10.0.1.16/28",
+			}))
+
+			Expect(nat.Resources).To(HaveLen(8))
+			Expect(nat.Resources).NotTo(HaveKey("NATInstance"))
+			Expect(nat.Resources).To(HaveKeyWithValue("NATSubnet2", templates.Resource{
+				Type: "AWS::EC2::Subnet",
+				Properties: templates.Subnet{
+					AvailabilityZone: map[string]interface{}{
+						"Fn::Select": []interface{}{
+							"1",
+							map[string]templates.Ref{
+								"Fn::GetAZs": templates.Ref{"AWS::Region"},
+							},
+						},
+					},
+					CidrBlock: templates.Ref{"NATSubnet2CIDR"},
+					VpcId:     templates.Ref{"VPC"},
+					Tags: []templates.Tag{
+						{
+							Key:   "Name",
+							Value: "NAT2",
+						},
+					},
+				},
+			}))
+			Expect(nat.Resources).To(HaveKeyWithValue("NATSubnet2RouteTableAssociation", templates.Resource{
+				Type: "AWS::EC2::SubnetRouteTableAssociation",
+				Properties: templates.SubnetRouteTableAssociation{
+					RouteTableId: templates.Ref{"BOSHRouteTable"},
+					SubnetId:     templates.Ref{"NATSubnet2"},
+				},
+			}))
+			Expect(nat.Resources).To(HaveKeyWithValue("NATGateway2EIP", templates.Resource{
+				Type:      "AWS::EC2::EIP",
+				DependsOn: "VPCGatewayAttachment",
+				Properties: templates.EIP{
+					Domain: "vpc",
+				},
+			}))
+			Expect(nat.Resources).To(HaveKeyWithValue("NATGateway2", templates.Resource{
+				Type: "AWS::EC2::NatGateway",
+				Properties: templates.NatGateway{
+					AllocationId: templates.FnGetAtt{[]string{"NATGateway2EIP", "AllocationId"}},
+					SubnetId:     templates.Ref{"NATSubnet2"},
+				},
+			}))
+		})
+	})
 })
@@ -0,0 +1,225 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Patch is a merge fragment for the generated template. Resources and outputs
+// in the patch are added to the template or replace the ones with the same
+// name, and a null value removes them.
+type Patch struct {
+	Name      string `json:"-"`
+	Resources map[string]*Resource
+	Outputs   map[string]*Output
+}
+
+func ParsePatch(name string, contents []byte) (Patch, error) {
+	patch := Patch{}
+
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
+		return Patch{}, err
+	}
+	patch.Name = name
+
+	if len(patch.Resources) == 0 && len(patch.Outputs) == 0 {
+		return Patch{}, errors.New("the patch must contain Resources or Outputs")
+	}
+
+	for _, resourceName := range patch.resourceNames() {
+		resource := patch.Resources[resourceName]
+		if resource != nil && !strings.HasPrefix(resource.Type, "AWS::") {
+			return Patch{}, fmt.Errorf("resource %q has an invalid type %q", resourceName, resource.Type)
+		}
+	}
+
+	return patch, nil
+}
+
+// Patch returns a copy of the template with the patches applied in order. The
+// patched template is validated so that every Ref, Fn::GetAtt and DependsOn
+// names a resource or parameter that is still in the template.
+func (t Template) Patch(patches ...Patch) (Template, error) {
+	if len(patches) == 0 {
+		return t, nil
+	}
+
+	resources := map[string]Resource{}
+	for name, resource := range t.Resources {
+		resources[name] = resource
+	}
+
+	outputs := map[string]Output{}
+	for name, output := range t.Outputs {
+		outputs[name] = output
+	}
+
+	for _, patch := range patches {
+		for _, name := range patch.resourceNames() {
+			resource := patch.Resources[name]
+			existing, ok := resources[name]
+
+			switch {
+			case resource == nil && !ok:
+				return Template{}, fmt.Errorf("cloudformation patch %q removes resource %q, which is not in the template", patch.Name, name)
+			case resource == nil:
+				delete(resources, name)
+			case ok && existing.Type != resource.Type:
+				return Template{}, fmt.Errorf("cloudformation patch %q changes the type of resource %q from %s to %s", patch.Name, name, existing.Type, resource.Type)
+			default:
+				resources[name] = *resource
+			}
+		}
+
+		for name, output := range patch.Outputs {
+			if output == nil {
+				delete(outputs, name)
+				continue
+			}
+			outputs[name] = *output
+		}
+	}
+
+	t.Resources = resources
+	t.Outputs = outputs
+
+	if err := t.validateReferences(); err != nil {
+		return Template{}, err
+	}
+
+	return t, nil
+}
+
+func (t Template) validateReferences() error {
+	names := []string{}
+	for name := range t.Resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		resource := t.Resources[name]
+
+		for _, dependency := range dependsOn(resource.DependsOn) {
+			if _, ok := t.Resources[dependency]; !ok {
+				return fmt.Errorf("resource %q depends on %q, which is not in the template", name, dependency)
+			}
+		}
+
+		references, err := findReferences(resource)
+		if err != nil {
+			return err
+		}
+
+		for _, reference := range references {
+			if !t.defines(reference) {
+				return fmt.Errorf("resource %q references %q, which is not in the template", name, reference)
+			}
+		}
+	}
+
+	for name, output := range t.Outputs {
+		references, err := findReferences(output)
+		if err != nil {
+			return err
+		}
+
+		for _, reference := range references {
+			if !t.defines(reference) {
+				return fmt.Errorf("output %q references %q, which is not in the template", name, reference)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (t Template) defines(name string) bool {
+	if strings.HasPrefix(name, "AWS::") {
+		return true
+	}
+
+	if _, ok := t.Resources[name]; ok {
+		return true
+	}
+
+	_, ok := t.Parameters[name]
+	return ok
+}
+
+func findReferences(v interface{}) ([]string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	references := []string{}
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch typedNode := node.(type) {
+		case map[string]interface{}:
+			for key, value := range typedNode {
+				switch key {
+				case "Ref":
+					if name, ok := value.(string); ok {
+						references = append(references, name)
+					}
+				case "Fn::GetAtt":
+					if values, ok := value.([]interface{}); ok && len(values) > 0 {
+						if name, ok := values[0].(string); ok {
+							references = append(references, name)
+						}
+					}
+				}
+				walk(value)
+			}
+		case []interface{}:
+			for _, value := range typedNode {
+				walk(value)
+			}
+		}
+	}
+	walk(document)
+
+	return references, nil
+}
+
+func dependsOn(v interface{}) []string {
+	switch typedValue := v.(type) {
+	case string:
+		return []string{typedValue}
+	case []string:
+		return typedValue
+	case []interface{}:
+		names := []string{}
+		for _, value := range typedValue {
+			if name, ok := value.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	default:
+		return nil
+	}
+}
+
+func (p Patch) resourceNames() []string {
+	names := []string{}
+	for name := range p.Resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
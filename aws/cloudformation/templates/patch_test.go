@@ -0,0 +1,152 @@
+package templates_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Patch", func() {
+	Describe("ParsePatch", func() {
+		It("parses the resources and outputs of the patch", func() {
+			patch, err := templates.ParsePatch("flow-logs.json", []byte(`{
+				"Resources": {
+					"FlowLogs": {
+						"Type": "AWS::EC2::FlowLog",
+						"Properties": {"ResourceId": {"Ref": "VPC"}}
+					},
+					"NATEIP": null
+				},
+				"Outputs": {
+					"FlowLogsID": {"Value": {"Ref": "FlowLogs"}}
+				}
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(patch.Name).To(Equal("flow-logs.json"))
+			Expect(patch.Resources).To(HaveLen(2))
+			Expect(patch.Resources["FlowLogs"].Type).To(Equal("AWS::EC2::FlowLog"))
+			Expect(patch.Resources["NATEIP"]).To(BeNil())
+			Expect(patch.Outputs).To(HaveKey("FlowLogsID"))
+		})
+
+		DescribeTable("returns an error for invalid patches", func(contents, expectedError string) {
+			_, err := templates.ParsePatch("some-patch.json", []byte(contents))
+			Expect(err).To(MatchError(ContainSubstring(expectedError)))
+		},
+			Entry("invalid json", `{`, "unexpected EOF"),
+			Entry("unknown sections", `{"Conditions": {}}`, `unknown field "Conditions"`),
+			Entry("unknown resource fields", `{"Resources": {"FlowLogs": {"Type": "AWS::EC2::FlowLog", "Condition": "x"}}}`, `unknown field "Condition"`),
+			Entry("empty patches", `{}`, "the patch must contain Resources or Outputs"),
+			Entry("invalid resource types", `{"Resources": {"FlowLogs": {"Type": "FlowLog"}}}`, `resource "FlowLogs" has an invalid type "FlowLog"`),
+		)
+	})
+
+	Describe("Template.Patch", func() {
+		var template templates.Template
+
+		BeforeEach(func() {
+			template = templates.Template{
+				Parameters: map[string]templates.Parameter{
+					"SSHKeyPairName": {Type: "AWS::EC2::KeyPair::KeyName"},
+				},
+				Resources: map[string]templates.Resource{
+					"VPC": {Type: "AWS::EC2::VPC"},
+					"NATInstance": {
+						Type:       "AWS::EC2::Instance",
+						Properties: templates.Instance{KeyName: templates.Ref{"SSHKeyPairName"}},
+					},
+					"NATEIP": {
+						Type:       "AWS::EC2::EIP",
+						Properties: templates.EIP{InstanceId: templates.Ref{"NATInstance"}},
+					},
+				},
+				Outputs: map[string]templates.Output{
+					"VPCID": {Value: templates.Ref{"VPC"}},
+				},
+			}
+		})
+
+		It("adds, replaces and removes resources and outputs", func() {
+			patched, err := template.Patch(templates.Patch{
+				Name: "some-patch.json",
+				Resources: map[string]*templates.Resource{
+					"FlowLogs": {
+						Type:       "AWS::EC2::FlowLog",
+						Properties: map[string]interface{}{"ResourceId": templates.Ref{"VPC"}},
+					},
+					"NATInstance": {
+						Type:       "AWS::EC2::Instance",
+						Properties: templates.Instance{InstanceType: "t2.small"},
+					},
+					"NATEIP": nil,
+				},
+				Outputs: map[string]*templates.Output{
+					"FlowLogsID": {Value: templates.Ref{"FlowLogs"}},
+					"VPCID":      nil,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(patched.Resources).To(HaveLen(3))
+			Expect(patched.Resources).To(HaveKey("FlowLogs"))
+			Expect(patched.Resources).NotTo(HaveKey("NATEIP"))
+			Expect(patched.Resources["NATInstance"].Properties).To(Equal(templates.Instance{InstanceType: "t2.small"}))
+			Expect(patched.Outputs).To(Equal(map[string]templates.Output{
+				"FlowLogsID": {Value: templates.Ref{"FlowLogs"}},
+			}))
+
+			Expect(template.Resources).To(HaveKey("NATEIP"))
+			Expect(template.Resources).NotTo(HaveKey("FlowLogs"))
+		})
+
+		It("returns the template unchanged when there are no patches", func() {
+			patched, err := template.Patch()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(patched).To(Equal(template))
+		})
+
+		DescribeTable("returns an error for patches that do not match the template", func(patch templates.Patch, expectedError string) {
+			patch.Name = "some-patch.json"
+
+			_, err := template.Patch(patch)
+			Expect(err).To(MatchError(expectedError))
+		},
+			Entry("removing a missing resource",
+				templates.Patch{Resources: map[string]*templates.Resource{"FlowLogs": nil}},
+				`cloudformation patch "some-patch.json" removes resource "FlowLogs", which is not in the template`),
+			Entry("changing the type of a resource",
+				templates.Patch{Resources: map[string]*templates.Resource{"VPC": {Type: "AWS::EC2::Subnet"}}},
+				`cloudformation patch "some-patch.json" changes the type of resource "VPC" from AWS::EC2::VPC to AWS::EC2::Subnet`),
+			Entry("removing a referenced resource",
+				templates.Patch{Resources: map[string]*templates.Resource{"NATInstance": nil}},
+				`resource "NATEIP" references "NATInstance", which is not in the template`),
+			Entry("depending on a missing resource",
+				templates.Patch{Resources: map[string]*templates.Resource{"FlowLogs": {Type: "AWS::EC2::FlowLog", DependsOn: []string{"FlowLogsRole"}}}},
+				`resource "FlowLogs" depends on "FlowLogsRole", which is not in the template`),
+			Entry("an output referencing a missing resource",
+				templates.Patch{Outputs: map[string]*templates.Output{"RoleARN": {Value: templates.FnGetAtt{[]string{"FlowLogsRole", "Arn"}}}}},
+				`output "RoleARN" references "FlowLogsRole", which is not in the template`),
+		)
+
+		DescribeTable("accepts the templates generated by bbl", func(lbType string) {
+			template := templates.NewTemplateBuilder(&fakes.Logger{}).Build("some-key-pair", 3, lbType, "some-certificate-arn", "some-iam-user", "some-env-id", defaultNetwork(3))
+
+			patched, err := template.Patch(templates.Patch{
+				Name: "some-patch.json",
+				Resources: map[string]*templates.Resource{
+					"FlowLogs": {Type: "AWS::EC2::FlowLog", Properties: map[string]interface{}{"ResourceId": templates.Ref{"VPC"}}},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(patched.Resources).To(HaveLen(len(template.Resources) + 1))
+		},
+			Entry("without a load balancer", ""),
+			Entry("with a concourse load balancer", "concourse"),
+			Entry("with a cf load balancer", "cf"),
+		)
+	})
+})
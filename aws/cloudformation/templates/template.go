@@ -2,10 +2,6 @@ package templates
 
 import "encoding/json"
 
-type AMI struct {
-	AMI string `json:",omitempty"`
-}
-
 type Ref struct {
 	Ref string `json:",omitempty"`
 }
@@ -129,16 +125,17 @@ type Route struct {
 	GatewayId            interface{} `json:",omitempty"`
 	RouteTableId         interface{} `json:",omitempty"`
 	InstanceId           interface{} `json:",omitempty"`
+	NatGatewayId         interface{} `json:",omitempty"`
 }
 
 type Instance struct {
-	InstanceType     string                 `json:",omitempty"`
-	PrivateIpAddress string                 `json:",omitempty"`
-	SubnetId         interface{}            `json:",omitempty"`
-	ImageId          map[string]interface{} `json:",omitempty"`
-	KeyName          interface{}            `json:",omitempty"`
-	SecurityGroupIds []interface{}          `json:",omitempty"`
-	Tags             []Tag                  `json:",omitempty"`
+	InstanceType     string        `json:",omitempty"`
+	PrivateIpAddress string        `json:",omitempty"`
+	SubnetId         interface{}   `json:",omitempty"`
+	ImageId          string        `json:",omitempty"`
+	KeyName          interface{}   `json:",omitempty"`
+	SecurityGroupIds []interface{} `json:",omitempty"`
+	Tags             []Tag         `json:",omitempty"`
 	SourceDestCheck  bool
 }
 
@@ -147,6 +144,11 @@ type EIP struct {
 	InstanceId interface{} `json:",omitempty"`
 }
 
+type NatGateway struct {
+	AllocationId interface{} `json:",omitempty"`
+	SubnetId     interface{} `json:",omitempty"`
+}
+
 type Subnet struct {
 	AvailabilityZone map[string]interface{} `json:",omitempty"`
 	CidrBlock        interface{}            `json:",omitempty"`
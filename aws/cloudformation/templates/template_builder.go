@@ -5,6 +5,21 @@ type logger interface {
 	Dot()
 }
 
+// Network holds the address ranges of the VPC and its subnets. There is an
+// internal and a load balancer subnet CIDR for each availability zone, and a
+// NAT subnet CIDR for each one when the NAT type is NATTypeGateway. NATIP and
+// NATAMI are only used by the NAT instance.
+type Network struct {
+	CIDR                    string
+	BOSHSubnetCIDR          string
+	NATType                 string
+	NATIP                   string
+	NATAMI                  string
+	NATSubnetCIDRs          []string
+	InternalSubnetCIDRs     []string
+	LoadBalancerSubnetCIDRs []string
+}
+
 type TemplateBuilder struct {
 	logger logger
 }
@@ -15,7 +30,7 @@ func NewTemplateBuilder(logger logger) TemplateBuilder {
 	}
 }
 
-func (t TemplateBuilder) Build(keyPairName string, numberOfAvailabilityZones int, lbType, lbCertificateARN string, iamUserName string, envID string) Template {
+func (t TemplateBuilder) Build(keyPairName string, numberOfAvailabilityZones int, lbType, lbCertificateARN string, iamUserName string, envID string, network Network) Template {
 	t.logger.Step("generating cloudformation template")
 
 	boshIAMTemplateBuilder := NewBOSHIAMTemplateBuilder()
@@ -29,16 +44,21 @@ func (t TemplateBuilder) Build(keyPairName string, numberOfAvailabilityZones int
 	loadBalancerSubnetsTemplateBuilder := NewLoadBalancerSubnetsTemplateBuilder()
 	loadBalancerTemplateBuilder := NewLoadBalancerTemplateBuilder()
 
+	natTemplate := natTemplateBuilder.NAT(network.NATIP, network.NATAMI)
+	if network.NATType == NATTypeGateway {
+		natTemplate = natTemplateBuilder.NATGateways(network.NATSubnetCIDRs)
+	}
+
 	template := Template{
 		AWSTemplateFormatVersion: "2010-09-09",
 		Description:              "Infrastructure for a BOSH deployment.",
 	}.Merge(
-		internalSubnetsTemplateBuilder.InternalSubnets(numberOfAvailabilityZones),
+		internalSubnetsTemplateBuilder.InternalSubnets(network.InternalSubnetCIDRs, network.NATType),
 		sshKeyPairTemplateBuilder.SSHKeyPairName(keyPairName),
 		boshIAMTemplateBuilder.BOSHIAMUser(iamUserName),
-		natTemplateBuilder.NAT(),
-		vpcTemplateBuilder.VPC(envID),
-		boshSubnetTemplateBuilder.BOSHSubnet(),
+		natTemplate,
+		vpcTemplateBuilder.VPC(envID, network.CIDR),
+		boshSubnetTemplateBuilder.BOSHSubnet(network.BOSHSubnetCIDR),
 		securityGroupTemplateBuilder.InternalSecurityGroup(),
 		securityGroupTemplateBuilder.BOSHSecurityGroup(),
 		boshEIPTemplateBuilder.BOSHEIP(),
@@ -49,7 +69,7 @@ func (t TemplateBuilder) Build(keyPairName string, numberOfAvailabilityZones int
 
 		lbTemplate := loadBalancerTemplateBuilder.ConcourseLoadBalancer(numberOfAvailabilityZones, lbCertificateARN)
 		template.Merge(
-			loadBalancerSubnetsTemplateBuilder.LoadBalancerSubnets(numberOfAvailabilityZones),
+			loadBalancerSubnetsTemplateBuilder.LoadBalancerSubnets(network.LoadBalancerSubnetCIDRs),
 			lbTemplate,
 			securityGroupTemplateBuilder.LBSecurityGroup("ConcourseSecurityGroup", "Concourse", "ConcourseLoadBalancer", lbTemplate),
 			securityGroupTemplateBuilder.LBInternalSecurityGroup("ConcourseInternalSecurityGroup", "ConcourseSecurityGroup", "ConcourseInternal", "ConcourseLoadBalancer", lbTemplate),
@@ -61,7 +81,7 @@ func (t TemplateBuilder) Build(keyPairName string, numberOfAvailabilityZones int
 		routerLBTemplate := loadBalancerTemplateBuilder.CFRouterLoadBalancer(numberOfAvailabilityZones, lbCertificateARN)
 		sshLBTemplate := loadBalancerTemplateBuilder.CFSSHProxyLoadBalancer(numberOfAvailabilityZones)
 		template.Merge(
-			loadBalancerSubnetsTemplateBuilder.LoadBalancerSubnets(numberOfAvailabilityZones),
+			loadBalancerSubnetsTemplateBuilder.LoadBalancerSubnets(network.LoadBalancerSubnetCIDRs),
 
 			routerLBTemplate,
 			securityGroupTemplateBuilder.LBSecurityGroup("CFRouterSecurityGroup", "Router", "CFRouterLoadBalancer", routerLBTemplate),
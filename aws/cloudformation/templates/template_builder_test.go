@@ -2,6 +2,7 @@ package templates_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
@@ -26,7 +27,7 @@ var _ = Describe("TemplateBuilder", func() {
 	Describe("Build", func() {
 		Context("concourse elb template", func() {
 			It("builds a cloudformation template", func() {
-				template := builder.Build("keypair-name", 5, "concourse", "", "", "")
+				template := builder.Build("keypair-name", 5, "concourse", "", "", "", defaultNetwork(5))
 				Expect(template.AWSTemplateFormatVersion).To(Equal("2010-09-09"))
 				Expect(template.Description).To(Equal("Infrastructure for a BOSH deployment with a Concourse ELB."))
 
@@ -56,7 +57,7 @@ var _ = Describe("TemplateBuilder", func() {
 
 		Context("cf elb template", func() {
 			It("builds a cloudformation template", func() {
-				template := builder.Build("keypair-name", 5, "cf", "", "", "")
+				template := builder.Build("keypair-name", 5, "cf", "", "", "", defaultNetwork(5))
 				Expect(template.AWSTemplateFormatVersion).To(Equal("2010-09-09"))
 				Expect(template.Description).To(Equal("Infrastructure for a BOSH deployment with a CloudFoundry ELB."))
 
@@ -89,7 +90,7 @@ var _ = Describe("TemplateBuilder", func() {
 
 		Context("no elb template", func() {
 			It("builds a cloudformation template", func() {
-				template := builder.Build("keypair-name", 5, "", "", "", "")
+				template := builder.Build("keypair-name", 5, "", "", "", "", defaultNetwork(5))
 				Expect(template.AWSTemplateFormatVersion).To(Equal("2010-09-09"))
 				Expect(template.Description).To(Equal("Infrastructure for a BOSH deployment."))
 
@@ -121,8 +122,86 @@ var _ = Describe("TemplateBuilder", func() {
 			})
 		})
 
+		It("uses the network for the VPC, the subnets and the NAT", func() {
+			template := builder.Build("keypair-name", 2, "concourse", "", "", "", templates.Network{
+				CIDR:                    "172.20.0.0/16",
+				BOSHSubnetCIDR:          "172.20.0.0/24",
+				NATIP:                   "172.20.0.11",
+				NATAMI:                  "ami-other-nat-ami",
+				InternalSubnetCIDRs:     []string{"172.20.16.0/20", "172.20.32.0/20"},
+				LoadBalancerSubnetCIDRs: []string{"172.20.2.0/24", "172.20.3.0/24"},
+			})
+
+			Expect(template.Parameters["VPCCIDR"].Default).To(Equal("172.20.0.0/16"))
+			Expect(template.Parameters["BOSHSubnetCIDR"].Default).To(Equal("172.20.0.0/24"))
+			Expect(template.Parameters["InternalSubnet1CIDR"].Default).To(Equal("172.20.16.0/20"))
+			Expect(template.Parameters["InternalSubnet2CIDR"].Default).To(Equal("172.20.32.0/20"))
+			Expect(template.Parameters["LoadBalancerSubnet1CIDR"].Default).To(Equal("172.20.2.0/24"))
+			Expect(template.Parameters["LoadBalancerSubnet2CIDR"].Default).To(Equal("172.20.3.0/24"))
+			Expect(template.Resources["NATInstance"].Properties.(templates.Instance).PrivateIpAddress).To(Equal("172.20.0.11"))
+			Expect(template.Resources["NATInstance"].Properties.(templates.Instance).ImageId).To(Equal("ami-other-nat-ami"))
+		})
+
+		It("replaces the NAT instance with a NAT gateway per availability zone when the NAT type is gateway", func() {
+			network := defaultNetwork(2)
+			network.NATType = templates.NATTypeGateway
+			network.NATSubnetCIDRs = []string{"10.0.1.0/28", "10.0.1.16/28"}
+
+			template := builder.Build("keypair-name", 2, "", "", "", "", network)
+
+			Expect(template.Resources).NotTo(HaveKey("NATInstance"))
+			Expect(template.Resources).NotTo(HaveKey("NATSecurityGroup"))
+			Expect(template.Resources).NotTo(HaveKey("InternalRouteTable"))
+			Expect(template.Resources).To(HaveKey("NATGateway1"))
+			Expect(template.Resources).To(HaveKey("NATGateway2"))
+			Expect(template.Resources).To(HaveKey("InternalRoute1"))
+			Expect(template.Resources).To(HaveKey("InternalRoute2"))
+			Expect(template.Parameters["NATSubnet2CIDR"].Default).To(Equal("10.0.1.16/28"))
+
+			_, err := template.Patch(templates.Patch{Name: "no-op.json", Outputs: map[string]*templates.Output{}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("moves the internal subnets to a route table per availability zone when the NAT instance is replaced by NAT gateways", func() {
+			instanceTemplate := builder.Build("keypair-name", 2, "", "", "", "", defaultNetwork(2))
+
+			network := defaultNetwork(2)
+			network.NATType = templates.NATTypeGateway
+			network.NATSubnetCIDRs = []string{"10.0.1.0/28", "10.0.1.16/28"}
+			gatewayTemplate := builder.Build("keypair-name", 2, "", "", "", "", network)
+
+			Expect(instanceTemplate.Resources["InternalRoute"].Properties).To(Equal(templates.Route{
+				DestinationCidrBlock: "0.0.0.0/0",
+				RouteTableId:         templates.Ref{"InternalRouteTable"},
+				InstanceId:           templates.Ref{"NATInstance"},
+			}))
+			Expect(gatewayTemplate.Resources).NotTo(HaveKey("InternalRoute"))
+
+			for _, suffix := range []string{"1", "2"} {
+				routeTableName := fmt.Sprintf("InternalRouteTable%s", suffix)
+				associationName := fmt.Sprintf("InternalSubnet%sRouteTableAssociation", suffix)
+
+				Expect(instanceTemplate.Resources).NotTo(HaveKey(routeTableName))
+				Expect(instanceTemplate.Resources[associationName].Properties).To(Equal(templates.SubnetRouteTableAssociation{
+					RouteTableId: templates.Ref{"InternalRouteTable"},
+					SubnetId:     templates.Ref{fmt.Sprintf("InternalSubnet%s", suffix)},
+				}))
+
+				Expect(gatewayTemplate.Resources[routeTableName].Type).To(Equal("AWS::EC2::RouteTable"))
+				Expect(gatewayTemplate.Resources[fmt.Sprintf("InternalRoute%s", suffix)].Properties).To(Equal(templates.Route{
+					DestinationCidrBlock: "0.0.0.0/0",
+					RouteTableId:         templates.Ref{routeTableName},
+					NatGatewayId:         templates.Ref{fmt.Sprintf("NATGateway%s", suffix)},
+				}))
+				Expect(gatewayTemplate.Resources[associationName].Properties).To(Equal(templates.SubnetRouteTableAssociation{
+					RouteTableId: templates.Ref{routeTableName},
+					SubnetId:     templates.Ref{fmt.Sprintf("InternalSubnet%s", suffix)},
+				}))
+			}
+		})
+
 		It("logs that the cloudformation template is being generated", func() {
-			builder.Build("keypair-name", 0, "", "", "", "")
+			builder.Build("keypair-name", 0, "", "", "", "", defaultNetwork(0))
 
 			Expect(logger.StepCall.Receives.Message).To(Equal("generating cloudformation template"))
 		})
@@ -130,7 +209,7 @@ var _ = Describe("TemplateBuilder", func() {
 
 	Describe("template marshaling", func() {
 		DescribeTable("marshals template to JSON", func(lbType string, fixture string) {
-			template := builder.Build("keypair-name", 4, lbType, "some-certificate-arn", "bosh-iam-user-some-env-id", "bbl-env-id")
+			template := builder.Build("keypair-name", 4, lbType, "some-certificate-arn", "bosh-iam-user-some-env-id", "bbl-env-id", defaultNetwork(4))
 
 			buf, err := ioutil.ReadFile("fixtures/" + fixture)
 			Expect(err).NotTo(HaveOccurred())
@@ -146,3 +225,19 @@ var _ = Describe("TemplateBuilder", func() {
 		)
 	})
 })
+
+func defaultNetwork(azCount int) templates.Network {
+	network := templates.Network{
+		CIDR:           "10.0.0.0/16",
+		BOSHSubnetCIDR: "10.0.0.0/24",
+		NATIP:          "10.0.0.7",
+		NATAMI:         "ami-some-nat-ami",
+	}
+
+	for i := 1; i <= azCount; i++ {
+		network.InternalSubnetCIDRs = append(network.InternalSubnetCIDRs, fmt.Sprintf("10.0.%d.0/20", 16*i))
+		network.LoadBalancerSubnetCIDRs = append(network.LoadBalancerSubnetCIDRs, fmt.Sprintf("10.0.%d.0/24", i+1))
+	}
+
+	return network
+}
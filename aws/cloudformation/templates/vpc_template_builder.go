@@ -8,13 +8,13 @@ func NewVPCTemplateBuilder() VPCTemplateBuilder {
 	return VPCTemplateBuilder{}
 }
 
-func (t VPCTemplateBuilder) VPC(envID string) Template {
+func (t VPCTemplateBuilder) VPC(envID, cidr string) Template {
 	return Template{
 		Parameters: map[string]Parameter{
 			"VPCCIDR": Parameter{
 				Description: "CIDR block for the VPC.",
 				Type:        "String",
-				Default:     "10.0.0.0/16",
+				Default:     cidr,
 			},
 		},
 
@@ -19,7 +19,7 @@ var _ = Describe("VPCTemplateBuilder", func() {
 
 	Describe("VPC", func() {
 		It("returns a template with the VPC-related parameters", func() {
-			vpc := builder.VPC("")
+			vpc := builder.VPC("", "10.0.0.0/16")
 
 			Expect(vpc.Parameters).To(HaveLen(1))
 			Expect(vpc.Parameters).To(HaveKeyWithValue("VPCCIDR", templates.Parameter{
@@ -31,7 +31,7 @@ var _ = Describe("VPCTemplateBuilder", func() {
 
 		It("returns a template with the VPC-related resources", func() {
 			envID := fmt.Sprintf("some-env-id-%v", rand.Int())
-			vpc := builder.VPC(envID)
+			vpc := builder.VPC(envID, "10.0.0.0/16")
 
 			Expect(vpc.Resources).To(HaveLen(3))
 			Expect(vpc.Resources).To(HaveKeyWithValue("VPC", templates.Resource{
@@ -61,7 +61,7 @@ var _ = Describe("VPCTemplateBuilder", func() {
 		})
 
 		It("returns a template with the VPC-related outputs", func() {
-			vpc := builder.VPC("")
+			vpc := builder.VPC("", "10.0.0.0/16")
 
 			Expect(vpc.Outputs).To(HaveKeyWithValue("VPCID", templates.Output{
 				Value: templates.Ref{Ref: "VPC"},
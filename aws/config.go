@@ -3,18 +3,25 @@ package aws
 import (
 	goaws "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/session"
 )
 
 type Config struct {
 	AccessKeyID      string
 	SecretAccessKey  string
+	SessionToken     string
+	Profile          string
+	AssumeRoleARN    string
+	ExternalID       string
 	Region           string
 	EndpointOverride string
 }
 
 func (c Config) ClientConfig() *goaws.Config {
 	awsConfig := &goaws.Config{
-		Credentials: credentials.NewStaticCredentials(c.AccessKeyID, c.SecretAccessKey, ""),
+		Credentials: c.credentials(),
 		Region:      goaws.String(c.Region),
 	}
 
@@ -22,5 +29,27 @@ func (c Config) ClientConfig() *goaws.Config {
 		awsConfig.WithEndpoint(c.EndpointOverride)
 	}
 
+	if c.AssumeRoleARN != "" {
+		awsConfig.Credentials = stscreds.NewCredentials(session.New(awsConfig.Copy()), c.AssumeRoleARN, func(provider *stscreds.AssumeRoleProvider) {
+			if c.ExternalID != "" {
+				provider.ExternalID = goaws.String(c.ExternalID)
+			}
+		})
+	}
+
 	return awsConfig
 }
+
+// credentials prefers the access keys, then the named profile from the shared
+// credentials file, and otherwise falls back to the environment and the
+// instance metadata the same way the AWS CLI does.
+func (c Config) credentials() *credentials.Credentials {
+	switch {
+	case c.AccessKeyID != "":
+		return credentials.NewStaticCredentials(c.AccessKeyID, c.SecretAccessKey, c.SessionToken)
+	case c.Profile != "":
+		return credentials.NewSharedCredentials("", c.Profile)
+	default:
+		return defaults.CredChain(defaults.Config(), defaults.Handlers())
+	}
+}
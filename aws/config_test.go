@@ -1,6 +1,14 @@
 package aws_test
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+
 	goaws "github.com/aws/aws-sdk-go/aws"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -27,5 +35,161 @@ var _ = Describe("Config", func() {
 
 			Expect(config.ClientConfig()).To(Equal(awsConfig))
 		})
+
+		It("uses the session token with the access keys", func() {
+			config := aws.Config{
+				AccessKeyID:     "some-access-key-id",
+				SecretAccessKey: "some-secret-access-key",
+				SessionToken:    "some-session-token",
+				Region:          "some-region",
+			}
+
+			value, err := config.ClientConfig().Credentials.Get()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value.AccessKeyID).To(Equal("some-access-key-id"))
+			Expect(value.SecretAccessKey).To(Equal("some-secret-access-key"))
+			Expect(value.SessionToken).To(Equal("some-session-token"))
+		})
+
+		Context("when there are no access keys", func() {
+			var (
+				tempDir     string
+				environment map[string]string
+			)
+
+			setEnv := func(name, value string) {
+				if _, ok := environment[name]; !ok {
+					environment[name] = os.Getenv(name)
+				}
+				os.Setenv(name, value)
+			}
+
+			BeforeEach(func() {
+				var err error
+				tempDir, err = ioutil.TempDir("", "")
+				Expect(err).NotTo(HaveOccurred())
+
+				environment = map[string]string{}
+				setEnv("AWS_ACCESS_KEY_ID", "")
+				setEnv("AWS_SECRET_ACCESS_KEY", "")
+				setEnv("AWS_SESSION_TOKEN", "")
+				setEnv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(tempDir, "credentials"))
+			})
+
+			AfterEach(func() {
+				for name, value := range environment {
+					os.Setenv(name, value)
+				}
+				os.RemoveAll(tempDir)
+			})
+
+			It("reads the named profile from the shared credentials file", func() {
+				err := ioutil.WriteFile(filepath.Join(tempDir, "credentials"), []byte(`
+[default]
+aws_access_key_id = default-access-key-id
+aws_secret_access_key = default-secret-access-key
+
+[some-profile]
+aws_access_key_id = profile-access-key-id
+aws_secret_access_key = profile-secret-access-key
+aws_session_token = profile-session-token
+`), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+
+				config := aws.Config{
+					Profile: "some-profile",
+					Region:  "some-region",
+				}
+
+				value, err := config.ClientConfig().Credentials.Get()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(value.AccessKeyID).To(Equal("profile-access-key-id"))
+				Expect(value.SecretAccessKey).To(Equal("profile-secret-access-key"))
+				Expect(value.SessionToken).To(Equal("profile-session-token"))
+			})
+
+			It("falls back to the credentials in the environment", func() {
+				setEnv("AWS_ACCESS_KEY_ID", "env-access-key-id")
+				setEnv("AWS_SECRET_ACCESS_KEY", "env-secret-access-key")
+				setEnv("AWS_SESSION_TOKEN", "env-session-token")
+
+				config := aws.Config{
+					Region: "some-region",
+				}
+
+				value, err := config.ClientConfig().Credentials.Get()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(value.AccessKeyID).To(Equal("env-access-key-id"))
+				Expect(value.SecretAccessKey).To(Equal("env-secret-access-key"))
+				Expect(value.SessionToken).To(Equal("env-session-token"))
+			})
+		})
+
+		Context("when a role ARN is provided", func() {
+			var (
+				server *httptest.Server
+				params url.Values
+			)
+
+			BeforeEach(func() {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					defer GinkgoRecover()
+
+					Expect(r.ParseForm()).To(Succeed())
+					params = r.PostForm
+
+					fmt.Fprint(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>role-access-key-id</AccessKeyId>
+      <SecretAccessKey>role-secret-access-key</SecretAccessKey>
+      <SessionToken>role-session-token</SessionToken>
+      <Expiration>2100-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`)
+				}))
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			It("assumes the role with the base credentials and the external ID", func() {
+				config := aws.Config{
+					AccessKeyID:      "some-access-key-id",
+					SecretAccessKey:  "some-secret-access-key",
+					AssumeRoleARN:    "arn:aws:iam::123456789012:role/some-role",
+					ExternalID:       "some-external-id",
+					Region:           "some-region",
+					EndpointOverride: server.URL,
+				}
+
+				value, err := config.ClientConfig().Credentials.Get()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(value.AccessKeyID).To(Equal("role-access-key-id"))
+				Expect(value.SecretAccessKey).To(Equal("role-secret-access-key"))
+				Expect(value.SessionToken).To(Equal("role-session-token"))
+
+				Expect(params.Get("Action")).To(Equal("AssumeRole"))
+				Expect(params.Get("RoleArn")).To(Equal("arn:aws:iam::123456789012:role/some-role"))
+				Expect(params.Get("ExternalId")).To(Equal("some-external-id"))
+			})
+
+			It("does not send an external ID when none is provided", func() {
+				config := aws.Config{
+					AccessKeyID:      "some-access-key-id",
+					SecretAccessKey:  "some-secret-access-key",
+					AssumeRoleARN:    "arn:aws:iam::123456789012:role/some-role",
+					Region:           "some-region",
+					EndpointOverride: server.URL,
+				}
+
+				_, err := config.ClientConfig().Credentials.Get()
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(params).NotTo(HaveKey("ExternalId"))
+			})
+		})
 	})
 })
@@ -0,0 +1,61 @@
+package ec2
+
+import (
+	"fmt"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+)
+
+const natAMIName = "amzn-ami-vpc-nat-hvm-*"
+
+type NATAMIResolver struct {
+	ec2ClientProvider ec2ClientProvider
+}
+
+func NewNATAMIResolver(ec2ClientProvider ec2ClientProvider) NATAMIResolver {
+	return NATAMIResolver{
+		ec2ClientProvider: ec2ClientProvider,
+	}
+}
+
+// Resolve returns the ID of the most recent Amazon Linux NAT AMI.
+func (r NATAMIResolver) Resolve(region string) (string, error) {
+	output, err := r.ec2ClientProvider.GetEC2Client().DescribeImages(&awsec2.DescribeImagesInput{
+		Owners: []*string{goaws.String("amazon")},
+		Filters: []*awsec2.Filter{
+			{
+				Name:   goaws.String("name"),
+				Values: []*string{goaws.String(natAMIName)},
+			},
+			{
+				Name:   goaws.String("architecture"),
+				Values: []*string{goaws.String("x86_64")},
+			},
+			{
+				Name:   goaws.String("state"),
+				Values: []*string{goaws.String("available")},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var latest *awsec2.Image
+	for _, image := range output.Images {
+		if image == nil || goaws.StringValue(image.ImageId) == "" {
+			continue
+		}
+
+		if latest == nil || goaws.StringValue(image.CreationDate) > goaws.StringValue(latest.CreationDate) {
+			latest = image
+		}
+	}
+
+	if latest == nil {
+		return "", fmt.Errorf("no Amazon NAT AMI was found in %s", region)
+	}
+
+	return goaws.StringValue(latest.ImageId), nil
+}
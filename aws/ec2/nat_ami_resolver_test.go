@@ -0,0 +1,78 @@
+package ec2_test
+
+import (
+	"errors"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	awsec2 "github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NATAMIResolver", func() {
+	var (
+		natAMIResolver    ec2.NATAMIResolver
+		ec2Client         *fakes.EC2Client
+		ec2ClientProvider *fakes.ClientProvider
+	)
+
+	BeforeEach(func() {
+		ec2Client = &fakes.EC2Client{}
+		ec2ClientProvider = &fakes.ClientProvider{}
+		ec2ClientProvider.GetEC2ClientCall.Returns.EC2Client = ec2Client
+		natAMIResolver = ec2.NewNATAMIResolver(ec2ClientProvider)
+	})
+
+	It("returns the most recent Amazon NAT AMI", func() {
+		ec2Client.DescribeImagesCall.Returns.Output = &awsec2.DescribeImagesOutput{
+			Images: []*awsec2.Image{
+				{ImageId: goaws.String("ami-older"), CreationDate: goaws.String("2016-10-26T22:32:29.000Z")},
+				{ImageId: goaws.String("ami-latest"), CreationDate: goaws.String("2017-01-20T23:39:56.000Z")},
+				{ImageId: goaws.String("ami-old"), CreationDate: goaws.String("2016-12-16T18:04:53.000Z")},
+			},
+		}
+
+		ami, err := natAMIResolver.Resolve("us-east-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ami).To(Equal("ami-latest"))
+
+		Expect(ec2Client.DescribeImagesCall.Receives.Input).To(Equal(&awsec2.DescribeImagesInput{
+			Owners: []*string{goaws.String("amazon")},
+			Filters: []*awsec2.Filter{
+				{
+					Name:   goaws.String("name"),
+					Values: []*string{goaws.String("amzn-ami-vpc-nat-hvm-*")},
+				},
+				{
+					Name:   goaws.String("architecture"),
+					Values: []*string{goaws.String("x86_64")},
+				},
+				{
+					Name:   goaws.String("state"),
+					Values: []*string{goaws.String("available")},
+				},
+			},
+		}))
+	})
+
+	Describe("failure cases", func() {
+		It("returns an error when there are no NAT AMIs in the region", func() {
+			ec2Client.DescribeImagesCall.Returns.Output = &awsec2.DescribeImagesOutput{
+				Images: []*awsec2.Image{nil, {CreationDate: goaws.String("2017-01-20T23:39:56.000Z")}},
+			}
+
+			_, err := natAMIResolver.Resolve("ap-south-1")
+			Expect(err).To(MatchError("no Amazon NAT AMI was found in ap-south-1"))
+		})
+
+		It("returns an error when the describe images call fails", func() {
+			ec2Client.DescribeImagesCall.Returns.Error = errors.New("failed to describe images")
+
+			_, err := natAMIResolver.Resolve("us-east-1")
+			Expect(err).To(MatchError("failed to describe images"))
+		})
+	})
+})
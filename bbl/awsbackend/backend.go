@@ -118,6 +118,15 @@ func (b *Backend) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.Des
 	}, nil
 }
 
+func (b *Backend) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	return &ec2.DescribeImagesOutput{
+		Images: []*ec2.Image{{
+			ImageId:      aws.String("ami-some-nat-ami"),
+			CreationDate: aws.String("2017-01-20T23:39:56.000Z"),
+		}},
+	}, nil
+}
+
 func (b *Backend) CreateStack(input *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
 	stack := Stack{
 		Name:     *input.StackName,
@@ -0,0 +1,82 @@
+package main_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("encrypted state", func() {
+	var (
+		tempDirectory string
+		stateKeyFile  string
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		tempDirectory, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		stateKeyFile = filepath.Join(tempDirectory, "state.key")
+		err = ioutil.WriteFile(stateKeyFile, []byte("some-state-key\n"), os.ModePerm)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = storage.NewStore(storage.NewLocalBackend(tempDirectory), "some-state-key", nil, storage.DefaultStateBackups).Set(storage.State{
+			BOSH: storage.BOSH{
+				DirectorAddress: "some-director-url",
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("reads the state with the key from --state-key-file", func() {
+		args := []string{
+			"--state-dir", tempDirectory,
+			"--state-key-file", stateKeyFile,
+			"director-address",
+		}
+
+		session, err := gexec.Start(exec.Command(pathToBBL, args...), GinkgoWriter, GinkgoWriter)
+
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Out.Contents()).To(ContainSubstring("some-director-url"))
+	})
+
+	It("reads the state with the key from BBL_STATE_KEY", func() {
+		args := []string{
+			"--state-dir", tempDirectory,
+			"director-address",
+		}
+
+		cmd := exec.Command(pathToBBL, args...)
+		cmd.Env = append(os.Environ(), "BBL_STATE_KEY=some-state-key")
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Out.Contents()).To(ContainSubstring("some-director-url"))
+	})
+
+	Context("failure cases", func() {
+		It("exits 1 when no state key is provided", func() {
+			args := []string{
+				"--state-dir", tempDirectory,
+				"director-address",
+			}
+
+			session, err := gexec.Start(exec.Command(pathToBBL, args...), GinkgoWriter, GinkgoWriter)
+
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(1))
+			Expect(session.Err.Contents()).To(ContainSubstring("bbl-state.json is encrypted, please provide the state key with --state-key-file or BBL_STATE_KEY"))
+		})
+	})
+})
@@ -89,7 +89,7 @@ var _ = Describe("bbl up gcp", func() {
 		executeCommand(args, 0)
 
 		state := readStateJson(tempDirectory)
-		Expect(state.Version).To(Equal(2))
+		Expect(state.Version).To(Equal(3))
 		Expect(state.IAAS).To(Equal("gcp"))
 		Expect(state.GCP.ServiceAccountKey).To(Equal(serviceAccountKey))
 		Expect(state.GCP.ProjectID).To(Equal("some-project-id"))
@@ -124,7 +124,7 @@ var _ = Describe("bbl up gcp", func() {
 			executeCommand(args, 0)
 
 			state := readStateJson(tempDirectory)
-			Expect(state.Version).To(Equal(2))
+			Expect(state.Version).To(Equal(3))
 			Expect(state.IAAS).To(Equal("gcp"))
 			Expect(state.GCP.ServiceAccountKey).To(Equal(serviceAccountKey))
 			Expect(state.GCP.ProjectID).To(Equal("some-project-id"))
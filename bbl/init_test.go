@@ -73,11 +73,7 @@ func writeStateJson(state storage.State, tempDirectory string) {
 }
 
 func readStateJson(tempDirectory string) storage.State {
-	buf, err := ioutil.ReadFile(filepath.Join(tempDirectory, storage.StateFileName))
-	Expect(err).NotTo(HaveOccurred())
-
-	var state storage.State
-	err = json.Unmarshal(buf, &state)
+	state, err := storage.GetState(storage.NewLocalBackend(tempDirectory), "")
 	Expect(err).NotTo(HaveOccurred())
 
 	return state
@@ -0,0 +1,66 @@
+package main_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("json output", func() {
+	var tempDirectory string
+
+	BeforeEach(func() {
+		var err error
+
+		tempDirectory, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDirectory)
+	})
+
+	It("prints state queries as json documents", func() {
+		err := ioutil.WriteFile(filepath.Join(tempDirectory, "bbl-state.json"), []byte(`{
+			"version": 3,
+			"iaas": "gcp",
+			"envID": "some-env-id",
+			"bosh": {"directorAddress": "some-director-address"}
+		}`), os.ModePerm)
+		Expect(err).NotTo(HaveOccurred())
+
+		session, err := gexec.Start(exec.Command(pathToBBL, "--state-dir", tempDirectory, "--output", "json", "director-address"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Out.Contents()).To(MatchJSON(`{"directorAddress": "some-director-address"}`))
+
+		session, err = gexec.Start(exec.Command(pathToBBL, "--state-dir", tempDirectory, "--output", "json", "state"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		var summary map[string]interface{}
+		Expect(json.Unmarshal(session.Out.Contents(), &summary)).To(Succeed())
+		Expect(summary["envID"]).To(Equal("some-env-id"))
+		Expect(summary["directorAddress"]).To(Equal("some-director-address"))
+	})
+
+	It("prints errors as json with a distinct exit code", func() {
+		session, err := gexec.Start(exec.Command(pathToBBL, "--state-dir", tempDirectory, "--output", "json", "director-address"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(3))
+
+		var output struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		Expect(json.Unmarshal(session.Err.Contents(), &output)).To(Succeed())
+		Expect(output.Error.Code).To(Equal("state_not_found"))
+	})
+})
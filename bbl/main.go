@@ -54,6 +54,16 @@ func main() {
 		commands.DeleteLBsCommand:        nil,
 		commands.LBsCommand:              nil,
 		commands.EnvIDCommand:            nil,
+		commands.ForceUnlockCommand:      nil,
+		commands.StateHistoryCommand:     nil,
+		commands.RestoreStateCommand:     nil,
+		commands.ExportCommand:           nil,
+		commands.ImportCommand:           nil,
+		commands.PrintEnvCommand:         nil,
+		commands.StateCommand:            nil,
+		commands.OutputsCommand:          nil,
+		commands.PlanCommand:             nil,
+		commands.VersionsCommand:         nil,
 	}
 
 	// Utilities
@@ -72,16 +82,35 @@ func main() {
 	configurationParser := application.NewConfigurationParser(commandLineParser)
 	configuration, err := configurationParser.Parse(os.Args[1:])
 	if err != nil {
-		fail(err)
+		fail(err, configuration.Global.Output)
 	}
 
-	stateStore := storage.NewStore(configuration.Global.StateDir)
-	stateValidator := application.NewStateValidator(configuration.Global.StateDir)
+	stateBackend, err := storage.NewBackend(configuration.Global.StateDir, configuration.Global.StateBackend)
+	if err != nil {
+		fail(err, configuration.Global.Output)
+	}
+
+	terraformOverrides, err := storage.NewTerraformOverridesBackend(configuration.Global.StateDir, configuration.Global.StateBackend)
+	if err != nil {
+		fail(err, configuration.Global.Output)
+	}
+
+	secretStore, err := storage.NewSecretStore(configuration.Global.SecretStore, configuration.Global.SecretStoreToken)
+	if err != nil {
+		fail(err, configuration.Global.Output)
+	}
+
+	stateStore := storage.NewStore(stateBackend, configuration.Global.StateKey, secretStore, configuration.Global.StateBackups)
+	stateValidator := application.NewStateValidator(stateBackend)
+	stateLocker := storage.NewLocker(stateBackend)
 
 	// Amazon
 	awsConfiguration := aws.Config{
 		AccessKeyID:      configuration.State.AWS.AccessKeyID,
 		SecretAccessKey:  configuration.State.AWS.SecretAccessKey,
+		Profile:          configuration.State.AWS.Profile,
+		AssumeRoleARN:    configuration.State.AWS.AssumeRoleARN,
+		ExternalID:       configuration.State.AWS.ExternalID,
 		Region:           configuration.State.AWS.Region,
 		EndpointOverride: configuration.Global.EndpointOverride,
 	}
@@ -97,6 +126,7 @@ func main() {
 	keyPairManager := ec2.NewKeyPairManager(awsKeyPairCreator, keyPairChecker, logger)
 	keyPairSynchronizer := ec2.NewKeyPairSynchronizer(keyPairManager)
 	availabilityZoneRetriever := ec2.NewAvailabilityZoneRetriever(clientProvider)
+	natAMIResolver := ec2.NewNATAMIResolver(clientProvider)
 	templateBuilder := templates.NewTemplateBuilder(logger)
 	stackManager := cloudformation.NewStackManager(clientProvider, logger)
 	infrastructureManager := cloudformation.NewInfrastructureManager(templateBuilder, stackManager)
@@ -119,12 +149,12 @@ func main() {
 	// bosh-init
 	tempDir, err := ioutil.TempDir("", "bosh-init")
 	if err != nil {
-		fail(err)
+		fail(err, configuration.Global.Output)
 	}
 
 	boshInitPath, err := exec.LookPath("bosh-init")
 	if err != nil {
-		fail(err)
+		fail(err, configuration.Global.Output)
 	}
 
 	cloudProviderManifestBuilder := manifests.NewCloudProviderManifestBuilder(stringGenerator)
@@ -153,15 +183,15 @@ func main() {
 	boshinitCommandBuilder := boshinit.NewCommandBuilder(boshInitPath, tempDir, os.Stdout, os.Stderr)
 	boshinitDeployCommand := boshinitCommandBuilder.DeployCommand()
 	boshinitDeleteCommand := boshinitCommandBuilder.DeleteCommand()
-	boshinitDeployRunner := boshinit.NewCommandRunner(tempDir, boshinitDeployCommand)
-	boshinitDeleteRunner := boshinit.NewCommandRunner(tempDir, boshinitDeleteCommand)
+	boshinitDeployRunner := boshinit.NewCommandRunner(tempDir, helpers.NewInterruptibleCommand(boshinitDeployCommand))
+	boshinitDeleteRunner := boshinit.NewCommandRunner(tempDir, helpers.NewInterruptibleCommand(boshinitDeleteCommand))
 	boshinitExecutor := boshinit.NewExecutor(
 		boshinitManifestBuilder, boshinitDeployRunner, boshinitDeleteRunner, logger,
 	)
 
 	// Terraform
 	terraformCmd := terraform.NewCmd(os.Stderr)
-	terraformExecutor := terraform.NewExecutor(terraformCmd)
+	terraformExecutor := terraform.NewExecutor(terraformCmd, terraformOverrides)
 	terraformOutputter := terraform.NewOutputter(terraformCmd)
 
 	// BOSH
@@ -173,8 +203,8 @@ func main() {
 	// Subcommands
 	awsUp := commands.NewAWSUp(
 		credentialValidator, infrastructureManager, keyPairSynchronizer, boshinitExecutor,
-		stringGenerator, cloudConfigurator, availabilityZoneRetriever, certificateDescriber,
-		cloudConfigManager, boshClientProvider, stateStore, clientProvider)
+		stringGenerator, cloudConfigurator, availabilityZoneRetriever, natAMIResolver, certificateDescriber,
+		cloudConfigManager, boshClientProvider, stateStore, clientProvider, logger, os.Stdin)
 
 	awsCreateLBs := commands.NewAWSCreateLBs(
 		logger, credentialValidator, certificateManager, infrastructureManager,
@@ -196,57 +226,73 @@ func main() {
 	gcpDeleteLBs := commands.NewGCPDeleteLBs(terraformOutputter, gcpCloudConfigGenerator, zones, logger,
 		boshClientProvider, stateStore, terraformExecutor)
 
-	gcpUp := commands.NewGCPUp(stateStore, gcpKeyPairUpdater, gcpClientProvider, terraformExecutor, boshinitExecutor, stringGenerator, logger, boshClientProvider, gcpCloudConfigGenerator, terraformOutputter, zones)
+	awsPlan := commands.NewAWSPlan(credentialValidator, infrastructureManager, availabilityZoneRetriever, certificateDescriber,
+		boshinitExecutor, stringGenerator, cloudConfigurator, cloudConfigManager, boshClientProvider, os.Stdout)
+	gcpPlan := commands.NewGCPPlan(terraformExecutor, terraformOutputter, boshinitExecutor, stringGenerator,
+		gcpCloudConfigGenerator, boshClientProvider, zones, os.Stdout)
+
+	gcpUp := commands.NewGCPUp(stateStore, gcpKeyPairUpdater, gcpClientProvider, terraformExecutor, boshinitExecutor, stringGenerator, logger, boshClientProvider, gcpCloudConfigGenerator, terraformOutputter, zones, os.Stdin)
 	envGetter := commands.NewEnvGetter()
 
 	// Commands
 	commandSet[commands.HelpCommand] = commands.NewUsage(os.Stdout)
 	commandSet[commands.VersionCommand] = commands.NewVersion(Version, os.Stdout)
+	commandSet[commands.VersionsCommand] = commands.NewVersions(boshinitManifestBuilder, os.Stdout, configuration.Global.Output)
 
 	commandSet[commands.UpCommand] = commands.NewUp(awsUp, gcpUp, envGetter, envIDGenerator)
+	commandSet[commands.PlanCommand] = commands.NewPlan(awsPlan, gcpPlan, stateValidator)
 
 	commandSet[commands.DestroyCommand] = commands.NewDestroy(
 		credentialValidator, logger, os.Stdin, boshinitExecutor, vpcStatusChecker, stackManager,
 		stringGenerator, infrastructureManager, awsKeyPairDeleter, gcpKeyPairDeleter, certificateDeleter,
-		stateStore, stateValidator, terraformExecutor, terraformOutputter, gcpNetworkInstancesChecker,
+		stateStore, stateValidator, terraformExecutor, terraformOutputter, gcpNetworkInstancesChecker, zones,
 	)
 
 	commandSet[commands.CreateLBsCommand] = commands.NewCreateLBs(awsCreateLBs, gcpCreateLBs, stateValidator)
 	commandSet[commands.UpdateLBsCommand] = commands.NewUpdateLBs(awsUpdateLBs, gcpUpdateLBs, certificateValidator, stateValidator, logger)
 	commandSet[commands.DeleteLBsCommand] = commands.NewDeleteLBs(gcpDeleteLBs, awsDeleteLBs, logger, stateValidator)
-	commandSet[commands.LBsCommand] = commands.NewLBs(credentialValidator, stateValidator, infrastructureManager, terraformOutputter, os.Stdout)
-	commandSet[commands.DirectorAddressCommand] = commands.NewStateQuery(logger, stateValidator, commands.DirectorAddressPropertyName, func(state storage.State) string {
+	commandSet[commands.LBsCommand] = commands.NewLBs(credentialValidator, stateValidator, infrastructureManager, terraformOutputter, os.Stdout, configuration.Global.Output)
+	commandSet[commands.DirectorAddressCommand] = commands.NewStateQuery(logger, stateValidator, configuration.Global.Output, commands.DirectorAddressPropertyName, func(state storage.State) string {
 		return state.BOSH.DirectorAddress
 	})
-	commandSet[commands.DirectorUsernameCommand] = commands.NewStateQuery(logger, stateValidator, commands.DirectorUsernamePropertyName, func(state storage.State) string {
+	commandSet[commands.DirectorUsernameCommand] = commands.NewStateQuery(logger, stateValidator, configuration.Global.Output, commands.DirectorUsernamePropertyName, func(state storage.State) string {
 		return state.BOSH.DirectorUsername
 	})
-	commandSet[commands.DirectorPasswordCommand] = commands.NewStateQuery(logger, stateValidator, commands.DirectorPasswordPropertyName, func(state storage.State) string {
+	commandSet[commands.DirectorPasswordCommand] = commands.NewStateQuery(logger, stateValidator, configuration.Global.Output, commands.DirectorPasswordPropertyName, func(state storage.State) string {
 		return state.BOSH.DirectorPassword
 	})
-	commandSet[commands.DirectorCACertCommand] = commands.NewStateQuery(logger, stateValidator, commands.DirectorCACertPropertyName, func(state storage.State) string {
+	commandSet[commands.DirectorCACertCommand] = commands.NewStateQuery(logger, stateValidator, configuration.Global.Output, commands.DirectorCACertPropertyName, func(state storage.State) string {
 		return state.BOSH.DirectorSSLCA
 	})
-	commandSet[commands.BOSHCACertCommand] = commands.NewStateQuery(logger, stateValidator, commands.BOSHCACertPropertyName, func(state storage.State) string {
+	commandSet[commands.BOSHCACertCommand] = commands.NewStateQuery(logger, stateValidator, configuration.Global.Output, commands.BOSHCACertPropertyName, func(state storage.State) string {
 		fmt.Fprintln(os.Stderr, "'bosh-ca-cert' has been deprecated and will be removed in future versions of bbl, please use 'director-ca-cert'")
 		return state.BOSH.DirectorSSLCA
 	})
-	commandSet[commands.SSHKeyCommand] = commands.NewStateQuery(logger, stateValidator, commands.SSHKeyPropertyName, func(state storage.State) string {
+	commandSet[commands.SSHKeyCommand] = commands.NewStateQuery(logger, stateValidator, configuration.Global.Output, commands.SSHKeyPropertyName, func(state storage.State) string {
 		return state.KeyPair.PrivateKey
 	})
-	commandSet[commands.EnvIDCommand] = commands.NewStateQuery(logger, stateValidator, commands.EnvIDPropertyName, func(state storage.State) string {
+	commandSet[commands.EnvIDCommand] = commands.NewStateQuery(logger, stateValidator, configuration.Global.Output, commands.EnvIDPropertyName, func(state storage.State) string {
 		return state.EnvID
 	})
 
-	app := application.New(commandSet, configuration, stateStore, usage)
+	commandSet[commands.StateCommand] = commands.NewStateSummary(stateValidator, os.Stdout, configuration.Global.Output)
+	commandSet[commands.OutputsCommand] = commands.NewOutputs(stateValidator, os.Stdout, configuration.Global.Output)
+	commandSet[commands.PrintEnvCommand] = commands.NewPrintEnv(stateValidator, os.Stdout)
+
+	commandSet[commands.ForceUnlockCommand] = commands.NewForceUnlock(logger, stateLocker)
+	commandSet[commands.StateHistoryCommand] = commands.NewStateHistory(stateStore, os.Stdout)
+	commandSet[commands.RestoreStateCommand] = commands.NewRestoreState(stateStore, logger)
+	commandSet[commands.ExportCommand] = commands.NewExport(stateValidator, logger, Version, terraformOverrides)
+	commandSet[commands.ImportCommand] = commands.NewImport(stateStore, clientProvider, infrastructureManager, gcpClientProvider, logger, terraformOverrides)
+
+	app := application.New(commandSet, configuration, stateStore, stateLocker, usage)
 
 	err = app.Run()
 	if err != nil {
-		fail(err)
+		fail(err, configuration.Global.Output)
 	}
 }
 
-func fail(err error) {
-	fmt.Fprintf(os.Stderr, "\n\n%s\n", err)
-	os.Exit(1)
+func fail(err error, output string) {
+	os.Exit(application.PrintError(os.Stderr, err, output))
 }
@@ -0,0 +1,126 @@
+package s3backend
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type S3Backend struct {
+	Bucket string
+
+	objects map[string][]byte
+	mutex   sync.Mutex
+}
+
+func (s *S3Backend) StartFakeS3Backend() *httptest.Server {
+	s.objects = map[string][]byte{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		path := strings.TrimPrefix(req.URL.Path, "/")
+		if path != s.Bucket && !strings.HasPrefix(path, s.Bucket+"/") {
+			log.Println("unexpected request recieved: ", req.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		key := strings.TrimPrefix(strings.TrimPrefix(path, s.Bucket), "/")
+		if key == "" {
+			w.WriteHeader(http.StatusOK)
+			if req.Method == "GET" {
+				s.listObjects(w, req.URL.Query().Get("prefix"), req.URL.Query().Get("delimiter"))
+			}
+			return
+		}
+
+		switch req.Method {
+		case "HEAD", "GET":
+			contents, ok := s.objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			if req.Method == "GET" {
+				w.Write(contents)
+			}
+		case "PUT":
+			if copySource := req.Header.Get("X-Amz-Copy-Source"); copySource != "" {
+				source, err := url.QueryUnescape(copySource)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				s.objects[key] = s.objects[strings.TrimPrefix(source, s.Bucket+"/")]
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`<CopyObjectResult></CopyObjectResult>`))
+				return
+			}
+
+			if _, ok := s.objects[key]; ok && req.Header.Get("If-None-Match") == "*" {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				w.Write([]byte(`<Error><Code>PreconditionFailed</Code><Message>At least one of the pre-conditions you specified did not hold</Message></Error>`))
+				return
+			}
+
+			contents, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			s.objects[key] = contents
+			w.WriteHeader(http.StatusOK)
+		case "DELETE":
+			delete(s.objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			log.Println("unexpected request recieved: ", req.Method, req.URL.Path)
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+}
+
+func (s *S3Backend) listObjects(w http.ResponseWriter, prefix, delimiter string) {
+	keys := []string{}
+	for key := range s.objects {
+		if delimiter != "" && strings.Contains(strings.TrimPrefix(key, prefix), delimiter) {
+			continue
+		}
+
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	w.Write([]byte(`<ListBucketResult><IsTruncated>false</IsTruncated>`))
+	for _, key := range keys {
+		fmt.Fprintf(w, "<Contents><Key>%s</Key></Contents>", key)
+	}
+	w.Write([]byte(`</ListBucketResult>`))
+}
+
+func (s *S3Backend) Object(key string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	contents, ok := s.objects[key]
+	return contents, ok
+}
+
+func (s *S3Backend) SetObject(key string, contents []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.objects[key] = contents
+}
@@ -0,0 +1,130 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+
+	"github.com/cloudfoundry/bosh-bootloader/bbl/s3backend"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("state backend", func() {
+	var (
+		s3Backend    *s3backend.S3Backend
+		fakeS3Server *httptest.Server
+		stateBackend string
+	)
+
+	BeforeEach(func() {
+		s3Backend = &s3backend.S3Backend{Bucket: "some-bucket"}
+		fakeS3Server = s3Backend.StartFakeS3Backend()
+
+		stateBackend = fmt.Sprintf("s3://some-bucket/some-env?region=us-west-1&endpoint=%s", fakeS3Server.URL)
+	})
+
+	AfterEach(func() {
+		fakeS3Server.Close()
+	})
+
+	bblCommand := func(args ...string) *exec.Cmd {
+		cmd := exec.Command(pathToBBL, args...)
+		cmd.Env = append(os.Environ(),
+			"AWS_ACCESS_KEY_ID=some-access-key-id",
+			"AWS_SECRET_ACCESS_KEY=some-secret-access-key",
+		)
+		return cmd
+	}
+
+	It("reads the state from the s3 backend provided with --state-backend", func() {
+		s3Backend.SetObject("some-env/bbl-state.json", []byte(`{"bosh": {"directorAddress": "some-director-url"}}`))
+
+		session, err := gexec.Start(bblCommand("--state-backend", stateBackend, "director-address"), GinkgoWriter, GinkgoWriter)
+
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Out.Contents()).To(ContainSubstring("some-director-url"))
+	})
+
+	It("reads the state from the s3 backend provided with BBL_STATE_BACKEND", func() {
+		s3Backend.SetObject("some-env/bbl-state.json", []byte(`{"bosh": {"directorAddress": "some-director-url"}}`))
+
+		cmd := bblCommand("director-address")
+		cmd.Env = append(cmd.Env, fmt.Sprintf("BBL_STATE_BACKEND=%s", stateBackend))
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Out.Contents()).To(ContainSubstring("some-director-url"))
+	})
+
+	It("reads state.json from the s3 backend without renaming it", func() {
+		s3Backend.SetObject("some-env/state.json", []byte(`{"bosh": {"directorAddress": "some-director-url"}}`))
+
+		session, err := gexec.Start(bblCommand("--state-backend", stateBackend, "director-address"), GinkgoWriter, GinkgoWriter)
+
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Out.Contents()).To(ContainSubstring("some-director-url"))
+
+		_, ok := s3Backend.Object("some-env/state.json")
+		Expect(ok).To(BeTrue())
+
+		_, ok = s3Backend.Object("some-env/bbl-state.json")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("does not take the lock while another process holds it", func() {
+		s3Backend.SetObject("some-env/bbl-state.json", []byte(`{"bosh": {"directorAddress": "some-old-director-url"}}`))
+		s3Backend.SetObject("some-env/bbl-state.json.backup-20161101T120000.000000000Z", []byte(`{"bosh": {"directorAddress": "some-director-url"}}`))
+		s3Backend.SetObject("some-env/bbl-state.lock", []byte(`{"command": "up"}`))
+
+		session, err := gexec.Start(bblCommand("--state-backend", stateBackend, "restore-state", "1"), GinkgoWriter, GinkgoWriter)
+
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(1))
+		Expect(session.Err.Contents()).To(ContainSubstring("bbl-state.json is locked"))
+
+		contents, ok := s3Backend.Object("some-env/bbl-state.json")
+		Expect(ok).To(BeTrue())
+		Expect(string(contents)).To(ContainSubstring("some-old-director-url"))
+	})
+
+	It("writes the state and its backups to the s3 backend", func() {
+		s3Backend.SetObject("some-env/bbl-state.json", []byte(`{"bosh": {"directorAddress": "some-old-director-url"}}`))
+		s3Backend.SetObject("some-env/bbl-state.json.backup-20161101T120000.000000000Z", []byte(`{"bosh": {"directorAddress": "some-director-url"}}`))
+
+		session, err := gexec.Start(bblCommand("--state-backend", stateBackend, "restore-state", "1"), GinkgoWriter, GinkgoWriter)
+
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		contents, ok := s3Backend.Object("some-env/bbl-state.json")
+		Expect(ok).To(BeTrue())
+		Expect(string(contents)).To(ContainSubstring("some-director-url"))
+
+		_, ok = s3Backend.Object("some-env/bbl-state.lock")
+		Expect(ok).To(BeFalse())
+	})
+
+	Context("failure cases", func() {
+		It("exits 1 when the bbl-state.json does not exist in the s3 backend", func() {
+			session, err := gexec.Start(bblCommand("--state-backend", stateBackend, "director-address"), GinkgoWriter, GinkgoWriter)
+
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(1))
+			Expect(session.Err.Contents()).To(ContainSubstring(`bbl-state.json not found in "s3://some-bucket/some-env"`))
+		})
+
+		It("exits 1 when the state backend is not supported", func() {
+			session, err := gexec.Start(bblCommand("--state-backend", "gcs://some-bucket", "director-address"), GinkgoWriter, GinkgoWriter)
+
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(1))
+			Expect(session.Err.Contents()).To(ContainSubstring(`"gcs://some-bucket" is an invalid state backend, supported values are: [local, s3://bucket/path]`))
+		})
+	})
+})
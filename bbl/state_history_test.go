@@ -0,0 +1,67 @@
+package main_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("state-history and restore-state", func() {
+	var (
+		tempDirectory string
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		tempDirectory, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, address := range []string{"some-old-director-url", "some-director-url"} {
+			store := storage.NewStore(storage.NewLocalBackend(tempDirectory), "", nil, storage.DefaultStateBackups)
+			err = store.Set(storage.State{
+				BOSH: storage.BOSH{
+					DirectorAddress: address,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("lists the backups and restores one of them", func() {
+		session, err := gexec.Start(exec.Command(pathToBBL, "--state-dir", tempDirectory, "state-history"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(string(session.Out.Contents())).To(MatchRegexp(`^1\t\d{4}-\d{2}-\d{2}T`))
+
+		session, err = gexec.Start(exec.Command(pathToBBL, "--state-dir", tempDirectory, "restore-state", "1"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Out.Contents()).To(ContainSubstring("restored bbl-state.json from backup 1"))
+
+		session, err = gexec.Start(exec.Command(pathToBBL, "--state-dir", tempDirectory, "director-address"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Out.Contents()).To(ContainSubstring("some-old-director-url"))
+	})
+
+	It("restores a backup over a corrupted bbl-state.json", func() {
+		err := ioutil.WriteFile(filepath.Join(tempDirectory, storage.StateFileName), []byte(`{"bosh": {"direc`), os.ModePerm)
+		Expect(err).NotTo(HaveOccurred())
+
+		session, err := gexec.Start(exec.Command(pathToBBL, "--state-dir", tempDirectory, "restore-state", "1"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		session, err = gexec.Start(exec.Command(pathToBBL, "--state-dir", tempDirectory, "director-address"), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Out.Contents()).To(ContainSubstring("some-old-director-url"))
+	})
+})
@@ -0,0 +1,64 @@
+package main_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("state lock", func() {
+	var (
+		tempDirectory string
+		lockFile      string
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		tempDirectory, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		lockFile = filepath.Join(tempDirectory, "bbl-state.lock")
+		err = ioutil.WriteFile(lockFile, []byte(`{
+			"holder": "some-user",
+			"host": "some-host",
+			"command": "up",
+			"timestamp": "2016-11-01T12:00:00Z"
+		}`), os.ModePerm)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("refuses to run a command that modifies the state while it is locked", func() {
+		args := []string{
+			"--state-dir", tempDirectory,
+			"destroy",
+			"--no-confirm",
+		}
+
+		session, err := gexec.Start(exec.Command(pathToBBL, args...), GinkgoWriter, GinkgoWriter)
+
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(1))
+		Expect(session.Err.Contents()).To(ContainSubstring(`bbl-state.json is locked by some-user@some-host running "bbl up" since 2016-11-01T12:00:00Z`))
+		Expect(lockFile).To(BeAnExistingFile())
+	})
+
+	It("removes a stale lock with force-unlock", func() {
+		args := []string{
+			"--state-dir", tempDirectory,
+			"force-unlock",
+		}
+
+		session, err := gexec.Start(exec.Command(pathToBBL, args...), GinkgoWriter, GinkgoWriter)
+
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session.Out.Contents()).To(ContainSubstring(`removed lock held by some-user@some-host running "bbl up" since 2016-11-01T12:00:00Z`))
+		Expect(lockFile).NotTo(BeAnExistingFile())
+	})
+})
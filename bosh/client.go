@@ -11,6 +11,7 @@ import (
 
 type Client interface {
 	UpdateCloudConfig(yaml []byte) error
+	CloudConfig() ([]byte, error)
 	Info() (Info, error)
 }
 
@@ -20,6 +21,10 @@ type Info struct {
 	Version string `json:"version"`
 }
 
+type cloudConfig struct {
+	Properties string `json:"properties"`
+}
+
 type client struct {
 	directorAddress string
 	username        string
@@ -86,3 +91,31 @@ func (c client) UpdateCloudConfig(yaml []byte) error {
 
 	return nil
 }
+
+func (c client) CloudConfig() ([]byte, error) {
+	request, err := http.NewRequest("GET", fmt.Sprintf("%s/cloud_configs?limit=1", c.directorAddress), strings.NewReader(""))
+	if err != nil {
+		return nil, err
+	}
+	request.SetBasicAuth(c.username, c.password)
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected http response %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	var cloudConfigs []cloudConfig
+	if err := json.NewDecoder(response.Body).Decode(&cloudConfigs); err != nil {
+		return nil, err
+	}
+
+	if len(cloudConfigs) == 0 {
+		return nil, nil
+	}
+
+	return []byte(cloudConfigs[0].Properties), nil
+}
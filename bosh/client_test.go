@@ -137,4 +137,74 @@ var _ = Describe("Client", func() {
 			})
 		})
 	})
+
+	Describe("CloudConfig", func() {
+		It("returns the latest cloud config", func() {
+			var (
+				path     string
+				username string
+				password string
+			)
+
+			fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				path = request.URL.String()
+				username, password, _ = request.BasicAuth()
+
+				responseWriter.Write([]byte(`[{"properties": "cloud: config", "created_at": "2016-11-01 00:00:00 UTC"}]`))
+			}))
+
+			client := bosh.NewClient(fakeBOSH.URL, "some-username", "some-password")
+
+			cloudConfig, err := client.CloudConfig()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cloudConfig).To(Equal([]byte("cloud: config")))
+			Expect(path).To(Equal("/cloud_configs?limit=1"))
+			Expect(username).To(Equal("some-username"))
+			Expect(password).To(Equal("some-password"))
+		})
+
+		It("returns nil when the director has no cloud config", func() {
+			fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+				responseWriter.Write([]byte(`[]`))
+			}))
+
+			client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+			cloudConfig, err := client.CloudConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cloudConfig).To(BeNil())
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the status code is not StatusOK", func() {
+				fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+					responseWriter.WriteHeader(http.StatusUnauthorized)
+				}))
+
+				client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+				_, err := client.CloudConfig()
+				Expect(err).To(MatchError("unexpected http response 401 Unauthorized"))
+			})
+
+			It("returns an error when the director address is malformed", func() {
+				client := bosh.NewClient("%%%%%%%%%%%%%%%", "", "")
+
+				_, err := client.CloudConfig()
+				Expect(err.(*url.Error).Op).To(Equal("parse"))
+			})
+
+			It("returns an error when it cannot parse the cloud configs", func() {
+				fakeBOSH := httptest.NewTLSServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+					responseWriter.Write([]byte(`%%%`))
+				}))
+
+				client := bosh.NewClient(fakeBOSH.URL, "", "")
+
+				_, err := client.CloudConfig()
+				Expect(err).To(MatchError(ContainSubstring("invalid character")))
+			})
+		})
+	})
 })
@@ -19,15 +19,8 @@ func NewCloudConfigManager(logger logger, cloudConfigGenerator cloudConfigGenera
 }
 
 func (c CloudConfigManager) Update(input CloudConfigInput, boshClient Client) error {
-	c.logger.Step("generating cloud config")
-	cloudConfig, err := c.cloudConfigGenerator.Generate(input)
-	if err != nil {
-		return err
-	}
-
-	manifestYAML, err := yaml.Marshal(cloudConfig)
+	manifestYAML, err := c.Generate(input)
 	if err != nil {
-		// not tested, should never fail since CloudConfig is a strongly typed struct
 		return err
 	}
 
@@ -38,3 +31,19 @@ func (c CloudConfigManager) Update(input CloudConfigInput, boshClient Client) er
 
 	return nil
 }
+
+func (c CloudConfigManager) Generate(input CloudConfigInput) ([]byte, error) {
+	c.logger.Step("generating cloud config")
+	cloudConfig, err := c.cloudConfigGenerator.Generate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestYAML, err := yaml.Marshal(cloudConfig)
+	if err != nil {
+		// not tested, should never fail since CloudConfig is a strongly typed struct
+		return nil, err
+	}
+
+	return manifestYAML, nil
+}
@@ -108,4 +108,52 @@ var _ = Describe("CloudConfigManager", func() {
 			})
 		})
 	})
+
+	Describe("Generate", func() {
+		var (
+			logger               *fakes.Logger
+			cloudConfigGenerator *fakes.CloudConfigGenerator
+			cloudConfigManager   bosh.CloudConfigManager
+		)
+
+		BeforeEach(func() {
+			logger = &fakes.Logger{}
+			cloudConfigGenerator = &fakes.CloudConfigGenerator{}
+			cloudConfigManager = bosh.NewCloudConfigManager(logger, cloudConfigGenerator)
+
+			cloudConfigGenerator.GenerateCall.Returns.CloudConfig = bosh.CloudConfig{
+				VMTypes: []bosh.VMType{
+					{
+						Name: "some-vm-type",
+					},
+				},
+			}
+		})
+
+		It("returns the generated cloud config yaml without applying it", func() {
+			cloudConfigInput := bosh.CloudConfigInput{AZs: []string{"us-east-1a"}}
+
+			cloudConfig, err := cloudConfigManager.Generate(cloudConfigInput)
+			Expect(err).NotTo(HaveOccurred())
+
+			manifestYAML, err := yaml.Marshal(bosh.CloudConfig{
+				VMTypes: []bosh.VMType{
+					{
+						Name: "some-vm-type",
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cloudConfig).To(Equal(manifestYAML))
+			Expect(cloudConfigGenerator.GenerateCall.Receives.CloudConfigInput).To(Equal(cloudConfigInput))
+		})
+
+		It("returns an error when the generate fails", func() {
+			cloudConfigGenerator.GenerateCall.Returns.Error = errors.New("generate failed")
+
+			_, err := cloudConfigManager.Generate(bosh.CloudConfigInput{})
+			Expect(err).To(MatchError("generate failed"))
+		})
+	})
 })
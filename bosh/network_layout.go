@@ -0,0 +1,162 @@
+package bosh
+
+import "fmt"
+
+const (
+	DefaultNetworkCIDR = "10.0.0.0/16"
+
+	largestNetworkSize    = 1 << 24
+	smallestNetworkSize   = 1 << 13
+	boshSubnetSize        = 1 << 8
+	internalSubnetSize    = 1 << 12
+	natSubnetSize         = 1 << 4
+	directorIPOffset      = 6
+	natIPOffset           = 7
+	firstAssignableOffset = 4
+)
+
+// NetworkLayout carves the subnets of a bbl environment out of a network
+// between a /8 and a /19. The BOSH subnet is the first /24, the load balancer
+// subnets are the /24s that follow it and the internal subnets are the /20s
+// after the first one. The second /24 is split into /28s for the subnets of
+// the NAT gateways.
+type NetworkLayout struct {
+	base         IP
+	size         int
+	prefixLength int
+	directorIP   IP
+	natIP        IP
+}
+
+func NewNetworkLayout(cidr, directorIP, natIP string) (NetworkLayout, error) {
+	if cidr == "" {
+		cidr = DefaultNetworkCIDR
+	}
+
+	cidrBlock, err := ParseCIDRBlock(cidr)
+	if err != nil {
+		return NetworkLayout{}, fmt.Errorf("invalid network CIDR %q: %s", cidr, err)
+	}
+
+	base := cidrBlock.GetFirstIP()
+	if cidrBlock.CIDRSize > largestNetworkSize || cidrBlock.CIDRSize < smallestNetworkSize {
+		return NetworkLayout{}, fmt.Errorf("invalid network CIDR %q: the network must be a /8 to /19 block, for example %s", cidr, DefaultNetworkCIDR)
+	}
+
+	length := prefixLength(cidrBlock.CIDRSize)
+	if offset := base.ip % cidrBlock.CIDRSize; offset != 0 {
+		return NetworkLayout{}, fmt.Errorf("invalid network CIDR %q: the network must start at the first address of the block, for example %s/%d", cidr, base.Subtract(offset), length)
+	}
+
+	layout := NetworkLayout{
+		base:         base,
+		size:         cidrBlock.CIDRSize,
+		prefixLength: length,
+	}
+
+	layout.directorIP, err = layout.parseBOSHSubnetIP("director", directorIP, directorIPOffset)
+	if err != nil {
+		return NetworkLayout{}, err
+	}
+
+	layout.natIP, err = layout.parseBOSHSubnetIP("NAT", natIP, natIPOffset)
+	if err != nil {
+		return NetworkLayout{}, err
+	}
+
+	if layout.directorIP == layout.natIP {
+		return NetworkLayout{}, fmt.Errorf("the director and the NAT cannot both use %s", layout.directorIP)
+	}
+
+	return layout, nil
+}
+
+func (n NetworkLayout) parseBOSHSubnetIP(name, ip string, defaultOffset int) (IP, error) {
+	if ip == "" {
+		return n.base.Add(defaultOffset), nil
+	}
+
+	parsedIP, err := ParseIP(ip)
+	if err != nil {
+		return IP{}, fmt.Errorf("invalid %s IP %q: %s", name, ip, err)
+	}
+
+	first := n.base.Add(firstAssignableOffset)
+	last := n.base.Add(boshSubnetSize - 2)
+	if parsedIP.ip < first.ip || parsedIP.ip > last.ip {
+		return IP{}, fmt.Errorf("invalid %s IP %q: it must be in the BOSH subnet between %s and %s", name, ip, first, last)
+	}
+
+	return parsedIP, nil
+}
+
+func prefixLength(size int) int {
+	length := 32
+	for ; size > 1; size >>= 1 {
+		length--
+	}
+	return length
+}
+
+func (n NetworkLayout) CIDR() string {
+	return fmt.Sprintf("%s/%d", n.base, n.prefixLength)
+}
+
+func (n NetworkLayout) BOSHSubnetCIDR() string {
+	return fmt.Sprintf("%s/24", n.base)
+}
+
+func (n NetworkLayout) Gateway() string {
+	return n.base.Add(1).String()
+}
+
+func (n NetworkLayout) DNS() string {
+	return n.base.Add(2).String()
+}
+
+func (n NetworkLayout) DirectorIP() string {
+	return n.directorIP.String()
+}
+
+func (n NetworkLayout) NATIP() string {
+	return n.natIP.String()
+}
+
+func (n NetworkLayout) InternalSubnetCIDRs(count int) ([]string, error) {
+	if max := n.size/internalSubnetSize - 1; count > max {
+		return nil, fmt.Errorf("the network %s has room for %d internal subnets, but %d are needed", n.CIDR(), max, count)
+	}
+
+	cidrs := []string{}
+	for i := 1; i <= count; i++ {
+		cidrs = append(cidrs, fmt.Sprintf("%s/20", n.base.Add(i*internalSubnetSize)))
+	}
+
+	return cidrs, nil
+}
+
+func (n NetworkLayout) LoadBalancerSubnetCIDRs(count int) ([]string, error) {
+	if max := internalSubnetSize/boshSubnetSize - 2; count > max {
+		return nil, fmt.Errorf("the network %s has room for %d load balancer subnets, but %d are needed", n.CIDR(), max, count)
+	}
+
+	cidrs := []string{}
+	for i := 1; i <= count; i++ {
+		cidrs = append(cidrs, fmt.Sprintf("%s/24", n.base.Add((i+1)*boshSubnetSize)))
+	}
+
+	return cidrs, nil
+}
+
+func (n NetworkLayout) NATSubnetCIDRs(count int) ([]string, error) {
+	if max := boshSubnetSize / natSubnetSize; count > max {
+		return nil, fmt.Errorf("the network %s has room for %d NAT subnets, but %d are needed", n.CIDR(), max, count)
+	}
+
+	cidrs := []string{}
+	for i := 0; i < count; i++ {
+		cidrs = append(cidrs, fmt.Sprintf("%s/28", n.base.Add(boshSubnetSize+i*natSubnetSize)))
+	}
+
+	return cidrs, nil
+}
@@ -0,0 +1,137 @@
+package bosh_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NetworkLayout", func() {
+	Context("when no values are provided", func() {
+		It("uses the default layout", func() {
+			layout, err := bosh.NewNetworkLayout("", "", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layout.CIDR()).To(Equal("10.0.0.0/16"))
+			Expect(layout.BOSHSubnetCIDR()).To(Equal("10.0.0.0/24"))
+			Expect(layout.Gateway()).To(Equal("10.0.0.1"))
+			Expect(layout.DNS()).To(Equal("10.0.0.2"))
+			Expect(layout.DirectorIP()).To(Equal("10.0.0.6"))
+			Expect(layout.NATIP()).To(Equal("10.0.0.7"))
+
+			internalSubnetCIDRs, err := layout.InternalSubnetCIDRs(3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(internalSubnetCIDRs).To(Equal([]string{"10.0.16.0/20", "10.0.32.0/20", "10.0.48.0/20"}))
+
+			loadBalancerSubnetCIDRs, err := layout.LoadBalancerSubnetCIDRs(3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loadBalancerSubnetCIDRs).To(Equal([]string{"10.0.2.0/24", "10.0.3.0/24", "10.0.4.0/24"}))
+
+			natSubnetCIDRs, err := layout.NATSubnetCIDRs(3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(natSubnetCIDRs).To(Equal([]string{"10.0.1.0/28", "10.0.1.16/28", "10.0.1.32/28"}))
+		})
+	})
+
+	Context("when a network CIDR and IPs are provided", func() {
+		It("carves the subnets out of the network", func() {
+			layout, err := bosh.NewNetworkLayout("172.20.0.0/16", "172.20.0.10", "172.20.0.11")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layout.CIDR()).To(Equal("172.20.0.0/16"))
+			Expect(layout.BOSHSubnetCIDR()).To(Equal("172.20.0.0/24"))
+			Expect(layout.Gateway()).To(Equal("172.20.0.1"))
+			Expect(layout.DNS()).To(Equal("172.20.0.2"))
+			Expect(layout.DirectorIP()).To(Equal("172.20.0.10"))
+			Expect(layout.NATIP()).To(Equal("172.20.0.11"))
+
+			internalSubnetCIDRs, err := layout.InternalSubnetCIDRs(2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(internalSubnetCIDRs).To(Equal([]string{"172.20.16.0/20", "172.20.32.0/20"}))
+
+			loadBalancerSubnetCIDRs, err := layout.LoadBalancerSubnetCIDRs(2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loadBalancerSubnetCIDRs).To(Equal([]string{"172.20.2.0/24", "172.20.3.0/24"}))
+
+			natSubnetCIDRs, err := layout.NATSubnetCIDRs(2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(natSubnetCIDRs).To(Equal([]string{"172.20.1.0/28", "172.20.1.16/28"}))
+		})
+
+		It("defaults the IPs within the provided network", func() {
+			layout, err := bosh.NewNetworkLayout("192.168.0.0/16", "", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layout.DirectorIP()).To(Equal("192.168.0.6"))
+			Expect(layout.NATIP()).To(Equal("192.168.0.7"))
+		})
+	})
+
+	Context("when the network is not a /16", func() {
+		It("carves the same subnets out of a smaller network", func() {
+			layout, err := bosh.NewNetworkLayout("10.1.64.0/18", "", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layout.CIDR()).To(Equal("10.1.64.0/18"))
+			Expect(layout.BOSHSubnetCIDR()).To(Equal("10.1.64.0/24"))
+			Expect(layout.DirectorIP()).To(Equal("10.1.64.6"))
+
+			internalSubnetCIDRs, err := layout.InternalSubnetCIDRs(3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(internalSubnetCIDRs).To(Equal([]string{"10.1.80.0/20", "10.1.96.0/20", "10.1.112.0/20"}))
+
+			_, err = layout.InternalSubnetCIDRs(4)
+			Expect(err).To(MatchError("the network 10.1.64.0/18 has room for 3 internal subnets, but 4 are needed"))
+		})
+
+		It("carves the same subnets out of a larger network", func() {
+			layout, err := bosh.NewNetworkLayout("10.16.0.0/12", "", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(layout.CIDR()).To(Equal("10.16.0.0/12"))
+			Expect(layout.BOSHSubnetCIDR()).To(Equal("10.16.0.0/24"))
+
+			internalSubnetCIDRs, err := layout.InternalSubnetCIDRs(2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(internalSubnetCIDRs).To(Equal([]string{"10.16.16.0/20", "10.16.32.0/20"}))
+
+			loadBalancerSubnetCIDRs, err := layout.LoadBalancerSubnetCIDRs(2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loadBalancerSubnetCIDRs).To(Equal([]string{"10.16.2.0/24", "10.16.3.0/24"}))
+		})
+	})
+
+	Context("when there are more availability zones than subnets in the network", func() {
+		It("returns an error", func() {
+			layout, err := bosh.NewNetworkLayout("", "", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = layout.InternalSubnetCIDRs(16)
+			Expect(err).To(MatchError("the network 10.0.0.0/16 has room for 15 internal subnets, but 16 are needed"))
+
+			_, err = layout.LoadBalancerSubnetCIDRs(15)
+			Expect(err).To(MatchError("the network 10.0.0.0/16 has room for 14 load balancer subnets, but 15 are needed"))
+
+			_, err = layout.NATSubnetCIDRs(17)
+			Expect(err).To(MatchError("the network 10.0.0.0/16 has room for 16 NAT subnets, but 17 are needed"))
+		})
+	})
+
+	DescribeTable("returns an error for invalid values", func(cidr, directorIP, natIP, expectedError string) {
+		_, err := bosh.NewNetworkLayout(cidr, directorIP, natIP)
+		Expect(err).To(MatchError(ContainSubstring(expectedError)))
+	},
+		Entry("an unparsable CIDR", "some-cidr", "", "", `invalid network CIDR "some-cidr": "some-cidr" cannot parse CIDR block`),
+		Entry("a CIDR that is too small", "10.0.0.0/24", "", "", `invalid network CIDR "10.0.0.0/24": the network must be a /8 to /19 block`),
+		Entry("a CIDR that is too large", "10.0.0.0/7", "", "", `invalid network CIDR "10.0.0.0/7": the network must be a /8 to /19 block`),
+		Entry("a CIDR that is not aligned", "10.0.1.0/16", "", "", `invalid network CIDR "10.0.1.0/16": the network must start at the first address of the block, for example 10.0.0.0/16`),
+		Entry("a smaller CIDR that is not aligned", "10.0.160.0/18", "", "", `invalid network CIDR "10.0.160.0/18": the network must start at the first address of the block, for example 10.0.128.0/18`),
+		Entry("an unparsable director IP", "", "some-ip", "", `invalid director IP "some-ip": 'some-ip' is not a valid ip address`),
+		Entry("a director IP outside of the BOSH subnet", "", "10.0.1.6", "", `invalid director IP "10.0.1.6": it must be in the BOSH subnet between 10.0.0.4 and 10.0.0.254`),
+		Entry("a reserved director IP", "", "10.0.0.2", "", `invalid director IP "10.0.0.2": it must be in the BOSH subnet between 10.0.0.4 and 10.0.0.254`),
+		Entry("a NAT IP outside of the BOSH subnet", "", "", "10.1.0.7", `invalid NAT IP "10.1.0.7": it must be in the BOSH subnet between 10.0.0.4 and 10.0.0.254`),
+		Entry("the same IP for the director and the NAT", "", "10.0.0.7", "", "the director and the NAT cannot both use 10.0.0.7"),
+	)
+})
@@ -0,0 +1,83 @@
+package boshinit
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/boshinit/manifests"
+	"gopkg.in/yaml.v2"
+)
+
+const localArtifactScheme = "file://"
+
+type manifestArtifacts struct {
+	Releases      []manifests.Release `yaml:"releases"`
+	ResourcePools []struct {
+		Name     string             `yaml:"name"`
+		Stemcell manifests.Stemcell `yaml:"stemcell"`
+	} `yaml:"resource_pools"`
+}
+
+// checkArtifacts verifies the SHA1 of every release and stemcell the manifest
+// references with a file:// URL. When airGapped is set, artifacts that bosh-init
+// would download are refused.
+func checkArtifacts(manifestYAML []byte, airGapped bool) error {
+	var artifacts manifestArtifacts
+	if err := yaml.Unmarshal(manifestYAML, &artifacts); err != nil {
+		return err
+	}
+
+	for _, release := range artifacts.Releases {
+		if err := checkArtifact(fmt.Sprintf("release %q", release.Name), release.URL, release.SHA1, airGapped); err != nil {
+			return err
+		}
+	}
+
+	for _, resourcePool := range artifacts.ResourcePools {
+		stemcell := resourcePool.Stemcell
+		if err := checkArtifact(fmt.Sprintf("stemcell of resource pool %q", resourcePool.Name), stemcell.URL, stemcell.SHA1, airGapped); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkArtifact(description, url, expectedSHA1 string, airGapped bool) error {
+	if !strings.HasPrefix(url, localArtifactScheme) {
+		if airGapped {
+			return fmt.Errorf("%s would be downloaded from %s, which is not allowed in an air-gapped environment", description, url)
+		}
+		return nil
+	}
+
+	actualSHA1, err := fileSHA1(strings.TrimPrefix(url, localArtifactScheme))
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", description, err)
+	}
+
+	if actualSHA1 != expectedSHA1 {
+		return fmt.Errorf("%s at %s has SHA1 %s, expected %s", description, url, actualSHA1, expectedSHA1)
+	}
+
+	return nil
+}
+
+func fileSHA1(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha1.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
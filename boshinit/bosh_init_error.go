@@ -0,0 +1,21 @@
+package boshinit
+
+type BOSHInitError struct {
+	boshInitState State
+	err           error
+}
+
+func NewBOSHInitError(boshInitState State, err error) BOSHInitError {
+	return BOSHInitError{
+		boshInitState: boshInitState,
+		err:           err,
+	}
+}
+
+func (b BOSHInitError) Error() string {
+	return b.err.Error()
+}
+
+func (b BOSHInitError) BOSHInitState() State {
+	return b.boshInitState
+}
@@ -52,10 +52,18 @@ func (r CommandRunner) Execute(manifest []byte, privateKey string, state State)
 
 	err = r.command.Run()
 	if err != nil {
-		return State{}, err
+		partialState, readErr := readState(stateJSONPath)
+		if readErr != nil || len(partialState) == 0 {
+			return State{}, err
+		}
+		return partialState, NewBOSHInitError(partialState, err)
 	}
 
-	_, err = os.Stat(stateJSONPath)
+	return readState(stateJSONPath)
+}
+
+func readState(stateJSONPath string) (State, error) {
+	_, err := os.Stat(stateJSONPath)
 	if err != nil {
 		return State{}, nil
 	}
@@ -65,7 +73,7 @@ func (r CommandRunner) Execute(manifest []byte, privateKey string, state State)
 		return State{}, err
 	}
 
-	state = State{}
+	state := State{}
 	err = json.Unmarshal(boshStateData, &state)
 	if err != nil {
 		return State{}, err
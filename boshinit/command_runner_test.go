@@ -108,6 +108,25 @@ var _ = Describe("CommandRunner", func() {
 			Expect(fileInfo.Mode()).To(Equal(os.FileMode(0644)))
 		})
 
+		Context("when the command fails after writing bosh-state.json", func() {
+			It("returns the partial bosh state with the error", func() {
+				executable.RunCall.Stub = func() error {
+					err := ioutil.WriteFile(filepath.Join(tempDir, "bosh-state.json"), []byte(`{"partial": "state"}`), os.ModePerm)
+					Expect(err).NotTo(HaveOccurred())
+
+					return errors.New("bbl received interrupt, bosh-init was stopped")
+				}
+
+				state, err := runner.Execute([]byte("some-manifest-yaml"), "some-private-key", boshinit.State{})
+				Expect(err).To(MatchError("bbl received interrupt, bosh-init was stopped"))
+				Expect(state).To(Equal(boshinit.State{"partial": "state"}))
+
+				boshInitErr, ok := err.(boshinit.BOSHInitError)
+				Expect(ok).To(BeTrue())
+				Expect(boshInitErr.BOSHInitState()).To(Equal(boshinit.State{"partial": "state"}))
+			})
+		})
+
 		Context("failure cases", func() {
 			Context("when the bosh-init state cannot be marshaled", func() {
 				It("returns an error", func() {
@@ -162,8 +181,24 @@ var _ = Describe("CommandRunner", func() {
 					executable.RunCall.Stub = nil
 					executable.RunCall.Returns.Error = errors.New("failed to run")
 
-					_, err := runner.Execute([]byte("some-manifest-yaml"), "some-private-key", boshinit.State{})
+					state, err := runner.Execute([]byte("some-manifest-yaml"), "some-private-key", boshinit.State{})
+					Expect(err).To(MatchError("failed to run"))
+					Expect(err).NotTo(BeAssignableToTypeOf(boshinit.BOSHInitError{}))
+					Expect(state).To(BeEmpty())
+				})
+
+				It("does not return a partial state when bosh-state.json is missing", func() {
+					executable.RunCall.Stub = func() error {
+						err := os.Remove(filepath.Join(tempDir, "bosh-state.json"))
+						Expect(err).NotTo(HaveOccurred())
+
+						return errors.New("failed to run")
+					}
+
+					state, err := runner.Execute([]byte("some-manifest-yaml"), "some-private-key", boshinit.State{"key": "value"})
 					Expect(err).To(MatchError("failed to run"))
+					Expect(err).NotTo(BeAssignableToTypeOf(boshinit.BOSHInitError{}))
+					Expect(state).To(BeEmpty())
 				})
 			})
 
@@ -23,6 +23,11 @@ type DeployInput struct {
 	SSLKeyPair                  ssl.KeyPair
 	EC2KeyPair                  ec2.KeyPair
 	Credentials                 map[string]string
+	OpsFiles                    []storage.OpsFile
+	VersionOverrides            []storage.VersionOverride
+	AirGapped                   bool
+	DirectorSizing              storage.DirectorSizing
+	Network                     storage.Network
 }
 
 type InfrastructureConfiguration struct {
@@ -68,6 +73,17 @@ func NewDeployInput(state storage.State, infrastructureConfiguration Infrastruct
 		InfrastructureConfiguration: infrastructureConfiguration,
 		SSLKeyPair:                  ssl.KeyPair{},
 		EC2KeyPair:                  ec2.KeyPair{},
+		OpsFiles:                    state.OpsFiles,
+		VersionOverrides:            state.VersionOverrides,
+		AirGapped:                   state.AirGapped,
+	}
+
+	if state.DirectorSizing != nil {
+		deployInput.DirectorSizing = *state.DirectorSizing
+	}
+
+	if state.Network != nil {
+		deployInput.Network = *state.Network
 	}
 
 	if !state.KeyPair.IsEmpty() {
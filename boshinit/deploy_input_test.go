@@ -100,6 +100,46 @@ var _ = Describe("DeployInput", func() {
 			}))
 		})
 
+		It("passes the ops files from the state", func() {
+			state.OpsFiles = []storage.OpsFile{{Name: "some-ops-file.yml", Contents: "some-ops"}}
+
+			deployInput, err := boshinit.NewDeployInput(state, infrastructureConfiguration, fakeStringGenerator, envID, iaas)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployInput.OpsFiles).To(Equal([]storage.OpsFile{{Name: "some-ops-file.yml", Contents: "some-ops"}}))
+		})
+
+		It("passes the version overrides from the state", func() {
+			state.VersionOverrides = []storage.VersionOverride{{Name: "stemcell", URL: "some-stemcell-url", SHA1: "some-stemcell-sha1"}}
+
+			deployInput, err := boshinit.NewDeployInput(state, infrastructureConfiguration, fakeStringGenerator, envID, iaas)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployInput.VersionOverrides).To(Equal(state.VersionOverrides))
+		})
+
+		It("passes the air-gapped setting from the state", func() {
+			state.AirGapped = true
+
+			deployInput, err := boshinit.NewDeployInput(state, infrastructureConfiguration, fakeStringGenerator, envID, iaas)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployInput.AirGapped).To(BeTrue())
+		})
+
+		It("passes the director sizing from the state", func() {
+			state.DirectorSizing = &storage.DirectorSizing{InstanceType: "m4.xlarge", PersistentDiskSizeGB: 200}
+
+			deployInput, err := boshinit.NewDeployInput(state, infrastructureConfiguration, fakeStringGenerator, envID, iaas)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployInput.DirectorSizing).To(Equal(storage.DirectorSizing{InstanceType: "m4.xlarge", PersistentDiskSizeGB: 200}))
+		})
+
+		It("passes the network from the state", func() {
+			state.Network = &storage.Network{CIDR: "172.20.0.0/16", DirectorIP: "172.20.0.10"}
+
+			deployInput, err := boshinit.NewDeployInput(state, infrastructureConfiguration, fakeStringGenerator, envID, iaas)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployInput.Network).To(Equal(storage.Network{CIDR: "172.20.0.0/16", DirectorIP: "172.20.0.10"}))
+		})
+
 		Context("when existing state contains bosh state without director name", func() {
 			It("sets director name to my-bosh", func() {
 				state.BOSH.DirectorName = ""
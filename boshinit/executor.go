@@ -1,6 +1,9 @@
 package boshinit
 
 import (
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
 	"github.com/cloudfoundry/bosh-bootloader/boshinit/manifests"
 	"gopkg.in/yaml.v2"
 )
@@ -51,6 +54,45 @@ func (e Executor) Delete(boshInitManifest string, boshInitState State, ec2Privat
 }
 
 func (e Executor) Deploy(input DeployInput) (DeployOutput, error) {
+	manifestYAML, manifestProperties, err := e.buildManifest(input)
+	if err != nil {
+		return DeployOutput{}, err
+	}
+
+	e.logger.Step("deploying bosh director")
+	state, err := e.deployCommand.Execute(manifestYAML, input.EC2KeyPair.PrivateKey, input.State)
+	output := DeployOutput{
+		BOSHInitState:      state,
+		DirectorSSLKeyPair: manifestProperties.SSLKeyPair,
+		Credentials:        manifestProperties.Credentials.ToMap(),
+		BOSHInitManifest:   string(manifestYAML),
+	}
+
+	switch err.(type) {
+	case BOSHInitError:
+		return output, err
+	case error:
+		return DeployOutput{}, err
+	}
+
+	return output, nil
+}
+
+func (e Executor) Manifest(input DeployInput) (string, error) {
+	manifestYAML, _, err := e.buildManifest(input)
+	if err != nil {
+		return "", err
+	}
+
+	return string(manifestYAML), nil
+}
+
+func (e Executor) buildManifest(input DeployInput) ([]byte, manifests.ManifestProperties, error) {
+	networkLayout, err := bosh.NewNetworkLayout(input.Network.CIDR, input.Network.DirectorIP, input.Network.NATIP)
+	if err != nil {
+		return nil, manifests.ManifestProperties{}, err
+	}
+
 	manifest, manifestProperties, err := e.manifestBuilder.Build(input.IAAS, manifests.ManifestProperties{
 		SSLKeyPair:       input.SSLKeyPair,
 		DirectorName:     input.DirectorName,
@@ -59,6 +101,20 @@ func (e Executor) Deploy(input DeployInput) (DeployOutput, error) {
 		ExternalIP:       input.InfrastructureConfiguration.ExternalIP,
 		CACommonName:     BOSH_BOOTLOADER_COMMON_NAME,
 		Credentials:      manifests.NewInternalCredentials(input.Credentials),
+		Versions:         OverriddenVersions(input.VersionOverrides),
+		DirectorSizing: manifests.DirectorSizing{
+			InstanceType:         input.DirectorSizing.InstanceType,
+			EphemeralDiskSizeGB:  input.DirectorSizing.EphemeralDiskSizeGB,
+			EphemeralDiskType:    input.DirectorSizing.EphemeralDiskType,
+			PersistentDiskSizeGB: input.DirectorSizing.PersistentDiskSizeGB,
+			PersistentDiskType:   input.DirectorSizing.PersistentDiskType,
+		},
+		DirectorNetwork: manifests.DirectorNetwork{
+			SubnetCIDR: networkLayout.BOSHSubnetCIDR(),
+			Gateway:    networkLayout.Gateway(),
+			DNS:        networkLayout.DNS(),
+			DirectorIP: networkLayout.DirectorIP(),
+		},
 		AWS: manifests.ManifestPropertiesAWS{
 			SubnetID:         input.InfrastructureConfiguration.AWS.SubnetID,
 			AvailabilityZone: input.InfrastructureConfiguration.AWS.AvailabilityZone,
@@ -79,24 +135,34 @@ func (e Executor) Deploy(input DeployInput) (DeployOutput, error) {
 		},
 	})
 	if err != nil {
-		return DeployOutput{}, err
+		return nil, manifests.ManifestProperties{}, err
 	}
 
-	manifestYAML, err := yaml.Marshal(manifest)
-	if err != nil {
-		return DeployOutput{}, err
+	var document interface{} = manifest
+	if len(input.OpsFiles) > 0 {
+		ops := []manifests.Op{}
+		for _, opsFile := range input.OpsFiles {
+			fileOps, err := manifests.ParseOps([]byte(opsFile.Contents))
+			if err != nil {
+				return nil, manifests.ManifestProperties{}, fmt.Errorf("invalid ops file %q: %s", opsFile.Name, err)
+			}
+			ops = append(ops, fileOps...)
+		}
+
+		document, err = manifests.ApplyOps(manifest, ops)
+		if err != nil {
+			return nil, manifests.ManifestProperties{}, err
+		}
 	}
 
-	e.logger.Step("deploying bosh director")
-	state, err := e.deployCommand.Execute(manifestYAML, input.EC2KeyPair.PrivateKey, input.State)
+	manifestYAML, err := yaml.Marshal(document)
 	if err != nil {
-		return DeployOutput{}, err
+		return nil, manifests.ManifestProperties{}, err
 	}
 
-	return DeployOutput{
-		BOSHInitState:      state,
-		DirectorSSLKeyPair: manifestProperties.SSLKeyPair,
-		Credentials:        manifestProperties.Credentials.ToMap(),
-		BOSHInitManifest:   string(manifestYAML),
-	}, nil
+	if err := checkArtifacts(manifestYAML, input.AirGapped); err != nil {
+		return nil, manifests.ManifestProperties{}, err
+	}
+
+	return manifestYAML, manifestProperties, nil
 }
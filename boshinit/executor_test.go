@@ -1,13 +1,18 @@
 package boshinit_test
 
 import (
+	"crypto/sha1"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
 	"github.com/cloudfoundry/bosh-bootloader/boshinit"
 	"github.com/cloudfoundry/bosh-bootloader/boshinit/manifests"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
 	"github.com/cloudfoundry/bosh-bootloader/ssl"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -175,6 +180,12 @@ var _ = Describe("Executor", func() {
 				DirectorPassword: "some-director-password",
 				CACommonName:     "BOSH Bootloader",
 				ExternalIP:       "some-elastic-ip",
+				DirectorNetwork: manifests.DirectorNetwork{
+					SubnetCIDR: "10.0.0.0/24",
+					Gateway:    "10.0.0.1",
+					DNS:        "10.0.0.2",
+					DirectorIP: "10.0.0.6",
+				},
 				AWS: manifests.ManifestPropertiesAWS{
 					SubnetID:         "subnet-12345",
 					AvailabilityZone: "some-az",
@@ -263,6 +274,12 @@ var _ = Describe("Executor", func() {
 				DirectorPassword: "some-director-password",
 				CACommonName:     "BOSH Bootloader",
 				ExternalIP:       "some-elastic-ip",
+				DirectorNetwork: manifests.DirectorNetwork{
+					SubnetCIDR: "10.0.0.0/24",
+					Gateway:    "10.0.0.1",
+					DNS:        "10.0.0.2",
+					DirectorIP: "10.0.0.6",
+				},
 				SSLKeyPair: ssl.KeyPair{
 					Certificate: []byte("some-certificate"),
 					PrivateKey:  []byte("some-private-key"),
@@ -307,7 +324,163 @@ var _ = Describe("Executor", func() {
 			Expect(logger.StepCall.Receives.Message).To(Equal("deploying bosh director"))
 		})
 
+		It("passes the version overrides to the manifest builder", func() {
+			_, err := executor.Deploy(boshinit.DeployInput{
+				IAAS:       "aws",
+				SSLKeyPair: sslKeyPair,
+				VersionOverrides: []storage.VersionOverride{
+					{Name: "bosh", URL: "some-bosh-url", SHA1: "some-bosh-sha1"},
+					{Name: "stemcell", URL: "some-stemcell-url", SHA1: "some-stemcell-sha1"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manifestBuilder.BuildCall.Receives.Properties.Versions).To(Equal(manifests.Versions{
+				BOSHURL:      "some-bosh-url",
+				BOSHSHA1:     "some-bosh-sha1",
+				StemcellURL:  "some-stemcell-url",
+				StemcellSHA1: "some-stemcell-sha1",
+			}))
+		})
+
+		It("passes the director sizing to the manifest builder", func() {
+			_, err := executor.Deploy(boshinit.DeployInput{
+				IAAS:       "gcp",
+				SSLKeyPair: sslKeyPair,
+				DirectorSizing: storage.DirectorSizing{
+					InstanceType:         "n1-standard-8",
+					EphemeralDiskSizeGB:  50,
+					EphemeralDiskType:    "pd-ssd",
+					PersistentDiskSizeGB: 200,
+					PersistentDiskType:   "pd-ssd",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manifestBuilder.BuildCall.Receives.Properties.DirectorSizing).To(Equal(manifests.DirectorSizing{
+				InstanceType:         "n1-standard-8",
+				EphemeralDiskSizeGB:  50,
+				EphemeralDiskType:    "pd-ssd",
+				PersistentDiskSizeGB: 200,
+				PersistentDiskType:   "pd-ssd",
+			}))
+		})
+
+		It("passes the director network to the manifest builder", func() {
+			_, err := executor.Deploy(boshinit.DeployInput{
+				IAAS:       "aws",
+				SSLKeyPair: sslKeyPair,
+				Network: storage.Network{
+					CIDR:       "172.20.0.0/16",
+					DirectorIP: "172.20.0.10",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manifestBuilder.BuildCall.Receives.Properties.DirectorNetwork).To(Equal(manifests.DirectorNetwork{
+				SubnetCIDR: "172.20.0.0/24",
+				Gateway:    "172.20.0.1",
+				DNS:        "172.20.0.2",
+				DirectorIP: "172.20.0.10",
+			}))
+		})
+
+		It("applies the ops files to the manifest", func() {
+			deployOutput, err := executor.Deploy(boshinit.DeployInput{
+				IAAS:       "aws",
+				SSLKeyPair: sslKeyPair,
+				OpsFiles: []storage.OpsFile{
+					{Name: "rename.yml", Contents: "- type: replace\n  path: /name\n  value: my-bosh\n"},
+					{Name: "workers.yml", Contents: "- type: replace\n  path: /properties?/workers\n  value: 6\n"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(string(deployCommandRunner.ExecuteCall.Receives.Manifest)).To(ContainSubstring("name: my-bosh"))
+			Expect(deployOutput.BOSHInitManifest).To(ContainSubstring("workers: 6"))
+		})
+
+		Context("local artifacts", func() {
+			var (
+				releasePath string
+				releaseSHA1 string
+			)
+
+			BeforeEach(func() {
+				releaseFile, err := ioutil.TempFile("", "bosh-release")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = releaseFile.Write([]byte("some-release-contents"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(releaseFile.Close()).To(Succeed())
+
+				releasePath = releaseFile.Name()
+				releaseSHA1 = fmt.Sprintf("%x", sha1.Sum([]byte("some-release-contents")))
+
+				manifestBuilder.BuildCall.Returns.Manifest = manifests.Manifest{
+					Name: "bosh",
+					Releases: []manifests.Release{
+						{Name: "bosh", URL: "file://" + releasePath, SHA1: releaseSHA1},
+					},
+					ResourcePools: []manifests.ResourcePool{{
+						Name:     "vms",
+						Stemcell: manifests.Stemcell{URL: "https://example.com/stemcell.tgz", SHA1: "some-stemcell-sha1"},
+					}},
+				}
+			})
+
+			AfterEach(func() {
+				os.Remove(releasePath)
+			})
+
+			It("deploys releases and stemcells from file urls when their sha1 matches", func() {
+				_, err := executor.Deploy(boshinit.DeployInput{IAAS: "aws", SSLKeyPair: sslKeyPair})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(string(deployCommandRunner.ExecuteCall.Receives.Manifest)).To(ContainSubstring("url: file://" + releasePath))
+			})
+
+			It("returns an error when the sha1 of a local artifact does not match", func() {
+				manifestBuilder.BuildCall.Returns.Manifest.Releases[0].SHA1 = "some-other-sha1"
+
+				_, err := executor.Deploy(boshinit.DeployInput{IAAS: "aws", SSLKeyPair: sslKeyPair})
+				Expect(err).To(MatchError(fmt.Sprintf(`release "bosh" at file://%s has SHA1 %s, expected some-other-sha1`, releasePath, releaseSHA1)))
+				Expect(deployCommandRunner.ExecuteCall.Receives.Manifest).To(BeNil())
+			})
+
+			It("returns an error when a local artifact cannot be read", func() {
+				Expect(os.Remove(releasePath)).To(Succeed())
+
+				_, err := executor.Deploy(boshinit.DeployInput{IAAS: "aws", SSLKeyPair: sslKeyPair})
+				Expect(err).To(MatchError(ContainSubstring(`error reading release "bosh": open ` + releasePath)))
+			})
+
+			It("refuses artifacts that would be downloaded in an air-gapped environment", func() {
+				_, err := executor.Deploy(boshinit.DeployInput{IAAS: "aws", SSLKeyPair: sslKeyPair, AirGapped: true})
+				Expect(err).To(MatchError(`stemcell of resource pool "vms" would be downloaded from https://example.com/stemcell.tgz, which is not allowed in an air-gapped environment`))
+				Expect(deployCommandRunner.ExecuteCall.Receives.Manifest).To(BeNil())
+			})
+		})
+
 		Context("failure cases", func() {
+			Context("when an ops file is invalid", func() {
+				It("returns an error", func() {
+					_, err := executor.Deploy(boshinit.DeployInput{
+						OpsFiles: []storage.OpsFile{{Name: "invalid.yml", Contents: "- type: merge\n  path: /name\n"}},
+					})
+					Expect(err).To(MatchError(`invalid ops file "invalid.yml": operation 0 has an invalid type "merge", supported types are: [replace, remove]`))
+					Expect(deployCommandRunner.ExecuteCall.Receives.Manifest).To(BeNil())
+				})
+			})
+
+			Context("when an ops file cannot be applied", func() {
+				It("returns an error", func() {
+					_, err := executor.Deploy(boshinit.DeployInput{
+						OpsFiles: []storage.OpsFile{{Name: "missing.yml", Contents: "- type: remove\n  path: /missing\n"}},
+					})
+					Expect(err).To(MatchError(`failed to apply remove operation for path "/missing": map key "missing" does not exist`))
+				})
+			})
+
 			Context("when the manifest cannot be built", func() {
 				It("returns an error", func() {
 					manifestBuilder.BuildCall.Returns.Error = errors.New("failed to build manifest")
@@ -325,6 +498,50 @@ var _ = Describe("Executor", func() {
 					Expect(err).To(MatchError("failed to deploy"))
 				})
 			})
+
+			Context("when the runner is stopped with a partial bosh-init state", func() {
+				It("returns the partial deploy output with the error", func() {
+					partialState := boshinit.State{"partial": "state"}
+					deployCommandRunner.ExecuteCall.Returns.State = partialState
+					deployCommandRunner.ExecuteCall.Returns.Error = boshinit.NewBOSHInitError(partialState, errors.New("bbl received interrupt, bosh-init was stopped"))
+
+					deployOutput, err := executor.Deploy(boshinit.DeployInput{
+						IAAS:       "aws",
+						SSLKeyPair: sslKeyPair,
+					})
+					Expect(err).To(MatchError("bbl received interrupt, bosh-init was stopped"))
+					Expect(deployOutput.BOSHInitState).To(Equal(partialState))
+					Expect(deployOutput.BOSHInitManifest).NotTo(BeEmpty())
+				})
+			})
+		})
+	})
+
+	Describe("Manifest", func() {
+		It("returns the bosh-init manifest without deploying", func() {
+			manifest, err := executor.Manifest(boshinit.DeployInput{
+				IAAS: "aws",
+				InfrastructureConfiguration: awsInfrastructureConfiguration,
+				SSLKeyPair:                  sslKeyPair,
+				EC2KeyPair:                  ec2KeyPair,
+				Credentials:                 credentials,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manifestBuilder.BuildCall.Receives.IAAS).To(Equal("aws"))
+			Expect(manifestBuilder.BuildCall.Receives.Properties.ExternalIP).To(Equal("some-elastic-ip"))
+			Expect(manifestBuilder.BuildCall.Receives.Properties.AWS.SubnetID).To(Equal("subnet-12345"))
+			Expect(manifestBuilder.BuildCall.Receives.Properties.AWS.DefaultKeyName).To(Equal("some-keypair-name"))
+
+			Expect(manifest).To(ContainSubstring("name: bosh"))
+			Expect(deployCommandRunner.ExecuteCall.Receives.Manifest).To(BeNil())
+		})
+
+		It("returns an error when the manifest cannot be built", func() {
+			manifestBuilder.BuildCall.Returns.Error = errors.New("failed to build manifest")
+
+			_, err := executor.Manifest(boshinit.DeployInput{})
+			Expect(err).To(MatchError("failed to build manifest"))
 		})
 	})
 })
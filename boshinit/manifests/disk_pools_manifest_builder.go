@@ -1,18 +1,22 @@
 package manifests
 
+const DefaultPersistentDiskSizeGB = 80
+
 type DiskPoolsManifestBuilder struct{}
 
 func NewDiskPoolsManifestBuilder() DiskPoolsManifestBuilder {
 	return DiskPoolsManifestBuilder{}
 }
 
-func (r DiskPoolsManifestBuilder) Build(iaas string) []DiskPool {
+func (r DiskPoolsManifestBuilder) Build(iaas string, manifestProperties ManifestProperties) []DiskPool {
+	sizing := manifestProperties.DirectorSizing
+
 	return []DiskPool{
 		{
 			Name:     "disks",
-			DiskSize: 80 * 1024,
+			DiskSize: intOrDefault(sizing.PersistentDiskSizeGB, DefaultPersistentDiskSizeGB) * 1024,
 			CloudProperties: DiskPoolsCloudProperties{
-				Type:      getDiskType(iaas),
+				Type:      stringOrDefault(sizing.PersistentDiskType, getDiskType(iaas)),
 				Encrypted: true,
 			},
 		},
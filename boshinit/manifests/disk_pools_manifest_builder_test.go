@@ -15,7 +15,7 @@ var _ = Describe("DiskPoolsManifestBuilder", func() {
 
 	Describe("Build", func() {
 		It("returns all disk pools for manifest for aws", func() {
-			diskPools := diskPoolsManifestBuilder.Build("aws")
+			diskPools := diskPoolsManifestBuilder.Build("aws", manifests.ManifestProperties{})
 
 			Expect(diskPools).To(HaveLen(1))
 			Expect(diskPools).To(ConsistOf([]manifests.DiskPool{
@@ -31,7 +31,7 @@ var _ = Describe("DiskPoolsManifestBuilder", func() {
 		})
 
 		It("returns all disk pools for manifest for gcp", func() {
-			diskPools := diskPoolsManifestBuilder.Build("gcp")
+			diskPools := diskPoolsManifestBuilder.Build("gcp", manifests.ManifestProperties{})
 
 			Expect(diskPools).To(HaveLen(1))
 			Expect(diskPools).To(ConsistOf([]manifests.DiskPool{
@@ -45,5 +45,25 @@ var _ = Describe("DiskPoolsManifestBuilder", func() {
 				},
 			}))
 		})
+
+		It("uses the persistent disk size and type of the director sizing", func() {
+			diskPools := diskPoolsManifestBuilder.Build("aws", manifests.ManifestProperties{
+				DirectorSizing: manifests.DirectorSizing{
+					PersistentDiskSizeGB: 200,
+					PersistentDiskType:   "io1",
+				},
+			})
+
+			Expect(diskPools).To(ConsistOf([]manifests.DiskPool{
+				{
+					Name:     "disks",
+					DiskSize: 200 * 1024,
+					CloudProperties: manifests.DiskPoolsCloudProperties{
+						Type:      "io1",
+						Encrypted: true,
+					},
+				},
+			}))
+		})
 	})
 })
@@ -49,11 +49,11 @@ func (j JobPropertiesManifestBuilder) Postgres() PostgresProperties {
 	}
 }
 
-func (j JobPropertiesManifestBuilder) Registry() RegistryJobProperties {
+func (j JobPropertiesManifestBuilder) Registry(directorIP string) RegistryJobProperties {
 	postgres := j.Postgres()
 	return RegistryJobProperties{
-		Host:     "10.0.0.6",
-		Address:  "10.0.0.6",
+		Host:     directorIP,
+		Address:  directorIP,
 		Username: j.registryUsername,
 		Password: j.registryPassword,
 		DB: RegistryPostgresProperties{
@@ -68,9 +68,9 @@ func (j JobPropertiesManifestBuilder) Registry() RegistryJobProperties {
 	}
 }
 
-func (j JobPropertiesManifestBuilder) Blobstore() BlobstoreJobProperties {
+func (j JobPropertiesManifestBuilder) Blobstore(directorIP string) BlobstoreJobProperties {
 	return BlobstoreJobProperties{
-		Address: "10.0.0.6",
+		Address: directorIP,
 		Director: Credentials{
 			User:     j.blobstoreDirectorUsername,
 			Password: j.blobstoreDirectorPassword,
@@ -125,9 +125,9 @@ func (j JobPropertiesManifestBuilder) HM() HMJobProperties {
 	}
 }
 
-func (j JobPropertiesManifestBuilder) Agent() AgentProperties {
+func (j JobPropertiesManifestBuilder) Agent(directorIP string) AgentProperties {
 	return AgentProperties{
-		MBus: fmt.Sprintf("nats://%s:%s@10.0.0.6:4222", j.natsUsername, j.natsPassword),
+		MBus: fmt.Sprintf("nats://%s:%s@%s:4222", j.natsUsername, j.natsPassword, directorIP),
 	}
 }
 
@@ -33,15 +33,17 @@ func (j JobsManifestBuilder) Build(iaas string, manifestProperties ManifestPrope
 		manifestProperties.Credentials.HMPassword,
 	)
 
+	directorIP := manifestProperties.DirectorNetwork.DirectorIP
+
 	cpiName, cpiRelease := getCPIJob(iaas)
 	jobProperties := JobProperties{
 		NATS:      jobPropertiesManifestBuilder.NATS(),
 		Postgres:  jobPropertiesManifestBuilder.Postgres(),
-		Registry:  jobPropertiesManifestBuilder.Registry(),
-		Blobstore: jobPropertiesManifestBuilder.Blobstore(),
+		Registry:  jobPropertiesManifestBuilder.Registry(directorIP),
+		Blobstore: jobPropertiesManifestBuilder.Blobstore(directorIP),
 		Director:  jobPropertiesManifestBuilder.Director(iaas, manifestProperties),
 		HM:        jobPropertiesManifestBuilder.HM(),
-		Agent:     jobPropertiesManifestBuilder.Agent(),
+		Agent:     jobPropertiesManifestBuilder.Agent(directorIP),
 	}
 
 	if iaas == "aws" {
@@ -70,7 +72,7 @@ func (j JobsManifestBuilder) Build(iaas string, manifestProperties ManifestPrope
 			Networks: []JobNetwork{
 				{
 					Name:      "private",
-					StaticIPs: []string{"10.0.0.6"},
+					StaticIPs: []string{directorIP},
 					Default:   []string{"dns", "gateway"},
 				},
 				{
@@ -33,6 +33,9 @@ type ManifestProperties struct {
 	Credentials      InternalCredentials
 	AWS              ManifestPropertiesAWS
 	GCP              ManifestPropertiesGCP
+	Versions         Versions
+	DirectorSizing   DirectorSizing
+	DirectorNetwork  DirectorNetwork
 }
 
 type ManifestPropertiesAWS struct {
@@ -79,6 +82,36 @@ type ManifestBuilderInput struct {
 	GCPStemcellSHA1 string
 }
 
+// Versions are the release and stemcell URLs and SHA1s used in the manifest.
+// Empty fields in overrides fall back to the versions bbl was built with.
+type Versions struct {
+	BOSHURL      string
+	BOSHSHA1     string
+	CPIURL       string
+	CPISHA1      string
+	StemcellURL  string
+	StemcellSHA1 string
+}
+
+// DirectorSizing overrides the instance type and disks of the director VM.
+// Empty fields keep the defaults for the IAAS.
+type DirectorSizing struct {
+	InstanceType         string
+	EphemeralDiskSizeGB  int
+	EphemeralDiskType    string
+	PersistentDiskSizeGB int
+	PersistentDiskType   string
+}
+
+// DirectorNetwork is the subnet of the director and its internal IP, as laid
+// out by bosh.NetworkLayout.
+type DirectorNetwork struct {
+	SubnetCIDR string
+	Gateway    string
+	DNS        string
+	DirectorIP string
+}
+
 func NewManifestBuilder(input ManifestBuilderInput, logger logger, sslKeyPairGenerator sslKeyPairGenerator, stringGenerator stringGenerator, cloudProviderManifestBuilder cloudProviderManifestBuilder, jobsManifestBuilder jobsManifestBuilder) ManifestBuilder {
 	return ManifestBuilder{
 		input:                        input,
@@ -117,21 +150,54 @@ func (m ManifestBuilder) Build(iaas string, manifestProperties ManifestPropertie
 		return Manifest{}, ManifestProperties{}, err
 	}
 
-	boshURL, boshSHA1 := getBOSHRelease(iaas, m.input.AWSBOSHURL, m.input.AWSBOSHSHA1, m.input.GCPBOSHURL, m.input.GCPBOSHSHA1)
-	cpiName, cpiURL, cpiSHA1 := getCPIRelease(iaas, m.input.BOSHAWSCPIURL, m.input.BOSHAWSCPISHA1, m.input.BOSHGCPCPIURL, m.input.BOSHGCPCPISHA1)
-	stemcellURL, stemcellSHA1 := getStemcell(iaas, m.input.AWSStemcellURL, m.input.AWSStemcellSHA1, m.input.GCPStemcellURL, m.input.GCPStemcellSHA1)
+	cpiName, _, _ := getCPIRelease(iaas, m.input.BOSHAWSCPIURL, m.input.BOSHAWSCPISHA1, m.input.BOSHGCPCPIURL, m.input.BOSHGCPCPISHA1)
+	versions := m.DefaultVersions(iaas).Override(manifestProperties.Versions)
 
 	return Manifest{
 		Name:          "bosh",
-		Releases:      releaseManifestBuilder.Build(boshURL, boshSHA1, cpiName, cpiURL, cpiSHA1),
-		ResourcePools: resourcePoolsManifestBuilder.Build(iaas, manifestProperties, stemcellURL, stemcellSHA1),
-		DiskPools:     diskPoolsManifestBuilder.Build(iaas),
+		Releases:      releaseManifestBuilder.Build(versions.BOSHURL, versions.BOSHSHA1, cpiName, versions.CPIURL, versions.CPISHA1),
+		ResourcePools: resourcePoolsManifestBuilder.Build(iaas, manifestProperties, versions.StemcellURL, versions.StemcellSHA1),
+		DiskPools:     diskPoolsManifestBuilder.Build(iaas, manifestProperties),
 		Networks:      networksManifestBuilder.Build(manifestProperties),
 		Jobs:          jobs,
 		CloudProvider: cloudProvider,
 	}, manifestProperties, nil
 }
 
+func (m ManifestBuilder) DefaultVersions(iaas string) Versions {
+	boshURL, boshSHA1 := getBOSHRelease(iaas, m.input.AWSBOSHURL, m.input.AWSBOSHSHA1, m.input.GCPBOSHURL, m.input.GCPBOSHSHA1)
+	_, cpiURL, cpiSHA1 := getCPIRelease(iaas, m.input.BOSHAWSCPIURL, m.input.BOSHAWSCPISHA1, m.input.BOSHGCPCPIURL, m.input.BOSHGCPCPISHA1)
+	stemcellURL, stemcellSHA1 := getStemcell(iaas, m.input.AWSStemcellURL, m.input.AWSStemcellSHA1, m.input.GCPStemcellURL, m.input.GCPStemcellSHA1)
+
+	return Versions{
+		BOSHURL:      boshURL,
+		BOSHSHA1:     boshSHA1,
+		CPIURL:       cpiURL,
+		CPISHA1:      cpiSHA1,
+		StemcellURL:  stemcellURL,
+		StemcellSHA1: stemcellSHA1,
+	}
+}
+
+func (v Versions) Override(overrides Versions) Versions {
+	if overrides.BOSHURL != "" {
+		v.BOSHURL = overrides.BOSHURL
+		v.BOSHSHA1 = overrides.BOSHSHA1
+	}
+
+	if overrides.CPIURL != "" {
+		v.CPIURL = overrides.CPIURL
+		v.CPISHA1 = overrides.CPISHA1
+	}
+
+	if overrides.StemcellURL != "" {
+		v.StemcellURL = overrides.StemcellURL
+		v.StemcellSHA1 = overrides.StemcellSHA1
+	}
+
+	return v
+}
+
 func getBOSHRelease(iaas, awsURL, awsSHA1, gcpURL, gcpSHA1 string) (url, sha1 string) {
 	switch iaas {
 	case "aws":
@@ -57,6 +57,12 @@ var _ = Describe("ManifestBuilder", func() {
 			DirectorPassword: "bosh-password",
 			CACommonName:     "BOSH Bootloader",
 			ExternalIP:       "52.0.112.12",
+			DirectorNetwork: manifests.DirectorNetwork{
+				SubnetCIDR: "10.0.0.0/24",
+				Gateway:    "10.0.0.1",
+				DNS:        "10.0.0.2",
+				DirectorIP: "10.0.0.6",
+			},
 			AWS: manifests.ManifestPropertiesAWS{
 				SubnetID:         "subnet-12345",
 				AvailabilityZone: "some-az",
@@ -74,6 +80,12 @@ var _ = Describe("ManifestBuilder", func() {
 			DirectorPassword: "bosh-password",
 			ExternalIP:       "52.0.112.12",
 			CACommonName:     "BOSH Bootloader",
+			DirectorNetwork: manifests.DirectorNetwork{
+				SubnetCIDR: "10.0.0.0/24",
+				Gateway:    "10.0.0.1",
+				DNS:        "10.0.0.2",
+				DirectorIP: "10.0.0.6",
+			},
 			GCP: manifests.ManifestPropertiesGCP{
 				Zone:           "some-zone",
 				NetworkName:    "some-network-name",
@@ -138,6 +150,12 @@ var _ = Describe("ManifestBuilder", func() {
 					DirectorPassword: "bosh-password",
 					CACommonName:     "BOSH Bootloader",
 					ExternalIP:       "52.0.112.12",
+					DirectorNetwork: manifests.DirectorNetwork{
+						SubnetCIDR: "10.0.0.0/24",
+						Gateway:    "10.0.0.1",
+						DNS:        "10.0.0.2",
+						DirectorIP: "10.0.0.6",
+					},
 					AWS: manifests.ManifestPropertiesAWS{
 						SubnetID:         "subnet-12345",
 						AvailabilityZone: "some-az",
@@ -255,6 +273,27 @@ var _ = Describe("ManifestBuilder", func() {
 			}))
 		})
 
+		It("uses the overridden versions instead of the defaults", func() {
+			awsManifestProperties.Versions = manifests.Versions{
+				BOSHURL:      "some-patched-bosh-url",
+				BOSHSHA1:     "some-patched-bosh-sha1",
+				StemcellURL:  "some-patched-stemcell-url",
+				StemcellSHA1: "some-patched-stemcell-sha1",
+			}
+
+			manifest, _, err := manifestBuilder.Build("aws", awsManifestProperties)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manifest.Releases).To(Equal([]manifests.Release{
+				{Name: "bosh", URL: "some-patched-bosh-url", SHA1: "some-patched-bosh-sha1"},
+				{Name: "bosh-aws-cpi", URL: "some-bosh-aws-cpi-url", SHA1: "some-bosh-aws-cpi-sha1"},
+			}))
+			Expect(manifest.ResourcePools[0].Stemcell).To(Equal(manifests.Stemcell{
+				URL:  "some-patched-stemcell-url",
+				SHA1: "some-patched-stemcell-sha1",
+			}))
+		})
+
 		It("does not generate an ssl keypair if it exists", func() {
 			awsManifestProperties.SSLKeyPair = ssl.KeyPair{
 				CA:          []byte(ca),
@@ -399,6 +438,19 @@ var _ = Describe("ManifestBuilder", func() {
 		})
 	})
 
+	Describe("DefaultVersions", func() {
+		It("returns the versions bbl was built with for the iaas", func() {
+			Expect(manifestBuilder.DefaultVersions("gcp")).To(Equal(manifests.Versions{
+				BOSHURL:      "some-google-bosh-url",
+				BOSHSHA1:     "some-google-bosh-sha1",
+				CPIURL:       "some-bosh-google-cpi-url",
+				CPISHA1:      "some-bosh-google-cpi-sha1",
+				StemcellURL:  "some-google-stemcell-url",
+				StemcellSHA1: "some-google-stemcell-sha1",
+			}))
+		})
+	})
+
 	Describe("template marshaling", func() {
 		BeforeEach(func() {
 			sslKeyPairGenerator.GenerateCall.Returns.KeyPair = ssl.KeyPair{
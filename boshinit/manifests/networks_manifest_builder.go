@@ -7,6 +7,7 @@ func NewNetworksManifestBuilder() NetworksManifestBuilder {
 }
 
 func (r NetworksManifestBuilder) Build(manifestProperties ManifestProperties) []Network {
+	network := manifestProperties.DirectorNetwork
 	cloudProperties := NetworksCloudProperties{}
 
 	if manifestProperties.AWS.SubnetID != "" {
@@ -35,9 +36,9 @@ func (r NetworksManifestBuilder) Build(manifestProperties ManifestProperties) []
 			Type: "manual",
 			Subnets: []Subnet{
 				{
-					Range:           "10.0.0.0/24",
-					Gateway:         "10.0.0.1",
-					DNS:             []string{"10.0.0.2"},
+					Range:           network.SubnetCIDR,
+					Gateway:         network.Gateway,
+					DNS:             []string{network.DNS},
 					CloudProperties: cloudProperties,
 				},
 			},
@@ -16,7 +16,13 @@ var _ = Describe("NetworksManifestBuilder", func() {
 	Describe("Build", func() {
 		It("returns all networks for manifest", func() {
 			networks := networksManifestBuilder.Build(manifests.ManifestProperties{
-				AWS: manifests.ManifestPropertiesAWS{SubnetID: "subnet-12345"}})
+				AWS: manifests.ManifestPropertiesAWS{SubnetID: "subnet-12345"},
+				DirectorNetwork: manifests.DirectorNetwork{
+					SubnetCIDR: "10.0.0.0/24",
+					Gateway:    "10.0.0.1",
+					DNS:        "10.0.0.2",
+				},
+			})
 
 			Expect(networks).To(HaveLen(2))
 			Expect(networks).To(ConsistOf([]manifests.Network{
@@ -41,6 +47,21 @@ var _ = Describe("NetworksManifestBuilder", func() {
 			}))
 		})
 
+		It("uses the subnet of the director network", func() {
+			networks := networksManifestBuilder.Build(manifests.ManifestProperties{
+				AWS: manifests.ManifestPropertiesAWS{SubnetID: "subnet-12345"},
+				DirectorNetwork: manifests.DirectorNetwork{
+					SubnetCIDR: "172.20.0.0/24",
+					Gateway:    "172.20.0.1",
+					DNS:        "172.20.0.2",
+				},
+			})
+
+			Expect(networks[0].Subnets[0].Range).To(Equal("172.20.0.0/24"))
+			Expect(networks[0].Subnets[0].Gateway).To(Equal("172.20.0.1"))
+			Expect(networks[0].Subnets[0].DNS).To(Equal([]string{"172.20.0.2"}))
+		})
+
 		It("returns networks with aws cloud properties", func() {
 			networks := networksManifestBuilder.Build(manifests.ManifestProperties{
 				AWS: manifests.ManifestPropertiesAWS{SubnetID: "subnet-12345"}})
@@ -0,0 +1,238 @@
+package manifests
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	ReplaceOp = "replace"
+	RemoveOp  = "remove"
+)
+
+type Op struct {
+	Type  string      `yaml:"type"`
+	Path  string      `yaml:"path"`
+	Value interface{} `yaml:"value,omitempty"`
+}
+
+type opToken struct {
+	key      string
+	index    int
+	isIndex  bool
+	isAppend bool
+	matchKey string
+	matchVal string
+	optional bool
+}
+
+func ParseOps(contents []byte) ([]Op, error) {
+	var ops []Op
+	if err := yaml.Unmarshal(contents, &ops); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		if op.Type != ReplaceOp && op.Type != RemoveOp {
+			return nil, fmt.Errorf("operation %d has an invalid type %q, supported types are: [replace, remove]", i, op.Type)
+		}
+
+		if !strings.HasPrefix(op.Path, "/") {
+			return nil, fmt.Errorf("operation %d has an invalid path %q, paths must start with /", i, op.Path)
+		}
+	}
+
+	return ops, nil
+}
+
+// ApplyOps applies BOSH style ops to the manifest. The result is a generic document,
+// since the ops can introduce keys the Manifest type does not know about.
+func ApplyOps(manifest Manifest, ops []Op) (interface{}, error) {
+	manifestYAML, err := yaml.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var document interface{}
+	if err := yaml.Unmarshal(manifestYAML, &document); err != nil {
+		return nil, err
+	}
+
+	for _, op := range ops {
+		document, err = op.apply(document, parseOpPath(op.Path), false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply %s operation for path %q: %s", op.Type, op.Path, err)
+		}
+	}
+
+	return document, nil
+}
+
+func parseOpPath(path string) []opToken {
+	tokens := []opToken{}
+	for _, segment := range strings.Split(path, "/")[1:] {
+		segment = strings.Replace(strings.Replace(segment, "~1", "/", -1), "~0", "~", -1)
+
+		token := opToken{}
+		if strings.HasSuffix(segment, "?") {
+			token.optional = true
+			segment = strings.TrimSuffix(segment, "?")
+		}
+
+		if segment == "-" {
+			token.isAppend = true
+		} else if index, err := strconv.Atoi(segment); err == nil {
+			token.isIndex = true
+			token.index = index
+		} else if parts := strings.SplitN(segment, "=", 2); len(parts) == 2 {
+			token.matchKey = parts[0]
+			token.matchVal = parts[1]
+		} else {
+			token.key = segment
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens
+}
+
+func (o Op) apply(node interface{}, tokens []opToken, optional bool) (interface{}, error) {
+	if len(tokens) == 0 {
+		if o.Type == RemoveOp {
+			return nil, errors.New("cannot remove the whole manifest")
+		}
+		return o.Value, nil
+	}
+
+	token := tokens[0]
+	optional = optional || token.optional
+	last := len(tokens) == 1
+
+	switch typedNode := node.(type) {
+	case map[interface{}]interface{}:
+		if token.key == "" {
+			return nil, fmt.Errorf("expected a map key, found %q", tokenString(token))
+		}
+
+		child, ok := typedNode[token.key]
+		if last {
+			switch {
+			case !ok && !optional:
+				return nil, fmt.Errorf("map key %q does not exist", token.key)
+			case o.Type == ReplaceOp:
+				typedNode[token.key] = o.Value
+			case ok:
+				delete(typedNode, token.key)
+			}
+			return typedNode, nil
+		}
+
+		if !ok {
+			switch {
+			case !optional:
+				return nil, fmt.Errorf("map key %q does not exist", token.key)
+			case o.Type == RemoveOp:
+				return typedNode, nil
+			}
+			child = emptyNodeFor(tokens[1])
+		}
+
+		value, err := o.apply(child, tokens[1:], optional)
+		if err != nil {
+			return nil, err
+		}
+		typedNode[token.key] = value
+
+		return typedNode, nil
+	case []interface{}:
+		index, err := o.arrayIndex(typedNode, token, optional)
+		if err != nil {
+			return nil, err
+		}
+
+		if index == len(typedNode) {
+			if !last || o.Type != ReplaceOp {
+				return nil, fmt.Errorf("%q can only be used as the last segment of a replace operation", tokenString(token))
+			}
+			return append(typedNode, o.Value), nil
+		}
+
+		if index < 0 {
+			if o.Type == RemoveOp {
+				return typedNode, nil
+			}
+			typedNode = append(typedNode, map[interface{}]interface{}{token.matchKey: token.matchVal})
+			index = len(typedNode) - 1
+		}
+
+		if last {
+			if o.Type == RemoveOp {
+				return append(typedNode[:index], typedNode[index+1:]...), nil
+			}
+			typedNode[index] = o.Value
+			return typedNode, nil
+		}
+
+		typedNode[index], err = o.apply(typedNode[index], tokens[1:], optional)
+		if err != nil {
+			return nil, err
+		}
+
+		return typedNode, nil
+	default:
+		return nil, fmt.Errorf("cannot traverse %q, its parent is not a map or an array", tokenString(token))
+	}
+}
+
+// arrayIndex returns len(array) for an append and -1 for an optional match that was not found.
+func (o Op) arrayIndex(array []interface{}, token opToken, optional bool) (int, error) {
+	switch {
+	case token.isAppend:
+		return len(array), nil
+	case token.isIndex:
+		if token.index < 0 || token.index >= len(array) {
+			return 0, fmt.Errorf("index %d is out of range, the array has %d elements", token.index, len(array))
+		}
+		return token.index, nil
+	case token.matchKey != "":
+		for i, element := range array {
+			if item, ok := element.(map[interface{}]interface{}); ok && fmt.Sprint(item[token.matchKey]) == token.matchVal {
+				return i, nil
+			}
+		}
+
+		if optional {
+			return -1, nil
+		}
+
+		return 0, fmt.Errorf("no array element with %s=%s", token.matchKey, token.matchVal)
+	default:
+		return 0, fmt.Errorf("expected an array index, found %q", token.key)
+	}
+}
+
+func emptyNodeFor(token opToken) interface{} {
+	if token.isAppend || token.isIndex || token.matchKey != "" {
+		return []interface{}{}
+	}
+
+	return map[interface{}]interface{}{}
+}
+
+func tokenString(token opToken) string {
+	switch {
+	case token.isAppend:
+		return "-"
+	case token.isIndex:
+		return strconv.Itoa(token.index)
+	case token.matchKey != "":
+		return token.matchKey + "=" + token.matchVal
+	default:
+		return token.key
+	}
+}
@@ -0,0 +1,160 @@
+package manifests_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/boshinit/manifests"
+	yaml "gopkg.in/yaml.v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ops", func() {
+	var manifest manifests.Manifest
+
+	BeforeEach(func() {
+		manifest = manifests.Manifest{
+			Name: "bosh",
+			Releases: []manifests.Release{
+				{Name: "bosh", URL: "some-bosh-url", SHA1: "some-bosh-sha1"},
+			},
+			Jobs: []manifests.Job{{
+				Name:      "bosh",
+				Instances: 1,
+				Templates: []manifests.Template{
+					{Name: "director", Release: "bosh"},
+					{Name: "health_monitor", Release: "bosh"},
+				},
+			}},
+		}
+	})
+
+	apply := func(opsYAML string) (string, error) {
+		ops, err := manifests.ParseOps([]byte(opsYAML))
+		Expect(err).NotTo(HaveOccurred())
+
+		document, err := manifests.ApplyOps(manifest, ops)
+		if err != nil {
+			return "", err
+		}
+
+		manifestYAML, err := yaml.Marshal(document)
+		Expect(err).NotTo(HaveOccurred())
+
+		return string(manifestYAML), nil
+	}
+
+	Describe("ParseOps", func() {
+		It("returns an error for an unsupported operation type", func() {
+			_, err := manifests.ParseOps([]byte("- type: merge\n  path: /name\n"))
+			Expect(err).To(MatchError(`operation 0 has an invalid type "merge", supported types are: [replace, remove]`))
+		})
+
+		It("returns an error for a path that does not start with a slash", func() {
+			_, err := manifests.ParseOps([]byte("- type: remove\n  path: name\n"))
+			Expect(err).To(MatchError(`operation 0 has an invalid path "name", paths must start with /`))
+		})
+
+		It("returns an error for invalid yaml", func() {
+			_, err := manifests.ParseOps([]byte("%%%"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ApplyOps", func() {
+		It("appends to an array and replaces a matched element", func() {
+			manifestYAML, err := apply(`
+- type: replace
+  path: /releases/-
+  value: {name: syslog, url: some-syslog-url, sha1: some-syslog-sha1}
+- type: replace
+  path: /jobs/name=bosh/templates/-
+  value: {name: syslog_forwarder, release: syslog}
+- type: replace
+  path: /jobs/0/instances
+  value: 2
+`)
+			Expect(err).NotTo(HaveOccurred())
+
+			var document struct {
+				Releases []manifests.Release `yaml:"releases"`
+				Jobs     []manifests.Job     `yaml:"jobs"`
+			}
+			Expect(yaml.Unmarshal([]byte(manifestYAML), &document)).To(Succeed())
+
+			Expect(document.Releases).To(Equal([]manifests.Release{
+				{Name: "bosh", URL: "some-bosh-url", SHA1: "some-bosh-sha1"},
+				{Name: "syslog", URL: "some-syslog-url", SHA1: "some-syslog-sha1"},
+			}))
+			Expect(document.Jobs[0].Instances).To(Equal(2))
+			Expect(document.Jobs[0].Templates).To(Equal([]manifests.Template{
+				{Name: "director", Release: "bosh"},
+				{Name: "health_monitor", Release: "bosh"},
+				{Name: "syslog_forwarder", Release: "syslog"},
+			}))
+		})
+
+		It("creates missing keys for optional paths", func() {
+			manifestYAML, err := apply(`
+- type: replace
+  path: /jobs/name=bosh/properties/director/workers?
+  value: 6
+`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manifestYAML).To(ContainSubstring("workers: 6"))
+		})
+
+		It("returns an error when the last key of a replace does not exist and the path is not optional", func() {
+			_, err := apply(`
+- type: replace
+  path: /jobs/name=bosh/workers
+  value: 6
+`)
+			Expect(err).To(MatchError(`failed to apply replace operation for path "/jobs/name=bosh/workers": map key "workers" does not exist`))
+		})
+
+		It("replaces existing keys without the optional marker", func() {
+			manifestYAML, err := apply(`
+- type: replace
+  path: /jobs/name=bosh/instances
+  value: 2
+`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manifestYAML).To(ContainSubstring("instances: 2"))
+		})
+
+		It("removes matched elements", func() {
+			manifestYAML, err := apply(`
+- type: remove
+  path: /jobs/name=bosh/templates/name=health_monitor
+`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manifestYAML).NotTo(ContainSubstring("health_monitor"))
+			Expect(manifestYAML).To(ContainSubstring("director"))
+		})
+
+		It("ignores removals of optional paths that do not exist", func() {
+			_, err := apply(`
+- type: remove
+  path: /jobs/name=bosh/properties/syslog?
+`)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error when a path does not exist", func() {
+			_, err := apply(`
+- type: replace
+  path: /jobs/name=missing/instances
+  value: 2
+`)
+			Expect(err).To(MatchError(`failed to apply replace operation for path "/jobs/name=missing/instances": no array element with name=missing`))
+		})
+
+		It("returns an error when an index is out of range", func() {
+			_, err := apply(`
+- type: remove
+  path: /releases/3
+`)
+			Expect(err).To(MatchError(`failed to apply remove operation for path "/releases/3": index 3 is out of range, the array has 1 elements`))
+		})
+	})
+})
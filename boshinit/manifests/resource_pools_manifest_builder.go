@@ -21,22 +21,24 @@ func (r ResourcePoolsManifestBuilder) Build(iaas string, manifestProperties Mani
 }
 
 func getCloudProperties(iaas string, manifestProperties ManifestProperties) ResourcePoolCloudProperties {
+	sizing := manifestProperties.DirectorSizing
+
 	switch iaas {
 	case "aws":
 		return ResourcePoolCloudProperties{
-			InstanceType: "m3.xlarge",
+			InstanceType: stringOrDefault(sizing.InstanceType, "m3.xlarge"),
 			EphemeralDisk: EphemeralDisk{
-				Size: 25000,
-				Type: "gp2",
+				Size: intOrDefault(sizing.EphemeralDiskSizeGB*1024, 25000),
+				Type: stringOrDefault(sizing.EphemeralDiskType, "gp2"),
 			},
 			AvailabilityZone: manifestProperties.AWS.AvailabilityZone,
 		}
 	case "gcp":
 		return ResourcePoolCloudProperties{
 			Zone:           manifestProperties.GCP.Zone,
-			MachineType:    "n1-standard-4",
-			RootDiskSizeGB: 25,
-			RootDiskType:   "pd-standard",
+			MachineType:    stringOrDefault(sizing.InstanceType, "n1-standard-4"),
+			RootDiskSizeGB: intOrDefault(sizing.EphemeralDiskSizeGB, 25),
+			RootDiskType:   stringOrDefault(sizing.EphemeralDiskType, "pd-standard"),
 			ServiceScopes: []string{
 				"compute",
 				"devstorage.full_control",
@@ -46,3 +48,19 @@ func getCloudProperties(iaas string, manifestProperties ManifestProperties) Reso
 		return ResourcePoolCloudProperties{}
 	}
 }
+
+func stringOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
+func intOrDefault(value, defaultValue int) int {
+	if value == 0 {
+		return defaultValue
+	}
+
+	return value
+}
@@ -71,5 +71,35 @@ var _ = Describe("ResourcePoolsManifestBuilder", func() {
 				},
 			}))
 		})
+
+		It("uses the instance type and ephemeral disk of the director sizing on aws", func() {
+			resourcePools := resourcePoolsManifestBuilder.Build("aws", manifests.ManifestProperties{
+				DirectorSizing: manifests.DirectorSizing{
+					InstanceType:        "m4.2xlarge",
+					EphemeralDiskSizeGB: 50,
+					EphemeralDiskType:   "io1",
+				},
+			}, "some-stemcell-url", "some-stemcell-sha1")
+
+			Expect(resourcePools[0].CloudProperties.InstanceType).To(Equal("m4.2xlarge"))
+			Expect(resourcePools[0].CloudProperties.EphemeralDisk).To(Equal(manifests.EphemeralDisk{
+				Size: 50 * 1024,
+				Type: "io1",
+			}))
+		})
+
+		It("uses the machine type and root disk of the director sizing on gcp", func() {
+			resourcePools := resourcePoolsManifestBuilder.Build("gcp", manifests.ManifestProperties{
+				DirectorSizing: manifests.DirectorSizing{
+					InstanceType:        "n1-standard-1",
+					EphemeralDiskSizeGB: 50,
+					EphemeralDiskType:   "pd-ssd",
+				},
+			}, "some-stemcell-url", "some-stemcell-sha1")
+
+			Expect(resourcePools[0].CloudProperties.MachineType).To(Equal("n1-standard-1"))
+			Expect(resourcePools[0].CloudProperties.RootDiskSizeGB).To(Equal(50))
+			Expect(resourcePools[0].CloudProperties.RootDiskType).To(Equal("pd-ssd"))
+		})
 	})
 })
@@ -0,0 +1,33 @@
+package boshinit
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/boshinit/manifests"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	BOSHReleaseVersion = "bosh"
+	CPIReleaseVersion  = "cpi"
+	StemcellVersion    = "stemcell"
+)
+
+var VersionNames = []string{BOSHReleaseVersion, CPIReleaseVersion, StemcellVersion}
+
+func OverriddenVersions(overrides []storage.VersionOverride) manifests.Versions {
+	versions := manifests.Versions{}
+	for _, override := range overrides {
+		switch override.Name {
+		case BOSHReleaseVersion:
+			versions.BOSHURL = override.URL
+			versions.BOSHSHA1 = override.SHA1
+		case CPIReleaseVersion:
+			versions.CPIURL = override.URL
+			versions.CPISHA1 = override.SHA1
+		case StemcellVersion:
+			versions.StemcellURL = override.URL
+			versions.StemcellSHA1 = override.SHA1
+		}
+	}
+
+	return versions
+}
@@ -9,6 +9,7 @@ type CloudConfigInput struct {
 	Tags                []string
 	NetworkName         string
 	SubnetworkName      string
+	NetworkCIDR         string
 	ConcourseTargetPool string
 	CFBackends          CFBackends
 }
@@ -115,7 +116,7 @@ func (CloudConfigGenerator) generateNetworks(input CloudConfigInput, cloudConfig
 		azs = append(azs, az.Name)
 	}
 
-	networksGenerator := NewNetworksGenerator(input.NetworkName, input.SubnetworkName, input.Tags, azs)
+	networksGenerator := NewNetworksGenerator(input.NetworkName, input.SubnetworkName, input.NetworkCIDR, input.Tags, azs)
 
 	var err error
 	cloudConfig.Networks, err = networksGenerator.Generate()
@@ -117,7 +117,7 @@ var _ = Describe("CloudConfigGenerator", func() {
 					SubnetworkName: "some-subnetwork-name",
 				})
 
-				Expect(err).To(MatchError(ContainSubstring("invalid ip")))
+				Expect(err).To(MatchError(ContainSubstring("has room for 15 internal subnets")))
 			})
 		})
 	})
@@ -9,6 +9,7 @@ import (
 type NetworksGenerator struct {
 	networkName    string
 	subnetworkName string
+	networkCIDR    string
 	tags           []string
 	azs            []string
 }
@@ -35,10 +36,11 @@ type SubnetCloudProperties struct {
 	Tags                []string `yaml:"tags"`
 }
 
-func NewNetworksGenerator(networkName, subnetworkName string, tags, azs []string) NetworksGenerator {
+func NewNetworksGenerator(networkName, subnetworkName, networkCIDR string, tags, azs []string) NetworksGenerator {
 	return NetworksGenerator{
 		networkName:    networkName,
 		subnetworkName: subnetworkName,
+		networkCIDR:    networkCIDR,
 		tags:           tags,
 		azs:            azs,
 	}
@@ -50,9 +52,14 @@ func (n NetworksGenerator) Generate() ([]Network, error) {
 		Type: "manual",
 	}
 
-	cidrBlocks := []string{}
-	for i := 1; i <= len(n.azs); i++ {
-		cidrBlocks = append(cidrBlocks, fmt.Sprintf("10.0.%d.0/20", 16*(i)))
+	layout, err := bosh.NewNetworkLayout(n.networkCIDR, "", "")
+	if err != nil {
+		return []Network{}, err
+	}
+
+	cidrBlocks, err := layout.InternalSubnetCIDRs(len(n.azs))
+	if err != nil {
+		return []Network{}, err
 	}
 
 	for i, az := range n.azs {
@@ -15,6 +15,7 @@ var _ = Describe("NetworksGenerator", func() {
 			generator := gcp.NewNetworksGenerator(
 				"some-network-name",
 				"some-subnetwork-name",
+				"",
 				[]string{"some-tag", "some-other-tag"},
 				[]string{"z1", "z2", "z3"},
 			)
@@ -94,8 +95,39 @@ var _ = Describe("NetworksGenerator", func() {
 			))
 		})
 
+		It("carves the subnets out of the network CIDR", func() {
+			generator := gcp.NewNetworksGenerator(
+				"some-network-name",
+				"some-subnetwork-name",
+				"172.20.0.0/16",
+				[]string{"some-tag"},
+				[]string{"z1", "z2"},
+			)
+
+			networks, err := generator.Generate()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(networks[0].Subnets).To(HaveLen(2))
+			Expect(networks[0].Subnets[0].Range).To(Equal("172.20.16.0/20"))
+			Expect(networks[0].Subnets[0].Gateway).To(Equal("172.20.16.1"))
+			Expect(networks[0].Subnets[1].Range).To(Equal("172.20.32.0/20"))
+			Expect(networks[0].Subnets[1].Gateway).To(Equal("172.20.32.1"))
+		})
+
 		Context("failure cases", func() {
-			It("returns an error when CIDR block cannot be parsed", func() {
+			It("returns an error when the network CIDR is invalid", func() {
+				generator := gcp.NewNetworksGenerator(
+					"some-network-name",
+					"some-subnetwork-name",
+					"some-cidr",
+					[]string{"some-tag"},
+					[]string{"z1"},
+				)
+				_, err := generator.Generate()
+				Expect(err).To(MatchError(ContainSubstring(`invalid network CIDR "some-cidr"`)))
+			})
+
+			It("returns an error when there are more zones than subnets in the network", func() {
 				azs := []string{}
 				for i := 0; i < 255; i++ {
 					azs = append(azs, fmt.Sprintf("az%d", i))
@@ -103,11 +135,12 @@ var _ = Describe("NetworksGenerator", func() {
 				generator := gcp.NewNetworksGenerator(
 					"some-network-name",
 					"some-subnetwork-name",
+					"",
 					[]string{"some-tag", "some-other-tag"},
 					azs,
 				)
 				_, err := generator.Generate()
-				Expect(err).To(MatchError(ContainSubstring("invalid ip")))
+				Expect(err).To(MatchError(ContainSubstring("has room for 15 internal subnets")))
 			})
 		})
 	})
@@ -91,7 +91,7 @@ func (c AWSCreateLBs) Execute(config AWSCreateLBsConfig, state storage.State) er
 
 	boshClient := c.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword)
 
-	if err := c.checkFastFails(config.LBType, state.Stack.LBType, state.Stack.Name, boshClient); err != nil {
+	if err := c.checkFastFails(config.LBType, state, boshClient); err != nil {
 		return err
 	}
 
@@ -110,10 +110,15 @@ func (c AWSCreateLBs) Execute(config AWSCreateLBsConfig, state storage.State) er
 	state.Stack.CertificateName = certificateName
 	state.Stack.LBType = config.LBType
 
-	if err := c.updateStackAndBOSH(state.AWS.Region, certificateName, state.KeyPair.Name, state.Stack.Name, config.LBType, boshClient, state.EnvID); err != nil {
+	stack, err := c.updateStackAndBOSH(state, certificateName, config.LBType, boshClient)
+	if err != nil {
 		return err
 	}
 
+	if state.NoDirector {
+		state.Outputs = stack.Outputs
+	}
+
 	err = c.stateStore.Set(state)
 	if err != nil {
 		return err
@@ -126,7 +131,7 @@ func (AWSCreateLBs) isValidLBType(lbType string) bool {
 	return lbType == "concourse" || lbType == "cf"
 }
 
-func (c AWSCreateLBs) checkFastFails(newLBType string, currentLBType string, stackName string, boshClient bosh.Client) error {
+func (c AWSCreateLBs) checkFastFails(newLBType string, state storage.State, boshClient bosh.Client) error {
 	if newLBType == "" {
 		return fmt.Errorf("--type is a required flag")
 	}
@@ -135,36 +140,46 @@ func (c AWSCreateLBs) checkFastFails(newLBType string, currentLBType string, sta
 		return fmt.Errorf("%q is not a valid lb type, valid lb types are: concourse and cf", newLBType)
 	}
 
-	if lbExists(currentLBType) {
-		return fmt.Errorf("bbl already has a %s load balancer attached, please remove the previous load balancer before attaching a new one", currentLBType)
+	if lbExists(state.Stack.LBType) {
+		return fmt.Errorf("bbl already has a %s load balancer attached, please remove the previous load balancer before attaching a new one", state.Stack.LBType)
 	}
 
-	return bblExists(stackName, c.infrastructureManager, boshClient)
+	return bblExists(state, c.infrastructureManager, boshClient)
 }
 
-func (c AWSCreateLBs) updateStackAndBOSH(
-	awsRegion string, certificateName string, keyPairName string, stackName string,
-	lbType string, boshClient bosh.Client, envID string,
-) error {
-
-	availabilityZones, err := c.availabilityZoneRetriever.Retrieve(awsRegion)
+func (c AWSCreateLBs) updateStackAndBOSH(state storage.State, certificateName string, lbType string, boshClient bosh.Client) (cloudformation.Stack, error) {
+	availabilityZones, err := c.availabilityZoneRetriever.Retrieve(state.AWS.Region)
 	if err != nil {
-		return err
+		return cloudformation.Stack{}, err
 	}
 
 	certificate, err := c.certificateManager.Describe(certificateName)
 
-	stack, err := c.infrastructureManager.Update(keyPairName, len(availabilityZones), stackName, lbType, certificate.ARN, envID)
+	patches, err := templatePatches(state)
 	if err != nil {
-		return err
+		return cloudformation.Stack{}, err
+	}
+
+	network, err := templateNetwork(state, len(availabilityZones))
+	if err != nil {
+		return cloudformation.Stack{}, err
+	}
+
+	stack, err := c.infrastructureManager.Update(state.KeyPair.Name, len(availabilityZones), state.Stack.Name, lbType, certificate.ARN, state.EnvID, network, patches)
+	if err != nil {
+		return cloudformation.Stack{}, err
+	}
+
+	if state.NoDirector {
+		return stack, nil
 	}
 
 	cloudConfigInput := c.boshCloudConfigurator.Configure(stack, availabilityZones)
 
 	err = c.cloudConfigManager.Update(cloudConfigInput, boshClient)
 	if err != nil {
-		return err
+		return cloudformation.Stack{}, err
 	}
 
-	return nil
+	return stack, nil
 }
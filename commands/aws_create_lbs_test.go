@@ -86,6 +86,32 @@ var _ = Describe("AWS Create LBs", func() {
 			Expect(err).To(MatchError("failed to validate aws credentials"))
 		})
 
+		Context("when the environment has no director", func() {
+			BeforeEach(func() {
+				incomingState.BOSH = storage.BOSH{}
+				incomingState.NoDirector = true
+				boshClient.InfoCall.Returns.Error = errors.New("director not found")
+				infrastructureManager.UpdateCall.Returns.Stack = cloudformation.Stack{
+					Outputs: map[string]string{"ConcourseLoadBalancer": "some-concourse-lb"},
+				}
+			})
+
+			It("updates the stack without updating the cloud config and records the outputs", func() {
+				err := command.Execute(commands.AWSCreateLBsConfig{
+					LBType:   "concourse",
+					CertPath: "temp/some-cert.crt",
+					KeyPath:  "temp/some-key.key",
+				}, incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(infrastructureManager.UpdateCall.CallCount).To(Equal(1))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(0))
+				Expect(stateStore.SetCall.Receives.State.Outputs).To(Equal(map[string]string{
+					"ConcourseLoadBalancer": "some-concourse-lb",
+				}))
+			})
+		})
+
 		It("uploads a cert and key", func() {
 			err := command.Execute(commands.AWSCreateLBsConfig{
 				LBType:   "concourse",
@@ -23,6 +23,7 @@ type AWSDeleteLBs struct {
 
 type cloudConfigManager interface {
 	Update(cloudConfigInput bosh.CloudConfigInput, boshClient bosh.Client) error
+	Generate(cloudConfigInput bosh.CloudConfigInput) ([]byte, error)
 }
 
 type deleteLBsConfig struct {
@@ -57,31 +58,47 @@ func (c AWSDeleteLBs) Execute(state storage.State) error {
 		return err
 	}
 
+	patches, err := templatePatches(state)
+	if err != nil {
+		return err
+	}
+
 	azs, err := c.availabilityZoneRetriever.Retrieve(state.AWS.Region)
 	if err != nil {
 		return err
 	}
 
-	stack, err := c.infrastructureManager.Describe(state.Stack.Name)
+	network, err := templateNetwork(state, len(azs))
 	if err != nil {
 		return err
 	}
 
-	cloudConfigInput := c.boshCloudConfigurator.Configure(stack, azs)
-	cloudConfigInput.LBs = nil
+	if !state.NoDirector {
+		stack, err := c.infrastructureManager.Describe(state.Stack.Name)
+		if err != nil {
+			return err
+		}
 
-	boshClient := c.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword)
+		cloudConfigInput := c.boshCloudConfigurator.Configure(stack, azs)
+		cloudConfigInput.LBs = nil
 
-	err = c.cloudConfigManager.Update(cloudConfigInput, boshClient)
-	if err != nil {
-		return err
+		boshClient := c.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword)
+
+		err = c.cloudConfigManager.Update(cloudConfigInput, boshClient)
+		if err != nil {
+			return err
+		}
 	}
 
-	_, err = c.infrastructureManager.Update(state.KeyPair.Name, len(azs), state.Stack.Name, "", "", state.EnvID)
+	stack, err := c.infrastructureManager.Update(state.KeyPair.Name, len(azs), state.Stack.Name, "", "", state.EnvID, network, patches)
 	if err != nil {
 		return err
 	}
 
+	if state.NoDirector {
+		state.Outputs = stack.Outputs
+	}
+
 	c.logger.Step("deleting certificate")
 	err = c.certificateManager.Delete(state.Stack.CertificateName)
 	if err != nil {
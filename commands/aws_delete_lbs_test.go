@@ -104,6 +104,27 @@ var _ = Describe("Delete LBs", func() {
 			Expect(cloudConfigManager.UpdateCall.Receives.BOSHClient).To(Equal(boshClient))
 		})
 
+		Context("when the environment has no director", func() {
+			BeforeEach(func() {
+				incomingState.BOSH = storage.BOSH{}
+				incomingState.NoDirector = true
+				boshClient.InfoCall.Returns.Error = errors.New("director not found")
+				infrastructureManager.UpdateCall.Returns.Stack = cloudformation.Stack{
+					Outputs: map[string]string{"VPCID": "some-vpc-id"},
+				}
+			})
+
+			It("deletes the lbs without updating the cloud config and records the outputs", func() {
+				err := command.Execute(incomingState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(0))
+				Expect(infrastructureManager.UpdateCall.Receives.LBType).To(Equal(""))
+				Expect(certificateManager.DeleteCall.Receives.CertificateName).To(Equal("some-certificate"))
+				Expect(stateStore.SetCall.Receives.State.Outputs).To(Equal(map[string]string{"VPCID": "some-vpc-id"}))
+			})
+		})
+
 		It("delete lbs from cloudformation and deletes certificate", func() {
 			availabilityZoneRetriever.RetrieveCall.Returns.AZs = []string{"a", "b", "c"}
 			err := command.Execute(incomingState)
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cloudfoundry/bosh-bootloader/boshinit"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+type AWSPlan struct {
+	credentialValidator       credentialValidator
+	infrastructureManager     infrastructureManager
+	availabilityZoneRetriever availabilityZoneRetriever
+	certificateDescriber      certificateDescriber
+	boshManifestGenerator     boshManifestGenerator
+	stringGenerator           stringGenerator
+	boshCloudConfigurator     boshCloudConfigurator
+	cloudConfigManager        cloudConfigManager
+	boshClientProvider        boshClientProvider
+	stdout                    io.Writer
+}
+
+func NewAWSPlan(credentialValidator credentialValidator, infrastructureManager infrastructureManager,
+	availabilityZoneRetriever availabilityZoneRetriever, certificateDescriber certificateDescriber,
+	boshManifestGenerator boshManifestGenerator, stringGenerator stringGenerator,
+	boshCloudConfigurator boshCloudConfigurator, cloudConfigManager cloudConfigManager,
+	boshClientProvider boshClientProvider, stdout io.Writer) AWSPlan {
+
+	return AWSPlan{
+		credentialValidator:       credentialValidator,
+		infrastructureManager:     infrastructureManager,
+		availabilityZoneRetriever: availabilityZoneRetriever,
+		certificateDescriber:      certificateDescriber,
+		boshManifestGenerator:     boshManifestGenerator,
+		stringGenerator:           stringGenerator,
+		boshCloudConfigurator:     boshCloudConfigurator,
+		cloudConfigManager:        cloudConfigManager,
+		boshClientProvider:        boshClientProvider,
+		stdout:                    stdout,
+	}
+}
+
+func (p AWSPlan) Execute(state storage.State) error {
+	err := p.credentialValidator.ValidateAWS()
+	if err != nil {
+		return err
+	}
+
+	availabilityZones, err := p.availabilityZoneRetriever.Retrieve(state.AWS.Region)
+	if err != nil {
+		return err
+	}
+
+	var certificateARN string
+	if lbExists(state.Stack.LBType) {
+		certificate, err := p.certificateDescriber.Describe(state.Stack.CertificateName)
+		if err != nil {
+			return err
+		}
+		certificateARN = certificate.ARN
+	}
+
+	patches, err := templatePatches(state)
+	if err != nil {
+		return err
+	}
+
+	network, err := templateNetwork(state, len(availabilityZones))
+	if err != nil {
+		return err
+	}
+
+	changes, err := p.infrastructureManager.Plan(state.KeyPair.Name, len(availabilityZones), state.Stack.Name,
+		state.Stack.LBType, certificateARN, state.EnvID, network, patches)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(p.stdout, formatResourceChanges(state.Stack.Name, changes))
+
+	if state.BOSH.IsEmpty() {
+		err = printSectionChanges(p.stdout, "bosh-init manifest", "", "")
+		if err != nil {
+			return err
+		}
+
+		return printSectionChanges(p.stdout, "cloud config", "", "")
+	}
+
+	stack, err := p.infrastructureManager.Describe(state.Stack.Name)
+	if err != nil {
+		return err
+	}
+
+	deployInput, err := boshinit.NewDeployInput(state, awsInfrastructureConfiguration(state, stack), p.stringGenerator, state.EnvID, "aws")
+	if err != nil {
+		return err
+	}
+
+	manifest, err := p.boshManifestGenerator.Manifest(deployInput)
+	if err != nil {
+		return err
+	}
+
+	err = printSectionChanges(p.stdout, "bosh-init manifest", state.BOSH.Manifest, manifest)
+	if err != nil {
+		return err
+	}
+
+	cloudConfig, err := p.cloudConfigManager.Generate(p.boshCloudConfigurator.Configure(stack, availabilityZones))
+	if err != nil {
+		return err
+	}
+
+	boshClient := p.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword)
+	currentCloudConfig, err := boshClient.CloudConfig()
+	if err != nil {
+		return err
+	}
+
+	return printSectionChanges(p.stdout, "cloud config", string(currentCloudConfig), string(cloudConfig))
+}
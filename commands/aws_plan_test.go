@@ -0,0 +1,241 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AWSPlan", func() {
+	var (
+		command                   commands.AWSPlan
+		credentialValidator       *fakes.CredentialValidator
+		infrastructureManager     *fakes.InfrastructureManager
+		availabilityZoneRetriever *fakes.AvailabilityZoneRetriever
+		certificateDescriber      *fakes.CertificateDescriber
+		boshManifestGenerator     *fakes.BOSHManifestGenerator
+		stringGenerator           *fakes.StringGenerator
+		cloudConfigurator         *fakes.BoshCloudConfigurator
+		cloudConfigManager        *fakes.CloudConfigManager
+		boshClientProvider        *fakes.BOSHClientProvider
+		boshClient                *fakes.BOSHClient
+		stdout                    *bytes.Buffer
+		state                     storage.State
+	)
+
+	BeforeEach(func() {
+		credentialValidator = &fakes.CredentialValidator{}
+		infrastructureManager = &fakes.InfrastructureManager{}
+		availabilityZoneRetriever = &fakes.AvailabilityZoneRetriever{}
+		certificateDescriber = &fakes.CertificateDescriber{}
+		boshManifestGenerator = &fakes.BOSHManifestGenerator{}
+		stringGenerator = &fakes.StringGenerator{}
+		cloudConfigurator = &fakes.BoshCloudConfigurator{}
+		cloudConfigManager = &fakes.CloudConfigManager{}
+		boshClient = &fakes.BOSHClient{}
+		boshClientProvider = &fakes.BOSHClientProvider{}
+		boshClientProvider.ClientCall.Returns.Client = boshClient
+		stdout = bytes.NewBuffer([]byte{})
+
+		availabilityZoneRetriever.RetrieveCall.Returns.AZs = []string{"some-zone-1", "some-zone-2"}
+		infrastructureManager.PlanCall.Returns.Changes = []cloudformation.ResourceChange{
+			{Action: "Add", LogicalID: "ConcourseLoadBalancer", ResourceType: "AWS::ElasticLoadBalancing::LoadBalancer"},
+			{Action: "Modify", LogicalID: "VPC", ResourceType: "AWS::EC2::VPC", Replacement: "True"},
+			{Action: "Remove", LogicalID: "NATInstance", ResourceType: "AWS::EC2::Instance"},
+		}
+		infrastructureManager.DescribeCall.Returns.Stack = cloudformation.Stack{
+			Name: "some-stack-name",
+			Outputs: map[string]string{
+				"BOSHEIP":    "some-bosh-eip",
+				"BOSHSubnet": "some-bosh-subnet",
+			},
+		}
+		certificateDescriber.DescribeCall.Returns.Certificate = iam.Certificate{ARN: "some-certificate-arn"}
+		boshManifestGenerator.ManifestCall.Returns.Manifest = "name: bosh\njobs:\n- name: bosh\n  instances: 1\nnetworks: []\n"
+		cloudConfigurator.ConfigureCall.Returns.CloudConfigInput = bosh.CloudConfigInput{AZs: []string{"some-zone-1"}}
+		cloudConfigManager.GenerateCall.Returns.CloudConfig = []byte("azs: []\nvm_types: []\n")
+		boshClient.CloudConfigCall.Returns.CloudConfig = []byte("azs: []\nvm_types: []\n")
+
+		state = storage.State{
+			IAAS:  "aws",
+			EnvID: "some-env-id",
+			AWS: storage.AWS{
+				Region: "some-region",
+			},
+			KeyPair: storage.KeyPair{
+				Name: "some-keypair-name",
+			},
+			Stack: storage.Stack{
+				Name:            "some-stack-name",
+				LBType:          "concourse",
+				CertificateName: "some-certificate-name",
+			},
+			BOSH: storage.BOSH{
+				DirectorAddress:  "some-director-address",
+				DirectorUsername: "some-director-username",
+				DirectorPassword: "some-director-password",
+				Manifest:         "name: bosh\njobs:\n- name: bosh\n  instances: 0\nnetworks: []\n",
+			},
+		}
+
+		command = commands.NewAWSPlan(credentialValidator, infrastructureManager, availabilityZoneRetriever,
+			certificateDescriber, boshManifestGenerator, stringGenerator, cloudConfigurator, cloudConfigManager,
+			boshClientProvider, stdout)
+	})
+
+	It("prints the stack changes and whether the manifest and cloud config would change", func() {
+		err := command.Execute(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(credentialValidator.ValidateAWSCall.CallCount).To(Equal(1))
+		Expect(availabilityZoneRetriever.RetrieveCall.Receives.Region).To(Equal("some-region"))
+		Expect(certificateDescriber.DescribeCall.Receives.CertificateName).To(Equal("some-certificate-name"))
+
+		Expect(infrastructureManager.PlanCall.Receives.KeyPairName).To(Equal("some-keypair-name"))
+		Expect(infrastructureManager.PlanCall.Receives.NumberOfAvailabilityZones).To(Equal(2))
+		Expect(infrastructureManager.PlanCall.Receives.StackName).To(Equal("some-stack-name"))
+		Expect(infrastructureManager.PlanCall.Receives.LBType).To(Equal("concourse"))
+		Expect(infrastructureManager.PlanCall.Receives.LBCertificateARN).To(Equal("some-certificate-arn"))
+		Expect(infrastructureManager.PlanCall.Receives.EnvID).To(Equal("some-env-id"))
+
+		Expect(boshManifestGenerator.ManifestCall.Receives.Input.IAAS).To(Equal("aws"))
+		Expect(boshManifestGenerator.ManifestCall.Receives.Input.DirectorUsername).To(Equal("some-director-username"))
+		Expect(boshManifestGenerator.ManifestCall.Receives.Input.InfrastructureConfiguration.ExternalIP).To(Equal("some-bosh-eip"))
+		Expect(boshManifestGenerator.ManifestCall.Receives.Input.InfrastructureConfiguration.AWS.SubnetID).To(Equal("some-bosh-subnet"))
+
+		Expect(cloudConfigurator.ConfigureCall.Receives.Stack).To(Equal(infrastructureManager.DescribeCall.Returns.Stack))
+		Expect(cloudConfigurator.ConfigureCall.Receives.AZs).To(Equal([]string{"some-zone-1", "some-zone-2"}))
+		Expect(cloudConfigManager.GenerateCall.Receives.CloudConfigInput).To(Equal(bosh.CloudConfigInput{AZs: []string{"some-zone-1"}}))
+
+		Expect(boshClientProvider.ClientCall.Receives.DirectorAddress).To(Equal("some-director-address"))
+		Expect(boshClientProvider.ClientCall.Receives.DirectorUsername).To(Equal("some-director-username"))
+		Expect(boshClientProvider.ClientCall.Receives.DirectorPassword).To(Equal("some-director-password"))
+
+		Expect(stdout.String()).To(Equal(`CloudFormation stack "some-stack-name":
+  + ConcourseLoadBalancer (AWS::ElasticLoadBalancing::LoadBalancer)
+  ~ VPC (AWS::EC2::VPC), replacement: True
+  - NATInstance (AWS::EC2::Instance)
+bosh-init manifest: changes to jobs
+cloud config: no changes
+`))
+	})
+
+	It("does not change anything", func() {
+		err := command.Execute(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(infrastructureManager.CreateCall.CallCount).To(Equal(0))
+		Expect(infrastructureManager.UpdateCall.CallCount).To(Equal(0))
+		Expect(cloudConfigManager.UpdateCall.Receives.BOSHClient).To(BeNil())
+		Expect(boshClient.UpdateCloudConfigCall.CallCount).To(Equal(0))
+	})
+
+	It("does not describe a certificate when there is no load balancer", func() {
+		state.Stack.LBType = "none"
+
+		err := command.Execute(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(certificateDescriber.DescribeCall.CallCount).To(Equal(0))
+		Expect(infrastructureManager.PlanCall.Receives.LBCertificateARN).To(BeEmpty())
+	})
+
+	It("plans the stack with the cloudformation patches from the state", func() {
+		state.Stack.TemplatePatches = []storage.TemplatePatch{
+			{Name: "flow-logs.json", Contents: `{"Resources": {"FlowLogs": {"Type": "AWS::EC2::FlowLog"}}}`},
+		}
+
+		err := command.Execute(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(infrastructureManager.PlanCall.Receives.TemplatePatches).To(HaveLen(1))
+		Expect(infrastructureManager.PlanCall.Receives.TemplatePatches[0].Resources).To(HaveKey("FlowLogs"))
+	})
+
+	It("reports when there are no stack changes", func() {
+		infrastructureManager.PlanCall.Returns.Changes = []cloudformation.ResourceChange{}
+
+		err := command.Execute(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(stdout.String()).To(ContainSubstring(`CloudFormation stack "some-stack-name": no changes`))
+	})
+
+	It("reports that the director will be created when there is no director", func() {
+		state.BOSH = storage.BOSH{}
+
+		err := command.Execute(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(stdout.String()).To(ContainSubstring("bosh-init manifest: will be created\ncloud config: will be created\n"))
+		Expect(boshManifestGenerator.ManifestCall.CallCount).To(Equal(0))
+		Expect(boshClient.CloudConfigCall.CallCount).To(Equal(0))
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when the credentials are invalid", func() {
+			credentialValidator.ValidateAWSCall.Returns.Error = errors.New("failed to validate credentials")
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to validate credentials"))
+		})
+
+		It("returns an error when the availability zones cannot be retrieved", func() {
+			availabilityZoneRetriever.RetrieveCall.Returns.Error = errors.New("failed to retrieve azs")
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to retrieve azs"))
+		})
+
+		It("returns an error when the certificate cannot be described", func() {
+			certificateDescriber.DescribeCall.Returns.Error = errors.New("failed to describe certificate")
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to describe certificate"))
+		})
+
+		It("returns an error when the infrastructure cannot be planned", func() {
+			infrastructureManager.PlanCall.Returns.Error = errors.New("failed to plan")
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to plan"))
+		})
+
+		It("returns an error when the stack cannot be described", func() {
+			infrastructureManager.DescribeCall.Returns.Error = errors.New("failed to describe stack")
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to describe stack"))
+		})
+
+		It("returns an error when the manifest cannot be generated", func() {
+			boshManifestGenerator.ManifestCall.Returns.Error = errors.New("failed to generate manifest")
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to generate manifest"))
+		})
+
+		It("returns an error when the cloud config cannot be generated", func() {
+			cloudConfigManager.GenerateCall.Returns.Error = errors.New("failed to generate cloud config")
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to generate cloud config"))
+		})
+
+		It("returns an error when the current cloud config cannot be retrieved", func() {
+			boshClient.CloudConfigCall.Returns.Error = errors.New("failed to get cloud config")
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to get cloud config"))
+		})
+	})
+})
@@ -3,18 +3,23 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws"
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
 	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
 	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
 	"github.com/cloudfoundry/bosh-bootloader/boshinit"
+	"github.com/cloudfoundry/bosh-bootloader/helpers"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
 const (
 	UpCommand = "up"
+
+	latestNATAMI = "latest"
 )
 
 type keyPairSynchronizer interface {
@@ -22,11 +27,12 @@ type keyPairSynchronizer interface {
 }
 
 type infrastructureManager interface {
-	Create(keyPairName string, numberOfAZs int, stackName, lbType, lbCertificateARN, envID string) (cloudformation.Stack, error)
-	Update(keyPairName string, numberOfAZs int, stackName, lbType, lbCertificateARN, envID string) (cloudformation.Stack, error)
+	Create(keyPairName string, numberOfAZs int, stackName, lbType, lbCertificateARN, envID string, network templates.Network, patches []templates.Patch) (cloudformation.Stack, error)
+	Update(keyPairName string, numberOfAZs int, stackName, lbType, lbCertificateARN, envID string, network templates.Network, patches []templates.Patch) (cloudformation.Stack, error)
 	Exists(stackName string) (bool, error)
 	Delete(stackName string) error
 	Describe(stackName string) (cloudformation.Stack, error)
+	Plan(keyPairName string, numberOfAZs int, stackName, lbType, lbCertificateARN, envID string, network templates.Network, patches []templates.Patch) ([]cloudformation.ResourceChange, error)
 }
 
 type boshDeployer interface {
@@ -37,6 +43,10 @@ type availabilityZoneRetriever interface {
 	Retrieve(region string) ([]string, error)
 }
 
+type natAMIResolver interface {
+	Resolve(region string) (string, error)
+}
+
 type credentialValidator interface {
 	ValidateAWS() error
 	ValidateGCP() error
@@ -68,27 +78,46 @@ type AWSUp struct {
 	stringGenerator           stringGenerator
 	boshCloudConfigurator     boshCloudConfigurator
 	availabilityZoneRetriever availabilityZoneRetriever
+	natAMIResolver            natAMIResolver
 	certificateDescriber      certificateDescriber
 	cloudConfigManager        cloudConfigManager
 	boshClientProvider        boshClientProvider
 	envIDGenerator            envIDGenerator
 	stateStore                stateStore
 	configProvider            configProvider
+	logger                    logger
+	stdin                     io.Reader
 }
 
 type AWSUpConfig struct {
-	AccessKeyID     string
-	SecretAccessKey string
-	Region          string
+	AccessKeyID      string
+	SecretAccessKey  string
+	SessionToken     string
+	Profile          string
+	AssumeRoleARN    string
+	ExternalID       string
+	Region           string
+	NoConfirm        bool
+	FromPhase        string
+	NoDirector       bool
+	OpsFiles         []storage.OpsFile
+	ClearOpsFiles    bool
+	TemplatePatches  []storage.TemplatePatch
+	VersionOverrides []storage.VersionOverride
+	AirGapped        bool
+	DirectorSizing   storage.DirectorSizing
+	Network          storage.Network
+	NATType          string
+	NATAMI           string
 }
 
 func NewAWSUp(
 	credentialValidator credentialValidator, infrastructureManager infrastructureManager,
 	keyPairSynchronizer keyPairSynchronizer, boshDeployer boshDeployer, stringGenerator stringGenerator,
 	boshCloudConfigurator boshCloudConfigurator, availabilityZoneRetriever availabilityZoneRetriever,
-	certificateDescriber certificateDescriber, cloudConfigManager cloudConfigManager,
+	natAMIResolver natAMIResolver, certificateDescriber certificateDescriber, cloudConfigManager cloudConfigManager,
 	boshClientProvider boshClientProvider, stateStore stateStore,
-	configProvider configProvider) AWSUp {
+	configProvider configProvider, logger logger, stdin io.Reader) AWSUp {
 
 	return AWSUp{
 		credentialValidator:       credentialValidator,
@@ -98,39 +127,54 @@ func NewAWSUp(
 		stringGenerator:           stringGenerator,
 		boshCloudConfigurator:     boshCloudConfigurator,
 		availabilityZoneRetriever: availabilityZoneRetriever,
+		natAMIResolver:            natAMIResolver,
 		certificateDescriber:      certificateDescriber,
 		cloudConfigManager:        cloudConfigManager,
 		boshClientProvider:        boshClientProvider,
 		stateStore:                stateStore,
 		configProvider:            configProvider,
+		logger:                    logger,
+		stdin:                     stdin,
 	}
 }
 
 func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
+	previousState := state
+
 	state.IAAS = "aws"
 
-	if u.awsCredentialsPresent(config) {
-		state.AWS.AccessKeyID = config.AccessKeyID
-		state.AWS.SecretAccessKey = config.SecretAccessKey
-		state.AWS.Region = config.Region
-		if err := u.stateStore.Set(state); err != nil {
-			return err
-		}
-		u.configProvider.SetConfig(aws.Config{
-			AccessKeyID:     config.AccessKeyID,
-			SecretAccessKey: config.SecretAccessKey,
-			Region:          config.Region,
-		})
-	} else if u.awsCredentialsNotPresent(config) {
+	if u.awsCredentialsNotPresent(config) {
 		err := u.credentialValidator.ValidateAWS()
 		if err != nil {
 			return err
 		}
 	} else {
-		return u.awsMissingCredentials(config)
+		if err := u.awsMissingCredentials(config, state.AWS); err != nil {
+			return err
+		}
+
+		state.AWS = u.awsCredentials(config, state.AWS)
+		if err := u.stateStore.Set(state); err != nil {
+			return err
+		}
+
+		awsConfig := aws.Config{
+			AccessKeyID:     state.AWS.AccessKeyID,
+			SecretAccessKey: state.AWS.SecretAccessKey,
+			Profile:         state.AWS.Profile,
+			AssumeRoleARN:   state.AWS.AssumeRoleARN,
+			ExternalID:      state.AWS.ExternalID,
+			Region:          state.AWS.Region,
+		}
+		if config.SessionToken != "" {
+			awsConfig.AccessKeyID = config.AccessKeyID
+			awsConfig.SecretAccessKey = config.SecretAccessKey
+			awsConfig.SessionToken = config.SessionToken
+		}
+		u.configProvider.SetConfig(awsConfig)
 	}
 
-	err := u.checkForFastFails(state)
+	stackExists, err := u.checkForFastFails(state)
 	if err != nil {
 		return err
 	}
@@ -139,34 +183,103 @@ func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
 		state.KeyPair.Name = fmt.Sprintf("keypair-%s", state.EnvID)
 	}
 
-	if err := u.stateStore.Set(state); err != nil {
+	if state.NoDirector && !config.NoDirector {
+		u.logger.Step("deploying a director to the infrastructure-only environment")
+		state.Outputs = nil
+	}
+	state.NoDirector = config.NoDirector
+
+	if config.ClearOpsFiles {
+		state.OpsFiles = nil
+	}
+
+	if len(config.OpsFiles) > 0 {
+		state.OpsFiles = config.OpsFiles
+	}
+
+	state.VersionOverrides = mergeVersionOverrides(state.VersionOverrides, config.VersionOverrides)
+
+	if config.AirGapped {
+		state.AirGapped = true
+	}
+
+	directorSizing, err := mergeDirectorSizing(state, config.DirectorSizing)
+	if err != nil {
 		return err
 	}
+	state.DirectorSizing = directorSizing
 
-	keyPair, err := u.keyPairSynchronizer.Sync(ec2.KeyPair{
-		Name:       state.KeyPair.Name,
-		PublicKey:  state.KeyPair.PublicKey,
-		PrivateKey: state.KeyPair.PrivateKey,
-	})
+	state.Network, err = mergeNetwork(state, config.Network, stackExists)
 	if err != nil {
 		return err
 	}
 
-	state.KeyPair.PublicKey = keyPair.PublicKey
-	state.KeyPair.PrivateKey = keyPair.PrivateKey
+	if len(config.TemplatePatches) > 0 {
+		state.Stack.TemplatePatches = config.TemplatePatches
+	}
+
+	natType := state.Stack.NATType
+	if config.NATType != "" && config.NATType != stackNATType(state) {
+		if stackExists {
+			u.logger.Step("switching the NAT type from %s to %s, outbound traffic from the internal subnets will be interrupted while the stack is updated", stackNATType(state), config.NATType)
+		}
+		natType = config.NATType
+	}
 
 	if err := u.stateStore.Set(state); err != nil {
 		return err
 	}
 
+	phases := newUpPhases(u.logger, config.FromPhase)
+
+	state, skip, err := phases.start(state, KeyPairPhase, state.KeyPair.PrivateKey != "", state.KeyPair.Name, state.AWS.Region)
+	if err != nil {
+		return err
+	}
+
+	if !skip {
+		keyPair, err := u.keyPairSynchronizer.Sync(ec2.KeyPair{
+			Name:       state.KeyPair.Name,
+			PublicKey:  state.KeyPair.PublicKey,
+			PrivateKey: state.KeyPair.PrivateKey,
+		})
+		if err != nil {
+			return err
+		}
+
+		state.KeyPair.PublicKey = keyPair.PublicKey
+		state.KeyPair.PrivateKey = keyPair.PrivateKey
+		state = phases.complete(state, KeyPairPhase)
+
+		if err := u.stateStore.Set(state); err != nil {
+			return err
+		}
+	}
+
 	availabilityZones, err := u.availabilityZoneRetriever.Retrieve(state.AWS.Region)
 	if err != nil {
 		return err
 	}
 
+	stackChanged := false
 	if state.Stack.Name == "" {
 		state.Stack.Name = fmt.Sprintf("stack-%s", strings.Replace(state.EnvID, ":", "-", -1))
+		stackChanged = true
+	}
+
+	natAMI := state.Stack.NATAMI
+	if natType == "" || natType == templates.NATTypeInstance {
+		natAMI, err = u.natAMI(state, config.NATAMI, stackExists)
+		if err != nil {
+			return err
+		}
+
+		if stackExists && state.Stack.NATAMI != "" && natAMI != state.Stack.NATAMI {
+			u.logger.Step("updating the NAT AMI from %s to %s, the NAT instance will be replaced", state.Stack.NATAMI, natAMI)
+		}
+	}
 
+	if stackChanged {
 		if err := u.stateStore.Set(state); err != nil {
 			return err
 		}
@@ -181,37 +294,136 @@ func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
 		certificateARN = certificate.ARN
 	}
 
-	stack, err := u.infrastructureManager.Create(state.KeyPair.Name, len(availabilityZones), state.Stack.Name, state.Stack.LBType, certificateARN, state.EnvID)
+	patches, err := templatePatches(state)
 	if err != nil {
 		return err
 	}
 
-	infrastructureConfiguration := boshinit.InfrastructureConfiguration{
-		ExternalIP: stack.Outputs["BOSHEIP"],
-		AWS: boshinit.InfrastructureConfigurationAWS{
-			AWSRegion:        state.AWS.Region,
-			SubnetID:         stack.Outputs["BOSHSubnet"],
-			AvailabilityZone: stack.Outputs["BOSHSubnetAZ"],
-			AccessKeyID:      stack.Outputs["BOSHUserAccessKey"],
-			SecretAccessKey:  stack.Outputs["BOSHUserSecretAccessKey"],
-			SecurityGroup:    stack.Outputs["BOSHSecurityGroup"],
-		},
+	desiredState := state
+	desiredState.Stack.NATType = natType
+	desiredState.Stack.NATAMI = natAMI
+	network, err := templateNetwork(desiredState, len(availabilityZones))
+	if err != nil {
+		return err
+	}
+
+	state, skip, err = phases.start(state, InfrastructurePhase, stackExists,
+		state.KeyPair.Name, len(availabilityZones), state.Stack.Name, state.Stack.LBType, certificateARN, state.EnvID, state.AWS.Region,
+		state.Stack.TemplatePatches, state.Network, natType, natAMI)
+	if err != nil {
+		return err
+	}
+
+	var stack cloudformation.Stack
+	if skip {
+		stack, err = u.infrastructureManager.Describe(state.Stack.Name)
+		if err != nil {
+			return err
+		}
+	} else {
+		if stackExists && !config.NoConfirm {
+			changes, err := u.infrastructureManager.Plan(state.KeyPair.Name, len(availabilityZones), state.Stack.Name, state.Stack.LBType, certificateARN, state.EnvID, network, patches)
+			if err != nil {
+				return err
+			}
+
+			if hasDestructiveChanges(changes) {
+				u.logger.Println(formatResourceChanges(state.Stack.Name, changes))
+				if !confirm(u.logger, u.stdin, fmt.Sprintf("Updating %q will replace or remove the resources above. Do you want to continue?", state.Stack.Name)) {
+					if err := u.stateStore.Set(restoreUpConfig(state, previousState)); err != nil {
+						return err
+					}
+					return UpCancelled
+				}
+			}
+		}
+
+		stack, err = u.infrastructureManager.Create(state.KeyPair.Name, len(availabilityZones), state.Stack.Name, state.Stack.LBType, certificateARN, state.EnvID, network, patches)
+		if err != nil {
+			return err
+		}
+
+		state.Stack.NATType = natType
+		state.Stack.NATAMI = natAMI
+		state = phases.complete(state, InfrastructurePhase)
+		if err := u.stateStore.Set(state); err != nil {
+			return err
+		}
+	}
+
+	if state.NoDirector {
+		state.Outputs = stack.Outputs
+		state.Checkpoints = nil
+		return u.stateStore.Set(state)
+	}
+
+	infrastructureConfiguration := awsInfrastructureConfiguration(state, stack)
+
+	state, skip, err = phases.start(state, DirectorPhase, !state.BOSH.IsEmpty(), infrastructureConfiguration, state.EnvID, state.OpsFiles, state.VersionOverrides, state.DirectorSizing, state.Network)
+	if err != nil {
+		return err
 	}
 
-	deployInput, err := boshinit.NewDeployInput(state, infrastructureConfiguration, u.stringGenerator, state.EnvID, "aws")
+	if !skip {
+		deployInput, err := boshinit.NewDeployInput(state, infrastructureConfiguration, u.stringGenerator, state.EnvID, "aws")
+		if err != nil {
+			return err
+		}
+
+		deployOutput, err := u.boshDeployer.Deploy(deployInput)
+		switch err.(type) {
+		case boshinit.BOSHInitError:
+			state = updateBOSHState(state, stack.Outputs["BOSHURL"], deployInput, deployOutput)
+			if setErr := u.stateStore.Set(state); setErr != nil {
+				errorList := helpers.Errors{}
+				errorList.Add(err)
+				errorList.Add(setErr)
+				return errorList
+			}
+			return err
+		case error:
+			return err
+		}
+
+		state = updateBOSHState(state, stack.Outputs["BOSHURL"], deployInput, deployOutput)
+		state = phases.complete(state, DirectorPhase)
+
+		err = u.stateStore.Set(state)
+		if err != nil {
+			return err
+		}
+	}
+
+	boshClient := u.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername,
+		state.BOSH.DirectorPassword)
+
+	cloudConfigInput := u.boshCloudConfigurator.Configure(stack, availabilityZones)
+
+	state, _, err = phases.start(state, CloudConfigPhase, false, cloudConfigInput)
 	if err != nil {
 		return err
 	}
 
-	deployOutput, err := u.boshDeployer.Deploy(deployInput)
+	err = u.cloudConfigManager.Update(cloudConfigInput, boshClient)
 	if err != nil {
 		return err
 	}
 
+	state.Checkpoints = nil
+
+	err = u.stateStore.Set(state)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateBOSHState(state storage.State, directorAddress string, deployInput boshinit.DeployInput, deployOutput boshinit.DeployOutput) storage.State {
 	if state.BOSH.IsEmpty() {
 		state.BOSH = storage.BOSH{
 			DirectorName:           deployInput.DirectorName,
-			DirectorAddress:        stack.Outputs["BOSHURL"],
+			DirectorAddress:        directorAddress,
 			DirectorUsername:       deployInput.DirectorUsername,
 			DirectorPassword:       deployInput.DirectorPassword,
 			DirectorSSLCA:          string(deployOutput.DirectorSSLKeyPair.CA),
@@ -224,63 +436,173 @@ func (u AWSUp) Execute(config AWSUpConfig, state storage.State) error {
 	state.BOSH.State = deployOutput.BOSHInitState
 	state.BOSH.Manifest = deployOutput.BOSHInitManifest
 
-	err = u.stateStore.Set(state)
-	if err != nil {
-		return err
+	return state
+}
+
+func templatePatches(state storage.State) ([]templates.Patch, error) {
+	var patches []templates.Patch
+	for _, patch := range state.Stack.TemplatePatches {
+		parsed, err := templates.ParsePatch(patch.Name, []byte(patch.Contents))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cloudformation patch %q: %s", patch.Name, err)
+		}
+		patches = append(patches, parsed)
 	}
 
-	boshClient := u.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername,
-		state.BOSH.DirectorPassword)
+	return patches, nil
+}
 
-	cloudConfigInput := u.boshCloudConfigurator.Configure(stack, availabilityZones)
+// natAMI keeps the NAT AMI recorded in the state, so that the NAT instance is
+// only replaced when --nat-ami is given. Stacks created before the AMI was
+// recorded keep the AMI the template used to look up for their region.
+func (u AWSUp) natAMI(state storage.State, natAMI string, stackExists bool) (string, error) {
+	switch {
+	case natAMI == latestNATAMI:
+		return u.natAMIResolver.Resolve(state.AWS.Region)
+	case natAMI != "":
+		return natAMI, nil
+	case state.Stack.NATAMI != "":
+		return state.Stack.NATAMI, nil
+	case stackExists && templates.LegacyNATAMIs[state.AWS.Region] != "":
+		return templates.LegacyNATAMIs[state.AWS.Region], nil
+	default:
+		return u.natAMIResolver.Resolve(state.AWS.Region)
+	}
+}
 
-	err = u.cloudConfigManager.Update(cloudConfigInput, boshClient)
+func templateNetwork(state storage.State, numberOfAZs int) (templates.Network, error) {
+	natType := state.Stack.NATType
+
+	natAMI := state.Stack.NATAMI
+	if natAMI == "" {
+		natAMI = templates.LegacyNATAMIs[state.AWS.Region]
+	}
+
+	layout, err := networkLayout(state.Network)
 	if err != nil {
-		return err
+		return templates.Network{}, err
 	}
 
-	err = u.stateStore.Set(state)
+	internalSubnetCIDRs, err := layout.InternalSubnetCIDRs(numberOfAZs)
 	if err != nil {
-		return err
+		return templates.Network{}, err
 	}
 
-	return nil
+	loadBalancerSubnetCIDRs, err := layout.LoadBalancerSubnetCIDRs(numberOfAZs)
+	if err != nil {
+		return templates.Network{}, err
+	}
+
+	var natSubnetCIDRs []string
+	if natType == templates.NATTypeGateway {
+		natSubnetCIDRs, err = layout.NATSubnetCIDRs(numberOfAZs)
+		if err != nil {
+			return templates.Network{}, err
+		}
+	}
+
+	return templates.Network{
+		CIDR:                    layout.CIDR(),
+		BOSHSubnetCIDR:          layout.BOSHSubnetCIDR(),
+		NATType:                 natType,
+		NATIP:                   layout.NATIP(),
+		NATAMI:                  natAMI,
+		NATSubnetCIDRs:          natSubnetCIDRs,
+		InternalSubnetCIDRs:     internalSubnetCIDRs,
+		LoadBalancerSubnetCIDRs: loadBalancerSubnetCIDRs,
+	}, nil
 }
 
-func (u AWSUp) checkForFastFails(state storage.State) error {
+func stackNATType(state storage.State) string {
+	if state.Stack.NATType == "" {
+		return templates.NATTypeInstance
+	}
+
+	return state.Stack.NATType
+}
+
+func awsInfrastructureConfiguration(state storage.State, stack cloudformation.Stack) boshinit.InfrastructureConfiguration {
+	return boshinit.InfrastructureConfiguration{
+		ExternalIP: stack.Outputs["BOSHEIP"],
+		AWS: boshinit.InfrastructureConfigurationAWS{
+			AWSRegion:        state.AWS.Region,
+			SubnetID:         stack.Outputs["BOSHSubnet"],
+			AvailabilityZone: stack.Outputs["BOSHSubnetAZ"],
+			AccessKeyID:      stack.Outputs["BOSHUserAccessKey"],
+			SecretAccessKey:  stack.Outputs["BOSHUserSecretAccessKey"],
+			SecurityGroup:    stack.Outputs["BOSHSecurityGroup"],
+		},
+	}
+}
+
+func (u AWSUp) checkForFastFails(state storage.State) (bool, error) {
 	stackExists, err := u.infrastructureManager.Exists(state.Stack.Name)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	if !state.BOSH.IsEmpty() && !stackExists {
-		return fmt.Errorf(
+		return false, fmt.Errorf(
 			"Found BOSH data in state directory, but Cloud Formation stack %q cannot be found "+
 				"for region %q and given AWS credentials. bbl cannot safely proceed. Open an issue on GitHub at "+
 				"https://github.com/cloudfoundry/bosh-bootloader/issues/new if you need assistance.",
 			state.Stack.Name, state.AWS.Region)
 	}
 
-	return nil
-}
-
-func (AWSUp) awsCredentialsPresent(config AWSUpConfig) bool {
-	return config.AccessKeyID != "" && config.SecretAccessKey != "" && config.Region != ""
+	return stackExists, nil
 }
 
 func (AWSUp) awsCredentialsNotPresent(config AWSUpConfig) bool {
-	return config.AccessKeyID == "" && config.SecretAccessKey == "" && config.Region == ""
+	return config.AccessKeyID == "" && config.SecretAccessKey == "" && config.SessionToken == "" &&
+		config.Profile == "" && config.AssumeRoleARN == "" && config.ExternalID == "" && config.Region == ""
 }
 
-func (AWSUp) awsMissingCredentials(config AWSUpConfig) error {
+func (AWSUp) awsMissingCredentials(config AWSUpConfig, current storage.AWS) error {
 	switch {
-	case config.AccessKeyID == "":
+	case config.AccessKeyID == "" && (config.SecretAccessKey != "" || config.SessionToken != ""):
 		return errors.New("AWS access key ID must be provided")
-	case config.SecretAccessKey == "":
+	case config.AccessKeyID != "" && config.SecretAccessKey == "":
 		return errors.New("AWS secret access key must be provided")
-	case config.Region == "":
+	case config.AccessKeyID != "" && config.Profile != "":
+		return errors.New("--aws-profile cannot be used with --aws-access-key-id")
+	case config.ExternalID != "" && config.AssumeRoleARN == "" && current.AssumeRoleARN == "":
+		return errors.New("--aws-external-id requires --aws-assume-role-arn")
+	case config.Region == "" && current.Region == "":
 		return errors.New("AWS region must be provided")
 	}
 
 	return nil
 }
+
+// awsCredentials merges the provided credentials into the ones in the state.
+// Access keys that come with a session token are temporary, so they are
+// only used for this run and never saved.
+func (AWSUp) awsCredentials(config AWSUpConfig, current storage.AWS) storage.AWS {
+	switch {
+	case config.AccessKeyID != "" && config.SessionToken != "":
+		current.AccessKeyID = ""
+		current.SecretAccessKey = ""
+		current.Profile = ""
+	case config.AccessKeyID != "":
+		current.AccessKeyID = config.AccessKeyID
+		current.SecretAccessKey = config.SecretAccessKey
+		current.Profile = ""
+	case config.Profile != "":
+		current.AccessKeyID = ""
+		current.SecretAccessKey = ""
+		current.Profile = config.Profile
+	}
+
+	if config.AssumeRoleARN != "" {
+		current.AssumeRoleARN = config.AssumeRoleARN
+		current.ExternalID = config.ExternalID
+	} else if config.ExternalID != "" {
+		current.ExternalID = config.ExternalID
+	}
+
+	if config.Region != "" {
+		current.Region = config.Region
+	}
+
+	return current
+}
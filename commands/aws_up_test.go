@@ -1,11 +1,13 @@
 package commands_test
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws"
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
 	"github.com/cloudfoundry/bosh-bootloader/aws/ec2"
 	"github.com/cloudfoundry/bosh-bootloader/aws/iam"
 	"github.com/cloudfoundry/bosh-bootloader/bosh"
@@ -16,6 +18,7 @@ import (
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -29,6 +32,7 @@ var _ = Describe("AWSUp", func() {
 			stringGenerator           *fakes.StringGenerator
 			cloudConfigurator         *fakes.BoshCloudConfigurator
 			availabilityZoneRetriever *fakes.AvailabilityZoneRetriever
+			natAMIResolver            *fakes.NATAMIResolver
 			certificateDescriber      *fakes.CertificateDescriber
 			credentialValidator       *fakes.CredentialValidator
 			cloudConfigManager        *fakes.CloudConfigManager
@@ -37,6 +41,8 @@ var _ = Describe("AWSUp", func() {
 			boshInitCredentials       map[string]string
 			stateStore                *fakes.StateStore
 			clientProvider            *fakes.ClientProvider
+			logger                    *fakes.Logger
+			stdin                     *bytes.Buffer
 		)
 
 		BeforeEach(func() {
@@ -84,6 +90,9 @@ var _ = Describe("AWSUp", func() {
 
 			availabilityZoneRetriever = &fakes.AvailabilityZoneRetriever{}
 
+			natAMIResolver = &fakes.NATAMIResolver{}
+			natAMIResolver.ResolveCall.Returns.AMI = "some-nat-ami"
+
 			certificateDescriber = &fakes.CertificateDescriber{}
 
 			credentialValidator = &fakes.CredentialValidator{}
@@ -95,12 +104,14 @@ var _ = Describe("AWSUp", func() {
 
 			stateStore = &fakes.StateStore{}
 			clientProvider = &fakes.ClientProvider{}
+			logger = &fakes.Logger{}
+			stdin = bytes.NewBuffer([]byte{})
 
 			command = commands.NewAWSUp(
 				credentialValidator, infrastructureManager, keyPairSynchronizer, boshDeployer,
-				stringGenerator, cloudConfigurator, availabilityZoneRetriever, certificateDescriber,
+				stringGenerator, cloudConfigurator, availabilityZoneRetriever, natAMIResolver, certificateDescriber,
 				cloudConfigManager, boshClientProvider, stateStore,
-				clientProvider,
+				clientProvider, logger, stdin,
 			)
 
 			boshInitCredentials = map[string]string{
@@ -266,6 +277,625 @@ var _ = Describe("AWSUp", func() {
 			})
 		})
 
+		Describe("confirmation", func() {
+			var state storage.State
+
+			BeforeEach(func() {
+				state = storage.State{
+					Stack: storage.Stack{
+						Name: "some-stack-name",
+					},
+					EnvID: "bbl-lake-time-stamp",
+				}
+				infrastructureManager.ExistsCall.Returns.Exists = true
+			})
+
+			Context("when the update replaces or removes resources", func() {
+				BeforeEach(func() {
+					infrastructureManager.PlanCall.Returns.Changes = []cloudformation.ResourceChange{
+						{Action: "Modify", LogicalID: "BOSHEIP", ResourceType: "AWS::EC2::EIP", Replacement: "True"},
+						{Action: "Remove", LogicalID: "NATInstance", ResourceType: "AWS::EC2::Instance"},
+					}
+				})
+
+				It("prints the changes and asks for confirmation", func() {
+					stdin.Write([]byte("yes\n"))
+
+					err := command.Execute(commands.AWSUpConfig{}, state)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(infrastructureManager.PlanCall.CallCount).To(Equal(1))
+					Expect(infrastructureManager.PlanCall.Receives.StackName).To(Equal("some-stack-name"))
+					Expect(logger.PrintlnCall.Receives.Message).To(Equal(`CloudFormation stack "some-stack-name":
+  ~ BOSHEIP (AWS::EC2::EIP), replacement: True
+  - NATInstance (AWS::EC2::Instance)`))
+					Expect(logger.PromptCall.Receives.Message).To(Equal(`Updating "some-stack-name" will replace or remove the resources above. Do you want to continue?`))
+					Expect(infrastructureManager.CreateCall.CallCount).To(Equal(1))
+				})
+
+				It("does not update the stack when the user declines", func() {
+					stdin.Write([]byte("no\n"))
+
+					err := command.Execute(commands.AWSUpConfig{}, state)
+					Expect(err).To(MatchError("bbl up was cancelled"))
+
+					Expect(logger.StepCall.Receives.Message).To(Equal("exiting"))
+					Expect(infrastructureManager.CreateCall.CallCount).To(Equal(0))
+					Expect(boshDeployer.DeployCall.CallCount).To(Equal(0))
+				})
+
+				It("keeps the previous director settings in the state when the user declines", func() {
+					stdin.Write([]byte("no\n"))
+					state.OpsFiles = []storage.OpsFile{{Name: "some-ops-file", Contents: "- type: remove\n  path: /some-path"}}
+
+					err := command.Execute(commands.AWSUpConfig{
+						OpsFiles:         []storage.OpsFile{{Name: "some-new-ops-file", Contents: "- type: remove\n  path: /some-new-path"}},
+						VersionOverrides: []storage.VersionOverride{{Name: "bosh", URL: "some-bosh-url", SHA1: "some-bosh-sha1"}},
+						AirGapped:        true,
+						DirectorSizing:   storage.DirectorSizing{InstanceType: "m4.xlarge"},
+					}, state)
+					Expect(err).To(MatchError("bbl up was cancelled"))
+
+					Expect(stateStore.SetCall.Receives.State.OpsFiles).To(Equal(state.OpsFiles))
+					Expect(stateStore.SetCall.Receives.State.VersionOverrides).To(BeNil())
+					Expect(stateStore.SetCall.Receives.State.AirGapped).To(BeFalse())
+					Expect(stateStore.SetCall.Receives.State.DirectorSizing).To(BeNil())
+				})
+
+				It("does not update the stack when there is no answer", func() {
+					err := command.Execute(commands.AWSUpConfig{}, state)
+					Expect(err).To(MatchError("bbl up was cancelled"))
+
+					Expect(infrastructureManager.CreateCall.CallCount).To(Equal(0))
+					Expect(boshDeployer.DeployCall.CallCount).To(Equal(0))
+				})
+
+				It("does not ask for confirmation when no-confirm is provided", func() {
+					err := command.Execute(commands.AWSUpConfig{NoConfirm: true}, state)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(infrastructureManager.PlanCall.CallCount).To(Equal(0))
+					Expect(logger.PromptCall.CallCount).To(Equal(0))
+					Expect(infrastructureManager.CreateCall.CallCount).To(Equal(1))
+				})
+			})
+
+			It("does not ask for confirmation when no resources are replaced or removed", func() {
+				infrastructureManager.PlanCall.Returns.Changes = []cloudformation.ResourceChange{
+					{Action: "Add", LogicalID: "NewSubnet", ResourceType: "AWS::EC2::Subnet"},
+					{Action: "Modify", LogicalID: "BOSHSecurityGroup", ResourceType: "AWS::EC2::SecurityGroup", Replacement: "False"},
+				}
+
+				err := command.Execute(commands.AWSUpConfig{}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.PromptCall.CallCount).To(Equal(0))
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(1))
+			})
+
+			It("does not plan when the stack does not exist yet", func() {
+				infrastructureManager.ExistsCall.Returns.Exists = false
+
+				err := command.Execute(commands.AWSUpConfig{}, state)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(infrastructureManager.PlanCall.CallCount).To(Equal(0))
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(1))
+			})
+
+			It("returns an error when the plan fails", func() {
+				infrastructureManager.PlanCall.Returns.Error = errors.New("failed to plan")
+
+				err := command.Execute(commands.AWSUpConfig{}, state)
+				Expect(err).To(MatchError("failed to plan"))
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Describe("ops files", func() {
+			var opsFiles []storage.OpsFile
+
+			BeforeEach(func() {
+				opsFiles = []storage.OpsFile{{Name: "syslog.yml", Contents: "- type: remove\n  path: /syslog?\n"}}
+			})
+
+			It("remembers the ops files in the state and passes them to the deployer", func() {
+				err := command.Execute(commands.AWSUpConfig{OpsFiles: opsFiles}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshDeployer.DeployCall.Receives.Input.OpsFiles).To(Equal(opsFiles))
+				Expect(stateStore.SetCall.Receives.State.OpsFiles).To(Equal(opsFiles))
+			})
+
+			It("reapplies the ops files from the state when none are given", func() {
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					EnvID:    "bbl-lake-time-stamp",
+					OpsFiles: opsFiles,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshDeployer.DeployCall.Receives.Input.OpsFiles).To(Equal(opsFiles))
+			})
+
+			It("forgets the ops files in the state when they are cleared", func() {
+				err := command.Execute(commands.AWSUpConfig{ClearOpsFiles: true}, storage.State{
+					EnvID:    "bbl-lake-time-stamp",
+					OpsFiles: opsFiles,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshDeployer.DeployCall.Receives.Input.OpsFiles).To(BeEmpty())
+				Expect(stateStore.SetCall.Receives.State.OpsFiles).To(BeEmpty())
+			})
+		})
+
+		Describe("version overrides", func() {
+			It("merges the overrides into the ones in the state and passes them to the deployer", func() {
+				err := command.Execute(commands.AWSUpConfig{
+					VersionOverrides: []storage.VersionOverride{
+						{Name: "stemcell", URL: "new-stemcell-url", SHA1: "new-stemcell-sha1"},
+					},
+				}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+					VersionOverrides: []storage.VersionOverride{
+						{Name: "stemcell", URL: "old-stemcell-url", SHA1: "old-stemcell-sha1"},
+						{Name: "bosh", URL: "some-bosh-url", SHA1: "some-bosh-sha1"},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				expectedOverrides := []storage.VersionOverride{
+					{Name: "bosh", URL: "some-bosh-url", SHA1: "some-bosh-sha1"},
+					{Name: "stemcell", URL: "new-stemcell-url", SHA1: "new-stemcell-sha1"},
+				}
+				Expect(boshDeployer.DeployCall.Receives.Input.VersionOverrides).To(Equal(expectedOverrides))
+				Expect(stateStore.SetCall.Receives.State.VersionOverrides).To(Equal(expectedOverrides))
+			})
+
+			It("remembers that the environment is air-gapped", func() {
+				err := command.Execute(commands.AWSUpConfig{AirGapped: true}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshDeployer.DeployCall.Receives.Input.AirGapped).To(BeTrue())
+				Expect(stateStore.SetCall.Receives.State.AirGapped).To(BeTrue())
+			})
+		})
+
+		Describe("director sizing", func() {
+			It("merges the sizing into the state and passes it to the deployer", func() {
+				err := command.Execute(commands.AWSUpConfig{
+					DirectorSizing: storage.DirectorSizing{PersistentDiskSizeGB: 200},
+				}, storage.State{
+					EnvID:          "bbl-lake-time-stamp",
+					DirectorSizing: &storage.DirectorSizing{InstanceType: "m4.xlarge"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				expectedSizing := storage.DirectorSizing{InstanceType: "m4.xlarge", PersistentDiskSizeGB: 200}
+				Expect(boshDeployer.DeployCall.Receives.Input.DirectorSizing).To(Equal(expectedSizing))
+				Expect(stateStore.SetCall.Receives.State.DirectorSizing).To(Equal(&expectedSizing))
+			})
+
+			It("returns an error when the persistent disk of an existing director would shrink", func() {
+				infrastructureManager.ExistsCall.Returns.Exists = true
+
+				err := command.Execute(commands.AWSUpConfig{
+					DirectorSizing: storage.DirectorSizing{PersistentDiskSizeGB: 50},
+				}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+					BOSH:  storage.BOSH{DirectorName: "some-director"},
+				})
+				Expect(err).To(MatchError("the persistent disk of the director cannot be shrunk from 80GB to 50GB"))
+				Expect(boshDeployer.DeployCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Describe("network", func() {
+			It("remembers the network in the state and passes it to the stack", func() {
+				err := command.Execute(commands.AWSUpConfig{
+					Network: storage.Network{CIDR: "172.20.0.0/16", NATIP: "172.20.0.11"},
+				}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				network := infrastructureManager.CreateCall.Receives.Network
+				Expect(network.CIDR).To(Equal("172.20.0.0/16"))
+				Expect(network.BOSHSubnetCIDR).To(Equal("172.20.0.0/24"))
+				Expect(network.NATIP).To(Equal("172.20.0.11"))
+				Expect(stateStore.SetCall.Receives.State.Network).To(Equal(&storage.Network{
+					CIDR:  "172.20.0.0/16",
+					NATIP: "172.20.0.11",
+				}))
+			})
+
+			It("returns an error when the network is invalid", func() {
+				err := command.Execute(commands.AWSUpConfig{
+					Network: storage.Network{CIDR: "10.0.0.0/24"},
+				}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).To(MatchError(ContainSubstring("the network must be a /8 to /19 block")))
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the network of an existing stack would change", func() {
+				infrastructureManager.ExistsCall.Returns.Exists = true
+
+				err := command.Execute(commands.AWSUpConfig{
+					Network: storage.Network{DirectorIP: "10.0.0.10"},
+				}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).To(MatchError("the network cannot be changed for an existing environment"))
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Describe("nat type", func() {
+			BeforeEach(func() {
+				availabilityZoneRetriever.RetrieveCall.Returns.AZs = []string{"some-az-1", "some-az-2"}
+			})
+
+			It("remembers the NAT type in the state and creates a NAT gateway per availability zone", func() {
+				err := command.Execute(commands.AWSUpConfig{NATType: "gateway"}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				network := infrastructureManager.CreateCall.Receives.Network
+				Expect(network.NATType).To(Equal("gateway"))
+				Expect(network.NATSubnetCIDRs).To(Equal([]string{"10.0.1.0/28", "10.0.1.16/28"}))
+				Expect(stateStore.SetCall.Receives.State.Stack.NATType).To(Equal("gateway"))
+			})
+
+			It("keeps the NAT type from the state when none is given", func() {
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+					Stack: storage.Stack{NATType: "gateway"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(infrastructureManager.CreateCall.Receives.Network.NATType).To(Equal("gateway"))
+			})
+
+			It("warns about the interruption when an existing stack switches NAT types", func() {
+				infrastructureManager.ExistsCall.Returns.Exists = true
+
+				err := command.Execute(commands.AWSUpConfig{NATType: "gateway", NoConfirm: true}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.StepCall.Messages).To(ContainElement("switching the NAT type from instance to gateway, outbound traffic from the internal subnets will be interrupted while the stack is updated"))
+				Expect(infrastructureManager.CreateCall.Receives.Network.NATType).To(Equal("gateway"))
+				Expect(stateStore.SetCall.Receives.State.Stack.NATType).To(Equal("gateway"))
+			})
+
+			It("does not remember the new NAT type when the stack update fails", func() {
+				infrastructureManager.ExistsCall.Returns.Exists = true
+				infrastructureManager.CreateCall.Returns.Error = errors.New("failed to update stack")
+
+				err := command.Execute(commands.AWSUpConfig{NATType: "gateway", NoConfirm: true}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+					Stack: storage.Stack{NATType: "instance"},
+				})
+				Expect(err).To(MatchError("failed to update stack"))
+
+				Expect(infrastructureManager.CreateCall.Receives.Network.NATType).To(Equal("gateway"))
+				Expect(stateStore.SetCall.CallCount).To(BeNumerically(">", 0))
+				Expect(stateStore.SetCall.Receives.State.Stack.NATType).To(Equal("instance"))
+			})
+		})
+
+		Describe("nat ami", func() {
+			It("records the latest NAT AMI for a new stack", func() {
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+					AWS:   storage.AWS{Region: "ap-south-1"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(natAMIResolver.ResolveCall.Receives.Region).To(Equal("ap-south-1"))
+				Expect(infrastructureManager.CreateCall.Receives.Network.NATAMI).To(Equal("some-nat-ami"))
+				Expect(stateStore.SetCall.Receives.State.Stack.NATAMI).To(Equal("some-nat-ami"))
+			})
+
+			It("keeps the NAT AMI from the state", func() {
+				infrastructureManager.ExistsCall.Returns.Exists = true
+
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+					Stack: storage.Stack{NATAMI: "some-recorded-nat-ami"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(natAMIResolver.ResolveCall.CallCount).To(Equal(0))
+				Expect(infrastructureManager.CreateCall.Receives.Network.NATAMI).To(Equal("some-recorded-nat-ami"))
+			})
+
+			It("records the AMI the template used to look up for stacks created before the AMI was recorded", func() {
+				infrastructureManager.ExistsCall.Returns.Exists = true
+
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+					AWS:   storage.AWS{Region: "us-east-1"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(natAMIResolver.ResolveCall.CallCount).To(Equal(0))
+				Expect(infrastructureManager.CreateCall.Receives.Network.NATAMI).To(Equal("ami-68115b02"))
+				Expect(stateStore.SetCall.Receives.State.Stack.NATAMI).To(Equal("ami-68115b02"))
+			})
+
+			It("replaces the NAT AMI of an existing stack when the latest is requested", func() {
+				infrastructureManager.ExistsCall.Returns.Exists = true
+
+				err := command.Execute(commands.AWSUpConfig{NATAMI: "latest", NoConfirm: true}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+					Stack: storage.Stack{NATAMI: "some-recorded-nat-ami"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.StepCall.Messages).To(ContainElement("updating the NAT AMI from some-recorded-nat-ami to some-nat-ami, the NAT instance will be replaced"))
+				Expect(infrastructureManager.CreateCall.Receives.Network.NATAMI).To(Equal("some-nat-ami"))
+				Expect(stateStore.SetCall.Receives.State.Stack.NATAMI).To(Equal("some-nat-ami"))
+			})
+
+			It("keeps the recorded NAT AMI when the user declines the replacement", func() {
+				infrastructureManager.ExistsCall.Returns.Exists = true
+				infrastructureManager.PlanCall.Returns.Changes = []cloudformation.ResourceChange{
+					{Action: "Modify", LogicalID: "NATInstance", ResourceType: "AWS::EC2::Instance", Replacement: "True"},
+				}
+				stdin.Write([]byte("no\n"))
+
+				err := command.Execute(commands.AWSUpConfig{NATAMI: "latest"}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+					Stack: storage.Stack{NATAMI: "some-recorded-nat-ami"},
+				})
+				Expect(err).To(MatchError("bbl up was cancelled"))
+
+				Expect(infrastructureManager.PlanCall.Receives.Network.NATAMI).To(Equal("some-nat-ami"))
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(0))
+				Expect(stateStore.SetCall.Receives.State.Stack.NATAMI).To(Equal("some-recorded-nat-ami"))
+			})
+
+			It("keeps the recorded NAT AMI when the stack update fails", func() {
+				infrastructureManager.ExistsCall.Returns.Exists = true
+				infrastructureManager.CreateCall.Returns.Error = errors.New("failed to update stack")
+
+				err := command.Execute(commands.AWSUpConfig{NATAMI: "latest", NoConfirm: true}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+					Stack: storage.Stack{NATAMI: "some-recorded-nat-ami"},
+				})
+				Expect(err).To(MatchError("failed to update stack"))
+
+				Expect(stateStore.SetCall.Receives.State.Stack.NATAMI).To(Equal("some-recorded-nat-ami"))
+			})
+
+			It("uses the given NAT AMI", func() {
+				err := command.Execute(commands.AWSUpConfig{NATAMI: "ami-some-custom-nat"}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(natAMIResolver.ResolveCall.CallCount).To(Equal(0))
+				Expect(infrastructureManager.CreateCall.Receives.Network.NATAMI).To(Equal("ami-some-custom-nat"))
+			})
+
+			It("does not look up a NAT AMI for NAT gateways", func() {
+				err := command.Execute(commands.AWSUpConfig{NATType: "gateway"}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(natAMIResolver.ResolveCall.CallCount).To(Equal(0))
+				Expect(stateStore.SetCall.Receives.State.Stack.NATAMI).To(BeEmpty())
+			})
+
+			It("returns an error when the NAT AMI cannot be resolved", func() {
+				natAMIResolver.ResolveCall.Returns.Error = errors.New("failed to resolve")
+
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).To(MatchError("failed to resolve"))
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Describe("cloudformation patches", func() {
+			var patches []storage.TemplatePatch
+
+			BeforeEach(func() {
+				patches = []storage.TemplatePatch{
+					{Name: "flow-logs.json", Contents: `{"Resources": {"FlowLogs": {"Type": "AWS::EC2::FlowLog"}}}`},
+				}
+			})
+
+			It("remembers the patches in the state and applies them to the stack", func() {
+				err := command.Execute(commands.AWSUpConfig{TemplatePatches: patches}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(infrastructureManager.CreateCall.Receives.TemplatePatches).To(Equal([]templates.Patch{{
+					Name: "flow-logs.json",
+					Resources: map[string]*templates.Resource{
+						"FlowLogs": {Type: "AWS::EC2::FlowLog"},
+					},
+				}}))
+				Expect(stateStore.SetCall.Receives.State.Stack.TemplatePatches).To(Equal(patches))
+			})
+
+			It("reapplies the patches from the state when none are given", func() {
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+					Stack: storage.Stack{TemplatePatches: patches},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(infrastructureManager.CreateCall.Receives.TemplatePatches).To(HaveLen(1))
+			})
+
+			It("returns an error when a patch in the state is invalid", func() {
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+					Stack: storage.Stack{TemplatePatches: []storage.TemplatePatch{{Name: "invalid.json", Contents: "{"}}},
+				})
+				Expect(err).To(MatchError(`invalid cloudformation patch "invalid.json": unexpected EOF`))
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Describe("no director", func() {
+			It("stops after the stack and records its outputs", func() {
+				err := command.Execute(commands.AWSUpConfig{NoDirector: true}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(1))
+				Expect(boshDeployer.DeployCall.CallCount).To(Equal(0))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(0))
+
+				state := stateStore.SetCall.Receives.State
+				Expect(state.NoDirector).To(BeTrue())
+				Expect(state.BOSH.IsEmpty()).To(BeTrue())
+				Expect(state.Checkpoints).To(BeNil())
+				Expect(state.Outputs).To(Equal(infrastructureManager.CreateCall.Returns.Stack.Outputs))
+			})
+
+			It("keeps an existing environment infrastructure-only when no-director is given again", func() {
+				err := command.Execute(commands.AWSUpConfig{NoDirector: true}, storage.State{
+					EnvID:      "bbl-lake-time-stamp",
+					NoDirector: true,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshDeployer.DeployCall.CallCount).To(Equal(0))
+				Expect(stateStore.SetCall.Receives.State.NoDirector).To(BeTrue())
+			})
+
+			It("deploys a director to an infrastructure-only environment when no-director is not given", func() {
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					EnvID:      "bbl-lake-time-stamp",
+					NoDirector: true,
+					Outputs:    map[string]string{"BOSHEIP": "some-bosh-eip"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.StepCall.Messages).To(ContainElement("deploying a director to the infrastructure-only environment"))
+				Expect(boshDeployer.DeployCall.CallCount).To(Equal(1))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(1))
+
+				state := stateStore.SetCall.Receives.State
+				Expect(state.NoDirector).To(BeFalse())
+				Expect(state.Outputs).To(BeNil())
+				Expect(state.BOSH.IsEmpty()).To(BeFalse())
+			})
+		})
+
+		Describe("resuming", func() {
+			var failedState storage.State
+
+			BeforeEach(func() {
+				infrastructureManager.DescribeCall.Returns.Stack = infrastructureManager.CreateCall.Returns.Stack
+				cloudConfigManager.UpdateCall.Returns.Error = errors.New("cloud config update failed")
+
+				err := command.Execute(commands.AWSUpConfig{}, storage.State{
+					EnvID: "bbl-lake-time-stamp",
+				})
+				Expect(err).To(MatchError("cloud config update failed"))
+
+				failedState = stateStore.SetCall.Receives.State
+				cloudConfigManager.UpdateCall.Returns.Error = nil
+				infrastructureManager.ExistsCall.Returns.Exists = true
+			})
+
+			It("records the completed phases in the state", func() {
+				Expect(failedState.Checkpoints).To(HaveLen(3))
+				Expect(failedState.Checkpoints[0].Phase).To(Equal("key-pair"))
+				Expect(failedState.Checkpoints[1].Phase).To(Equal("infrastructure"))
+				Expect(failedState.Checkpoints[2].Phase).To(Equal("director"))
+				Expect(failedState.Checkpoints[2].InputHash).NotTo(BeEmpty())
+			})
+
+			It("skips the phases whose inputs have not changed", func() {
+				err := command.Execute(commands.AWSUpConfig{NoConfirm: true}, failedState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(keyPairSynchronizer.SyncCall.CallCount).To(Equal(1))
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(1))
+				Expect(infrastructureManager.DescribeCall.Receives.StackName).To(Equal("stack-bbl-lake-time-stamp"))
+				Expect(boshDeployer.DeployCall.CallCount).To(Equal(1))
+				Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(2))
+
+				Expect(logger.StepCall.Messages).To(ContainElement(`skipping phase "director", its inputs have not changed since the last run`))
+				Expect(logger.StepCall.Messages).To(ContainElement(`resuming bbl up from phase "cloud-config"`))
+			})
+
+			It("clears the checkpoints when up completes", func() {
+				err := command.Execute(commands.AWSUpConfig{NoConfirm: true}, failedState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stateStore.SetCall.Receives.State.Checkpoints).To(BeNil())
+			})
+
+			It("re-runs a phase and the phases after it when its inputs have changed", func() {
+				failedState.Stack.LBType = "concourse"
+
+				err := command.Execute(commands.AWSUpConfig{NoConfirm: true}, failedState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(keyPairSynchronizer.SyncCall.CallCount).To(Equal(1))
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(2))
+				Expect(boshDeployer.DeployCall.CallCount).To(Equal(2))
+				Expect(logger.StepCall.Messages).To(ContainElement(`resuming bbl up from phase "infrastructure"`))
+			})
+
+			It("re-runs the infrastructure phase when the cloudformation patches have changed", func() {
+				err := command.Execute(commands.AWSUpConfig{
+					NoConfirm: true,
+					TemplatePatches: []storage.TemplatePatch{
+						{Name: "flow-logs.json", Contents: `{"Resources": {"FlowLogs": {"Type": "AWS::EC2::FlowLog"}}}`},
+					},
+				}, failedState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(infrastructureManager.CreateCall.CallCount).To(Equal(2))
+				Expect(logger.StepCall.Messages).To(ContainElement(`resuming bbl up from phase "infrastructure"`))
+			})
+
+			Context("when a phase is provided with --from-phase", func() {
+				It("skips the phases before it and runs the remaining phases", func() {
+					err := command.Execute(commands.AWSUpConfig{NoConfirm: true, FromPhase: "director"}, failedState)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(keyPairSynchronizer.SyncCall.CallCount).To(Equal(1))
+					Expect(infrastructureManager.CreateCall.CallCount).To(Equal(1))
+					Expect(boshDeployer.DeployCall.CallCount).To(Equal(2))
+					Expect(cloudConfigManager.UpdateCall.CallCount).To(Equal(2))
+					Expect(logger.StepCall.Messages).To(ContainElement(`skipping phase "infrastructure", starting from phase "director"`))
+				})
+
+				It("returns an error when an earlier phase has not completed", func() {
+					err := command.Execute(commands.AWSUpConfig{FromPhase: "cloud-config"}, storage.State{
+						EnvID: "bbl-lake-time-stamp",
+						KeyPair: storage.KeyPair{
+							Name:       "some-keypair-name",
+							PrivateKey: "some-private-key",
+						},
+					})
+					Expect(err).To(MatchError(`cannot start from phase "cloud-config", phase "director" has not completed yet`))
+				})
+			})
+		})
+
 		Describe("cloud configurator", func() {
 			BeforeEach(func() {
 				infrastructureManager.CreateCall.Stub = func(keyPairName string, numberOfAZs int, stackName, lbType, envID string) (cloudformation.Stack, error) {
@@ -468,6 +1098,26 @@ var _ = Describe("AWSUp", func() {
 				})
 			})
 
+			Context("when bosh-init is stopped", func() {
+				It("saves the partial bosh-init state and returns an error", func() {
+					boshDeployer.DeployCall.Returns.Error = boshinit.NewBOSHInitError(boshinit.State{"partial": "state"},
+						errors.New("bbl received interrupt, bosh-init was stopped"))
+					boshDeployer.DeployCall.Returns.Output.BOSHInitState = boshinit.State{"partial": "state"}
+
+					err := command.Execute(commands.AWSUpConfig{}, storage.State{
+						EnvID: "bbl-lake-time-stamp",
+					})
+					Expect(err).To(MatchError("bbl received interrupt, bosh-init was stopped"))
+					Expect(stateStore.SetCall.CallCount).To(Equal(5))
+
+					state := stateStore.SetCall.Receives.State
+					Expect(state.BOSH.State).To(Equal(map[string]interface{}{"partial": "state"}))
+					Expect(state.BOSH.DirectorUsername).To(Equal("user-some-random-string"))
+					Expect(state.BOSH.DirectorSSLCA).To(Equal("updated-ca"))
+					Expect(state.Checkpoints).To(HaveLen(2))
+				})
+			})
+
 			Context("when the bosh cloud config fails", func() {
 				It("saves the bosh properties and returns an error", func() {
 					cloudConfigManager.UpdateCall.Returns.Error = errors.New("cloud config update failed")
@@ -476,7 +1126,7 @@ var _ = Describe("AWSUp", func() {
 						EnvID: "bbl-lake-time-stamp",
 					})
 					Expect(err).To(MatchError("cloud config update failed"))
-					Expect(stateStore.SetCall.CallCount).To(Equal(4))
+					Expect(stateStore.SetCall.CallCount).To(Equal(5))
 					Expect(stateStore.SetCall.Receives.State.BOSH).To(Equal(storage.BOSH{
 						DirectorName:           "bosh-bbl-lake-time-stamp",
 						DirectorUsername:       "user-some-random-string",
@@ -559,6 +1209,25 @@ var _ = Describe("AWSUp", func() {
 						}))
 					})
 
+					It("keeps the saved credentials when only the region is passed in", func() {
+						err := command.Execute(commands.AWSUpConfig{
+							Region: "new-aws-region",
+						}, storage.State{
+							AWS: storage.AWS{
+								AccessKeyID:     "old-aws-access-key-id",
+								SecretAccessKey: "old-aws-secret-access-key",
+								Region:          "old-aws-region",
+							},
+						})
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(stateStore.SetCall.Receives.State.AWS).To(Equal(storage.AWS{
+							AccessKeyID:     "old-aws-access-key-id",
+							SecretAccessKey: "old-aws-secret-access-key",
+							Region:          "new-aws-region",
+						}))
+					})
+
 					It("does not override the credentials when they're not passed in", func() {
 						err := command.Execute(commands.AWSUpConfig{}, storage.State{
 							AWS: storage.AWS{
@@ -578,6 +1247,114 @@ var _ = Describe("AWSUp", func() {
 				})
 			})
 
+			Context("temporary and delegated aws credentials", func() {
+				It("uses the session token without saving the temporary credentials", func() {
+					err := command.Execute(commands.AWSUpConfig{
+						AccessKeyID:     "temporary-access-key-id",
+						SecretAccessKey: "temporary-secret-access-key",
+						SessionToken:    "some-session-token",
+						Region:          "some-aws-region",
+					}, storage.State{
+						AWS: storage.AWS{
+							AccessKeyID:     "old-aws-access-key-id",
+							SecretAccessKey: "old-aws-secret-access-key",
+							Region:          "some-aws-region",
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(clientProvider.SetConfigCall.Receives.Config).To(Equal(aws.Config{
+						AccessKeyID:     "temporary-access-key-id",
+						SecretAccessKey: "temporary-secret-access-key",
+						SessionToken:    "some-session-token",
+						Region:          "some-aws-region",
+					}))
+
+					Expect(stateStore.SetCall.Receives.State.AWS).To(Equal(storage.AWS{
+						Region: "some-aws-region",
+					}))
+				})
+
+				It("saves the profile in place of the access keys", func() {
+					err := command.Execute(commands.AWSUpConfig{
+						Profile: "some-profile",
+					}, storage.State{
+						AWS: storage.AWS{
+							AccessKeyID:     "old-aws-access-key-id",
+							SecretAccessKey: "old-aws-secret-access-key",
+							Region:          "some-aws-region",
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(stateStore.SetCall.Receives.State.AWS).To(Equal(storage.AWS{
+						Profile: "some-profile",
+						Region:  "some-aws-region",
+					}))
+					Expect(clientProvider.SetConfigCall.Receives.Config).To(Equal(aws.Config{
+						Profile: "some-profile",
+						Region:  "some-aws-region",
+					}))
+				})
+
+				It("saves the role to assume and the external id", func() {
+					err := command.Execute(commands.AWSUpConfig{
+						AssumeRoleARN: "some-role-arn",
+						ExternalID:    "some-external-id",
+						Region:        "some-aws-region",
+					}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(stateStore.SetCall.Receives.State.AWS).To(Equal(storage.AWS{
+						AssumeRoleARN: "some-role-arn",
+						ExternalID:    "some-external-id",
+						Region:        "some-aws-region",
+					}))
+					Expect(clientProvider.SetConfigCall.Receives.Config).To(Equal(aws.Config{
+						AssumeRoleARN: "some-role-arn",
+						ExternalID:    "some-external-id",
+						Region:        "some-aws-region",
+					}))
+				})
+
+				It("drops the saved external id when a new role is assumed without one", func() {
+					err := command.Execute(commands.AWSUpConfig{
+						AssumeRoleARN: "new-role-arn",
+					}, storage.State{
+						AWS: storage.AWS{
+							AssumeRoleARN: "old-role-arn",
+							ExternalID:    "old-external-id",
+							Region:        "some-aws-region",
+						},
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(stateStore.SetCall.Receives.State.AWS).To(Equal(storage.AWS{
+						AssumeRoleARN: "new-role-arn",
+						Region:        "some-aws-region",
+					}))
+				})
+
+				DescribeTable("returns an error for invalid combinations", func(config commands.AWSUpConfig, expectedError string) {
+					err := command.Execute(config, storage.State{})
+					Expect(err).To(MatchError(expectedError))
+					Expect(stateStore.SetCall.CallCount).To(Equal(0))
+				},
+					Entry("a session token without access keys",
+						commands.AWSUpConfig{SessionToken: "some-session-token", Region: "some-region"},
+						"AWS access key ID must be provided"),
+					Entry("a profile and access keys",
+						commands.AWSUpConfig{AccessKeyID: "some-key-id", SecretAccessKey: "some-secret", Profile: "some-profile", Region: "some-region"},
+						"--aws-profile cannot be used with --aws-access-key-id"),
+					Entry("an external id without a role",
+						commands.AWSUpConfig{ExternalID: "some-external-id", Region: "some-region"},
+						"--aws-external-id requires --aws-assume-role-arn"),
+					Entry("no region",
+						commands.AWSUpConfig{Profile: "some-profile"},
+						"AWS region must be provided"),
+				)
+			})
+
 			Context("aws keypair", func() {
 				Context("when the keypair exists", func() {
 					It("saves the given state unmodified", func() {
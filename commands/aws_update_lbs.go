@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"strings"
 
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
@@ -45,6 +46,11 @@ func (c AWSUpdateLBs) Execute(config AWSCreateLBsConfig, state storage.State) er
 		return err
 	}
 
+	patches, err := templatePatches(state)
+	if err != nil {
+		return err
+	}
+
 	if match, err := c.checkCertificateAndChain(config.CertPath, config.ChainPath, state.Stack.CertificateName); err != nil {
 		return err
 	} else if match {
@@ -64,7 +70,7 @@ func (c AWSUpdateLBs) Execute(config AWSCreateLBsConfig, state storage.State) er
 		return err
 	}
 
-	if err := c.updateStack(certificateName, state.KeyPair.Name, state.Stack.Name, state.Stack.LBType, state.AWS.Region, state.EnvID); err != nil {
+	if err := c.updateStack(certificateName, state, patches); err != nil {
 		return err
 	}
 
@@ -113,8 +119,8 @@ func (c AWSUpdateLBs) checkCertificateAndChain(certPath string, chainPath string
 	return true, nil
 }
 
-func (c AWSUpdateLBs) updateStack(certificateName string, keyPairName string, stackName string, lbType string, awsRegion, envID string) error {
-	availabilityZones, err := c.availabilityZoneRetriever.Retrieve(awsRegion)
+func (c AWSUpdateLBs) updateStack(certificateName string, state storage.State, patches []templates.Patch) error {
+	availabilityZones, err := c.availabilityZoneRetriever.Retrieve(state.AWS.Region)
 	if err != nil {
 		return err
 	}
@@ -124,7 +130,12 @@ func (c AWSUpdateLBs) updateStack(certificateName string, keyPairName string, st
 		return err
 	}
 
-	_, err = c.infrastructureManager.Update(keyPairName, len(availabilityZones), stackName, lbType, certificate.ARN, envID)
+	network, err := templateNetwork(state, len(availabilityZones))
+	if err != nil {
+		return err
+	}
+
+	_, err = c.infrastructureManager.Update(state.KeyPair.Name, len(availabilityZones), state.Stack.Name, state.Stack.LBType, certificate.ARN, state.EnvID, network, patches)
 	if err != nil {
 		return err
 	}
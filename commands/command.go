@@ -13,13 +13,17 @@ type Command interface {
 	Usage() string
 }
 
-func bblExists(stackName string, infrastructureManager infrastructureManager, boshClient bosh.Client) error {
-	if stackExists, err := infrastructureManager.Exists(stackName); err != nil {
+func bblExists(state storage.State, infrastructureManager infrastructureManager, boshClient bosh.Client) error {
+	if stackExists, err := infrastructureManager.Exists(state.Stack.Name); err != nil {
 		return err
 	} else if !stackExists {
 		return BBLNotFound
 	}
 
+	if state.NoDirector {
+		return nil
+	}
+
 	if _, err := boshClient.Info(); err != nil {
 		return BBLNotFound
 	}
@@ -31,7 +35,7 @@ func checkBBLAndLB(state storage.State, boshClientProvider boshClientProvider, i
 	boshClient := boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername,
 		state.BOSH.DirectorPassword)
 
-	if err := bblExists(state.Stack.Name, infrastructureManager, boshClient); err != nil {
+	if err := bblExists(state, infrastructureManager, boshClient); err != nil {
 		return err
 	}
 
@@ -3,17 +3,47 @@ package commands
 const (
 	UpCommandUsage = `Deploys BOSH director on an IAAS
 
-  --iaas                     IAAS to deploy your BOSH Director onto. Valid options: "gcp", "aws" (Defaults to environment variable BBL_IAAS)
-  --name                     Name to assign to your BOSH Director (optional, will be randomly generated)
-
-  --aws-access-key-id        AWS Access Key ID to use (Defaults to environment variable BBL_AWS_ACCESS_KEY_ID)
-  --aws-secret-access-key    AWS Secret Access Key to use (Defaults to environment variable BBL_AWS_SECRET_ACCESS_KEY)
-  --aws-region               AWS region to use (Defaults to environment variable BBL_AWS_REGION)
-
-  --gcp-service-account-key  GCP Service Access Key to use (Defaults to environment variable BBL_GCP_SERVICE_ACCOUNT_KEY)
-  --gcp-project-id           GCP Project ID to use (Defaults to environment variable BBL_GCP_PROJECT_ID)
-  --gcp-zone                 GCP Zone to use (Defaults to environment variable BBL_GCP_ZONE)
-  --gcp-region               GCP Region to use (Defaults to environment variable BBL_GCP_REGION)`
+  --iaas                           IAAS to deploy your BOSH Director onto. Valid options: "gcp", "aws" (Defaults to environment variable BBL_IAAS)
+  --name                           Name to assign to your BOSH Director (optional, will be randomly generated)
+  --no-confirm                     Do not ask for confirmation before replacing or removing infrastructure (optional)
+  --from-phase                     Phase to resume from: "key-pair", "infrastructure", "director" or "cloud-config" (optional)
+  --no-director                    Only create the infrastructure, without deploying a BOSH director, must be given on every run (optional)
+  --ops-file                       Ops-file to apply to the BOSH director manifest, can be given more than once (optional)
+  --clear-ops-files                Stop applying the ops-files saved by previous runs (optional)
+
+  --bosh-url                       URL or local path of the BOSH release to deploy, requires --bosh-sha1 (optional)
+  --bosh-sha1                      SHA1 of the BOSH release given with --bosh-url (optional)
+  --cpi-url                        URL or local path of the CPI release to deploy, requires --cpi-sha1 (optional)
+  --cpi-sha1                       SHA1 of the CPI release given with --cpi-url (optional)
+  --stemcell-url                   URL or local path of the stemcell to deploy, requires --stemcell-sha1 (optional)
+  --stemcell-sha1                  SHA1 of the stemcell given with --stemcell-url (optional)
+  --air-gapped                     Refuse to deploy releases or stemcells that are not local files (optional)
+
+  --director-instance-type         Instance type (AWS) or machine type (GCP) of the director VM (optional)
+  --director-ephemeral-disk-size   Size of the director's ephemeral disk in GB (optional)
+  --director-ephemeral-disk-type   Volume type (AWS) or disk type (GCP) of the director's ephemeral disk (optional)
+  --director-persistent-disk-size  Size of the director's persistent disk in GB, it can only be increased (optional)
+  --director-persistent-disk-type  Volume type (AWS) or disk type (GCP) of the director's persistent disk (optional)
+
+  --network-cidr                   /8 to /19 CIDR block of the VPC (AWS, at most a /16) or network (GCP), defaults to 10.0.0.0/16 (optional)
+  --director-ip                    Internal IP of the director, must be in the first /24 of the network (optional)
+  --nat-ip                         Internal IP of the NAT instance, must be in the first /24 of the network, AWS only (optional)
+  --nat-type                       "instance" for a NAT instance or "gateway" for a NAT gateway per AZ, defaults to "instance", AWS only (optional)
+  --nat-ami                        AMI of the NAT instance, or "latest" for the latest Amazon NAT AMI, AWS only (optional)
+
+  --aws-access-key-id              AWS Access Key ID to use (Defaults to environment variable BBL_AWS_ACCESS_KEY_ID)
+  --aws-secret-access-key          AWS Secret Access Key to use (Defaults to environment variable BBL_AWS_SECRET_ACCESS_KEY)
+  --aws-session-token              AWS session token of temporary credentials, it is not saved in the state (Defaults to environment variable BBL_AWS_SESSION_TOKEN)
+  --aws-profile                    Profile in the shared AWS credentials file to use instead of access keys (Defaults to environment variable BBL_AWS_PROFILE)
+  --aws-assume-role-arn            ARN of an IAM role to assume with the AWS credentials (Defaults to environment variable BBL_AWS_ASSUME_ROLE_ARN)
+  --aws-external-id                External ID to assume the role with, requires --aws-assume-role-arn (Defaults to environment variable BBL_AWS_EXTERNAL_ID)
+  --aws-region                     AWS region to use (Defaults to environment variable BBL_AWS_REGION)
+  --cloudformation-patch           JSON fragment to merge into the CloudFormation template, can be given more than once (optional)
+
+  --gcp-service-account-key        GCP Service Access Key to use (Defaults to environment variable BBL_GCP_SERVICE_ACCOUNT_KEY)
+  --gcp-project-id                 GCP Project ID to use (Defaults to environment variable BBL_GCP_PROJECT_ID)
+  --gcp-zone                       GCP Zone to use (Defaults to environment variable BBL_GCP_ZONE)
+  --gcp-region                     GCP Region to use (Defaults to environment variable BBL_GCP_REGION)`
 
 	DestroyCommandUsage = `Tears down BOSH director infrastructure
 
@@ -45,6 +75,38 @@ const (
 
 	VersionCommandUsage = "Prints version"
 
+	ForceUnlockCommandUsage = "Removes a stale lock on bbl-state.json"
+
+	StateHistoryCommandUsage = "Lists backups of bbl-state.json, most recent first"
+
+	RestoreStateCommandUsage = `Restores bbl-state.json from a backup
+
+  <n>  Number of the backup to restore, as listed by "bbl state-history"`
+
+	ExportCommandUsage = `Writes bbl-state.json, the terraform state and the bosh-init state and manifest to a single bundle
+
+  [--output]    Path of the bundle to write (defaults to bbl-<env-id>.tgz)
+  [--key-file]  File containing a key used to encrypt the bundle (optional)`
+
+	ImportCommandUsage = `Restores an environment from a bundle written by "bbl export"
+
+  <bundle>      Path of the bundle to import
+  [--key-file]  File containing the key the bundle was encrypted with (optional)`
+
+	PrintEnvCommandUsage = `Prints environment variables for the BOSH CLI and an SSH gateway to the director
+
+  [--shell]  Shell to print the variables for. Valid options: "bash", "fish", "powershell" (Defaults to "bash")`
+
+	StateCommandUsage = "Prints a summary of the environment"
+
+	OutputsCommandUsage = "Prints the infrastructure outputs of an environment created with bbl up --no-director"
+
+	VersionsCommandUsage = `Prints the BOSH, CPI and stemcell versions bbl up deploys, and the defaults they override
+
+  [--iaas]  IAAS to print the default versions for when there is no state. Valid options: "gcp", "aws"`
+
+	PlanCommandUsage = "Previews the infrastructure, bosh-init manifest and cloud config changes bbl up would make, without applying them"
+
 	UsageCommandUsage = "Prints helpful message for the given command"
 
 	EnvIdCommandUsage = "Prints environment ID"
@@ -74,6 +136,26 @@ func (LBs) Usage() string { return LBsCommandUsage }
 
 func (Version) Usage() string { return VersionCommandUsage }
 
+func (ForceUnlock) Usage() string { return ForceUnlockCommandUsage }
+
+func (StateHistory) Usage() string { return StateHistoryCommandUsage }
+
+func (RestoreState) Usage() string { return RestoreStateCommandUsage }
+
+func (Export) Usage() string { return ExportCommandUsage }
+
+func (Import) Usage() string { return ImportCommandUsage }
+
+func (PrintEnv) Usage() string { return PrintEnvCommandUsage }
+
+func (StateSummary) Usage() string { return StateCommandUsage }
+
+func (Outputs) Usage() string { return OutputsCommandUsage }
+
+func (Plan) Usage() string { return PlanCommandUsage }
+
+func (Versions) Usage() string { return VersionsCommandUsage }
+
 func (Usage) Usage() string { return UsageCommandUsage }
 
 func (s StateQuery) Usage() string {
@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func confirm(logger logger, stdin io.Reader, message string) bool {
+	logger.Prompt(message)
+
+	var proceed string
+	fmt.Fscanln(stdin, &proceed)
+
+	proceed = strings.ToLower(proceed)
+	if proceed != "yes" && proceed != "y" {
+		logger.Step("exiting")
+		return false
+	}
+
+	return true
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"reflect"
-	"strings"
 
 	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
 	"github.com/cloudfoundry/bosh-bootloader/boshinit"
@@ -34,6 +33,7 @@ type Destroy struct {
 	terraformExecutor       terraformExecutor
 	terraformOutputter      terraformOutputter
 	networkInstancesChecker networkInstancesChecker
+	zones                   zones
 }
 
 type destroyConfig struct {
@@ -81,7 +81,8 @@ func NewDestroy(credentialValidator credentialValidator, logger logger, stdin io
 	boshDeleter boshDeleter, vpcStatusChecker vpcStatusChecker, stackManager stackManager,
 	stringGenerator stringGenerator, infrastructureManager infrastructureManager, awsKeyPairDeleter awsKeyPairDeleter,
 	gcpKeyPairDeleter gcpKeyPairDeleter, certificateDeleter certificateDeleter, stateStore stateStore, stateValidator stateValidator,
-	terraformExecutor terraformExecutor, terraformOutputter terraformOutputter, networkInstancesChecker networkInstancesChecker) Destroy {
+	terraformExecutor terraformExecutor, terraformOutputter terraformOutputter, networkInstancesChecker networkInstancesChecker,
+	zones zones) Destroy {
 	return Destroy{
 		credentialValidator:     credentialValidator,
 		logger:                  logger,
@@ -99,6 +100,7 @@ func NewDestroy(credentialValidator credentialValidator, logger logger, stdin io
 		terraformExecutor:       terraformExecutor,
 		terraformOutputter:      terraformOutputter,
 		networkInstancesChecker: networkInstancesChecker,
+		zones:                   zones,
 	}
 }
 
@@ -144,14 +146,7 @@ func (d Destroy) Execute(subcommandFlags []string, state storage.State) error {
 	}
 
 	if !config.NoConfirm {
-		d.logger.Prompt(fmt.Sprintf("Are you sure you want to delete infrastructure for %q? This operation cannot be undone!", state.EnvID))
-
-		var proceed string
-		fmt.Fscanln(d.stdin, &proceed)
-
-		proceed = strings.ToLower(proceed)
-		if proceed != "yes" && proceed != "y" {
-			d.logger.Step("exiting")
+		if !confirm(d.logger, d.stdin, fmt.Sprintf("Are you sure you want to delete infrastructure for %q? This operation cannot be undone!", state.EnvID)) {
 			return nil
 		}
 	}
@@ -179,7 +174,16 @@ func (d Destroy) Execute(subcommandFlags []string, state storage.State) error {
 	}
 
 	state, err = d.deleteBOSH(state)
-	if err != nil {
+	switch err.(type) {
+	case boshinit.BOSHInitError:
+		if setErr := d.stateStore.Set(state); setErr != nil {
+			errorList := helpers.Errors{}
+			errorList.Add(err)
+			errorList.Add(setErr)
+			return errorList
+		}
+		return err
+	case error:
 		return err
 	}
 
@@ -196,8 +200,14 @@ func (d Destroy) Execute(subcommandFlags []string, state storage.State) error {
 	}
 
 	if state.IAAS == "gcp" {
+		networkCIDR, err := gcpNetworkCIDR(state)
+		if err != nil {
+			return err
+		}
+
+		template := gcpUpTemplate(state.LB.Type, d.zones.Get(state.GCP.Region), networkCIDR)
 		state.TFState, err = d.terraformExecutor.Destroy(state.GCP.ServiceAccountKey, state.EnvID, state.GCP.ProjectID, state.GCP.Zone,
-			state.GCP.Region, terraformVarsTemplate, state.TFState)
+			state.GCP.Region, template, state.TFState)
 		if err != nil {
 			if setErr := d.stateStore.Set(state); setErr != nil {
 				errorList := helpers.Errors{}
@@ -274,6 +284,9 @@ func (d Destroy) deleteBOSH(state storage.State) (storage.State, error) {
 	}
 
 	if err := d.boshDeleter.Delete(state.BOSH.Manifest, state.BOSH.State, state.KeyPair.PrivateKey); err != nil {
+		if boshInitErr, ok := err.(boshinit.BOSHInitError); ok {
+			state.BOSH.State = boshInitErr.BOSHInitState()
+		}
 		return state, err
 	}
 
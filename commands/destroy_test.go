@@ -34,6 +34,7 @@ var _ = Describe("Destroy", func() {
 		terraformExecutor       *fakes.TerraformExecutor
 		terraformOutputter      *fakes.TerraformOutputter
 		networkInstancesChecker *fakes.NetworkInstancesChecker
+		zones                   *fakes.Zones
 		stdin                   *bytes.Buffer
 	)
 
@@ -55,11 +56,12 @@ var _ = Describe("Destroy", func() {
 		terraformExecutor = &fakes.TerraformExecutor{}
 		terraformOutputter = &fakes.TerraformOutputter{}
 		networkInstancesChecker = &fakes.NetworkInstancesChecker{}
+		zones = &fakes.Zones{}
 
 		destroy = commands.NewDestroy(credentialValidator, logger, stdin, boshDeleter,
 			vpcStatusChecker, stackManager, stringGenerator, infrastructureManager,
 			awsKeyPairDeleter, gcpKeyPairDeleter, certificateDeleter, stateStore,
-			stateValidator, terraformExecutor, terraformOutputter, networkInstancesChecker)
+			stateValidator, terraformExecutor, terraformOutputter, networkInstancesChecker, zones)
 	})
 
 	Describe("Execute", func() {
@@ -165,6 +167,24 @@ var _ = Describe("Destroy", func() {
 					})
 					Expect(err).To(MatchError("BOSH Delete Failed"))
 				})
+
+				It("saves the partial bosh-init state when bosh-init was stopped", func() {
+					boshDeleter.DeleteCall.Returns.Error = boshinit.NewBOSHInitError(boshinit.State{"partial": "state"},
+						errors.New("bbl received interrupt, bosh-init was stopped"))
+
+					err := destroy.Execute([]string{}, storage.State{
+						BOSH: storage.BOSH{
+							DirectorName: "some-director",
+							State:        boshinit.State{"key": "value"},
+						},
+					})
+					Expect(err).To(MatchError("bbl received interrupt, bosh-init was stopped"))
+					Expect(stateStore.SetCall.CallCount).To(Equal(1))
+					Expect(stateStore.SetCall.Receives.State.BOSH).To(Equal(storage.BOSH{
+						DirectorName: "some-director",
+						State:        boshinit.State{"partial": "state"},
+					}))
+				})
 			})
 
 			Context("when state store fails to set the state before destroying infrastructure", func() {
@@ -552,6 +572,33 @@ var _ = Describe("Destroy", func() {
 				Expect(terraformExecutor.DestroyCall.Returns.TFState).To(Equal(""))
 			})
 
+			It("destroys with the template the environment was created with, so overrides can reference its resources", func() {
+				zones.GetCall.Returns.Zones = []string{"zone-1", "zone-2"}
+				stdin.Write([]byte("yes\n"))
+				err := destroy.Execute([]string{}, storage.State{
+					IAAS: "gcp",
+					GCP: storage.GCP{
+						Region: "some-region",
+					},
+					LB: storage.LB{
+						Type: "cf",
+					},
+					Network: &storage.Network{
+						CIDR: "172.20.0.0/16",
+					},
+					TFState: "some-tf-state",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(zones.GetCall.Receives.Region).To(Equal("some-region"))
+
+				template := terraformExecutor.DestroyCall.Receives.Template
+				Expect(template).To(ContainSubstring(`resource "google_compute_network" "bbl-network"`))
+				Expect(template).To(ContainSubstring(`resource "google_compute_target_pool" "cf-ssh-proxy"`))
+				Expect(template).To(ContainSubstring(`resource "google_compute_instance_group" "router-lb-1"`))
+				Expect(template).To(ContainSubstring("172.20.0.0/16"))
+			})
+
 			Context("when terraform destroy fails", func() {
 				It("saves the partially destroyed tf state", func() {
 					terraformExecutor.DestroyCall.Returns.Error = errors.New("failed to terraform destroy")
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const ExportCommand = "export"
+
+type Export struct {
+	stateValidator stateValidator
+	logger         logger
+	version        string
+	overrides      storage.Backend
+}
+
+type exportConfig struct {
+	output  string
+	keyFile string
+}
+
+func NewExport(stateValidator stateValidator, logger logger, version string, overrides storage.Backend) Export {
+	if version == "" {
+		version = BBLDevVersion
+	}
+
+	return Export{
+		stateValidator: stateValidator,
+		logger:         logger,
+		version:        version,
+		overrides:      overrides,
+	}
+}
+
+func (e Export) Execute(subcommandFlags []string, state storage.State) error {
+	config, err := e.parseFlags(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	err = e.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	if config.output == "" {
+		config.output = fmt.Sprintf("bbl-%s.tgz", state.EnvID)
+	}
+
+	key, err := readBundleKey(config.keyFile)
+	if err != nil {
+		return err
+	}
+
+	state.TerraformOverrides, err = storage.ReadTerraformOverrides(e.overrides)
+	if err != nil {
+		return err
+	}
+
+	var bundle bytes.Buffer
+	_, err = storage.WriteBundle(&bundle, state, e.version, key)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(config.output, bundle.Bytes(), storage.OS_PRIVATE_READ_WRITE_MODE)
+	if err != nil {
+		return err
+	}
+
+	e.logger.Println(fmt.Sprintf("exported environment %q to %s", state.EnvID, config.output))
+	return nil
+}
+
+func (Export) parseFlags(subcommandFlags []string) (exportConfig, error) {
+	exportFlags := flags.New("export")
+
+	config := exportConfig{}
+	exportFlags.String(&config.output, "output", "")
+	exportFlags.String(&config.keyFile, "key-file", "")
+
+	err := exportFlags.Parse(subcommandFlags)
+	if err != nil {
+		return config, err
+	}
+
+	if len(exportFlags.Args()) > 0 {
+		return config, errors.New("export does not accept arguments, use --output to choose where to write the bundle")
+	}
+
+	return config, nil
+}
+
+func readBundleKey(keyFile string) (string, error) {
+	if keyFile == "" {
+		return "", nil
+	}
+
+	key, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return "", fmt.Errorf("error reading key file: %v", err)
+	}
+
+	return strings.TrimSpace(string(key)), nil
+}
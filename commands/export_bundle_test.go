@@ -0,0 +1,151 @@
+package commands_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Export", func() {
+	var (
+		stateValidator *fakes.StateValidator
+		logger         *fakes.Logger
+		export         commands.Export
+		tempDir        string
+		state          storage.State
+	)
+
+	BeforeEach(func() {
+		stateValidator = &fakes.StateValidator{}
+		logger = &fakes.Logger{}
+
+		var err error
+		tempDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		state = storage.State{
+			IAAS:    "aws",
+			EnvID:   "some-env-id",
+			TFState: "some-tf-state",
+		}
+
+		export = commands.NewExport(stateValidator, logger, "1.2.3", storage.NewLocalBackend(filepath.Join(tempDir, "overrides")))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	Describe("Execute", func() {
+		It("writes the environment to a private bundle", func() {
+			output := filepath.Join(tempDir, "some-env.tgz")
+
+			err := export.Execute([]string{"--output", output}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stateValidator.ValidateCall.CallCount).To(Equal(1))
+
+			info, err := os.Stat(output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(os.FileMode(0600)))
+
+			bundle, err := os.Open(output)
+			Expect(err).NotTo(HaveOccurred())
+			defer bundle.Close()
+
+			imported, manifest, err := storage.ReadBundle(bundle, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(imported.TFState).To(Equal("some-tf-state"))
+			Expect(manifest.BBLVersion).To(Equal("1.2.3"))
+
+			Expect(logger.PrintlnCall.Receives.Message).To(Equal(`exported environment "some-env-id" to ` + output))
+		})
+
+		It("includes the terraform overrides in the bundle", func() {
+			err := os.Mkdir(filepath.Join(tempDir, "overrides"), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(filepath.Join(tempDir, "overrides", "firewall.tf"), []byte("some-override"), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			output := filepath.Join(tempDir, "some-env.tgz")
+			err = export.Execute([]string{"--output", output}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			bundle, err := os.Open(output)
+			Expect(err).NotTo(HaveOccurred())
+			defer bundle.Close()
+
+			imported, _, err := storage.ReadBundle(bundle, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(imported.TerraformOverrides).To(Equal(map[string]string{"firewall.tf": "some-override"}))
+		})
+
+		It("encrypts the bundle with the key from the key file", func() {
+			output := filepath.Join(tempDir, "some-env.tgz")
+			keyFile := filepath.Join(tempDir, "some-key")
+			err := ioutil.WriteFile(keyFile, []byte("some-key\n"), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = export.Execute([]string{"--output", output, "--key-file", keyFile}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			bundle, err := os.Open(output)
+			Expect(err).NotTo(HaveOccurred())
+			defer bundle.Close()
+
+			_, _, err = storage.ReadBundle(bundle, "some-key")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("names the bundle after the env id by default", func() {
+			wd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Chdir(tempDir)).To(Succeed())
+			defer os.Chdir(wd)
+
+			err = export.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = os.Stat(filepath.Join(tempDir, "bbl-some-env-id.tgz"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the state does not exist", func() {
+				stateValidator.ValidateCall.Returns.Error = errors.New("state validator failed")
+
+				err := export.Execute([]string{}, state)
+				Expect(err).To(MatchError("state validator failed"))
+			})
+
+			It("returns an error when the flags cannot be parsed", func() {
+				err := export.Execute([]string{"--unknown-flag"}, state)
+				Expect(err).To(MatchError("flag provided but not defined: -unknown-flag"))
+			})
+
+			It("returns an error when given arguments", func() {
+				err := export.Execute([]string{"some-path"}, state)
+				Expect(err).To(MatchError("export does not accept arguments, use --output to choose where to write the bundle"))
+			})
+
+			It("returns an error when the key file cannot be read", func() {
+				err := export.Execute([]string{"--key-file", "/some/missing/key"}, state)
+				Expect(err).To(MatchError(ContainSubstring("error reading key file")))
+			})
+
+			It("returns an error when the bundle cannot be written", func() {
+				err := export.Execute([]string{"--output", "/some/missing/dir/bundle.tgz"}, state)
+				Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+			})
+		})
+	})
+})
@@ -1,6 +1,11 @@
 package commands
 
-import yaml "gopkg.in/yaml.v2"
+import (
+	"io/ioutil"
+	"os"
+
+	yaml "gopkg.in/yaml.v2"
+)
 
 func SetMarshal(f func(interface{}) ([]byte, error)) {
 	marshal = f
@@ -9,3 +14,11 @@ func SetMarshal(f func(interface{}) ([]byte, error)) {
 func ResetMarshal() {
 	marshal = yaml.Marshal
 }
+
+func SetTempFile(f func(string, string) (*os.File, error)) {
+	tempFile = f
+}
+
+func ResetTempFile() {
+	tempFile = ioutil.TempFile
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const ForceUnlockCommand = "force-unlock"
+
+type stateLockRemover interface {
+	Current() (storage.Lock, bool, error)
+	Unlock() error
+}
+
+type ForceUnlock struct {
+	logger      logger
+	stateLocker stateLockRemover
+}
+
+func NewForceUnlock(logger logger, stateLocker stateLockRemover) ForceUnlock {
+	return ForceUnlock{
+		logger:      logger,
+		stateLocker: stateLocker,
+	}
+}
+
+func (f ForceUnlock) Execute(subcommandFlags []string, state storage.State) error {
+	lock, locked, err := f.stateLocker.Current()
+	if err != nil {
+		return err
+	}
+
+	if !locked {
+		f.logger.Println("bbl-state.json is not locked")
+		return nil
+	}
+
+	err = f.stateLocker.Unlock()
+	if err != nil {
+		return err
+	}
+
+	f.logger.Println(fmt.Sprintf("removed lock held by %s", lock))
+	return nil
+}
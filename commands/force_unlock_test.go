@@ -0,0 +1,71 @@
+package commands_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ForceUnlock", func() {
+	var (
+		logger      *fakes.Logger
+		stateLocker *fakes.StateLocker
+		forceUnlock commands.ForceUnlock
+	)
+
+	BeforeEach(func() {
+		logger = &fakes.Logger{}
+		stateLocker = &fakes.StateLocker{}
+
+		forceUnlock = commands.NewForceUnlock(logger, stateLocker)
+	})
+
+	Describe("Execute", func() {
+		It("removes the lock and prints who held it", func() {
+			stateLocker.CurrentCall.Returns.Lock = storage.Lock{
+				Holder:    "some-user",
+				Host:      "some-host",
+				Command:   "up",
+				Timestamp: time.Date(2016, time.November, 1, 12, 0, 0, 0, time.UTC),
+			}
+			stateLocker.CurrentCall.Returns.Locked = true
+
+			err := forceUnlock.Execute([]string{}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stateLocker.UnlockCall.CallCount).To(Equal(1))
+			Expect(logger.PrintlnCall.Receives.Message).To(Equal(`removed lock held by some-user@some-host running "bbl up" since 2016-11-01T12:00:00Z`))
+		})
+
+		It("does nothing when the state is not locked", func() {
+			err := forceUnlock.Execute([]string{}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stateLocker.UnlockCall.CallCount).To(Equal(0))
+			Expect(logger.PrintlnCall.Receives.Message).To(Equal("bbl-state.json is not locked"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the lock cannot be read", func() {
+				stateLocker.CurrentCall.Returns.Error = errors.New("failed to read lock")
+
+				err := forceUnlock.Execute([]string{}, storage.State{})
+				Expect(err).To(MatchError("failed to read lock"))
+			})
+
+			It("returns an error when the lock cannot be removed", func() {
+				stateLocker.CurrentCall.Returns.Locked = true
+				stateLocker.UnlockCall.Returns.Error = errors.New("failed to remove lock")
+
+				err := forceUnlock.Execute([]string{}, storage.State{})
+				Expect(err).To(MatchError("failed to remove lock"))
+			})
+		})
+	})
+})
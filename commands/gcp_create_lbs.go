@@ -58,7 +58,10 @@ func (c GCPCreateLBs) Execute(config GCPCreateLBsConfig, state storage.State) er
 	}
 
 	c.logger.Step("generating terraform template")
-	var err error
+	networkCIDR, err := gcpNetworkCIDR(state)
+	if err != nil {
+		return err
+	}
 
 	var lbTemplate string
 	var cert, key []byte
@@ -86,7 +89,7 @@ func (c GCPCreateLBs) Execute(config GCPCreateLBsConfig, state storage.State) er
 		}
 	}
 
-	templateWithLB := strings.Join([]string{terraformVarsTemplate, terraformBOSHDirectorTemplate, lbTemplate}, "\n")
+	templateWithLB := strings.Join([]string{terraformVarsTemplate, terraformBOSHDirector(networkCIDR), lbTemplate}, "\n")
 	tfState, err := c.terraformExecutor.Apply(state.GCP.ServiceAccountKey, state.EnvID, state.GCP.ProjectID, state.GCP.Zone,
 		state.GCP.Region, string(cert), string(key), config.Domain, templateWithLB, state.TFState)
 	switch err.(type) {
@@ -110,24 +113,48 @@ func (c GCPCreateLBs) Execute(config GCPCreateLBsConfig, state storage.State) er
 		return err
 	}
 
-	network, err := c.terraformOutputter.Get(state.TFState, "network_name")
+	if state.NoDirector {
+		state.Outputs, err = gcpOutputs(c.terraformOutputter, state.TFState, config.LBType)
+		if err != nil {
+			return err
+		}
+	} else if err := c.updateCloudConfig(config.LBType, zones, networkCIDR, state.TFState, boshClient); err != nil {
+		return err
+	}
+
+	state.LB.Type = config.LBType
+	if config.LBType == "cf" {
+		state.LB.Cert = string(cert)
+		state.LB.Key = string(key)
+		state.LB.Domain = config.Domain
+	}
+
+	if err := c.stateStore.Set(state); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c GCPCreateLBs) updateCloudConfig(lbType string, zones []string, networkCIDR, tfState string, boshClient bosh.Client) error {
+	network, err := c.terraformOutputter.Get(tfState, "network_name")
 	if err != nil {
 		return err
 	}
 
-	subnetwork, err := c.terraformOutputter.Get(state.TFState, "subnetwork_name")
+	subnetwork, err := c.terraformOutputter.Get(tfState, "subnetwork_name")
 	if err != nil {
 		return err
 	}
 
-	internalTag, err := c.terraformOutputter.Get(state.TFState, "internal_tag_name")
+	internalTag, err := c.terraformOutputter.Get(tfState, "internal_tag_name")
 	if err != nil {
 		return err
 	}
 
 	concourseTargetPool := ""
-	if config.LBType == "concourse" {
-		concourseTargetPool, err = c.terraformOutputter.Get(state.TFState, "concourse_target_pool")
+	if lbType == "concourse" {
+		concourseTargetPool, err = c.terraformOutputter.Get(tfState, "concourse_target_pool")
 		if err != nil {
 			return err
 		}
@@ -136,16 +163,16 @@ func (c GCPCreateLBs) Execute(config GCPCreateLBsConfig, state storage.State) er
 	routerBackendService := ""
 	sshProxyTargetPool := ""
 	tcpRouterTargetPool := ""
-	if config.LBType == "cf" {
-		if routerBackendService, err = c.terraformOutputter.Get(state.TFState, "router_backend_service"); err != nil {
+	if lbType == "cf" {
+		if routerBackendService, err = c.terraformOutputter.Get(tfState, "router_backend_service"); err != nil {
 			return err
 		}
 
-		if sshProxyTargetPool, err = c.terraformOutputter.Get(state.TFState, "ssh_proxy_target_pool"); err != nil {
+		if sshProxyTargetPool, err = c.terraformOutputter.Get(tfState, "ssh_proxy_target_pool"); err != nil {
 			return err
 		}
 
-		if tcpRouterTargetPool, err = c.terraformOutputter.Get(state.TFState, "tcp_router_target_pool"); err != nil {
+		if tcpRouterTargetPool, err = c.terraformOutputter.Get(tfState, "tcp_router_target_pool"); err != nil {
 			return err
 		}
 	}
@@ -156,6 +183,7 @@ func (c GCPCreateLBs) Execute(config GCPCreateLBsConfig, state storage.State) er
 		Tags:                []string{internalTag},
 		NetworkName:         network,
 		SubnetworkName:      subnetwork,
+		NetworkCIDR:         networkCIDR,
 		ConcourseTargetPool: concourseTargetPool,
 		CFBackends: gcp.CFBackends{
 			Router:    routerBackendService,
@@ -177,17 +205,6 @@ func (c GCPCreateLBs) Execute(config GCPCreateLBsConfig, state storage.State) er
 		return err
 	}
 
-	state.LB.Type = config.LBType
-	if config.LBType == "cf" {
-		state.LB.Cert = string(cert)
-		state.LB.Key = string(key)
-		state.LB.Domain = config.Domain
-	}
-
-	if err := c.stateStore.Set(state); err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -204,6 +221,10 @@ func (GCPCreateLBs) checkFastFails(config GCPCreateLBsConfig, state storage.Stat
 		return fmt.Errorf("iaas type must be gcp")
 	}
 
+	if state.NoDirector {
+		return nil
+	}
+
 	_, err := boshClient.Info()
 	if err != nil {
 		return BBLNotFound
@@ -848,6 +848,32 @@ var _ = Describe("GCPCreateLBs", func() {
 			Expect(boshClient.UpdateCloudConfigCall.CallCount).To(Equal(1))
 		})
 
+		Context("when the environment has no director", func() {
+			It("applies terraform without updating the cloud config and records the outputs", func() {
+				boshClient.InfoCall.Returns.Error = errors.New("director not found")
+				terraformOutputter.GetCall.Stub = func(output string) (string, error) {
+					return "some-" + output, nil
+				}
+
+				err := command.Execute(commands.GCPCreateLBsConfig{
+					LBType: "concourse",
+				}, storage.State{
+					IAAS:       "gcp",
+					NoDirector: true,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(1))
+				Expect(boshClient.UpdateCloudConfigCall.CallCount).To(Equal(0))
+
+				state := stateStore.SetCall.Receives.State
+				Expect(state.LB.Type).To(Equal("concourse"))
+				Expect(state.Outputs).To(HaveKeyWithValue("concourse_target_pool", "some-concourse_target_pool"))
+				Expect(state.Outputs).To(HaveKeyWithValue("concourse_lb_ip", "some-concourse_lb_ip"))
+				Expect(state.Outputs).To(HaveKeyWithValue("network_name", "some-network_name"))
+			})
+		})
+
 		It("no-ops if SkipIfExists is supplied and the LBType does not change", func() {
 			err := command.Execute(commands.GCPCreateLBsConfig{
 				LBType:       "concourse",
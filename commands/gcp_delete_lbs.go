@@ -34,6 +34,58 @@ func NewGCPDeleteLBs(terraformOutputter terraformOutputter, cloudConfigGenerator
 }
 
 func (g GCPDeleteLBs) Execute(state storage.State) error {
+	networkCIDR, err := gcpNetworkCIDR(state)
+	if err != nil {
+		return err
+	}
+
+	if !state.NoDirector {
+		if err := g.updateCloudConfig(state, networkCIDR); err != nil {
+			return err
+		}
+	}
+
+	template := strings.Join([]string{terraformVarsTemplate, terraformBOSHDirector(networkCIDR)}, "\n")
+
+	g.logger.Step("generating terraform template")
+	tfState, err := g.terraformExecutor.Apply(state.GCP.ServiceAccountKey, state.EnvID, state.GCP.ProjectID,
+		state.GCP.Zone, state.GCP.Region, "", "", "", template, state.TFState)
+
+	switch err.(type) {
+	case terraform.TerraformApplyError:
+		taErr := err.(terraform.TerraformApplyError)
+		state.TFState = taErr.TFState()
+		if setErr := g.stateStore.Set(state); setErr != nil {
+			errorList := helpers.Errors{}
+			errorList.Add(err)
+			errorList.Add(setErr)
+			return errorList
+		}
+		return err
+	case error:
+		return err
+	}
+	g.logger.Step("finished applying terraform template")
+
+	state.TFState = tfState
+
+	if state.NoDirector {
+		state.Outputs, err = gcpOutputs(g.terraformOutputter, state.TFState, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	state.Stack.LBType = ""
+	err = g.stateStore.Set(state)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g GCPDeleteLBs) updateCloudConfig(state storage.State, networkCIDR string) error {
 	azs := g.zones.Get(state.GCP.Region)
 	networkName, err := g.terraformOutputter.Get(state.TFState, "network_name")
 	if err != nil {
@@ -56,6 +108,7 @@ func (g GCPDeleteLBs) Execute(state storage.State) error {
 		Tags:           []string{internalTagName},
 		NetworkName:    networkName,
 		SubnetworkName: subnetworkName,
+		NetworkCIDR:    networkCIDR,
 	})
 
 	boshClient := g.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword)
@@ -71,35 +124,5 @@ func (g GCPDeleteLBs) Execute(state storage.State) error {
 		return err
 	}
 
-	template := strings.Join([]string{terraformVarsTemplate, terraformBOSHDirectorTemplate}, "\n")
-
-	g.logger.Step("generating terraform template")
-	tfState, err := g.terraformExecutor.Apply(state.GCP.ServiceAccountKey, state.EnvID, state.GCP.ProjectID,
-		state.GCP.Zone, state.GCP.Region, "", "", "", template, state.TFState)
-
-	switch err.(type) {
-	case terraform.TerraformApplyError:
-		taErr := err.(terraform.TerraformApplyError)
-		state.TFState = taErr.TFState()
-		if setErr := g.stateStore.Set(state); setErr != nil {
-			errorList := helpers.Errors{}
-			errorList.Add(err)
-			errorList.Add(setErr)
-			return errorList
-		}
-		return err
-	case error:
-		return err
-	}
-	g.logger.Step("finished applying terraform template")
-
-	state.TFState = tfState
-
-	state.Stack.LBType = ""
-	err = g.stateStore.Set(state)
-	if err != nil {
-		return err
-	}
-
 	return nil
 }
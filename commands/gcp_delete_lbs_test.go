@@ -116,6 +116,7 @@ var _ = Describe("GCPDeleteLBs", func() {
 				Tags:           []string{"some-internal-tag"},
 				NetworkName:    "some-network-name",
 				SubnetworkName: "some-subnetwork-name",
+				NetworkCIDR:    "10.0.0.0/16",
 			}))
 
 			Expect(boshClientProvider.ClientCall.Receives.DirectorAddress).To(Equal("some-director-address"))
@@ -164,6 +165,30 @@ var _ = Describe("GCPDeleteLBs", func() {
 			}))
 		})
 
+		Context("when the environment has no director", func() {
+			It("applies terraform without updating the cloud config and records the outputs", func() {
+				terraformOutputter.GetCall.Stub = func(output string) (string, error) {
+					return "some-" + output, nil
+				}
+
+				err := command.Execute(storage.State{
+					IAAS:       "gcp",
+					NoDirector: true,
+					LB: storage.LB{
+						Type: "concourse",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(1))
+				Expect(boshClient.UpdateCloudConfigCall.CallCount).To(Equal(0))
+
+				state := stateStore.SetCall.Receives.State
+				Expect(state.Outputs).To(HaveKeyWithValue("network_name", "some-network_name"))
+				Expect(state.Outputs).NotTo(HaveKey("concourse_target_pool"))
+			})
+		})
+
 		Context("state manipulation", func() {
 			It("removes the lb from the state", func() {
 				err := command.Execute(storage.State{
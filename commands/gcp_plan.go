@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cloudfoundry/bosh-bootloader/boshinit"
+	"github.com/cloudfoundry/bosh-bootloader/cloudconfig/gcp"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+type GCPPlan struct {
+	terraformExecutor     terraformExecutor
+	terraformOutputter    terraformOutputter
+	boshManifestGenerator boshManifestGenerator
+	stringGenerator       stringGenerator
+	cloudConfigGenerator  gcpCloudConfigGenerator
+	boshClientProvider    boshClientProvider
+	zones                 zones
+	stdout                io.Writer
+}
+
+func NewGCPPlan(terraformExecutor terraformExecutor, terraformOutputter terraformOutputter,
+	boshManifestGenerator boshManifestGenerator, stringGenerator stringGenerator,
+	cloudConfigGenerator gcpCloudConfigGenerator, boshClientProvider boshClientProvider,
+	zones zones, stdout io.Writer) GCPPlan {
+
+	return GCPPlan{
+		terraformExecutor:     terraformExecutor,
+		terraformOutputter:    terraformOutputter,
+		boshManifestGenerator: boshManifestGenerator,
+		stringGenerator:       stringGenerator,
+		cloudConfigGenerator:  cloudConfigGenerator,
+		boshClientProvider:    boshClientProvider,
+		zones:                 zones,
+		stdout:                stdout,
+	}
+}
+
+func (p GCPPlan) Execute(state storage.State) error {
+	zones := p.zones.Get(state.GCP.Region)
+
+	networkCIDR, err := gcpNetworkCIDR(state)
+	if err != nil {
+		return err
+	}
+
+	planOutput, err := p.terraformExecutor.Plan(state.GCP.ServiceAccountKey, state.EnvID, state.GCP.ProjectID, state.GCP.Zone,
+		state.GCP.Region, state.LB.Cert, state.LB.Key, state.LB.Domain, gcpUpTemplate(state.LB.Type, zones, networkCIDR), state.TFState)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(p.stdout, planOutput)
+
+	if state.BOSH.IsEmpty() || state.TFState == "" {
+		err = printSectionChanges(p.stdout, "bosh-init manifest", "", "")
+		if err != nil {
+			return err
+		}
+
+		return printSectionChanges(p.stdout, "cloud config", "", "")
+	}
+
+	outputs, err := getGCPTerraformOutputs(p.terraformOutputter, state.TFState)
+	if err != nil {
+		return err
+	}
+
+	deployInput, err := boshinit.NewDeployInput(state, outputs.infrastructureConfiguration(state), p.stringGenerator, state.EnvID, "gcp")
+	if err != nil {
+		return err
+	}
+
+	manifest, err := p.boshManifestGenerator.Manifest(deployInput)
+	if err != nil {
+		return err
+	}
+
+	err = printSectionChanges(p.stdout, "bosh-init manifest", state.BOSH.Manifest, manifest)
+	if err != nil {
+		return err
+	}
+
+	cloudConfig, err := p.cloudConfigGenerator.Generate(gcp.CloudConfigInput{
+		AZs:            zones,
+		Tags:           []string{outputs.InternalTag},
+		NetworkName:    outputs.NetworkName,
+		SubnetworkName: outputs.SubnetworkName,
+		NetworkCIDR:    networkCIDR,
+	})
+	if err != nil {
+		return err
+	}
+
+	cloudConfigYAML, err := marshal(cloudConfig)
+	if err != nil {
+		return err
+	}
+
+	boshClient := p.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername, state.BOSH.DirectorPassword)
+	currentCloudConfig, err := boshClient.CloudConfig()
+	if err != nil {
+		return err
+	}
+
+	return printSectionChanges(p.stdout, "cloud config", string(currentCloudConfig), string(cloudConfigYAML))
+}
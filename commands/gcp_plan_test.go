@@ -0,0 +1,163 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/cloudconfig/gcp"
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GCPPlan", func() {
+	var (
+		command               commands.GCPPlan
+		terraformExecutor     *fakes.TerraformExecutor
+		terraformOutputter    *fakes.TerraformOutputter
+		boshManifestGenerator *fakes.BOSHManifestGenerator
+		stringGenerator       *fakes.StringGenerator
+		cloudConfigGenerator  *fakes.GCPCloudConfigGenerator
+		boshClientProvider    *fakes.BOSHClientProvider
+		boshClient            *fakes.BOSHClient
+		zones                 *fakes.Zones
+		stdout                *bytes.Buffer
+		state                 storage.State
+	)
+
+	BeforeEach(func() {
+		terraformExecutor = &fakes.TerraformExecutor{}
+		terraformOutputter = &fakes.TerraformOutputter{}
+		boshManifestGenerator = &fakes.BOSHManifestGenerator{}
+		stringGenerator = &fakes.StringGenerator{}
+		cloudConfigGenerator = &fakes.GCPCloudConfigGenerator{}
+		boshClient = &fakes.BOSHClient{}
+		boshClientProvider = &fakes.BOSHClientProvider{}
+		boshClientProvider.ClientCall.Returns.Client = boshClient
+		zones = &fakes.Zones{}
+		stdout = bytes.NewBuffer([]byte{})
+
+		zones.GetCall.Returns.Zones = []string{"some-zone-1", "some-zone-2"}
+		terraformOutputter.GetCall.Stub = func(output string) (string, error) {
+			return "some-" + output, nil
+		}
+		boshManifestGenerator.ManifestCall.Returns.Manifest = "name: bosh\njobs: []\n"
+		cloudConfigGenerator.GenerateCall.Returns.CloudConfig = gcp.CloudConfig{
+			AZs: []gcp.AZ{{Name: "z1"}},
+		}
+		boshClient.CloudConfigCall.Returns.CloudConfig = []byte("azs: []\n")
+		terraformExecutor.PlanCall.Returns.Output = "Plan: 0 to add, 1 to change, 0 to destroy.\n"
+
+		state = storage.State{
+			IAAS:  "gcp",
+			EnvID: "some-env-id",
+			GCP: storage.GCP{
+				ServiceAccountKey: "some-service-account-key",
+				ProjectID:         "some-project-id",
+				Zone:              "some-zone",
+				Region:            "some-region",
+			},
+			LB: storage.LB{
+				Type: "concourse",
+			},
+			TFState: "some-tf-state",
+			BOSH: storage.BOSH{
+				DirectorAddress:  "some-director-address",
+				DirectorUsername: "some-director-username",
+				DirectorPassword: "some-director-password",
+				Manifest:         "name: bosh\njobs: []\n",
+			},
+		}
+
+		command = commands.NewGCPPlan(terraformExecutor, terraformOutputter, boshManifestGenerator, stringGenerator,
+			cloudConfigGenerator, boshClientProvider, zones, stdout)
+	})
+
+	It("runs terraform plan and prints whether the manifest and cloud config would change", func() {
+		err := command.Execute(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(zones.GetCall.Receives.Region).To(Equal("some-region"))
+
+		Expect(terraformExecutor.PlanCall.CallCount).To(Equal(1))
+		Expect(terraformExecutor.PlanCall.Receives.Credentials).To(Equal("some-service-account-key"))
+		Expect(terraformExecutor.PlanCall.Receives.EnvID).To(Equal("some-env-id"))
+		Expect(terraformExecutor.PlanCall.Receives.ProjectID).To(Equal("some-project-id"))
+		Expect(terraformExecutor.PlanCall.Receives.Zone).To(Equal("some-zone"))
+		Expect(terraformExecutor.PlanCall.Receives.Region).To(Equal("some-region"))
+		Expect(terraformExecutor.PlanCall.Receives.Template).To(ContainSubstring("concourse_target_pool"))
+		Expect(terraformExecutor.PlanCall.Receives.TFState).To(Equal("some-tf-state"))
+		Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(0))
+
+		Expect(boshManifestGenerator.ManifestCall.Receives.Input.IAAS).To(Equal("gcp"))
+		Expect(boshManifestGenerator.ManifestCall.Receives.Input.InfrastructureConfiguration.ExternalIP).To(Equal("some-external_ip"))
+		Expect(boshManifestGenerator.ManifestCall.Receives.Input.InfrastructureConfiguration.GCP.NetworkName).To(Equal("some-network_name"))
+
+		Expect(cloudConfigGenerator.GenerateCall.Receives.CloudConfigInput).To(Equal(gcp.CloudConfigInput{
+			AZs:            []string{"some-zone-1", "some-zone-2"},
+			Tags:           []string{"some-internal_tag_name"},
+			NetworkName:    "some-network_name",
+			SubnetworkName: "some-subnetwork_name",
+			NetworkCIDR:    "10.0.0.0/16",
+		}))
+
+		Expect(boshClientProvider.ClientCall.Receives.DirectorAddress).To(Equal("some-director-address"))
+		Expect(boshClient.UpdateCloudConfigCall.CallCount).To(Equal(0))
+
+		Expect(stdout.String()).To(Equal("Plan: 0 to add, 1 to change, 0 to destroy.\nbosh-init manifest: no changes\ncloud config: changes to azs\n"))
+	})
+
+	It("reports that the director will be created when there is no terraform state", func() {
+		state.TFState = ""
+		state.BOSH = storage.BOSH{}
+
+		err := command.Execute(state)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(terraformExecutor.PlanCall.CallCount).To(Equal(1))
+		Expect(stdout.String()).To(HaveSuffix("bosh-init manifest: will be created\ncloud config: will be created\n"))
+		Expect(terraformOutputter.GetCall.CallCount).To(Equal(0))
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when terraform plan fails", func() {
+			terraformExecutor.PlanCall.Returns.Error = errors.New("failed to plan")
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to plan"))
+		})
+
+		It("returns an error when a terraform output cannot be read", func() {
+			terraformOutputter.GetCall.Stub = func(output string) (string, error) {
+				return "", errors.New("failed to get output")
+			}
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to get output"))
+		})
+
+		It("returns an error when the manifest cannot be generated", func() {
+			boshManifestGenerator.ManifestCall.Returns.Error = errors.New("failed to generate manifest")
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to generate manifest"))
+		})
+
+		It("returns an error when the cloud config cannot be generated", func() {
+			cloudConfigGenerator.GenerateCall.Returns.Error = errors.New("failed to generate cloud config")
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to generate cloud config"))
+		})
+
+		It("returns an error when the current cloud config cannot be retrieved", func() {
+			boshClient.CloudConfigCall.Returns.Error = errors.New("failed to get cloud config")
+
+			err := command.Execute(state)
+			Expect(err).To(MatchError("failed to get cloud config"))
+		})
+	})
+})
@@ -1,5 +1,7 @@
 package commands
 
+import "fmt"
+
 const terraformVarsTemplate = `variable "project_id" {
 	type = "string"
 }
@@ -57,7 +59,7 @@ resource "google_compute_network" "bbl-network" {
 
 resource "google_compute_subnetwork" "bbl-subnet" {
   name			= "${var.env_id}-subnet"
-  ip_cidr_range = "10.0.0.0/16"
+  ip_cidr_range = "%s"
   network		= "${google_compute_network.bbl-network.self_link}"
 }
 
@@ -103,6 +105,10 @@ resource "google_compute_firewall" "internal" {
 }
 `
 
+func terraformBOSHDirector(networkCIDR string) string {
+	return fmt.Sprintf(terraformBOSHDirectorTemplate, networkCIDR)
+}
+
 const terraformConcourseLBTemplate = `output "concourse_target_pool" {
 	value = "${google_compute_target_pool.target-pool.name}"
 }
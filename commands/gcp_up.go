@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"strings"
 
@@ -32,6 +33,7 @@ type GCPUp struct {
 	terraformOutputter   terraformOutputter
 	terraformExecutor    terraformExecutor
 	zones                zones
+	stdin                io.Reader
 }
 
 type GCPUpConfig struct {
@@ -39,6 +41,15 @@ type GCPUpConfig struct {
 	ProjectID             string
 	Zone                  string
 	Region                string
+	NoConfirm             bool
+	FromPhase             string
+	NoDirector            bool
+	OpsFiles              []storage.OpsFile
+	ClearOpsFiles         bool
+	VersionOverrides      []storage.VersionOverride
+	AirGapped             bool
+	DirectorSizing        storage.DirectorSizing
+	Network               storage.Network
 }
 
 type gcpCloudConfigGenerator interface {
@@ -59,6 +70,7 @@ type gcpProvider interface {
 
 type terraformExecutor interface {
 	Apply(credentials, envID, projectID, zone, region, certPath, keyPath, domain, template, tfState string) (string, error)
+	Plan(credentials, envID, projectID, zone, region, certPath, keyPath, domain, template, tfState string) (string, error)
 	Destroy(serviceAccountKey, envID, projectID, zone, region, template, tfState string) (string, error)
 }
 
@@ -72,7 +84,7 @@ type zones interface {
 
 func NewGCPUp(stateStore stateStore, keyPairUpdater keyPairUpdater, gcpProvider gcpProvider, terraformExecutor terraformExecutor, boshDeployer boshDeployer,
 	stringGenerator stringGenerator, logger logger, boshClientProvider boshClientProvider, cloudConfigGenerator gcpCloudConfigGenerator,
-	terraformOutputter terraformOutputter, zones zones) GCPUp {
+	terraformOutputter terraformOutputter, zones zones, stdin io.Reader) GCPUp {
 	return GCPUp{
 		stateStore:           stateStore,
 		keyPairUpdater:       keyPairUpdater,
@@ -85,10 +97,13 @@ func NewGCPUp(stateStore stateStore, keyPairUpdater keyPairUpdater, gcpProvider
 		cloudConfigGenerator: cloudConfigGenerator,
 		terraformOutputter:   terraformOutputter,
 		zones:                zones,
+		stdin:                stdin,
 	}
 }
 
 func (u GCPUp) Execute(upConfig GCPUpConfig, state storage.State) error {
+	previousState := state
+
 	if !upConfig.empty() {
 		gcpDetails, err := u.parseUpConfig(upConfig)
 		if err != nil {
@@ -108,142 +123,196 @@ func (u GCPUp) Execute(upConfig GCPUpConfig, state storage.State) error {
 		return err
 	}
 
-	if err := u.stateStore.Set(state); err != nil {
-		return err
+	if state.NoDirector && !upConfig.NoDirector {
+		u.logger.Step("deploying a director to the infrastructure-only environment")
+		state.Outputs = nil
 	}
+	state.NoDirector = upConfig.NoDirector
 
-	if err := u.gcpProvider.SetConfig(state.GCP.ServiceAccountKey, state.GCP.ProjectID, state.GCP.Zone); err != nil {
-		return err
+	if upConfig.ClearOpsFiles {
+		state.OpsFiles = nil
 	}
 
-	if state.KeyPair.IsEmpty() {
-		keyPair, err := u.keyPairUpdater.Update()
-		if err != nil {
-			return err
-		}
-		state.KeyPair = keyPair
-		if err := u.stateStore.Set(state); err != nil {
-			return err
-		}
+	if len(upConfig.OpsFiles) > 0 {
+		state.OpsFiles = upConfig.OpsFiles
 	}
 
-	var template string
-	zones := u.zones.Get(state.GCP.Region)
-	switch state.LB.Type {
-	case "concourse":
-		template = strings.Join([]string{terraformVarsTemplate, terraformBOSHDirectorTemplate, terraformConcourseLBTemplate}, "\n")
-	case "cf":
-		terraformCFLBBackendService := generateBackendServiceTerraform(len(zones))
-		instanceGroups := generateInstanceGroups(zones)
-		template = strings.Join([]string{terraformVarsTemplate, terraformBOSHDirectorTemplate, terraformCFLBTemplate, instanceGroups, terraformCFLBBackendService}, "\n")
-	default:
-		template = strings.Join([]string{terraformVarsTemplate, terraformBOSHDirectorTemplate}, "\n")
+	state.VersionOverrides = mergeVersionOverrides(state.VersionOverrides, upConfig.VersionOverrides)
+
+	if upConfig.AirGapped {
+		state.AirGapped = true
 	}
 
-	tfState, err := u.terraformExecutor.Apply(state.GCP.ServiceAccountKey,
-		state.EnvID, state.GCP.ProjectID, state.GCP.Zone, state.GCP.Region, state.LB.Cert, state.LB.Key, state.LB.Domain,
-		template, state.TFState,
-	)
-	switch err.(type) {
-	case terraform.TerraformApplyError:
-		taErr := err.(terraform.TerraformApplyError)
-		state.TFState = taErr.TFState()
-		if setErr := u.stateStore.Set(state); setErr != nil {
-			errorList := helpers.Errors{}
-			errorList.Add(err)
-			errorList.Add(setErr)
-			return errorList
-		}
+	directorSizing, err := mergeDirectorSizing(state, upConfig.DirectorSizing)
+	if err != nil {
 		return err
-	case error:
+	}
+	state.DirectorSizing = directorSizing
+
+	state.Network, err = mergeNetwork(state, upConfig.Network, state.TFState != "")
+	if err != nil {
 		return err
 	}
 
-	state.TFState = tfState
 	if err := u.stateStore.Set(state); err != nil {
 		return err
 	}
 
-	externalIP, err := u.terraformOutputter.Get(state.TFState, "external_ip")
-	if err != nil {
+	if err := u.gcpProvider.SetConfig(state.GCP.ServiceAccountKey, state.GCP.ProjectID, state.GCP.Zone); err != nil {
 		return err
 	}
 
-	networkName, err := u.terraformOutputter.Get(state.TFState, "network_name")
+	phases := newUpPhases(u.logger, upConfig.FromPhase)
+
+	state, skip, err := phases.start(state, KeyPairPhase, !state.KeyPair.IsEmpty(), state.GCP.ProjectID)
 	if err != nil {
 		return err
 	}
-	subnetworkName, err := u.terraformOutputter.Get(state.TFState, "subnetwork_name")
-	if err != nil {
-		return err
+
+	if !skip {
+		if state.KeyPair.IsEmpty() {
+			keyPair, err := u.keyPairUpdater.Update()
+			if err != nil {
+				return err
+			}
+			state.KeyPair = keyPair
+		}
+
+		state = phases.complete(state, KeyPairPhase)
+		if err := u.stateStore.Set(state); err != nil {
+			return err
+		}
 	}
-	boshTag, err := u.terraformOutputter.Get(state.TFState, "bosh_open_tag_name")
+
+	networkCIDR, err := gcpNetworkCIDR(state)
 	if err != nil {
 		return err
 	}
-	internalTag, err := u.terraformOutputter.Get(state.TFState, "internal_tag_name")
+
+	zones := u.zones.Get(state.GCP.Region)
+	template := gcpUpTemplate(state.LB.Type, zones, networkCIDR)
+
+	state, skip, err = phases.start(state, InfrastructurePhase, state.TFState != "",
+		template, state.EnvID, state.GCP.ProjectID, state.GCP.Zone, state.GCP.Region, state.LB.Cert, state.LB.Key, state.LB.Domain)
 	if err != nil {
 		return err
 	}
-	directorAddress, err := u.terraformOutputter.Get(state.TFState, "director_address")
-	if err != nil {
-		return err
+
+	if !skip {
+		if state.TFState != "" && !upConfig.NoConfirm {
+			planOutput, err := u.terraformExecutor.Plan(state.GCP.ServiceAccountKey, state.EnvID, state.GCP.ProjectID, state.GCP.Zone,
+				state.GCP.Region, state.LB.Cert, state.LB.Key, state.LB.Domain, template, state.TFState)
+			if err != nil {
+				return err
+			}
+
+			if terraform.PlanDestroyCount(planOutput) > 0 {
+				u.logger.Println(planOutput)
+				if !confirm(u.logger, u.stdin, fmt.Sprintf("Updating %q will replace or destroy the resources above. Do you want to continue?", state.EnvID)) {
+					if err := u.stateStore.Set(restoreUpConfig(state, previousState)); err != nil {
+						return err
+					}
+					return UpCancelled
+				}
+			}
+		}
+
+		tfState, err := u.terraformExecutor.Apply(state.GCP.ServiceAccountKey,
+			state.EnvID, state.GCP.ProjectID, state.GCP.Zone, state.GCP.Region, state.LB.Cert, state.LB.Key, state.LB.Domain,
+			template, state.TFState,
+		)
+		switch err.(type) {
+		case terraform.TerraformApplyError:
+			taErr := err.(terraform.TerraformApplyError)
+			state.TFState = taErr.TFState()
+			if setErr := u.stateStore.Set(state); setErr != nil {
+				errorList := helpers.Errors{}
+				errorList.Add(err)
+				errorList.Add(setErr)
+				return errorList
+			}
+			return err
+		case error:
+			return err
+		}
+
+		state.TFState = tfState
+		state = phases.complete(state, InfrastructurePhase)
+		if err := u.stateStore.Set(state); err != nil {
+			return err
+		}
 	}
 
-	infrastructureConfiguration := boshinit.InfrastructureConfiguration{
-		ExternalIP: externalIP,
-		GCP: boshinit.InfrastructureConfigurationGCP{
-			Zone:           state.GCP.Zone,
-			NetworkName:    networkName,
-			SubnetworkName: subnetworkName,
-			BOSHTag:        boshTag,
-			InternalTag:    internalTag,
-			Project:        state.GCP.ProjectID,
-			JsonKey:        state.GCP.ServiceAccountKey,
-		},
+	if state.NoDirector {
+		state.Outputs, err = gcpOutputs(u.terraformOutputter, state.TFState, state.LB.Type)
+		if err != nil {
+			return err
+		}
+
+		state.Checkpoints = nil
+		return u.stateStore.Set(state)
 	}
 
-	deployInput, err := boshinit.NewDeployInput(state, infrastructureConfiguration, u.stringGenerator, state.EnvID, "gcp")
+	outputs, err := getGCPTerraformOutputs(u.terraformOutputter, state.TFState)
 	if err != nil {
 		return err
 	}
 
-	deployOutput, err := u.boshDeployer.Deploy(deployInput)
+	infrastructureConfiguration := outputs.infrastructureConfiguration(state)
+
+	state, skip, err = phases.start(state, DirectorPhase, !state.BOSH.IsEmpty(), infrastructureConfiguration, state.EnvID, state.OpsFiles, state.VersionOverrides, state.DirectorSizing, state.Network)
 	if err != nil {
 		return err
 	}
 
-	if state.BOSH.IsEmpty() {
-		state.BOSH = storage.BOSH{
-			DirectorName:           deployInput.DirectorName,
-			DirectorAddress:        directorAddress,
-			DirectorUsername:       deployInput.DirectorUsername,
-			DirectorPassword:       deployInput.DirectorPassword,
-			DirectorSSLCA:          string(deployOutput.DirectorSSLKeyPair.CA),
-			DirectorSSLCertificate: string(deployOutput.DirectorSSLKeyPair.Certificate),
-			DirectorSSLPrivateKey:  string(deployOutput.DirectorSSLKeyPair.PrivateKey),
-			Credentials:            deployOutput.Credentials,
+	if !skip {
+		deployInput, err := boshinit.NewDeployInput(state, infrastructureConfiguration, u.stringGenerator, state.EnvID, "gcp")
+		if err != nil {
+			return err
+		}
+
+		deployOutput, err := u.boshDeployer.Deploy(deployInput)
+		switch err.(type) {
+		case boshinit.BOSHInitError:
+			state = updateBOSHState(state, outputs.DirectorAddress, deployInput, deployOutput)
+			if setErr := u.stateStore.Set(state); setErr != nil {
+				errorList := helpers.Errors{}
+				errorList.Add(err)
+				errorList.Add(setErr)
+				return errorList
+			}
+			return err
+		case error:
+			return err
 		}
-	}
 
-	state.BOSH.State = deployOutput.BOSHInitState
-	state.BOSH.Manifest = deployOutput.BOSHInitManifest
+		state = updateBOSHState(state, outputs.DirectorAddress, deployInput, deployOutput)
+		state = phases.complete(state, DirectorPhase)
 
-	err = u.stateStore.Set(state)
-	if err != nil {
-		return err
+		err = u.stateStore.Set(state)
+		if err != nil {
+			return err
+		}
 	}
 
 	boshClient := u.boshClientProvider.Client(state.BOSH.DirectorAddress, state.BOSH.DirectorUsername,
 		state.BOSH.DirectorPassword)
 
-	u.logger.Step("generating cloud config")
-	cloudConfig, err := u.cloudConfigGenerator.Generate(gcp.CloudConfigInput{
+	cloudConfigInput := gcp.CloudConfigInput{
 		AZs:            zones,
-		Tags:           []string{internalTag},
-		NetworkName:    networkName,
-		SubnetworkName: subnetworkName,
-	})
+		Tags:           []string{outputs.InternalTag},
+		NetworkName:    outputs.NetworkName,
+		SubnetworkName: outputs.SubnetworkName,
+		NetworkCIDR:    networkCIDR,
+	}
+
+	state, _, err = phases.start(state, CloudConfigPhase, false, cloudConfigInput)
+	if err != nil {
+		return err
+	}
+
+	u.logger.Step("generating cloud config")
+	cloudConfig, err := u.cloudConfigGenerator.Generate(cloudConfigInput)
 	if err != nil {
 		return err
 	}
@@ -258,7 +327,104 @@ func (u GCPUp) Execute(upConfig GCPUpConfig, state storage.State) error {
 		return err
 	}
 
-	return nil
+	state.Checkpoints = nil
+
+	return u.stateStore.Set(state)
+}
+
+func gcpUpTemplate(lbType string, zones []string, networkCIDR string) string {
+	switch lbType {
+	case "concourse":
+		return strings.Join([]string{terraformVarsTemplate, terraformBOSHDirector(networkCIDR), terraformConcourseLBTemplate}, "\n")
+	case "cf":
+		terraformCFLBBackendService := generateBackendServiceTerraform(len(zones))
+		instanceGroups := generateInstanceGroups(zones)
+		return strings.Join([]string{terraformVarsTemplate, terraformBOSHDirector(networkCIDR), terraformCFLBTemplate, instanceGroups, terraformCFLBBackendService}, "\n")
+	default:
+		return strings.Join([]string{terraformVarsTemplate, terraformBOSHDirector(networkCIDR)}, "\n")
+	}
+}
+
+func gcpNetworkCIDR(state storage.State) (string, error) {
+	layout, err := networkLayout(state.Network)
+	if err != nil {
+		return "", err
+	}
+
+	return layout.CIDR(), nil
+}
+
+type gcpTerraformOutputs struct {
+	ExternalIP      string
+	NetworkName     string
+	SubnetworkName  string
+	BOSHTag         string
+	InternalTag     string
+	DirectorAddress string
+}
+
+func getGCPTerraformOutputs(terraformOutputter terraformOutputter, tfState string) (gcpTerraformOutputs, error) {
+	var (
+		outputs gcpTerraformOutputs
+		err     error
+	)
+
+	for _, output := range []struct {
+		name  string
+		value *string
+	}{
+		{"external_ip", &outputs.ExternalIP},
+		{"network_name", &outputs.NetworkName},
+		{"subnetwork_name", &outputs.SubnetworkName},
+		{"bosh_open_tag_name", &outputs.BOSHTag},
+		{"internal_tag_name", &outputs.InternalTag},
+		{"director_address", &outputs.DirectorAddress},
+	} {
+		*output.value, err = terraformOutputter.Get(tfState, output.name)
+		if err != nil {
+			return gcpTerraformOutputs{}, err
+		}
+	}
+
+	return outputs, nil
+}
+
+func gcpOutputs(terraformOutputter terraformOutputter, tfState, lbType string) (map[string]string, error) {
+	names := []string{"external_ip", "network_name", "subnetwork_name", "bosh_open_tag_name", "internal_tag_name", "director_address"}
+
+	switch lbType {
+	case "concourse":
+		names = append(names, "concourse_target_pool", "concourse_lb_ip")
+	case "cf":
+		names = append(names, "router_backend_service", "router_lb_ip", "ssh_proxy_target_pool", "ssh_proxy_lb_ip",
+			"tcp_router_target_pool", "tcp_router_lb_ip")
+	}
+
+	outputs := map[string]string{}
+	for _, name := range names {
+		value, err := terraformOutputter.Get(tfState, name)
+		if err != nil {
+			return nil, err
+		}
+		outputs[name] = value
+	}
+
+	return outputs, nil
+}
+
+func (o gcpTerraformOutputs) infrastructureConfiguration(state storage.State) boshinit.InfrastructureConfiguration {
+	return boshinit.InfrastructureConfiguration{
+		ExternalIP: o.ExternalIP,
+		GCP: boshinit.InfrastructureConfigurationGCP{
+			Zone:           state.GCP.Zone,
+			NetworkName:    o.NetworkName,
+			SubnetworkName: o.SubnetworkName,
+			BOSHTag:        o.BOSHTag,
+			InternalTag:    o.InternalTag,
+			Project:        state.GCP.ProjectID,
+			JsonKey:        state.GCP.ServiceAccountKey,
+		},
+	}
 }
 
 func (u GCPUp) validateState(state storage.State) error {
@@ -1,6 +1,7 @@
 package commands_test
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -34,6 +35,7 @@ var _ = Describe("gcp up", func() {
 		gcpCloudConfigGenerator *fakes.GCPCloudConfigGenerator
 		logger                  *fakes.Logger
 		zones                   *fakes.Zones
+		stdin                   *bytes.Buffer
 		boshInitCredentials     map[string]string
 
 		serviceAccountKeyPath     string
@@ -109,8 +111,10 @@ var _ = Describe("gcp up", func() {
 			}
 		}
 
+		stdin = bytes.NewBuffer([]byte{})
+
 		gcpUp = commands.NewGCPUp(stateStore, keyPairUpdater, gcpClientProvider, terraformExecutor, boshDeployer,
-			stringGenerator, logger, boshClientProvider, gcpCloudConfigGenerator, terraformOutputter, zones)
+			stringGenerator, logger, boshClientProvider, gcpCloudConfigGenerator, terraformOutputter, zones, stdin)
 
 		tempFile, err := ioutil.TempFile("", "gcpServiceAccountKey")
 		Expect(err).NotTo(HaveOccurred())
@@ -219,6 +223,399 @@ var _ = Describe("gcp up", func() {
 				Expect(stateStore.SetCall.Receives.State.TFState).To(Equal("some-tf-state"))
 
 			})
+
+			Context("confirmation", func() {
+				var state storage.State
+
+				BeforeEach(func() {
+					state = storage.State{
+						IAAS: "gcp",
+						GCP: storage.GCP{
+							ServiceAccountKey: serviceAccountKey,
+							ProjectID:         "some-project-id",
+							Zone:              "some-zone",
+							Region:            "us-west1",
+						},
+						EnvID:   "bbl-lake-time:stamp",
+						TFState: "some-existing-tf-state",
+					}
+				})
+
+				Context("when the plan destroys resources", func() {
+					BeforeEach(func() {
+						terraformExecutor.PlanCall.Returns.Output = "-/+ google_compute_address.bosh-external-ip\n\nPlan: 1 to add, 0 to change, 1 to destroy.\n"
+					})
+
+					It("prints the plan and asks for confirmation", func() {
+						stdin.Write([]byte("yes\n"))
+
+						err := gcpUp.Execute(commands.GCPUpConfig{}, state)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(terraformExecutor.PlanCall.CallCount).To(Equal(1))
+						Expect(terraformExecutor.PlanCall.Receives.TFState).To(Equal("some-existing-tf-state"))
+						Expect(terraformExecutor.PlanCall.Receives.Template).To(Equal(expectedTerraformTemplate))
+						Expect(logger.PrintlnCall.Receives.Message).To(Equal("-/+ google_compute_address.bosh-external-ip\n\nPlan: 1 to add, 0 to change, 1 to destroy.\n"))
+						Expect(logger.PromptCall.Receives.Message).To(Equal(`Updating "bbl-lake-time:stamp" will replace or destroy the resources above. Do you want to continue?`))
+						Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(1))
+					})
+
+					It("does not apply when the user declines", func() {
+						stdin.Write([]byte("no\n"))
+
+						err := gcpUp.Execute(commands.GCPUpConfig{}, state)
+						Expect(err).To(MatchError("bbl up was cancelled"))
+
+						Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(0))
+						Expect(boshDeployer.DeployCall.CallCount).To(Equal(0))
+					})
+
+					It("keeps the previous director settings in the state when the user declines", func() {
+						stdin.Write([]byte("no\n"))
+						state.OpsFiles = []storage.OpsFile{{Name: "some-ops-file", Contents: "- type: remove\n  path: /some-path"}}
+
+						err := gcpUp.Execute(commands.GCPUpConfig{
+							OpsFiles:         []storage.OpsFile{{Name: "some-new-ops-file", Contents: "- type: remove\n  path: /some-new-path"}},
+							VersionOverrides: []storage.VersionOverride{{Name: "bosh", URL: "some-bosh-url", SHA1: "some-bosh-sha1"}},
+							AirGapped:        true,
+							DirectorSizing:   storage.DirectorSizing{InstanceType: "n1-standard-4"},
+						}, state)
+						Expect(err).To(MatchError("bbl up was cancelled"))
+
+						Expect(stateStore.SetCall.Receives.State.OpsFiles).To(Equal(state.OpsFiles))
+						Expect(stateStore.SetCall.Receives.State.VersionOverrides).To(BeNil())
+						Expect(stateStore.SetCall.Receives.State.AirGapped).To(BeFalse())
+						Expect(stateStore.SetCall.Receives.State.DirectorSizing).To(BeNil())
+						Expect(stateStore.SetCall.Receives.State.TFState).To(Equal("some-existing-tf-state"))
+					})
+
+					It("does not apply when there is no answer", func() {
+						err := gcpUp.Execute(commands.GCPUpConfig{}, state)
+						Expect(err).To(MatchError("bbl up was cancelled"))
+
+						Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(0))
+						Expect(boshDeployer.DeployCall.CallCount).To(Equal(0))
+					})
+
+					It("does not ask for confirmation when no-confirm is provided", func() {
+						err := gcpUp.Execute(commands.GCPUpConfig{NoConfirm: true}, state)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(terraformExecutor.PlanCall.CallCount).To(Equal(0))
+						Expect(logger.PromptCall.CallCount).To(Equal(0))
+						Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(1))
+					})
+				})
+
+				It("does not ask for confirmation when nothing is destroyed", func() {
+					terraformExecutor.PlanCall.Returns.Output = "Plan: 1 to add, 2 to change, 0 to destroy.\n"
+
+					err := gcpUp.Execute(commands.GCPUpConfig{}, state)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(logger.PromptCall.CallCount).To(Equal(0))
+					Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(1))
+				})
+
+				It("does not plan when there is no existing tf state", func() {
+					state.TFState = ""
+
+					err := gcpUp.Execute(commands.GCPUpConfig{}, state)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(terraformExecutor.PlanCall.CallCount).To(Equal(0))
+				})
+
+				It("returns an error when the plan fails", func() {
+					terraformExecutor.PlanCall.Returns.Error = errors.New("failed to plan")
+
+					err := gcpUp.Execute(commands.GCPUpConfig{}, state)
+					Expect(err).To(MatchError("failed to plan"))
+					Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(0))
+				})
+			})
+		})
+
+		Context("ops files", func() {
+			It("remembers the ops files in the state and passes them to the deployer", func() {
+				opsFiles := []storage.OpsFile{{Name: "syslog.yml", Contents: "- type: remove\n  path: /syslog?\n"}}
+				keyPairUpdater.UpdateCall.Returns.KeyPair = storage.KeyPair{
+					PrivateKey: "some-private-key",
+					PublicKey:  "some-public-key",
+				}
+
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKeyPath: serviceAccountKeyPath,
+					ProjectID:             "some-project-id",
+					Zone:                  "some-zone",
+					Region:                "us-west1",
+					OpsFiles:              opsFiles,
+				}, storage.State{
+					EnvID: "bbl-lake-time:stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshDeployer.DeployCall.Receives.Input.OpsFiles).To(Equal(opsFiles))
+				Expect(stateStore.SetCall.Receives.State.OpsFiles).To(Equal(opsFiles))
+			})
+
+			It("forgets the ops files in the state when they are cleared", func() {
+				keyPairUpdater.UpdateCall.Returns.KeyPair = storage.KeyPair{
+					PrivateKey: "some-private-key",
+					PublicKey:  "some-public-key",
+				}
+
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKeyPath: serviceAccountKeyPath,
+					ProjectID:             "some-project-id",
+					Zone:                  "some-zone",
+					Region:                "us-west1",
+					ClearOpsFiles:         true,
+				}, storage.State{
+					EnvID:    "bbl-lake-time:stamp",
+					OpsFiles: []storage.OpsFile{{Name: "syslog.yml", Contents: "- type: remove\n  path: /syslog?\n"}},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshDeployer.DeployCall.Receives.Input.OpsFiles).To(BeEmpty())
+				Expect(stateStore.SetCall.Receives.State.OpsFiles).To(BeEmpty())
+			})
+		})
+
+		Context("version overrides", func() {
+			It("remembers the overrides and the air-gapped setting in the state", func() {
+				versionOverrides := []storage.VersionOverride{{Name: "cpi", URL: "some-cpi-url", SHA1: "some-cpi-sha1"}}
+				keyPairUpdater.UpdateCall.Returns.KeyPair = storage.KeyPair{
+					PrivateKey: "some-private-key",
+					PublicKey:  "some-public-key",
+				}
+
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKeyPath: serviceAccountKeyPath,
+					ProjectID:             "some-project-id",
+					Zone:                  "some-zone",
+					Region:                "us-west1",
+					VersionOverrides:      versionOverrides,
+					AirGapped:             true,
+				}, storage.State{
+					EnvID: "bbl-lake-time:stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshDeployer.DeployCall.Receives.Input.VersionOverrides).To(Equal(versionOverrides))
+				Expect(stateStore.SetCall.Receives.State.VersionOverrides).To(Equal(versionOverrides))
+				Expect(stateStore.SetCall.Receives.State.AirGapped).To(BeTrue())
+			})
+		})
+
+		Context("director sizing", func() {
+			It("remembers the sizing in the state and passes it to the deployer", func() {
+				keyPairUpdater.UpdateCall.Returns.KeyPair = storage.KeyPair{
+					PrivateKey: "some-private-key",
+					PublicKey:  "some-public-key",
+				}
+
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKeyPath: serviceAccountKeyPath,
+					ProjectID:             "some-project-id",
+					Zone:                  "some-zone",
+					Region:                "us-west1",
+					DirectorSizing:        storage.DirectorSizing{InstanceType: "n1-standard-1"},
+				}, storage.State{
+					EnvID: "bbl-lake-time:stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(boshDeployer.DeployCall.Receives.Input.DirectorSizing).To(Equal(storage.DirectorSizing{InstanceType: "n1-standard-1"}))
+				Expect(stateStore.SetCall.Receives.State.DirectorSizing).To(Equal(&storage.DirectorSizing{InstanceType: "n1-standard-1"}))
+			})
+		})
+
+		Context("network", func() {
+			BeforeEach(func() {
+				keyPairUpdater.UpdateCall.Returns.KeyPair = storage.KeyPair{
+					PrivateKey: "some-private-key",
+					PublicKey:  "some-public-key",
+				}
+			})
+
+			It("remembers the network in the state and uses it for the subnet and the cloud config", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKeyPath: serviceAccountKeyPath,
+					ProjectID:             "some-project-id",
+					Zone:                  "some-zone",
+					Region:                "us-west1",
+					Network:               storage.Network{CIDR: "172.20.0.0/16"},
+				}, storage.State{
+					EnvID: "bbl-lake-time:stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformExecutor.ApplyCall.Receives.Template).To(ContainSubstring(`ip_cidr_range = "172.20.0.0/16"`))
+				Expect(gcpCloudConfigGenerator.GenerateCall.Receives.CloudConfigInput.NetworkCIDR).To(Equal("172.20.0.0/16"))
+				Expect(stateStore.SetCall.Receives.State.Network).To(Equal(&storage.Network{CIDR: "172.20.0.0/16"}))
+			})
+
+			It("returns an error when the network of an existing environment would change", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKeyPath: serviceAccountKeyPath,
+					ProjectID:             "some-project-id",
+					Zone:                  "some-zone",
+					Region:                "us-west1",
+					Network:               storage.Network{CIDR: "172.20.0.0/16"},
+				}, storage.State{
+					EnvID:   "bbl-lake-time:stamp",
+					TFState: "some-tf-state",
+				})
+				Expect(err).To(MatchError("the network cannot be changed for an existing environment"))
+				Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("no director", func() {
+			It("stops after terraform apply and records the terraform outputs", func() {
+				keyPairUpdater.UpdateCall.Returns.KeyPair = storage.KeyPair{
+					PrivateKey: "some-private-key",
+					PublicKey:  "some-public-key",
+				}
+
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKeyPath: serviceAccountKeyPath,
+					ProjectID:             "some-project-id",
+					Zone:                  "some-zone",
+					Region:                "us-west1",
+					NoDirector:            true,
+				}, storage.State{
+					EnvID: "bbl-lake-time:stamp",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(1))
+				Expect(boshDeployer.DeployCall.CallCount).To(Equal(0))
+				Expect(boshClient.UpdateCloudConfigCall.CallCount).To(Equal(0))
+
+				state := stateStore.SetCall.Receives.State
+				Expect(state.NoDirector).To(BeTrue())
+				Expect(state.BOSH.IsEmpty()).To(BeTrue())
+				Expect(state.Checkpoints).To(BeNil())
+				Expect(state.Outputs).To(Equal(map[string]string{
+					"external_ip":        "some-external-ip",
+					"network_name":       "bbl-lake-time:stamp-network",
+					"subnetwork_name":    "bbl-lake-time:stamp-subnet",
+					"bosh_open_tag_name": "bbl-lake-time:stamp-bosh-open",
+					"internal_tag_name":  "bbl-lake-time:stamp-internal",
+					"director_address":   "some-director-address",
+				}))
+			})
+
+			It("returns an error when the terraform outputs cannot be read", func() {
+				terraformOutputter.GetCall.Stub = nil
+				terraformOutputter.GetCall.Returns.Error = errors.New("failed to get output")
+
+				err := gcpUp.Execute(commands.GCPUpConfig{}, storage.State{
+					IAAS:       "gcp",
+					EnvID:      "bbl-lake-time:stamp",
+					NoDirector: true,
+					GCP: storage.GCP{
+						ServiceAccountKey: serviceAccountKey,
+						ProjectID:         "some-project-id",
+						Zone:              "some-zone",
+						Region:            "us-west1",
+					},
+					KeyPair: storage.KeyPair{
+						PrivateKey: "some-private-key",
+						PublicKey:  "some-public-key",
+					},
+				})
+				Expect(err).To(MatchError("failed to get output"))
+				Expect(boshDeployer.DeployCall.CallCount).To(Equal(0))
+			})
+
+			It("deploys a director to an infrastructure-only environment when no-director is not given", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{}, storage.State{
+					IAAS:       "gcp",
+					EnvID:      "bbl-lake-time:stamp",
+					NoDirector: true,
+					Outputs:    map[string]string{"external_ip": "some-external-ip"},
+					GCP: storage.GCP{
+						ServiceAccountKey: serviceAccountKey,
+						ProjectID:         "some-project-id",
+						Zone:              "some-zone",
+						Region:            "us-west1",
+					},
+					KeyPair: storage.KeyPair{
+						PrivateKey: "some-private-key",
+						PublicKey:  "some-public-key",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(logger.StepCall.Messages).To(ContainElement("deploying a director to the infrastructure-only environment"))
+				Expect(boshDeployer.DeployCall.CallCount).To(Equal(1))
+				Expect(boshClient.UpdateCloudConfigCall.CallCount).To(Equal(1))
+
+				state := stateStore.SetCall.Receives.State
+				Expect(state.NoDirector).To(BeFalse())
+				Expect(state.Outputs).To(BeNil())
+			})
+		})
+
+		Context("resuming", func() {
+			var failedState storage.State
+
+			BeforeEach(func() {
+				keyPairUpdater.UpdateCall.Returns.KeyPair = storage.KeyPair{
+					PrivateKey: "some-private-key",
+					PublicKey:  "some-public-key",
+				}
+				boshClient.UpdateCloudConfigCall.Returns.Error = errors.New("failed to update cloud config")
+
+				err := gcpUp.Execute(commands.GCPUpConfig{
+					ServiceAccountKeyPath: serviceAccountKeyPath,
+					ProjectID:             "some-project-id",
+					Zone:                  "some-zone",
+					Region:                "us-west1",
+				}, storage.State{
+					EnvID: "bbl-lake-time:stamp",
+				})
+				Expect(err).To(MatchError("failed to update cloud config"))
+
+				failedState = stateStore.SetCall.Receives.State
+				boshClient.UpdateCloudConfigCall.Returns.Error = nil
+			})
+
+			It("skips the phases whose inputs have not changed", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{NoConfirm: true}, failedState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(1))
+				Expect(boshDeployer.DeployCall.CallCount).To(Equal(1))
+				Expect(boshClient.UpdateCloudConfigCall.CallCount).To(Equal(2))
+				Expect(logger.StepCall.Messages).To(ContainElement(`skipping phase "infrastructure", its inputs have not changed since the last run`))
+				Expect(logger.StepCall.Messages).To(ContainElement(`resuming bbl up from phase "cloud-config"`))
+				Expect(stateStore.SetCall.Receives.State.Checkpoints).To(BeNil())
+			})
+
+			It("re-runs a phase and the phases after it when its inputs have changed", func() {
+				failedState.LB.Type = "concourse"
+
+				err := gcpUp.Execute(commands.GCPUpConfig{NoConfirm: true}, failedState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(2))
+				Expect(boshDeployer.DeployCall.CallCount).To(Equal(2))
+				Expect(logger.StepCall.Messages).To(ContainElement(`resuming bbl up from phase "infrastructure"`))
+			})
+
+			It("starts from the phase provided with --from-phase", func() {
+				err := gcpUp.Execute(commands.GCPUpConfig{NoConfirm: true, FromPhase: "infrastructure"}, failedState)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(keyPairUpdater.UpdateCall.CallCount).To(Equal(1))
+				Expect(terraformExecutor.ApplyCall.CallCount).To(Equal(2))
+				Expect(boshDeployer.DeployCall.CallCount).To(Equal(2))
+				Expect(logger.StepCall.Messages).To(ContainElement(`skipping phase "key-pair", starting from phase "infrastructure"`))
+			})
 		})
 
 		Context("bosh", func() {
@@ -348,6 +745,24 @@ var _ = Describe("gcp up", func() {
 				})
 			})
 
+			Context("when bosh-init is stopped", func() {
+				It("saves the partial bosh-init state and returns an error", func() {
+					boshDeployer.DeployCall.Returns.Error = boshinit.NewBOSHInitError(boshinit.State{"partial": "state"},
+						errors.New("bbl received interrupt, bosh-init was stopped"))
+					boshDeployer.DeployCall.Returns.Output.BOSHInitState = boshinit.State{"partial": "state"}
+
+					err := gcpUp.Execute(commands.GCPUpConfig{
+						ServiceAccountKeyPath: serviceAccountKeyPath,
+						ProjectID:             "some-project-id",
+						Zone:                  "some-zone",
+						Region:                "us-west1",
+					}, storage.State{})
+					Expect(err).To(MatchError("bbl received interrupt, bosh-init was stopped"))
+					Expect(stateStore.SetCall.Receives.State.BOSH.State).To(Equal(map[string]interface{}{"partial": "state"}))
+					Expect(stateStore.SetCall.Receives.State.BOSH.DirectorAddress).To(Equal("some-director-address"))
+				})
+			})
+
 			Context("failure cases", func() {
 				DescribeTable("returns an error when we fail to get an output", func(outputName string) {
 					terraformOutputter.GetCall.Stub = func(output string) (string, error) {
@@ -460,6 +875,7 @@ var _ = Describe("gcp up", func() {
 				Tags:           []string{"bbl-lake-time:stamp-internal"},
 				NetworkName:    "bbl-lake-time:stamp-network",
 				SubnetworkName: "bbl-lake-time:stamp-subnet",
+				NetworkCIDR:    "10.0.0.0/16",
 			}))
 
 			Expect(boshClient.UpdateCloudConfigCall.CallCount).To(Equal(1))
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws"
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/gcp"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const ImportCommand = "import"
+
+type gcpClientProvider interface {
+	SetConfig(serviceAccountKey, projectID, zone string) error
+	Client() gcp.Client
+}
+
+type Import struct {
+	stateStore            stateStore
+	configProvider        configProvider
+	infrastructureManager infrastructureManager
+	gcpClientProvider     gcpClientProvider
+	logger                logger
+	overrides             storage.Backend
+}
+
+func NewImport(stateStore stateStore, configProvider configProvider, infrastructureManager infrastructureManager,
+	gcpClientProvider gcpClientProvider, logger logger, overrides storage.Backend) Import {
+	return Import{
+		stateStore:            stateStore,
+		configProvider:        configProvider,
+		infrastructureManager: infrastructureManager,
+		gcpClientProvider:     gcpClientProvider,
+		logger:                logger,
+		overrides:             overrides,
+	}
+}
+
+func (i Import) Execute(subcommandFlags []string, state storage.State) error {
+	bundlePath, keyFile, err := i.parseFlags(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(state, storage.State{}) {
+		return fmt.Errorf("a bbl environment %q already exists in the state directory, refusing to overwrite it", state.EnvID)
+	}
+
+	key, err := readBundleKey(keyFile)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := os.Open(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer bundle.Close()
+
+	imported, manifest, err := storage.ReadBundle(bundle, key)
+	if err != nil {
+		return err
+	}
+
+	i.logger.Step(fmt.Sprintf("validating environment %q exported by bbl %s against %s", manifest.EnvID, manifest.BBLVersion, imported.IAAS))
+	err = i.validate(imported)
+	if err != nil {
+		return err
+	}
+
+	err = storage.WriteTerraformOverrides(i.overrides, imported.TerraformOverrides)
+	if err != nil {
+		return err
+	}
+
+	err = i.stateStore.Set(imported)
+	if err != nil {
+		return err
+	}
+
+	i.logger.Println(fmt.Sprintf("imported environment %q", imported.EnvID))
+	return nil
+}
+
+func (Import) parseFlags(subcommandFlags []string) (string, string, error) {
+	importFlags := flags.New("import")
+
+	var keyFile string
+	importFlags.String(&keyFile, "key-file", "")
+
+	args := []string{}
+	for {
+		err := importFlags.Parse(subcommandFlags)
+		if err != nil {
+			return "", "", err
+		}
+
+		if len(importFlags.Args()) == 0 {
+			break
+		}
+
+		args = append(args, importFlags.Args()[0])
+		subcommandFlags = importFlags.Args()[1:]
+	}
+
+	if len(args) != 1 {
+		return "", "", errors.New("import requires the path of a bundle created by bbl export")
+	}
+
+	return args[0], keyFile, nil
+}
+
+func (i Import) validate(state storage.State) error {
+	switch state.IAAS {
+	case "aws":
+		i.configProvider.SetConfig(aws.Config{
+			AccessKeyID:     state.AWS.AccessKeyID,
+			SecretAccessKey: state.AWS.SecretAccessKey,
+			Profile:         state.AWS.Profile,
+			AssumeRoleARN:   state.AWS.AssumeRoleARN,
+			ExternalID:      state.AWS.ExternalID,
+			Region:          state.AWS.Region,
+		})
+
+		if state.Stack.Name == "" {
+			return nil
+		}
+
+		exists, err := i.infrastructureManager.Exists(state.Stack.Name)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return fmt.Errorf("the bundle references the stack %q, which does not exist in %s", state.Stack.Name, state.AWS.Region)
+		}
+	case "gcp":
+		err := i.gcpClientProvider.SetConfig(state.GCP.ServiceAccountKey, state.GCP.ProjectID, state.GCP.Zone)
+		if err != nil {
+			return err
+		}
+
+		_, err = i.gcpClientProvider.Client().GetProject()
+		if err != nil {
+			return fmt.Errorf("the bundle references the project %q, which could not be reached: %s", state.GCP.ProjectID, err)
+		}
+	default:
+		return fmt.Errorf("the bundle has an invalid iaas %q", state.IAAS)
+	}
+
+	return nil
+}
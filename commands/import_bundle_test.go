@@ -0,0 +1,222 @@
+package commands_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws"
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Import", func() {
+	var (
+		stateStore            *fakes.StateStore
+		clientProvider        *fakes.ClientProvider
+		infrastructureManager *fakes.InfrastructureManager
+		gcpClientProvider     *fakes.GCPClientProvider
+		gcpClient             *fakes.GCPClient
+		logger                *fakes.Logger
+		importCommand         commands.Import
+		tempDir               string
+	)
+
+	writeBundle := func(state storage.State, key string) string {
+		path := filepath.Join(tempDir, "bundle.tgz")
+		bundle, err := os.Create(path)
+		Expect(err).NotTo(HaveOccurred())
+		defer bundle.Close()
+
+		_, err = storage.WriteBundle(bundle, state, "1.2.3", key)
+		Expect(err).NotTo(HaveOccurred())
+
+		return path
+	}
+
+	BeforeEach(func() {
+		stateStore = &fakes.StateStore{}
+		clientProvider = &fakes.ClientProvider{}
+		infrastructureManager = &fakes.InfrastructureManager{}
+		gcpClient = &fakes.GCPClient{}
+		gcpClientProvider = &fakes.GCPClientProvider{}
+		gcpClientProvider.ClientCall.Returns.Client = gcpClient
+		logger = &fakes.Logger{}
+
+		var err error
+		tempDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		importCommand = commands.NewImport(stateStore, clientProvider, infrastructureManager, gcpClientProvider, logger,
+			storage.NewLocalBackend(filepath.Join(tempDir, "overrides")))
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	Describe("Execute", func() {
+		Context("for an aws environment", func() {
+			var awsState storage.State
+
+			BeforeEach(func() {
+				awsState = storage.State{
+					IAAS:  "aws",
+					EnvID: "some-env-id",
+					AWS: storage.AWS{
+						AccessKeyID:     "some-access-key-id",
+						SecretAccessKey: "some-secret-access-key",
+						AssumeRoleARN:   "some-role-arn",
+						ExternalID:      "some-external-id",
+						Region:          "some-region",
+					},
+					Stack: storage.Stack{
+						Name: "some-stack-name",
+					},
+				}
+				infrastructureManager.ExistsCall.Returns.Exists = true
+			})
+
+			It("validates the stack exists and stores the state", func() {
+				err := importCommand.Execute([]string{writeBundle(awsState, "")}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(clientProvider.SetConfigCall.Receives.Config).To(Equal(aws.Config{
+					AccessKeyID:     "some-access-key-id",
+					SecretAccessKey: "some-secret-access-key",
+					AssumeRoleARN:   "some-role-arn",
+					ExternalID:      "some-external-id",
+					Region:          "some-region",
+				}))
+				Expect(infrastructureManager.ExistsCall.Receives.StackName).To(Equal("some-stack-name"))
+
+				awsState.Version = 3
+				Expect(stateStore.SetCall.Receives.State).To(Equal(awsState))
+				Expect(logger.PrintlnCall.Receives.Message).To(Equal(`imported environment "some-env-id"`))
+			})
+
+			It("returns an error when the stack does not exist", func() {
+				infrastructureManager.ExistsCall.Returns.Exists = false
+
+				err := importCommand.Execute([]string{writeBundle(awsState, "")}, storage.State{})
+				Expect(err).To(MatchError(`the bundle references the stack "some-stack-name", which does not exist in some-region`))
+				Expect(stateStore.SetCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when the stack cannot be checked", func() {
+				infrastructureManager.ExistsCall.Returns.Error = errors.New("failed to check stack")
+
+				err := importCommand.Execute([]string{writeBundle(awsState, "")}, storage.State{})
+				Expect(err).To(MatchError("failed to check stack"))
+			})
+		})
+
+		Context("for a gcp environment", func() {
+			var gcpState storage.State
+
+			BeforeEach(func() {
+				gcpState = storage.State{
+					IAAS:  "gcp",
+					EnvID: "some-env-id",
+					GCP: storage.GCP{
+						ServiceAccountKey: "some-service-account-key",
+						ProjectID:         "some-project-id",
+						Zone:              "some-zone",
+					},
+				}
+			})
+
+			It("validates the project can be reached and stores the state", func() {
+				err := importCommand.Execute([]string{writeBundle(gcpState, "")}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(gcpClientProvider.SetConfigCall.Receives.ServiceAccountKey).To(Equal("some-service-account-key"))
+				Expect(gcpClientProvider.SetConfigCall.Receives.ProjectID).To(Equal("some-project-id"))
+				Expect(gcpClientProvider.SetConfigCall.Receives.Zone).To(Equal("some-zone"))
+				Expect(gcpClient.GetProjectCall.CallCount).To(Equal(1))
+				Expect(stateStore.SetCall.CallCount).To(Equal(1))
+			})
+
+			It("restores the terraform overrides to the overrides directory", func() {
+				gcpState.TerraformOverrides = map[string]string{"firewall.tf": "some-override"}
+
+				err := importCommand.Execute([]string{writeBundle(gcpState, "")}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				contents, err := ioutil.ReadFile(filepath.Join(tempDir, "overrides", "firewall.tf"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("some-override"))
+			})
+
+			It("returns an error when the gcp credentials are invalid", func() {
+				gcpClientProvider.SetConfigCall.Returns.Error = errors.New("invalid service account key")
+
+				err := importCommand.Execute([]string{writeBundle(gcpState, "")}, storage.State{})
+				Expect(err).To(MatchError("invalid service account key"))
+			})
+
+			It("returns an error when the project cannot be reached", func() {
+				gcpClient.GetProjectCall.Returns.Error = errors.New("project not found")
+
+				err := importCommand.Execute([]string{writeBundle(gcpState, "")}, storage.State{})
+				Expect(err).To(MatchError(`the bundle references the project "some-project-id", which could not be reached: project not found`))
+				Expect(stateStore.SetCall.CallCount).To(Equal(0))
+			})
+		})
+
+		It("decrypts the bundle with the key from the key file", func() {
+			keyFile := filepath.Join(tempDir, "some-key")
+			err := ioutil.WriteFile(keyFile, []byte("some-key"), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			bundle := writeBundle(storage.State{IAAS: "gcp", EnvID: "some-env-id"}, "some-key")
+
+			err = importCommand.Execute([]string{bundle, "--key-file", keyFile}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when no bundle is provided", func() {
+				err := importCommand.Execute([]string{}, storage.State{})
+				Expect(err).To(MatchError("import requires the path of a bundle created by bbl export"))
+			})
+
+			It("returns an error when an environment already exists", func() {
+				err := importCommand.Execute([]string{"some-bundle"}, storage.State{EnvID: "some-existing-env"})
+				Expect(err).To(MatchError(`a bbl environment "some-existing-env" already exists in the state directory, refusing to overwrite it`))
+			})
+
+			It("returns an error when the bundle does not exist", func() {
+				err := importCommand.Execute([]string{"/some/missing/bundle"}, storage.State{})
+				Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+			})
+
+			It("returns an error when the bundle is encrypted and no key is provided", func() {
+				bundle := writeBundle(storage.State{IAAS: "gcp"}, "some-key")
+
+				err := importCommand.Execute([]string{bundle}, storage.State{})
+				Expect(err).To(Equal(storage.MissingBundleKey))
+			})
+
+			It("returns an error when the bundle has an unknown iaas", func() {
+				bundle := writeBundle(storage.State{IAAS: "some-iaas"}, "")
+
+				err := importCommand.Execute([]string{bundle}, storage.State{})
+				Expect(err).To(MatchError(`the bundle has an invalid iaas "some-iaas"`))
+			})
+
+			It("returns an error when the state cannot be stored", func() {
+				stateStore.SetCall.Returns = []fakes.SetCallReturn{{Error: errors.New("failed to set state")}}
+				bundle := writeBundle(storage.State{IAAS: "gcp"}, "")
+
+				err := importCommand.Execute([]string{bundle}, storage.State{})
+				Expect(err).To(MatchError("failed to set state"))
+			})
+		})
+	})
+})
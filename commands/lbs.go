@@ -18,15 +18,31 @@ type LBs struct {
 	stateValidator        stateValidator
 	terraformOutputter    terraformOutputter
 	stdout                io.Writer
+	output                string
 }
 
-func NewLBs(credentialValidator credentialValidator, stateValidator stateValidator, infrastructureManager infrastructureManager, terraformOutputter terraformOutputter, stdout io.Writer) LBs {
+type loadBalancer struct {
+	Name         string `json:"name"`
+	LoadBalancer string `json:"loadBalancer,omitempty"`
+	URL          string `json:"url,omitempty"`
+	IP           string `json:"ip,omitempty"`
+
+	label string
+}
+
+type loadBalancers struct {
+	Type          string         `json:"type"`
+	LoadBalancers []loadBalancer `json:"loadBalancers"`
+}
+
+func NewLBs(credentialValidator credentialValidator, stateValidator stateValidator, infrastructureManager infrastructureManager, terraformOutputter terraformOutputter, stdout io.Writer, output string) LBs {
 	return LBs{
 		credentialValidator:   credentialValidator,
 		infrastructureManager: infrastructureManager,
 		stateValidator:        stateValidator,
 		terraformOutputter:    terraformOutputter,
 		stdout:                stdout,
+		output:                output,
 	}
 }
 
@@ -36,58 +52,93 @@ func (c LBs) Execute(subcommandFlags []string, state storage.State) error {
 		return err
 	}
 
+	var lbs loadBalancers
 	switch state.IAAS {
 	case "aws":
-		err = c.credentialValidator.ValidateAWS()
-		if err != nil {
-			return err
+		lbs, err = c.awsLBs(state)
+	case "gcp":
+		lbs, err = c.gcpLBs(state)
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.output == JSONOutput {
+		return printJSON(c.stdout, lbs)
+	}
+
+	for _, lb := range lbs.LoadBalancers {
+		if lb.URL != "" {
+			fmt.Fprintf(c.stdout, "%s: %s [%s]\n", lb.label, lb.LoadBalancer, lb.URL)
+		} else {
+			fmt.Fprintf(c.stdout, "%s: %s\n", lb.label, lb.IP)
 		}
+	}
 
-		stack, err := c.infrastructureManager.Describe(state.Stack.Name)
-		if err != nil {
-			return err
+	return nil
+}
+
+func (c LBs) awsLBs(state storage.State) (loadBalancers, error) {
+	err := c.credentialValidator.ValidateAWS()
+	if err != nil {
+		return loadBalancers{}, err
+	}
+
+	stack, err := c.infrastructureManager.Describe(state.Stack.Name)
+	if err != nil {
+		return loadBalancers{}, err
+	}
+
+	lbs := loadBalancers{Type: state.Stack.LBType}
+	switch state.Stack.LBType {
+	case "cf":
+		lbs.LoadBalancers = []loadBalancer{
+			{Name: "cf-router", label: "CF Router LB", LoadBalancer: stack.Outputs["CFRouterLoadBalancer"], URL: stack.Outputs["CFRouterLoadBalancerURL"]},
+			{Name: "cf-ssh-proxy", label: "CF SSH Proxy LB", LoadBalancer: stack.Outputs["CFSSHProxyLoadBalancer"], URL: stack.Outputs["CFSSHProxyLoadBalancerURL"]},
+		}
+	case "concourse":
+		lbs.LoadBalancers = []loadBalancer{
+			{Name: "concourse", label: "Concourse LB", LoadBalancer: stack.Outputs["ConcourseLoadBalancer"], URL: stack.Outputs["ConcourseLoadBalancerURL"]},
 		}
+	default:
+		return loadBalancers{}, errors.New("no lbs found")
+	}
+
+	return lbs, nil
+}
 
-		switch state.Stack.LBType {
-		case "cf":
-			fmt.Fprintf(c.stdout, "CF Router LB: %s [%s]\n", stack.Outputs["CFRouterLoadBalancer"], stack.Outputs["CFRouterLoadBalancerURL"])
-			fmt.Fprintf(c.stdout, "CF SSH Proxy LB: %s [%s]\n", stack.Outputs["CFSSHProxyLoadBalancer"], stack.Outputs["CFSSHProxyLoadBalancerURL"])
-		case "concourse":
-			fmt.Fprintf(c.stdout, "Concourse LB: %s [%s]\n", stack.Outputs["ConcourseLoadBalancer"], stack.Outputs["ConcourseLoadBalancerURL"])
-		default:
-			return errors.New("no lbs found")
+func (c LBs) gcpLBs(state storage.State) (loadBalancers, error) {
+	type terraformLB struct {
+		name   string
+		label  string
+		output string
+	}
+
+	var terraformLBs []terraformLB
+	switch state.LB.Type {
+	case "cf":
+		terraformLBs = []terraformLB{
+			{"cf-router", "CF Router LB", "router_lb_ip"},
+			{"cf-ssh-proxy", "CF SSH Proxy LB", "ssh_proxy_lb_ip"},
+			{"cf-tcp-router", "CF TCP Router LB", "tcp_router_lb_ip"},
 		}
-	case "gcp":
-		switch state.LB.Type {
-		case "cf":
-			routerLB, err := c.terraformOutputter.Get(state.TFState, "router_lb_ip")
-			if err != nil {
-				return err
-			}
-
-			sshProxyLB, err := c.terraformOutputter.Get(state.TFState, "ssh_proxy_lb_ip")
-			if err != nil {
-				return err
-			}
-
-			tcpRouterLB, err := c.terraformOutputter.Get(state.TFState, "tcp_router_lb_ip")
-			if err != nil {
-				return err
-			}
-
-			fmt.Fprintf(c.stdout, "CF Router LB: %s\n", routerLB)
-			fmt.Fprintf(c.stdout, "CF SSH Proxy LB: %s\n", sshProxyLB)
-			fmt.Fprintf(c.stdout, "CF TCP Router LB: %s\n", tcpRouterLB)
-		case "concourse":
-			concourseLB, err := c.terraformOutputter.Get(state.TFState, "concourse_lb_ip")
-			if err != nil {
-				return err
-			}
-			fmt.Fprintf(c.stdout, "Concourse LB: %s\n", concourseLB)
-		default:
-			return errors.New("no lbs found")
+	case "concourse":
+		terraformLBs = []terraformLB{
+			{"concourse", "Concourse LB", "concourse_lb_ip"},
 		}
+	default:
+		return loadBalancers{}, errors.New("no lbs found")
 	}
 
-	return nil
+	lbs := loadBalancers{Type: state.LB.Type}
+	for _, lb := range terraformLBs {
+		ip, err := c.terraformOutputter.Get(state.TFState, lb.output)
+		if err != nil {
+			return loadBalancers{}, err
+		}
+
+		lbs.LoadBalancers = append(lbs.LoadBalancers, loadBalancer{Name: lb.name, label: lb.label, IP: ip})
+	}
+
+	return lbs, nil
 }
@@ -31,7 +31,7 @@ var _ = Describe("LBs", func() {
 		terraformOutputter = &fakes.TerraformOutputter{}
 		stdout = bytes.NewBuffer([]byte{})
 
-		lbsCommand = commands.NewLBs(credentialValidator, stateValidator, infrastructureManager, terraformOutputter, stdout)
+		lbsCommand = commands.NewLBs(credentialValidator, stateValidator, infrastructureManager, terraformOutputter, stdout, commands.TextOutput)
 	})
 
 	Describe("Execute", func() {
@@ -69,6 +69,32 @@ var _ = Describe("LBs", func() {
 				Expect(stdout.String()).To(ContainSubstring("CF SSH Proxy LB: some-other-lb-name [http://some.other.lb.url]"))
 			})
 
+			It("prints a json document in json mode", func() {
+				lbsCommand = commands.NewLBs(credentialValidator, stateValidator, infrastructureManager, terraformOutputter, stdout, commands.JSONOutput)
+				infrastructureManager.DescribeCall.Returns.Stack = cloudformation.Stack{
+					Name: "some-stack-name",
+					Outputs: map[string]string{
+						"ConcourseLoadBalancer":    "some-lb-name",
+						"ConcourseLoadBalancerURL": "http://some.lb.url",
+					},
+				}
+
+				incomingState.Stack = storage.Stack{
+					LBType: "concourse",
+					Name:   "some-stack-name",
+				}
+				err := lbsCommand.Execute([]string{}, incomingState)
+
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stdout.String()).To(MatchJSON(`{
+					"type": "concourse",
+					"loadBalancers": [
+						{"name": "concourse", "loadBalancer": "some-lb-name", "url": "http://some.lb.url"}
+					]
+				}`))
+			})
+
 			It("prints LB names and URLs for lb type concourse", func() {
 				infrastructureManager.DescribeCall.Returns.Stack = cloudformation.Stack{
 					Name: "some-stack-name",
@@ -159,6 +185,25 @@ var _ = Describe("LBs", func() {
 				Expect(stdout.String()).To(ContainSubstring("CF TCP Router LB: some-tcp-router-lb-ip"))
 			})
 
+			It("prints a json document in json mode", func() {
+				lbsCommand = commands.NewLBs(credentialValidator, stateValidator, infrastructureManager, terraformOutputter, stdout, commands.JSONOutput)
+				incomingState.LB = storage.LB{
+					Type: "cf",
+				}
+				err := lbsCommand.Execute([]string{}, incomingState)
+
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(stdout.String()).To(MatchJSON(`{
+					"type": "cf",
+					"loadBalancers": [
+						{"name": "cf-router", "ip": "some-router-lb-ip"},
+						{"name": "cf-ssh-proxy", "ip": "some-ssh-proxy-lb-ip"},
+						{"name": "cf-tcp-router", "ip": "some-tcp-router-lb-ip"}
+					]
+				}`))
+			})
+
 			It("prints LB ips for lb type concourse", func() {
 				incomingState.LB = storage.LB{
 					Type: "concourse",
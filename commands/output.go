@@ -0,0 +1,17 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	TextOutput = "text"
+	JSONOutput = "json"
+)
+
+func printJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
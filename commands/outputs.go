@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const OutputsCommand = "outputs"
+
+type Outputs struct {
+	stateValidator stateValidator
+	stdout         io.Writer
+	output         string
+}
+
+func NewOutputs(stateValidator stateValidator, stdout io.Writer, output string) Outputs {
+	return Outputs{
+		stateValidator: stateValidator,
+		stdout:         stdout,
+		output:         output,
+	}
+}
+
+func (o Outputs) Execute(subcommandFlags []string, state storage.State) error {
+	err := o.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	if len(state.Outputs) == 0 {
+		return errors.New("Could not retrieve outputs, please make sure you are targeting the proper state dir and have run bbl up --no-director.")
+	}
+
+	if o.output == JSONOutput {
+		return printJSON(o.stdout, state.Outputs)
+	}
+
+	names := []string{}
+	for name := range state.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(o.stdout, "%s: %s\n", name, state.Outputs[name])
+	}
+
+	return nil
+}
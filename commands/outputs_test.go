@@ -0,0 +1,72 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Outputs", func() {
+	var (
+		stateValidator *fakes.StateValidator
+		stdout         *bytes.Buffer
+		state          storage.State
+	)
+
+	BeforeEach(func() {
+		stateValidator = &fakes.StateValidator{}
+		stdout = &bytes.Buffer{}
+
+		state = storage.State{
+			IAAS:       "aws",
+			NoDirector: true,
+			Outputs: map[string]string{
+				"VPCID":             "some-vpc-id",
+				"BOSHSubnet":        "some-bosh-subnet",
+				"BOSHSecurityGroup": "some-security-group",
+			},
+		}
+	})
+
+	Describe("Execute", func() {
+		It("prints the outputs sorted by name", func() {
+			err := commands.NewOutputs(stateValidator, stdout, commands.TextOutput).Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stateValidator.ValidateCall.CallCount).To(Equal(1))
+			Expect(stdout.String()).To(Equal(`BOSHSecurityGroup: some-security-group
+BOSHSubnet: some-bosh-subnet
+VPCID: some-vpc-id
+`))
+		})
+
+		It("prints the outputs as json", func() {
+			err := commands.NewOutputs(stateValidator, stdout, commands.JSONOutput).Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(MatchJSON(`{
+				"VPCID": "some-vpc-id",
+				"BOSHSubnet": "some-bosh-subnet",
+				"BOSHSecurityGroup": "some-security-group"
+			}`))
+		})
+
+		It("returns an error when there are no outputs", func() {
+			err := commands.NewOutputs(stateValidator, stdout, commands.TextOutput).Execute([]string{}, storage.State{})
+			Expect(err).To(MatchError("Could not retrieve outputs, please make sure you are targeting the proper state dir and have run bbl up --no-director."))
+		})
+
+		It("returns an error when the state validator fails", func() {
+			stateValidator.ValidateCall.Returns.Error = errors.New("state validator failed")
+
+			err := commands.NewOutputs(stateValidator, stdout, commands.TextOutput).Execute([]string{}, state)
+			Expect(err).To(MatchError("state validator failed"))
+		})
+	})
+})
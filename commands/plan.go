@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+	"github.com/cloudfoundry/bosh-bootloader/boshinit"
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	PlanCommand = "plan"
+)
+
+type Plan struct {
+	awsPlan        awsPlan
+	gcpPlan        gcpPlan
+	stateValidator stateValidator
+}
+
+type awsPlan interface {
+	Execute(state storage.State) error
+}
+
+type gcpPlan interface {
+	Execute(state storage.State) error
+}
+
+type boshManifestGenerator interface {
+	Manifest(boshinit.DeployInput) (string, error)
+}
+
+func NewPlan(awsPlan awsPlan, gcpPlan gcpPlan, stateValidator stateValidator) Plan {
+	return Plan{
+		awsPlan:        awsPlan,
+		gcpPlan:        gcpPlan,
+		stateValidator: stateValidator,
+	}
+}
+
+func (p Plan) Execute(subcommandFlags []string, state storage.State) error {
+	planFlags := flags.New("plan")
+
+	err := planFlags.Parse(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	err = p.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	switch state.IAAS {
+	case "aws":
+		return p.awsPlan.Execute(state)
+	case "gcp":
+		return p.gcpPlan.Execute(state)
+	default:
+		return fmt.Errorf("%q is an invalid iaas type in state, supported iaas types are: [gcp, aws]", state.IAAS)
+	}
+}
+
+func formatResourceChanges(stackName string, changes []cloudformation.ResourceChange) string {
+	if len(changes) == 0 {
+		return fmt.Sprintf("CloudFormation stack %q: no changes", stackName)
+	}
+
+	lines := []string{fmt.Sprintf("CloudFormation stack %q:", stackName)}
+	for _, change := range changes {
+		line := fmt.Sprintf("  %s %s (%s)", resourceChangeSymbol(change.Action), change.LogicalID, change.ResourceType)
+		if replacesResource(change) {
+			line = fmt.Sprintf("%s, replacement: %s", line, change.Replacement)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func replacesResource(change cloudformation.ResourceChange) bool {
+	return change.Replacement == "True" || change.Replacement == "Conditional"
+}
+
+func hasDestructiveChanges(changes []cloudformation.ResourceChange) bool {
+	for _, change := range changes {
+		if change.Action == "Remove" || replacesResource(change) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func resourceChangeSymbol(action string) string {
+	switch action {
+	case "Add":
+		return "+"
+	case "Remove":
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+func printSectionChanges(stdout io.Writer, name, current, desired string) error {
+	if current == "" {
+		fmt.Fprintf(stdout, "%s: will be created\n", name)
+		return nil
+	}
+
+	sections, err := changedSections(current, desired)
+	if err != nil {
+		return err
+	}
+
+	if len(sections) == 0 {
+		fmt.Fprintf(stdout, "%s: no changes\n", name)
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "%s: changes to %s\n", name, strings.Join(sections, ", "))
+	return nil
+}
+
+func changedSections(current, desired string) ([]string, error) {
+	currentSections := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(current), &currentSections); err != nil {
+		return nil, err
+	}
+
+	desiredSections := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(desired), &desiredSections); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for name := range currentSections {
+		names[name] = true
+	}
+	for name := range desiredSections {
+		names[name] = true
+	}
+
+	sections := []string{}
+	for name := range names {
+		if !reflect.DeepEqual(currentSections[name], desiredSections[name]) {
+			sections = append(sections, name)
+		}
+	}
+	sort.Strings(sections)
+
+	return sections, nil
+}
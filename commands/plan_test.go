@@ -0,0 +1,81 @@
+package commands_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Plan", func() {
+	var (
+		command        commands.Plan
+		awsPlan        *fakes.AWSPlan
+		gcpPlan        *fakes.GCPPlan
+		stateValidator *fakes.StateValidator
+	)
+
+	BeforeEach(func() {
+		awsPlan = &fakes.AWSPlan{}
+		gcpPlan = &fakes.GCPPlan{}
+		stateValidator = &fakes.StateValidator{}
+
+		command = commands.NewPlan(awsPlan, gcpPlan, stateValidator)
+	})
+
+	Describe("Execute", func() {
+		It("plans the aws environment", func() {
+			state := storage.State{IAAS: "aws", EnvID: "some-env-id"}
+
+			err := command.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stateValidator.ValidateCall.CallCount).To(Equal(1))
+			Expect(awsPlan.ExecuteCall.CallCount).To(Equal(1))
+			Expect(awsPlan.ExecuteCall.Receives.State).To(Equal(state))
+			Expect(gcpPlan.ExecuteCall.CallCount).To(Equal(0))
+		})
+
+		It("plans the gcp environment", func() {
+			state := storage.State{IAAS: "gcp", EnvID: "some-env-id"}
+
+			err := command.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(gcpPlan.ExecuteCall.CallCount).To(Equal(1))
+			Expect(gcpPlan.ExecuteCall.Receives.State).To(Equal(state))
+			Expect(awsPlan.ExecuteCall.CallCount).To(Equal(0))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the state is invalid", func() {
+				stateValidator.ValidateCall.Returns.Error = errors.New("state validator failed")
+
+				err := command.Execute([]string{}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError("state validator failed"))
+				Expect(awsPlan.ExecuteCall.CallCount).To(Equal(0))
+			})
+
+			It("returns an error when an unknown flag is provided", func() {
+				err := command.Execute([]string{"--unknown-flag"}, storage.State{IAAS: "aws"})
+				Expect(err).To(MatchError("flag provided but not defined: -unknown-flag"))
+			})
+
+			It("returns an error when the iaas in state is invalid", func() {
+				err := command.Execute([]string{}, storage.State{IAAS: "some-iaas"})
+				Expect(err).To(MatchError(`"some-iaas" is an invalid iaas type in state, supported iaas types are: [gcp, aws]`))
+			})
+
+			It("returns an error when the plan fails", func() {
+				gcpPlan.ExecuteCall.Returns.Error = errors.New("failed to plan")
+
+				err := command.Execute([]string{}, storage.State{IAAS: "gcp"})
+				Expect(err).To(MatchError("failed to plan"))
+			})
+		})
+	})
+})
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	PrintEnvCommand = "print-env"
+
+	jumpboxUser = "vcap"
+)
+
+var tempFile = ioutil.TempFile
+
+type PrintEnv struct {
+	stateValidator stateValidator
+	stdout         io.Writer
+	queries        []envQuery
+}
+
+type envQuery struct {
+	name  string
+	query StateQuery
+}
+
+type envVar struct {
+	name  string
+	value string
+}
+
+func NewPrintEnv(stateValidator stateValidator, stdout io.Writer) PrintEnv {
+	return PrintEnv{
+		stateValidator: stateValidator,
+		stdout:         stdout,
+		queries: []envQuery{
+			{"BOSH_ENVIRONMENT", NewStateQuery(nil, stateValidator, TextOutput, DirectorAddressPropertyName, func(state storage.State) string {
+				return state.BOSH.DirectorAddress
+			})},
+			{"BOSH_CLIENT", NewStateQuery(nil, stateValidator, TextOutput, DirectorUsernamePropertyName, func(state storage.State) string {
+				return state.BOSH.DirectorUsername
+			})},
+			{"BOSH_CLIENT_SECRET", NewStateQuery(nil, stateValidator, TextOutput, DirectorPasswordPropertyName, func(state storage.State) string {
+				return state.BOSH.DirectorPassword
+			})},
+			{"BOSH_CA_CERT", NewStateQuery(nil, stateValidator, TextOutput, DirectorCACertPropertyName, func(state storage.State) string {
+				return state.BOSH.DirectorSSLCA
+			})},
+		},
+	}
+}
+
+func (p PrintEnv) Execute(subcommandFlags []string, state storage.State) error {
+	printEnvFlags := flags.New("print-env")
+
+	var shell string
+	printEnvFlags.String(&shell, "shell", "bash")
+
+	err := printEnvFlags.Parse(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	format, err := exportFormat(shell)
+	if err != nil {
+		return err
+	}
+
+	err = p.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	vars := []envVar{}
+	for _, q := range p.queries {
+		value, err := q.query.query(state)
+		if err != nil {
+			return err
+		}
+		vars = append(vars, envVar{q.name, value})
+	}
+
+	if state.KeyPair.PrivateKey != "" {
+		gatewayVars, err := p.gatewayVars(state)
+		if err != nil {
+			return err
+		}
+		vars = append(vars, gatewayVars...)
+	}
+
+	for _, v := range vars {
+		fmt.Fprintln(p.stdout, format(v.name, v.value))
+	}
+
+	return nil
+}
+
+func (p PrintEnv) gatewayVars(state storage.State) ([]envVar, error) {
+	host := state.BOSH.DirectorAddress
+	if directorURL, err := url.Parse(state.BOSH.DirectorAddress); err == nil && directorURL.Host != "" {
+		host = directorURL.Host
+	}
+	host = strings.Split(host, ":")[0]
+
+	keyFile, err := tempFile("", "bbl-jumpbox-key")
+	if err != nil {
+		return nil, err
+	}
+	defer keyFile.Close()
+
+	_, err = keyFile.WriteString(state.KeyPair.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	err = keyFile.Chmod(storage.OS_PRIVATE_READ_WRITE_MODE)
+	if err != nil {
+		return nil, err
+	}
+
+	return []envVar{
+		{"BOSH_GW_HOST", host},
+		{"BOSH_GW_USER", jumpboxUser},
+		{"BOSH_GW_PRIVATE_KEY", keyFile.Name()},
+		{"BOSH_ALL_PROXY", fmt.Sprintf("ssh+socks5://%s@%s:22?private-key=%s", jumpboxUser, host, keyFile.Name())},
+	}, nil
+}
+
+func exportFormat(shell string) (func(name, value string) string, error) {
+	switch shell {
+	case "bash":
+		return func(name, value string) string {
+			return fmt.Sprintf("export %s='%s'", name, strings.Replace(value, "'", `'\''`, -1))
+		}, nil
+	case "fish":
+		return func(name, value string) string {
+			value = strings.Replace(value, `\`, `\\`, -1)
+			return fmt.Sprintf("set -x %s '%s'", name, strings.Replace(value, "'", `\'`, -1))
+		}, nil
+	case "powershell":
+		return func(name, value string) string {
+			return fmt.Sprintf("$env:%s = '%s'", name, strings.Replace(value, "'", "''", -1))
+		}, nil
+	default:
+		return nil, fmt.Errorf("%q is an invalid shell, supported values are: [bash, fish, powershell]", shell)
+	}
+}
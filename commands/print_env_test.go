@@ -0,0 +1,159 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrintEnv", func() {
+	var (
+		stateValidator *fakes.StateValidator
+		stdout         *bytes.Buffer
+		printEnv       commands.PrintEnv
+		state          storage.State
+		tempDir        string
+	)
+
+	BeforeEach(func() {
+		stateValidator = &fakes.StateValidator{}
+		stdout = &bytes.Buffer{}
+
+		var err error
+		tempDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		commands.SetTempFile(func(dir, prefix string) (*os.File, error) {
+			return os.Create(tempDir + "/" + prefix)
+		})
+
+		state = storage.State{
+			BOSH: storage.BOSH{
+				DirectorAddress:  "https://some-director:25555",
+				DirectorUsername: "some-director-username",
+				DirectorPassword: "some-director-password",
+				DirectorSSLCA:    "some-ca-cert",
+			},
+			KeyPair: storage.KeyPair{
+				PrivateKey: "some-private-key",
+			},
+		}
+
+		printEnv = commands.NewPrintEnv(stateValidator, stdout)
+	})
+
+	AfterEach(func() {
+		commands.ResetTempFile()
+		os.RemoveAll(tempDir)
+	})
+
+	Describe("Execute", func() {
+		It("prints bash exports for the director and ssh gateway", func() {
+			err := printEnv.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(Equal(strings.Join([]string{
+				"export BOSH_ENVIRONMENT='https://some-director:25555'",
+				"export BOSH_CLIENT='some-director-username'",
+				"export BOSH_CLIENT_SECRET='some-director-password'",
+				"export BOSH_CA_CERT='some-ca-cert'",
+				"export BOSH_GW_HOST='some-director'",
+				"export BOSH_GW_USER='vcap'",
+				"export BOSH_GW_PRIVATE_KEY='" + tempDir + "/bbl-jumpbox-key'",
+				"export BOSH_ALL_PROXY='ssh+socks5://vcap@some-director:22?private-key=" + tempDir + "/bbl-jumpbox-key'",
+				"",
+			}, "\n")))
+		})
+
+		It("writes the private key to a file only readable by its owner", func() {
+			err := printEnv.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			key, err := ioutil.ReadFile(tempDir + "/bbl-jumpbox-key")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(key)).To(Equal("some-private-key"))
+
+			info, err := os.Stat(tempDir + "/bbl-jumpbox-key")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode()).To(Equal(os.FileMode(0600)))
+		})
+
+		It("escapes single quotes for bash", func() {
+			state.BOSH.DirectorPassword = "some-'password"
+
+			err := printEnv.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(ContainSubstring(`export BOSH_CLIENT_SECRET='some-'\''password'`))
+		})
+
+		It("prints fish variables", func() {
+			state.BOSH.DirectorPassword = `some-'pass\word`
+
+			err := printEnv.Execute([]string{"--shell", "fish"}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(ContainSubstring("set -x BOSH_ENVIRONMENT 'https://some-director:25555'\n"))
+			Expect(stdout.String()).To(ContainSubstring(`set -x BOSH_CLIENT_SECRET 'some-\'pass\\word'`))
+		})
+
+		It("prints powershell variables", func() {
+			state.BOSH.DirectorPassword = "some-'password"
+
+			err := printEnv.Execute([]string{"--shell", "powershell"}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(ContainSubstring("$env:BOSH_ENVIRONMENT = 'https://some-director:25555'\n"))
+			Expect(stdout.String()).To(ContainSubstring("$env:BOSH_CLIENT_SECRET = 'some-''password'"))
+		})
+
+		It("does not print a gateway when there is no private key", func() {
+			state.KeyPair.PrivateKey = ""
+
+			err := printEnv.Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).NotTo(ContainSubstring("BOSH_GW_HOST"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the shell is not supported", func() {
+				err := printEnv.Execute([]string{"--shell", "tcsh"}, state)
+				Expect(err).To(MatchError(`"tcsh" is an invalid shell, supported values are: [bash, fish, powershell]`))
+			})
+
+			It("returns an error when the state validator fails", func() {
+				stateValidator.ValidateCall.Returns.Error = errors.New("state validator failed")
+
+				err := printEnv.Execute([]string{}, state)
+				Expect(err).To(MatchError("state validator failed"))
+			})
+
+			It("returns an error when a director property is missing", func() {
+				state.BOSH.DirectorPassword = ""
+
+				err := printEnv.Execute([]string{}, state)
+				Expect(err).To(MatchError("Could not retrieve director password, please make sure you are targeting the proper state dir."))
+				Expect(stdout.String()).To(BeEmpty())
+			})
+
+			It("returns an error when the key file cannot be created", func() {
+				commands.SetTempFile(func(string, string) (*os.File, error) {
+					return nil, errors.New("failed to create temp file")
+				})
+
+				err := printEnv.Execute([]string{}, state)
+				Expect(err).To(MatchError("failed to create temp file"))
+			})
+		})
+	})
+})
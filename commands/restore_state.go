@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const RestoreStateCommand = "restore-state"
+
+type RestoreState struct {
+	stateBackups stateBackups
+	logger       logger
+}
+
+func NewRestoreState(stateBackups stateBackups, logger logger) RestoreState {
+	return RestoreState{
+		stateBackups: stateBackups,
+		logger:       logger,
+	}
+}
+
+func (r RestoreState) Execute(subcommandFlags []string, state storage.State) error {
+	if len(subcommandFlags) != 1 {
+		return errors.New("restore-state requires the number of the backup to restore, see bbl state-history")
+	}
+
+	n, err := strconv.Atoi(subcommandFlags[0])
+	if err != nil {
+		return fmt.Errorf("%q is not a valid backup number, see bbl state-history", subcommandFlags[0])
+	}
+
+	backup, err := r.stateBackups.Restore(n)
+	if err != nil {
+		return err
+	}
+
+	r.logger.Println(fmt.Sprintf("restored bbl-state.json from backup %d (%s)", n, backup.Timestamp.Format(time.RFC3339)))
+	return nil
+}
@@ -0,0 +1,61 @@
+package commands_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RestoreState", func() {
+	var (
+		stateBackups *fakes.StateBackups
+		logger       *fakes.Logger
+		restoreState commands.RestoreState
+	)
+
+	BeforeEach(func() {
+		stateBackups = &fakes.StateBackups{}
+		logger = &fakes.Logger{}
+
+		restoreState = commands.NewRestoreState(stateBackups, logger)
+	})
+
+	Describe("Execute", func() {
+		It("restores the given backup", func() {
+			stateBackups.RestoreCall.Returns.Backup = storage.StateBackup{
+				Timestamp: time.Date(2016, time.November, 1, 12, 2, 0, 0, time.UTC),
+			}
+
+			err := restoreState.Execute([]string{"2"}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stateBackups.RestoreCall.Receives.N).To(Equal(2))
+			Expect(logger.PrintlnCall.Receives.Message).To(Equal("restored bbl-state.json from backup 2 (2016-11-01T12:02:00Z)"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when no backup number is provided", func() {
+				err := restoreState.Execute([]string{}, storage.State{})
+				Expect(err).To(MatchError("restore-state requires the number of the backup to restore, see bbl state-history"))
+			})
+
+			It("returns an error when the backup number is not a number", func() {
+				err := restoreState.Execute([]string{"latest"}, storage.State{})
+				Expect(err).To(MatchError(`"latest" is not a valid backup number, see bbl state-history`))
+			})
+
+			It("returns an error when the backup cannot be restored", func() {
+				stateBackups.RestoreCall.Returns.Error = errors.New("failed to restore")
+
+				err := restoreState.Execute([]string{"2"}, storage.State{})
+				Expect(err).To(MatchError("failed to restore"))
+			})
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const StateHistoryCommand = "state-history"
+
+type stateBackups interface {
+	Backups() ([]storage.StateBackup, error)
+	Restore(n int) (storage.StateBackup, error)
+}
+
+type StateHistory struct {
+	stateBackups stateBackups
+	stdout       io.Writer
+}
+
+func NewStateHistory(stateBackups stateBackups, stdout io.Writer) StateHistory {
+	return StateHistory{
+		stateBackups: stateBackups,
+		stdout:       stdout,
+	}
+}
+
+func (s StateHistory) Execute(subcommandFlags []string, state storage.State) error {
+	backups, err := s.stateBackups.Backups()
+	if err != nil {
+		return err
+	}
+
+	if len(backups) == 0 {
+		fmt.Fprintln(s.stdout, "No backups of bbl-state.json found")
+		return nil
+	}
+
+	for i, backup := range backups {
+		fmt.Fprintf(s.stdout, "%d\t%s\n", i+1, backup.Timestamp.Format(time.RFC3339))
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StateHistory", func() {
+	var (
+		stateBackups *fakes.StateBackups
+		stdout       *bytes.Buffer
+		stateHistory commands.StateHistory
+	)
+
+	BeforeEach(func() {
+		stateBackups = &fakes.StateBackups{}
+		stdout = bytes.NewBuffer([]byte{})
+
+		stateHistory = commands.NewStateHistory(stateBackups, stdout)
+	})
+
+	Describe("Execute", func() {
+		It("prints the numbered backups", func() {
+			stateBackups.BackupsCall.Returns.Backups = []storage.StateBackup{
+				{Timestamp: time.Date(2016, time.November, 1, 12, 3, 0, 0, time.UTC)},
+				{Timestamp: time.Date(2016, time.November, 1, 12, 2, 0, 0, time.UTC)},
+			}
+
+			err := stateHistory.Execute([]string{}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(Equal("1\t2016-11-01T12:03:00Z\n2\t2016-11-01T12:02:00Z\n"))
+		})
+
+		It("prints a message when there are no backups", func() {
+			err := stateHistory.Execute([]string{}, storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(Equal("No backups of bbl-state.json found\n"))
+		})
+
+		It("returns an error when the backups cannot be listed", func() {
+			stateBackups.BackupsCall.Returns.Error = errors.New("failed to list backups")
+
+			err := stateHistory.Execute([]string{}, storage.State{})
+			Expect(err).To(MatchError("failed to list backups"))
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/cloudfoundry/bosh-bootloader/storage"
@@ -24,19 +25,31 @@ const (
 	BOSHCACertPropertyName       = "bosh ca cert"
 )
 
+var propertyJSONKeys = map[string]string{
+	EnvIDPropertyName:            "envID",
+	SSHKeyPropertyName:           "sshKey",
+	DirectorUsernamePropertyName: "directorUsername",
+	DirectorPasswordPropertyName: "directorPassword",
+	DirectorAddressPropertyName:  "directorAddress",
+	DirectorCACertPropertyName:   "directorCACert",
+	BOSHCACertPropertyName:       "directorCACert",
+}
+
 type StateQuery struct {
 	logger         logger
 	stateValidator stateValidator
+	output         string
 	propertyName   string
 	getProperty    getPropertyFunc
 }
 
 type getPropertyFunc func(storage.State) string
 
-func NewStateQuery(logger logger, stateValidator stateValidator, propertyName string, getProperty getPropertyFunc) StateQuery {
+func NewStateQuery(logger logger, stateValidator stateValidator, output string, propertyName string, getProperty getPropertyFunc) StateQuery {
 	return StateQuery{
 		logger:         logger,
 		stateValidator: stateValidator,
+		output:         output,
 		propertyName:   propertyName,
 		getProperty:    getProperty,
 	}
@@ -48,11 +61,30 @@ func (s StateQuery) Execute(subcommandFlags []string, state storage.State) error
 		return err
 	}
 
-	propertyValue := s.getProperty(state)
-	if propertyValue == "" {
-		return fmt.Errorf("Could not retrieve %s, please make sure you are targeting the proper state dir.", s.propertyName)
+	propertyValue, err := s.query(state)
+	if err != nil {
+		return err
+	}
+
+	if s.output == JSONOutput {
+		document, err := json.Marshal(map[string]string{propertyJSONKeys[s.propertyName]: propertyValue})
+		if err != nil {
+			return err
+		}
+
+		s.logger.Println(string(document))
+		return nil
 	}
 
 	s.logger.Println(propertyValue)
 	return nil
 }
+
+func (s StateQuery) query(state storage.State) (string, error) {
+	propertyValue := s.getProperty(state)
+	if propertyValue == "" {
+		return "", fmt.Errorf("Could not retrieve %s, please make sure you are targeting the proper state dir.", s.propertyName)
+	}
+
+	return propertyValue, nil
+}
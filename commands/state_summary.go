@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const StateCommand = "state"
+
+type StateSummary struct {
+	stateValidator stateValidator
+	stdout         io.Writer
+	output         string
+}
+
+type stateSummary struct {
+	IAAS             string `json:"iaas"`
+	EnvID            string `json:"envID"`
+	Region           string `json:"region"`
+	ProjectID        string `json:"projectID,omitempty"`
+	Zone             string `json:"zone,omitempty"`
+	StackName        string `json:"stackName,omitempty"`
+	DirectorName     string `json:"directorName"`
+	DirectorAddress  string `json:"directorAddress"`
+	DirectorUsername string `json:"directorUsername"`
+	LBType           string `json:"lbType"`
+	StateVersion     int    `json:"stateVersion"`
+}
+
+func NewStateSummary(stateValidator stateValidator, stdout io.Writer, output string) StateSummary {
+	return StateSummary{
+		stateValidator: stateValidator,
+		stdout:         stdout,
+		output:         output,
+	}
+}
+
+func (s StateSummary) Execute(subcommandFlags []string, state storage.State) error {
+	err := s.stateValidator.Validate()
+	if err != nil {
+		return err
+	}
+
+	summary := stateSummary{
+		IAAS:             state.IAAS,
+		EnvID:            state.EnvID,
+		DirectorName:     state.BOSH.DirectorName,
+		DirectorAddress:  state.BOSH.DirectorAddress,
+		DirectorUsername: state.BOSH.DirectorUsername,
+		StateVersion:     state.Version,
+	}
+
+	switch state.IAAS {
+	case "aws":
+		summary.Region = state.AWS.Region
+		summary.StackName = state.Stack.Name
+		summary.LBType = state.Stack.LBType
+	case "gcp":
+		summary.Region = state.GCP.Region
+		summary.ProjectID = state.GCP.ProjectID
+		summary.Zone = state.GCP.Zone
+		summary.LBType = state.LB.Type
+	}
+
+	if s.output == JSONOutput {
+		return printJSON(s.stdout, summary)
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"IAAS", summary.IAAS},
+		{"Environment ID", summary.EnvID},
+		{"Region", summary.Region},
+		{"Project ID", summary.ProjectID},
+		{"Zone", summary.Zone},
+		{"Stack", summary.StackName},
+		{"Director Name", summary.DirectorName},
+		{"Director Address", summary.DirectorAddress},
+		{"Director Username", summary.DirectorUsername},
+		{"LB Type", summary.LBType},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(s.stdout, "%s: %s\n", field.name, field.value)
+		}
+	}
+
+	return nil
+}
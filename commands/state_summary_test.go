@@ -0,0 +1,122 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StateSummary", func() {
+	var (
+		stateValidator *fakes.StateValidator
+		stdout         *bytes.Buffer
+		awsState       storage.State
+		gcpState       storage.State
+	)
+
+	BeforeEach(func() {
+		stateValidator = &fakes.StateValidator{}
+		stdout = &bytes.Buffer{}
+
+		awsState = storage.State{
+			Version: 3,
+			IAAS:    "aws",
+			EnvID:   "some-env-id",
+			AWS: storage.AWS{
+				AccessKeyID:     "some-access-key-id",
+				SecretAccessKey: "some-secret-access-key",
+				Region:          "some-region",
+			},
+			Stack: storage.Stack{
+				Name:   "some-stack-name",
+				LBType: "cf",
+			},
+			BOSH: storage.BOSH{
+				DirectorName:     "some-director-name",
+				DirectorAddress:  "some-director-address",
+				DirectorUsername: "some-director-username",
+				DirectorPassword: "some-director-password",
+			},
+		}
+
+		gcpState = storage.State{
+			Version: 3,
+			IAAS:    "gcp",
+			EnvID:   "some-env-id",
+			GCP: storage.GCP{
+				ServiceAccountKey: "some-service-account-key",
+				ProjectID:         "some-project-id",
+				Zone:              "some-zone",
+				Region:            "some-region",
+			},
+			LB: storage.LB{
+				Type: "concourse",
+			},
+		}
+	})
+
+	Describe("Execute", func() {
+		It("prints a summary of an aws environment", func() {
+			err := commands.NewStateSummary(stateValidator, stdout, commands.TextOutput).Execute([]string{}, awsState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(Equal(`IAAS: aws
+Environment ID: some-env-id
+Region: some-region
+Stack: some-stack-name
+Director Name: some-director-name
+Director Address: some-director-address
+Director Username: some-director-username
+LB Type: cf
+`))
+		})
+
+		It("prints a json summary of an aws environment without secrets", func() {
+			err := commands.NewStateSummary(stateValidator, stdout, commands.JSONOutput).Execute([]string{}, awsState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(MatchJSON(`{
+				"iaas": "aws",
+				"envID": "some-env-id",
+				"region": "some-region",
+				"stackName": "some-stack-name",
+				"directorName": "some-director-name",
+				"directorAddress": "some-director-address",
+				"directorUsername": "some-director-username",
+				"lbType": "cf",
+				"stateVersion": 3
+			}`))
+		})
+
+		It("prints a json summary of a gcp environment", func() {
+			err := commands.NewStateSummary(stateValidator, stdout, commands.JSONOutput).Execute([]string{}, gcpState)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(MatchJSON(`{
+				"iaas": "gcp",
+				"envID": "some-env-id",
+				"region": "some-region",
+				"projectID": "some-project-id",
+				"zone": "some-zone",
+				"directorName": "",
+				"directorAddress": "",
+				"directorUsername": "",
+				"lbType": "concourse",
+				"stateVersion": 3
+			}`))
+		})
+
+		It("returns an error when the state validator fails", func() {
+			stateValidator.ValidateCall.Returns.Error = errors.New("state validator failed")
+
+			err := commands.NewStateSummary(stateValidator, stdout, commands.TextOutput).Execute([]string{}, awsState)
+			Expect(err).To(MatchError("state validator failed"))
+		})
+	})
+})
@@ -3,11 +3,22 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
+	"github.com/cloudfoundry/bosh-bootloader/bosh"
+	"github.com/cloudfoundry/bosh-bootloader/boshinit"
+	"github.com/cloudfoundry/bosh-bootloader/boshinit/manifests"
 	"github.com/cloudfoundry/bosh-bootloader/flags"
 	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
+var validSHA1 = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
 type Up struct {
 	awsUp          awsUp
 	gcpUp          gcpUp
@@ -34,6 +45,10 @@ type envIDGenerator interface {
 type upConfig struct {
 	awsAccessKeyID       string
 	awsSecretAccessKey   string
+	awsSessionToken      string
+	awsProfile           string
+	awsAssumeRoleARN     string
+	awsExternalID        string
 	awsRegion            string
 	gcpServiceAccountKey string
 	gcpProjectID         string
@@ -41,6 +56,26 @@ type upConfig struct {
 	gcpRegion            string
 	iaas                 string
 	name                 string
+	noConfirm            bool
+	fromPhase            string
+	noDirector           bool
+	opsFilePaths         []string
+	opsFiles             []storage.OpsFile
+	clearOpsFiles        bool
+	templatePatchPaths   []string
+	templatePatches      []storage.TemplatePatch
+	boshURL              string
+	boshSHA1             string
+	cpiURL               string
+	cpiSHA1              string
+	stemcellURL          string
+	stemcellSHA1         string
+	versionOverrides     []storage.VersionOverride
+	airGapped            bool
+	directorSizing       storage.DirectorSizing
+	network              storage.Network
+	natType              string
+	natAMI               string
 }
 
 func NewUp(awsUp awsUp, gcpUp gcpUp, envGetter envGetter,
@@ -76,6 +111,42 @@ func (u Up) Execute(args []string, state storage.State) error {
 		}
 	}
 
+	if len(config.templatePatches) > 0 && desiredIAAS != "aws" {
+		return errors.New("--cloudformation-patch can only be used for aws environments")
+	}
+
+	if config.network.NATIP != "" && desiredIAAS != "aws" {
+		return errors.New("--nat-ip can only be used for aws environments")
+	}
+
+	if config.network.CIDR != "" && desiredIAAS == "aws" {
+		cidrBlock, err := bosh.ParseCIDRBlock(config.network.CIDR)
+		if err == nil && cidrBlock.CIDRSize > 1<<16 {
+			return errors.New("--network-cidr must be a /16 to /19 block for aws environments")
+		}
+	}
+
+	if config.natType != "" && desiredIAAS != "aws" {
+		return errors.New("--nat-type can only be used for aws environments")
+	}
+
+	if config.natAMI != "" && desiredIAAS != "aws" {
+		return errors.New("--nat-ami can only be used for aws environments")
+	}
+
+	natGateways := config.natType == templates.NATTypeGateway || config.natType == "" && state.Stack.NATType == templates.NATTypeGateway
+	if config.network.NATIP != "" && natGateways {
+		return errors.New("--nat-ip cannot be used with NAT gateways")
+	}
+
+	if config.natAMI != "" && natGateways {
+		return errors.New("--nat-ami cannot be used with NAT gateways")
+	}
+
+	if config.noDirector && !state.BOSH.IsEmpty() {
+		return errors.New("--no-director cannot be used for an environment that already has a director")
+	}
+
 	if state.EnvID != "" && config.name != "" {
 		return fmt.Errorf("The director name cannot be changed for an existing environment. Current name is %s.", state.EnvID)
 	}
@@ -94,9 +165,25 @@ func (u Up) Execute(args []string, state storage.State) error {
 	switch desiredIAAS {
 	case "aws":
 		err = u.awsUp.Execute(AWSUpConfig{
-			AccessKeyID:     config.awsAccessKeyID,
-			SecretAccessKey: config.awsSecretAccessKey,
-			Region:          config.awsRegion,
+			AccessKeyID:      config.awsAccessKeyID,
+			SecretAccessKey:  config.awsSecretAccessKey,
+			SessionToken:     config.awsSessionToken,
+			Profile:          config.awsProfile,
+			AssumeRoleARN:    config.awsAssumeRoleARN,
+			ExternalID:       config.awsExternalID,
+			Region:           config.awsRegion,
+			NoConfirm:        config.noConfirm,
+			FromPhase:        config.fromPhase,
+			NoDirector:       config.noDirector,
+			OpsFiles:         config.opsFiles,
+			ClearOpsFiles:    config.clearOpsFiles,
+			TemplatePatches:  config.templatePatches,
+			VersionOverrides: config.versionOverrides,
+			AirGapped:        config.airGapped,
+			DirectorSizing:   config.directorSizing,
+			Network:          config.network,
+			NATType:          config.natType,
+			NATAMI:           config.natAMI,
 		}, state)
 	case "gcp":
 		err = u.gcpUp.Execute(GCPUpConfig{
@@ -104,6 +191,15 @@ func (u Up) Execute(args []string, state storage.State) error {
 			ProjectID:             config.gcpProjectID,
 			Zone:                  config.gcpZone,
 			Region:                config.gcpRegion,
+			NoConfirm:             config.noConfirm,
+			FromPhase:             config.fromPhase,
+			NoDirector:            config.noDirector,
+			OpsFiles:              config.opsFiles,
+			ClearOpsFiles:         config.clearOpsFiles,
+			VersionOverrides:      config.versionOverrides,
+			AirGapped:             config.airGapped,
+			DirectorSizing:        config.directorSizing,
+			Network:               config.network,
 		}, state)
 	default:
 		return fmt.Errorf("%q is an invalid iaas type, supported values are: [gcp, aws]", desiredIAAS)
@@ -116,6 +212,145 @@ func (u Up) Execute(args []string, state storage.State) error {
 	return nil
 }
 
+// restoreUpConfig puts back the settings given to up when the update is cancelled,
+// so that a later up with --no-confirm does not apply them without asking.
+func restoreUpConfig(state, previous storage.State) storage.State {
+	state.NoDirector = previous.NoDirector
+	state.Outputs = previous.Outputs
+	state.OpsFiles = previous.OpsFiles
+	state.VersionOverrides = previous.VersionOverrides
+	state.AirGapped = previous.AirGapped
+	state.DirectorSizing = previous.DirectorSizing
+	state.Network = previous.Network
+	state.Stack.TemplatePatches = previous.Stack.TemplatePatches
+	return state
+}
+
+// mergeVersionOverrides replaces the overrides in the state with the ones given to
+// up, keeping the overrides for the releases and stemcell that were not given.
+func mergeVersionOverrides(current, overrides []storage.VersionOverride) []storage.VersionOverride {
+	merged := []storage.VersionOverride{}
+	for _, name := range boshinit.VersionNames {
+		if override, ok := findVersionOverride(overrides, name); ok {
+			merged = append(merged, override)
+		} else if override, ok := findVersionOverride(current, name); ok {
+			merged = append(merged, override)
+		}
+	}
+
+	if len(merged) == 0 {
+		return nil
+	}
+
+	return merged
+}
+
+// mergeDirectorSizing replaces the director sizing in the state with the fields given
+// to up. The persistent disk of an existing director can only grow, since bosh-init
+// copies its contents to the new disk.
+func mergeDirectorSizing(state storage.State, sizing storage.DirectorSizing) (*storage.DirectorSizing, error) {
+	merged := storage.DirectorSizing{}
+	if state.DirectorSizing != nil {
+		merged = *state.DirectorSizing
+	}
+
+	if sizing.InstanceType != "" {
+		merged.InstanceType = sizing.InstanceType
+	}
+
+	if sizing.EphemeralDiskSizeGB != 0 {
+		merged.EphemeralDiskSizeGB = sizing.EphemeralDiskSizeGB
+	}
+
+	if sizing.EphemeralDiskType != "" {
+		merged.EphemeralDiskType = sizing.EphemeralDiskType
+	}
+
+	if sizing.PersistentDiskSizeGB != 0 {
+		currentSize := merged.PersistentDiskSizeGB
+		if currentSize == 0 {
+			currentSize = manifests.DefaultPersistentDiskSizeGB
+		}
+
+		if !state.BOSH.IsEmpty() && sizing.PersistentDiskSizeGB < currentSize {
+			return nil, fmt.Errorf("the persistent disk of the director cannot be shrunk from %dGB to %dGB", currentSize, sizing.PersistentDiskSizeGB)
+		}
+
+		merged.PersistentDiskSizeGB = sizing.PersistentDiskSizeGB
+	}
+
+	if sizing.PersistentDiskType != "" {
+		merged.PersistentDiskType = sizing.PersistentDiskType
+	}
+
+	if merged == (storage.DirectorSizing{}) {
+		return nil, nil
+	}
+
+	return &merged, nil
+}
+
+// mergeNetwork replaces the network in the state with the fields given to up. The
+// address ranges cannot be changed once the infrastructure has been created.
+func mergeNetwork(state storage.State, network storage.Network, infrastructureExists bool) (*storage.Network, error) {
+	merged := storage.Network{}
+	if state.Network != nil {
+		merged = *state.Network
+	}
+
+	if network.CIDR != "" {
+		merged.CIDR = network.CIDR
+	}
+
+	if network.DirectorIP != "" {
+		merged.DirectorIP = network.DirectorIP
+	}
+
+	if network.NATIP != "" {
+		merged.NATIP = network.NATIP
+	}
+
+	layout, err := bosh.NewNetworkLayout(merged.CIDR, merged.DirectorIP, merged.NATIP)
+	if err != nil {
+		return nil, err
+	}
+
+	if infrastructureExists {
+		current, err := networkLayout(state.Network)
+		if err != nil {
+			return nil, err
+		}
+
+		if layout != current {
+			return nil, errors.New("the network cannot be changed for an existing environment")
+		}
+	}
+
+	if merged == (storage.Network{}) {
+		return nil, nil
+	}
+
+	return &merged, nil
+}
+
+func networkLayout(network *storage.Network) (bosh.NetworkLayout, error) {
+	if network == nil {
+		return bosh.NewNetworkLayout("", "", "")
+	}
+
+	return bosh.NewNetworkLayout(network.CIDR, network.DirectorIP, network.NATIP)
+}
+
+func findVersionOverride(overrides []storage.VersionOverride, name string) (storage.VersionOverride, bool) {
+	for _, override := range overrides {
+		if override.Name == name {
+			return override, true
+		}
+	}
+
+	return storage.VersionOverride{}, false
+}
+
 func (u Up) parseArgs(args []string) (upConfig, error) {
 	var config upConfig
 
@@ -125,6 +360,10 @@ func (u Up) parseArgs(args []string) (upConfig, error) {
 
 	upFlags.String(&config.awsAccessKeyID, "aws-access-key-id", u.envGetter.Get("BBL_AWS_ACCESS_KEY_ID"))
 	upFlags.String(&config.awsSecretAccessKey, "aws-secret-access-key", u.envGetter.Get("BBL_AWS_SECRET_ACCESS_KEY"))
+	upFlags.String(&config.awsSessionToken, "aws-session-token", u.envGetter.Get("BBL_AWS_SESSION_TOKEN"))
+	upFlags.String(&config.awsProfile, "aws-profile", u.envGetter.Get("BBL_AWS_PROFILE"))
+	upFlags.String(&config.awsAssumeRoleARN, "aws-assume-role-arn", u.envGetter.Get("BBL_AWS_ASSUME_ROLE_ARN"))
+	upFlags.String(&config.awsExternalID, "aws-external-id", u.envGetter.Get("BBL_AWS_EXTERNAL_ID"))
 	upFlags.String(&config.awsRegion, "aws-region", u.envGetter.Get("BBL_AWS_REGION"))
 
 	upFlags.String(&config.gcpServiceAccountKey, "gcp-service-account-key", u.envGetter.Get("BBL_GCP_SERVICE_ACCOUNT_KEY"))
@@ -133,11 +372,123 @@ func (u Up) parseArgs(args []string) (upConfig, error) {
 	upFlags.String(&config.gcpRegion, "gcp-region", u.envGetter.Get("BBL_GCP_REGION"))
 
 	upFlags.String(&config.name, "name", "")
+	upFlags.Bool(&config.noConfirm, "n", "no-confirm", false)
+	upFlags.String(&config.fromPhase, "from-phase", "")
+	upFlags.Bool(&config.noDirector, "", "no-director", false)
+	upFlags.StringSlice(&config.opsFilePaths, "ops-file", nil)
+	upFlags.Bool(&config.clearOpsFiles, "", "clear-ops-files", false)
+	upFlags.StringSlice(&config.templatePatchPaths, "cloudformation-patch", nil)
+	upFlags.String(&config.boshURL, "bosh-url", "")
+	upFlags.String(&config.boshSHA1, "bosh-sha1", "")
+	upFlags.String(&config.cpiURL, "cpi-url", "")
+	upFlags.String(&config.cpiSHA1, "cpi-sha1", "")
+	upFlags.String(&config.stemcellURL, "stemcell-url", "")
+	upFlags.String(&config.stemcellSHA1, "stemcell-sha1", "")
+	upFlags.Bool(&config.airGapped, "", "air-gapped", false)
+	upFlags.String(&config.directorSizing.InstanceType, "director-instance-type", "")
+	upFlags.Int(&config.directorSizing.EphemeralDiskSizeGB, "director-ephemeral-disk-size", 0)
+	upFlags.String(&config.directorSizing.EphemeralDiskType, "director-ephemeral-disk-type", "")
+	upFlags.Int(&config.directorSizing.PersistentDiskSizeGB, "director-persistent-disk-size", 0)
+	upFlags.String(&config.directorSizing.PersistentDiskType, "director-persistent-disk-type", "")
+	upFlags.String(&config.network.CIDR, "network-cidr", "")
+	upFlags.String(&config.network.DirectorIP, "director-ip", "")
+	upFlags.String(&config.network.NATIP, "nat-ip", "")
+	upFlags.String(&config.natType, "nat-type", "")
+	upFlags.String(&config.natAMI, "nat-ami", "")
 
 	err := upFlags.Parse(args)
 	if err != nil {
 		return upConfig{}, err
 	}
 
+	if config.fromPhase != "" {
+		if err := validatePhase(config.fromPhase); err != nil {
+			return upConfig{}, err
+		}
+	}
+
+	switch config.natType {
+	case "", templates.NATTypeInstance, templates.NATTypeGateway:
+	default:
+		return upConfig{}, fmt.Errorf("--nat-type must be %q or %q, got %q", templates.NATTypeInstance, templates.NATTypeGateway, config.natType)
+	}
+
+	if config.clearOpsFiles && len(config.opsFilePaths) > 0 {
+		return upConfig{}, errors.New("--clear-ops-files cannot be used with --ops-file")
+	}
+
+	for _, path := range config.opsFilePaths {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return upConfig{}, fmt.Errorf("error reading ops file: %v", err)
+		}
+
+		if _, err := manifests.ParseOps(contents); err != nil {
+			return upConfig{}, fmt.Errorf("invalid ops file %q: %s", path, err)
+		}
+
+		config.opsFiles = append(config.opsFiles, storage.OpsFile{
+			Name:     filepath.Base(path),
+			Contents: string(contents),
+		})
+	}
+
+	if config.directorSizing.EphemeralDiskSizeGB < 0 {
+		return upConfig{}, errors.New("--director-ephemeral-disk-size must be a positive number of GB")
+	}
+
+	if config.directorSizing.PersistentDiskSizeGB < 0 {
+		return upConfig{}, errors.New("--director-persistent-disk-size must be a positive number of GB")
+	}
+
+	for _, override := range []storage.VersionOverride{
+		{Name: boshinit.BOSHReleaseVersion, URL: config.boshURL, SHA1: config.boshSHA1},
+		{Name: boshinit.CPIReleaseVersion, URL: config.cpiURL, SHA1: config.cpiSHA1},
+		{Name: boshinit.StemcellVersion, URL: config.stemcellURL, SHA1: config.stemcellSHA1},
+	} {
+		if override.URL == "" && override.SHA1 == "" {
+			continue
+		}
+
+		if override.URL == "" || override.SHA1 == "" {
+			return upConfig{}, fmt.Errorf("--%s-url and --%s-sha1 must be provided together", override.Name, override.Name)
+		}
+
+		if !validSHA1.MatchString(override.SHA1) {
+			return upConfig{}, fmt.Errorf("%q is an invalid --%s-sha1, it must be 40 hexadecimal characters", override.SHA1, override.Name)
+		}
+
+		if !strings.Contains(override.URL, "://") {
+			path, err := filepath.Abs(override.URL)
+			if err != nil {
+				return upConfig{}, err
+			}
+
+			if _, err := os.Stat(path); err != nil {
+				return upConfig{}, fmt.Errorf("error reading --%s-url: %v", override.Name, err)
+			}
+
+			override.URL = "file://" + path
+		}
+
+		config.versionOverrides = append(config.versionOverrides, override)
+	}
+
+	for _, path := range config.templatePatchPaths {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return upConfig{}, fmt.Errorf("error reading cloudformation patch: %v", err)
+		}
+
+		if _, err := templates.ParsePatch(filepath.Base(path), contents); err != nil {
+			return upConfig{}, fmt.Errorf("invalid cloudformation patch %q: %s", path, err)
+		}
+
+		config.templatePatches = append(config.templatePatches, storage.TemplatePatch{
+			Name:     filepath.Base(path),
+			Contents: string(contents),
+		})
+	}
+
 	return config, nil
 }
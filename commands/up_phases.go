@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const (
+	KeyPairPhase        = "key-pair"
+	InfrastructurePhase = "infrastructure"
+	DirectorPhase       = "director"
+	CloudConfigPhase    = "cloud-config"
+)
+
+var upPhaseNames = []string{KeyPairPhase, InfrastructurePhase, DirectorPhase, CloudConfigPhase}
+
+type upPhases struct {
+	logger    logger
+	fromPhase string
+	running   bool
+	skipped   bool
+	hashes    map[string]string
+}
+
+func newUpPhases(logger logger, fromPhase string) *upPhases {
+	return &upPhases{
+		logger:    logger,
+		fromPhase: fromPhase,
+		hashes:    map[string]string{},
+	}
+}
+
+func validatePhase(phase string) error {
+	for _, name := range upPhaseNames {
+		if name == phase {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%q is an invalid phase, supported phases are: [%s]", phase, strings.Join(upPhaseNames, ", "))
+}
+
+// start decides whether a phase can be skipped. A phase is skipped when it precedes
+// --from-phase, or when the previous run completed it with the same inputs and every
+// phase before it was skipped as well. completed reports whether the outputs of the
+// phase are available in the state.
+func (p *upPhases) start(state storage.State, phase string, completed bool, inputs ...interface{}) (storage.State, bool, error) {
+	hash, err := phaseInputHash(inputs...)
+	if err != nil {
+		return state, false, err
+	}
+	p.hashes[phase] = hash
+
+	if !p.running {
+		if p.fromPhase != "" && p.fromPhase != phase {
+			if !completed {
+				return state, false, fmt.Errorf("cannot start from phase %q, phase %q has not completed yet", p.fromPhase, phase)
+			}
+
+			p.logger.Step("skipping phase %q, starting from phase %q", phase, p.fromPhase)
+			p.skipped = true
+			return state, true, nil
+		}
+
+		if p.fromPhase == "" && completed && checkpointHash(state.Checkpoints, phase) == hash {
+			p.logger.Step("skipping phase %q, its inputs have not changed since the last run", phase)
+			p.skipped = true
+			return state, true, nil
+		}
+
+		p.running = true
+		if p.skipped {
+			p.logger.Step("resuming bbl up from phase %q", phase)
+		}
+	}
+
+	state.Checkpoints = checkpointsBefore(state.Checkpoints, phase)
+
+	return state, false, nil
+}
+
+func (p *upPhases) complete(state storage.State, phase string) storage.State {
+	state.Checkpoints = append(checkpointsBefore(state.Checkpoints, phase), storage.Checkpoint{
+		Phase:     phase,
+		InputHash: p.hashes[phase],
+	})
+
+	return state
+}
+
+func phaseInputHash(inputs ...interface{}) (string, error) {
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+func checkpointHash(checkpoints []storage.Checkpoint, phase string) string {
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Phase == phase {
+			return checkpoint.InputHash
+		}
+	}
+
+	return ""
+}
+
+func checkpointsBefore(checkpoints []storage.Checkpoint, phase string) []storage.Checkpoint {
+	before := []storage.Checkpoint{}
+	for _, name := range upPhaseNames {
+		if name == phase {
+			break
+		}
+
+		for _, checkpoint := range checkpoints {
+			if checkpoint.Phase == name {
+				before = append(before, checkpoint)
+			}
+		}
+	}
+
+	if len(before) == 0 {
+		return nil
+	}
+
+	return before
+}
@@ -2,6 +2,9 @@ package commands_test
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	"github.com/cloudfoundry/bosh-bootloader/commands"
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
@@ -95,9 +98,63 @@ var _ = Describe("Up", func() {
 						Region:          "region-from-args",
 					},
 				),
+				Entry("no confirm",
+					[]string{"--no-confirm"},
+					commands.AWSUpConfig{
+						AccessKeyID:     "access-key-id-from-env",
+						SecretAccessKey: "secret-access-key-from-env",
+						Region:          "region-from-env",
+						NoConfirm:       true,
+					},
+				),
+				Entry("from phase",
+					[]string{"--from-phase", "director"},
+					commands.AWSUpConfig{
+						AccessKeyID:     "access-key-id-from-env",
+						SecretAccessKey: "secret-access-key-from-env",
+						Region:          "region-from-env",
+						FromPhase:       "director",
+					},
+				),
+				Entry("no director",
+					[]string{"--no-director"},
+					commands.AWSUpConfig{
+						AccessKeyID:     "access-key-id-from-env",
+						SecretAccessKey: "secret-access-key-from-env",
+						Region:          "region-from-env",
+						NoDirector:      true,
+					},
+				),
 			)
 		})
 
+		Context("when aws session, profile and assume role args are provided", func() {
+			It("passes them to the aws up command, giving precedence to command line args", func() {
+				fakeEnvGetter.Values = map[string]string{
+					"BBL_AWS_SESSION_TOKEN":   "session-token-from-env",
+					"BBL_AWS_PROFILE":         "profile-from-env",
+					"BBL_AWS_ASSUME_ROLE_ARN": "assume-role-arn-from-env",
+					"BBL_AWS_EXTERNAL_ID":     "external-id-from-env",
+					"BBL_AWS_REGION":          "region-from-env",
+				}
+
+				err := command.Execute([]string{
+					"--iaas", "aws",
+					"--aws-profile", "profile-from-args",
+					"--aws-external-id", "external-id-from-args",
+				}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig).To(Equal(commands.AWSUpConfig{
+					SessionToken:  "session-token-from-env",
+					Profile:       "profile-from-args",
+					AssumeRoleARN: "assume-role-arn-from-env",
+					ExternalID:    "external-id-from-args",
+					Region:        "region-from-env",
+				}))
+			})
+		})
+
 		Context("env id", func() {
 			Context("when the env id doesn't exist", func() {
 				It("populates a new bbl env id", func() {
@@ -241,6 +298,36 @@ var _ = Describe("Up", func() {
 						Region:                "some-region-from-args",
 					},
 				),
+				Entry("no confirm",
+					[]string{"--no-confirm"},
+					commands.GCPUpConfig{
+						ServiceAccountKeyPath: "some-service-account-key-env",
+						ProjectID:             "some-project-id-env",
+						Zone:                  "some-zone-env",
+						Region:                "some-region-env",
+						NoConfirm:             true,
+					},
+				),
+				Entry("from phase",
+					[]string{"--from-phase", "cloud-config"},
+					commands.GCPUpConfig{
+						ServiceAccountKeyPath: "some-service-account-key-env",
+						ProjectID:             "some-project-id-env",
+						Zone:                  "some-zone-env",
+						Region:                "some-region-env",
+						FromPhase:             "cloud-config",
+					},
+				),
+				Entry("no director",
+					[]string{"--no-director"},
+					commands.GCPUpConfig{
+						ServiceAccountKeyPath: "some-service-account-key-env",
+						ProjectID:             "some-project-id-env",
+						Zone:                  "some-zone-env",
+						Region:                "some-region-env",
+						NoDirector:            true,
+					},
+				),
 			)
 		})
 
@@ -372,6 +459,311 @@ var _ = Describe("Up", func() {
 					err := command.Execute([]string{"--foo", "bar"}, storage.State{})
 					Expect(err).To(MatchError("flag provided but not defined: -foo"))
 				})
+
+				It("returns an error when an invalid phase is passed to --from-phase", func() {
+					err := command.Execute([]string{"--iaas", "aws", "--from-phase", "bosh"}, storage.State{})
+					Expect(err).To(MatchError(`"bosh" is an invalid phase, supported phases are: [key-pair, infrastructure, director, cloud-config]`))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				It("returns an error when --no-director is passed for an environment with a director", func() {
+					err := command.Execute([]string{"--iaas", "aws", "--no-director"}, storage.State{
+						BOSH: storage.BOSH{DirectorName: "some-director"},
+					})
+					Expect(err).To(MatchError("--no-director cannot be used for an environment that already has a director"))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
+			Context("ops files", func() {
+				var opsFileDir string
+
+				BeforeEach(func() {
+					var err error
+					opsFileDir, err = ioutil.TempDir("", "ops-files")
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				AfterEach(func() {
+					os.RemoveAll(opsFileDir)
+				})
+
+				It("reads the ops files and passes them to up", func() {
+					syslogPath := filepath.Join(opsFileDir, "syslog.yml")
+					workersPath := filepath.Join(opsFileDir, "workers.yml")
+					Expect(ioutil.WriteFile(syslogPath, []byte("- type: remove\n  path: /syslog?\n"), os.ModePerm)).To(Succeed())
+					Expect(ioutil.WriteFile(workersPath, []byte("- type: replace\n  path: /workers?\n  value: 6\n"), os.ModePerm)).To(Succeed())
+
+					err := command.Execute([]string{"--iaas", "aws", "--ops-file", syslogPath, "--ops-file", workersPath}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.OpsFiles).To(Equal([]storage.OpsFile{
+						{Name: "syslog.yml", Contents: "- type: remove\n  path: /syslog?\n"},
+						{Name: "workers.yml", Contents: "- type: replace\n  path: /workers?\n  value: 6\n"},
+					}))
+				})
+
+				It("returns an error when an ops file cannot be read", func() {
+					err := command.Execute([]string{"--iaas", "aws", "--ops-file", filepath.Join(opsFileDir, "missing.yml")}, storage.State{})
+					Expect(err).To(MatchError(ContainSubstring("error reading ops file")))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				It("returns an error when an ops file is invalid", func() {
+					invalidPath := filepath.Join(opsFileDir, "invalid.yml")
+					Expect(ioutil.WriteFile(invalidPath, []byte("- type: merge\n  path: /name\n"), os.ModePerm)).To(Succeed())
+
+					err := command.Execute([]string{"--iaas", "aws", "--ops-file", invalidPath}, storage.State{})
+					Expect(err).To(MatchError(ContainSubstring(`invalid ops file "` + invalidPath + `"`)))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				It("passes clear-ops-files to aws up", func() {
+					err := command.Execute([]string{"--iaas", "aws", "--clear-ops-files"}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.ClearOpsFiles).To(BeTrue())
+				})
+
+				It("passes clear-ops-files to gcp up", func() {
+					err := command.Execute([]string{"--iaas", "gcp", "--clear-ops-files"}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.ClearOpsFiles).To(BeTrue())
+				})
+
+				It("returns an error when clear-ops-files is given with ops files", func() {
+					syslogPath := filepath.Join(opsFileDir, "syslog.yml")
+					Expect(ioutil.WriteFile(syslogPath, []byte("- type: remove\n  path: /syslog?\n"), os.ModePerm)).To(Succeed())
+
+					err := command.Execute([]string{"--iaas", "aws", "--clear-ops-files", "--ops-file", syslogPath}, storage.State{})
+					Expect(err).To(MatchError("--clear-ops-files cannot be used with --ops-file"))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
+			Context("cloudformation patches", func() {
+				var patchDir string
+
+				BeforeEach(func() {
+					var err error
+					patchDir, err = ioutil.TempDir("", "cloudformation-patches")
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				AfterEach(func() {
+					os.RemoveAll(patchDir)
+				})
+
+				It("reads the patches and passes them to aws up", func() {
+					patchPath := filepath.Join(patchDir, "flow-logs.json")
+					patch := `{"Resources": {"FlowLogs": {"Type": "AWS::EC2::FlowLog"}}}`
+					Expect(ioutil.WriteFile(patchPath, []byte(patch), os.ModePerm)).To(Succeed())
+
+					err := command.Execute([]string{"--iaas", "aws", "--cloudformation-patch", patchPath}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.TemplatePatches).To(Equal([]storage.TemplatePatch{
+						{Name: "flow-logs.json", Contents: patch},
+					}))
+				})
+
+				It("returns an error when a patch cannot be read", func() {
+					err := command.Execute([]string{"--iaas", "aws", "--cloudformation-patch", filepath.Join(patchDir, "missing.json")}, storage.State{})
+					Expect(err).To(MatchError(ContainSubstring("error reading cloudformation patch")))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				It("returns an error when a patch is invalid", func() {
+					invalidPath := filepath.Join(patchDir, "invalid.json")
+					Expect(ioutil.WriteFile(invalidPath, []byte(`{"Resources": {"FlowLogs": {"Type": "FlowLog"}}}`), os.ModePerm)).To(Succeed())
+
+					err := command.Execute([]string{"--iaas", "aws", "--cloudformation-patch", invalidPath}, storage.State{})
+					Expect(err).To(MatchError(`invalid cloudformation patch "` + invalidPath + `": resource "FlowLogs" has an invalid type "FlowLog"`))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				It("returns an error for gcp environments", func() {
+					patchPath := filepath.Join(patchDir, "flow-logs.json")
+					Expect(ioutil.WriteFile(patchPath, []byte(`{"Resources": {"FlowLogs": {"Type": "AWS::EC2::FlowLog"}}}`), os.ModePerm)).To(Succeed())
+
+					err := command.Execute([]string{"--iaas", "gcp", "--cloudformation-patch", patchPath}, storage.State{})
+					Expect(err).To(MatchError("--cloudformation-patch can only be used for aws environments"))
+					Expect(fakeGCPUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
+			Context("director sizing", func() {
+				It("passes the director sizing to up", func() {
+					err := command.Execute([]string{
+						"--iaas", "aws",
+						"--director-instance-type", "m4.2xlarge",
+						"--director-ephemeral-disk-size", "50",
+						"--director-ephemeral-disk-type", "io1",
+						"--director-persistent-disk-size", "200",
+						"--director-persistent-disk-type", "io1",
+					}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.DirectorSizing).To(Equal(storage.DirectorSizing{
+						InstanceType:         "m4.2xlarge",
+						EphemeralDiskSizeGB:  50,
+						EphemeralDiskType:    "io1",
+						PersistentDiskSizeGB: 200,
+						PersistentDiskType:   "io1",
+					}))
+				})
+
+				It("returns an error when a disk size is negative", func() {
+					err := command.Execute([]string{"--iaas", "gcp", "--director-persistent-disk-size", "-10"}, storage.State{})
+					Expect(err).To(MatchError("--director-persistent-disk-size must be a positive number of GB"))
+					Expect(fakeGCPUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
+			Context("network", func() {
+				It("passes the network to aws up", func() {
+					err := command.Execute([]string{
+						"--iaas", "aws",
+						"--network-cidr", "172.20.0.0/16",
+						"--director-ip", "172.20.0.10",
+						"--nat-ip", "172.20.0.11",
+					}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.Network).To(Equal(storage.Network{
+						CIDR:       "172.20.0.0/16",
+						DirectorIP: "172.20.0.10",
+						NATIP:      "172.20.0.11",
+					}))
+				})
+
+				It("passes the network to gcp up", func() {
+					err := command.Execute([]string{
+						"--iaas", "gcp",
+						"--network-cidr", "172.20.0.0/16",
+						"--director-ip", "172.20.0.10",
+					}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.Network).To(Equal(storage.Network{
+						CIDR:       "172.20.0.0/16",
+						DirectorIP: "172.20.0.10",
+					}))
+				})
+
+				It("returns an error when the network is larger than an aws VPC", func() {
+					err := command.Execute([]string{"--iaas", "aws", "--network-cidr", "10.0.0.0/12"}, storage.State{})
+					Expect(err).To(MatchError("--network-cidr must be a /16 to /19 block for aws environments"))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				It("allows a network larger than a /16 for gcp", func() {
+					err := command.Execute([]string{"--iaas", "gcp", "--network-cidr", "10.0.0.0/12"}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.Network.CIDR).To(Equal("10.0.0.0/12"))
+				})
+
+				It("passes the NAT type to aws up", func() {
+					err := command.Execute([]string{"--iaas", "aws", "--nat-type", "gateway"}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.NATType).To(Equal("gateway"))
+				})
+
+				It("returns an error when the NAT type is invalid", func() {
+					err := command.Execute([]string{"--iaas", "aws", "--nat-type", "some-nat-type"}, storage.State{})
+					Expect(err).To(MatchError(`--nat-type must be "instance" or "gateway", got "some-nat-type"`))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				It("returns an error when a NAT type is given for gcp", func() {
+					err := command.Execute([]string{"--iaas", "gcp", "--nat-type", "gateway"}, storage.State{})
+					Expect(err).To(MatchError("--nat-type can only be used for aws environments"))
+					Expect(fakeGCPUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				It("returns an error when a NAT IP is given for NAT gateways", func() {
+					err := command.Execute([]string{"--nat-ip", "10.0.0.10"}, storage.State{
+						IAAS:  "aws",
+						Stack: storage.Stack{NATType: "gateway"},
+					})
+					Expect(err).To(MatchError("--nat-ip cannot be used with NAT gateways"))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				It("passes the NAT AMI to aws up", func() {
+					err := command.Execute([]string{"--iaas", "aws", "--nat-ami", "latest"}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.NATAMI).To(Equal("latest"))
+				})
+
+				It("returns an error when a NAT AMI is given for NAT gateways", func() {
+					err := command.Execute([]string{"--iaas", "aws", "--nat-type", "gateway", "--nat-ami", "latest"}, storage.State{})
+					Expect(err).To(MatchError("--nat-ami cannot be used with NAT gateways"))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				It("returns an error when a NAT IP is given for gcp", func() {
+					err := command.Execute([]string{"--iaas", "gcp", "--nat-ip", "10.0.0.7"}, storage.State{})
+					Expect(err).To(MatchError("--nat-ip can only be used for aws environments"))
+					Expect(fakeGCPUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+			})
+
+			Context("version overrides", func() {
+				var sha1 = "0123456789abcdef0123456789abcdef01234567"
+
+				It("passes the release and stemcell overrides to up", func() {
+					err := command.Execute([]string{
+						"--iaas", "gcp",
+						"--stemcell-url", "https://example.com/stemcell.tgz", "--stemcell-sha1", sha1,
+						"--bosh-url", "https://example.com/bosh.tgz", "--bosh-sha1", sha1,
+					}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeGCPUp.ExecuteCall.Receives.GCPUpConfig.VersionOverrides).To(Equal([]storage.VersionOverride{
+						{Name: "bosh", URL: "https://example.com/bosh.tgz", SHA1: sha1},
+						{Name: "stemcell", URL: "https://example.com/stemcell.tgz", SHA1: sha1},
+					}))
+				})
+
+				It("returns an error when a url is given without a sha1", func() {
+					err := command.Execute([]string{"--iaas", "aws", "--cpi-url", "some-cpi-url"}, storage.State{})
+					Expect(err).To(MatchError("--cpi-url and --cpi-sha1 must be provided together"))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				It("returns an error when a sha1 is invalid", func() {
+					err := command.Execute([]string{"--iaas", "aws", "--bosh-url", "some-bosh-url", "--bosh-sha1", "some-sha1"}, storage.State{})
+					Expect(err).To(MatchError(`"some-sha1" is an invalid --bosh-sha1, it must be 40 hexadecimal characters`))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
+
+				It("turns local paths into file urls", func() {
+					stemcellFile, err := ioutil.TempFile("", "stemcell")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(stemcellFile.Close()).To(Succeed())
+					defer os.Remove(stemcellFile.Name())
+
+					err = command.Execute([]string{"--iaas", "aws", "--stemcell-url", stemcellFile.Name(), "--stemcell-sha1", sha1, "--air-gapped"}, storage.State{})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.VersionOverrides).To(Equal([]storage.VersionOverride{
+						{Name: "stemcell", URL: "file://" + stemcellFile.Name(), SHA1: sha1},
+					}))
+					Expect(fakeAWSUp.ExecuteCall.Receives.AWSUpConfig.AirGapped).To(BeTrue())
+				})
+
+				It("returns an error when a local path does not exist", func() {
+					missingPath := filepath.Join(os.TempDir(), "missing-stemcell.tgz")
+
+					err := command.Execute([]string{"--iaas", "aws", "--stemcell-url", missingPath, "--stemcell-sha1", sha1}, storage.State{})
+					Expect(err).To(MatchError(ContainSubstring("error reading --stemcell-url: stat " + missingPath)))
+					Expect(fakeAWSUp.ExecuteCall.CallCount).To(Equal(0))
+				})
 			})
 		})
 
@@ -18,6 +18,12 @@ Usage:
 Global Options:
   --help      [-h]       Print usage
   --state-dir            Directory containing bbl-state.json
+  --state-backend        Where to store bbl-state.json: local (default) or s3://bucket/path
+  --state-key-file       File containing key used to encrypt bbl-state.json
+  --lock-timeout         How long to wait for a lock on bbl-state.json (e.g. 5m, default 0)
+  --state-backups        How many backups of bbl-state.json to keep (default 10)
+  --secret-store         Vault-compatible KV store for director credentials (https://vault-address/mount/path)
+  --output               Output format: text (default) or json
 %s
 `
 	CommandUsage = `
@@ -36,12 +42,22 @@ Commands:
   director-password      Prints BOSH director password
   director-ca-cert       Prints BOSH director CA certificate
   env-id                 Prints environment ID
+  export                 Writes the environment to a single bundle
+  force-unlock           Removes a stale lock on bbl-state.json
   help                   Prints usage
+  import                 Restores an environment from a bundle
   lbs                    Prints attached load balancer(s)
+  outputs                Prints infrastructure outputs
+  plan                   Previews the changes bbl up would make
+  print-env              Prints BOSH CLI environment variables
+  restore-state          Restores bbl-state.json from a backup
   ssh-key                Prints SSH private key
+  state                  Prints a summary of the environment
+  state-history          Lists backups of bbl-state.json
   up                     Deploys BOSH director on AWS
   update-lbs             Updates load balancer(s)
   version                Prints version
+  versions               Prints BOSH, CPI and stemcell versions
 
   Use "bbl [command] --help" for more information about a command.`
 
@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cloudfoundry/bosh-bootloader/boshinit"
+	"github.com/cloudfoundry/bosh-bootloader/boshinit/manifests"
+	"github.com/cloudfoundry/bosh-bootloader/flags"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+const VersionsCommand = "versions"
+
+type defaultVersionsProvider interface {
+	DefaultVersions(iaas string) manifests.Versions
+}
+
+type Versions struct {
+	defaultVersionsProvider defaultVersionsProvider
+	stdout                  io.Writer
+	output                  string
+}
+
+type versionsConfig struct {
+	iaas string
+}
+
+type versionEntry struct {
+	Effective string `json:"effective"`
+	Default   string `json:"default"`
+}
+
+func NewVersions(defaultVersionsProvider defaultVersionsProvider, stdout io.Writer, output string) Versions {
+	return Versions{
+		defaultVersionsProvider: defaultVersionsProvider,
+		stdout:                  stdout,
+		output:                  output,
+	}
+}
+
+func (v Versions) Execute(subcommandFlags []string, state storage.State) error {
+	config, err := v.parseFlags(subcommandFlags)
+	if err != nil {
+		return err
+	}
+
+	iaas := state.IAAS
+	if iaas == "" {
+		iaas = config.iaas
+	}
+
+	switch iaas {
+	case "aws", "gcp":
+	case "":
+		return errors.New("--iaas [gcp, aws] must be provided")
+	default:
+		return fmt.Errorf("%q is an invalid iaas type, supported values are: [gcp, aws]", iaas)
+	}
+
+	defaults := v.defaultVersionsProvider.DefaultVersions(iaas)
+	effective := defaults.Override(boshinit.OverriddenVersions(state.VersionOverrides))
+
+	names := []string{"bosh-url", "bosh-sha1", "cpi-url", "cpi-sha1", "stemcell-url", "stemcell-sha1"}
+	entries := map[string]versionEntry{
+		"bosh-url":      {effective.BOSHURL, defaults.BOSHURL},
+		"bosh-sha1":     {effective.BOSHSHA1, defaults.BOSHSHA1},
+		"cpi-url":       {effective.CPIURL, defaults.CPIURL},
+		"cpi-sha1":      {effective.CPISHA1, defaults.CPISHA1},
+		"stemcell-url":  {effective.StemcellURL, defaults.StemcellURL},
+		"stemcell-sha1": {effective.StemcellSHA1, defaults.StemcellSHA1},
+	}
+
+	if v.output == JSONOutput {
+		return printJSON(v.stdout, entries)
+	}
+
+	for _, name := range names {
+		entry := entries[name]
+		if entry.Effective == entry.Default {
+			fmt.Fprintf(v.stdout, "%s: %s\n", name, entry.Effective)
+			continue
+		}
+		fmt.Fprintf(v.stdout, "%s: %s (default: %s)\n", name, entry.Effective, entry.Default)
+	}
+
+	return nil
+}
+
+func (Versions) parseFlags(subcommandFlags []string) (versionsConfig, error) {
+	versionsFlags := flags.New("versions")
+
+	config := versionsConfig{}
+	versionsFlags.String(&config.iaas, "iaas", "")
+
+	err := versionsFlags.Parse(subcommandFlags)
+	if err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
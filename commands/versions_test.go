@@ -0,0 +1,96 @@
+package commands_test
+
+import (
+	"bytes"
+
+	"github.com/cloudfoundry/bosh-bootloader/boshinit/manifests"
+	"github.com/cloudfoundry/bosh-bootloader/commands"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Versions", func() {
+	var (
+		manifestBuilder *fakes.BOSHInitManifestBuilder
+		stdout          *bytes.Buffer
+		state           storage.State
+	)
+
+	BeforeEach(func() {
+		manifestBuilder = &fakes.BOSHInitManifestBuilder{}
+		manifestBuilder.DefaultVersionsCall.Returns.Versions = manifests.Versions{
+			BOSHURL:      "some-bosh-url",
+			BOSHSHA1:     "some-bosh-sha1",
+			CPIURL:       "some-cpi-url",
+			CPISHA1:      "some-cpi-sha1",
+			StemcellURL:  "some-stemcell-url",
+			StemcellSHA1: "some-stemcell-sha1",
+		}
+		stdout = &bytes.Buffer{}
+
+		state = storage.State{
+			IAAS: "gcp",
+			VersionOverrides: []storage.VersionOverride{
+				{Name: "stemcell", URL: "other-stemcell-url", SHA1: "other-stemcell-sha1"},
+			},
+		}
+	})
+
+	Describe("Execute", func() {
+		It("prints the effective versions and the defaults they override", func() {
+			err := commands.NewVersions(manifestBuilder, stdout, commands.TextOutput).Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manifestBuilder.DefaultVersionsCall.Receives.IAAS).To(Equal("gcp"))
+			Expect(stdout.String()).To(Equal(`bosh-url: some-bosh-url
+bosh-sha1: some-bosh-sha1
+cpi-url: some-cpi-url
+cpi-sha1: some-cpi-sha1
+stemcell-url: other-stemcell-url (default: some-stemcell-url)
+stemcell-sha1: other-stemcell-sha1 (default: some-stemcell-sha1)
+`))
+		})
+
+		It("prints the versions as json", func() {
+			err := commands.NewVersions(manifestBuilder, stdout, commands.JSONOutput).Execute([]string{}, state)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stdout.String()).To(MatchJSON(`{
+				"bosh-url": {"effective": "some-bosh-url", "default": "some-bosh-url"},
+				"bosh-sha1": {"effective": "some-bosh-sha1", "default": "some-bosh-sha1"},
+				"cpi-url": {"effective": "some-cpi-url", "default": "some-cpi-url"},
+				"cpi-sha1": {"effective": "some-cpi-sha1", "default": "some-cpi-sha1"},
+				"stemcell-url": {"effective": "other-stemcell-url", "default": "some-stemcell-url"},
+				"stemcell-sha1": {"effective": "other-stemcell-sha1", "default": "some-stemcell-sha1"}
+			}`))
+		})
+
+		Context("when there is no state", func() {
+			It("prints the default versions for the --iaas flag", func() {
+				err := commands.NewVersions(manifestBuilder, stdout, commands.TextOutput).Execute([]string{"--iaas", "aws"}, storage.State{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(manifestBuilder.DefaultVersionsCall.Receives.IAAS).To(Equal("aws"))
+				Expect(stdout.String()).To(ContainSubstring("stemcell-url: some-stemcell-url\n"))
+			})
+
+			It("returns an error when --iaas is not provided", func() {
+				err := commands.NewVersions(manifestBuilder, stdout, commands.TextOutput).Execute([]string{}, storage.State{})
+				Expect(err).To(MatchError("--iaas [gcp, aws] must be provided"))
+			})
+
+			It("returns an error when --iaas is invalid", func() {
+				err := commands.NewVersions(manifestBuilder, stdout, commands.TextOutput).Execute([]string{"--iaas", "azure"}, storage.State{})
+				Expect(err).To(MatchError(`"azure" is an invalid iaas type, supported values are: [gcp, aws]`))
+			})
+		})
+
+		It("returns an error when the flags cannot be parsed", func() {
+			err := commands.NewVersions(manifestBuilder, stdout, commands.TextOutput).Execute([]string{"--unknown-flag"}, state)
+			Expect(err).To(MatchError("flag provided but not defined: -unknown-flag"))
+		})
+	})
+})
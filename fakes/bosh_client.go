@@ -13,6 +13,14 @@ type BOSHClient struct {
 		}
 	}
 
+	CloudConfigCall struct {
+		CallCount int
+		Returns   struct {
+			CloudConfig []byte
+			Error       error
+		}
+	}
+
 	InfoCall struct {
 		CallCount int
 		Returns   struct {
@@ -28,6 +36,11 @@ func (c *BOSHClient) UpdateCloudConfig(yaml []byte) error {
 	return c.UpdateCloudConfigCall.Returns.Error
 }
 
+func (c *BOSHClient) CloudConfig() ([]byte, error) {
+	c.CloudConfigCall.CallCount++
+	return c.CloudConfigCall.Returns.CloudConfig, c.CloudConfigCall.Returns.Error
+}
+
 func (c *BOSHClient) Info() (bosh.Info, error) {
 	c.InfoCall.CallCount++
 	return c.InfoCall.Returns.Info, c.InfoCall.Returns.Error
@@ -4,7 +4,8 @@ import "github.com/cloudfoundry/bosh-bootloader/boshinit"
 
 type BOSHDeployer struct {
 	DeployCall struct {
-		Receives struct {
+		CallCount int
+		Receives  struct {
 			Input boshinit.DeployInput
 		}
 		Returns struct {
@@ -15,6 +16,7 @@ type BOSHDeployer struct {
 }
 
 func (d *BOSHDeployer) Deploy(input boshinit.DeployInput) (boshinit.DeployOutput, error) {
+	d.DeployCall.CallCount++
 	d.DeployCall.Receives.Input = input
 
 	return d.DeployCall.Returns.Output, d.DeployCall.Returns.Error
@@ -14,6 +14,15 @@ type BOSHInitManifestBuilder struct {
 			Error      error
 		}
 	}
+
+	DefaultVersionsCall struct {
+		Receives struct {
+			IAAS string
+		}
+		Returns struct {
+			Versions manifests.Versions
+		}
+	}
 }
 
 func (b *BOSHInitManifestBuilder) Build(iaas string, properties manifests.ManifestProperties) (manifests.Manifest, manifests.ManifestProperties, error) {
@@ -22,3 +31,9 @@ func (b *BOSHInitManifestBuilder) Build(iaas string, properties manifests.Manife
 
 	return b.BuildCall.Returns.Manifest, b.BuildCall.Returns.Properties, b.BuildCall.Returns.Error
 }
+
+func (b *BOSHInitManifestBuilder) DefaultVersions(iaas string) manifests.Versions {
+	b.DefaultVersionsCall.Receives.IAAS = iaas
+
+	return b.DefaultVersionsCall.Returns.Versions
+}
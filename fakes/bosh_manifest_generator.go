@@ -0,0 +1,23 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/boshinit"
+
+type BOSHManifestGenerator struct {
+	ManifestCall struct {
+		CallCount int
+		Receives  struct {
+			Input boshinit.DeployInput
+		}
+		Returns struct {
+			Manifest string
+			Error    error
+		}
+	}
+}
+
+func (g *BOSHManifestGenerator) Manifest(input boshinit.DeployInput) (string, error) {
+	g.ManifestCall.CallCount++
+	g.ManifestCall.Receives.Input = input
+
+	return g.ManifestCall.Returns.Manifest, g.ManifestCall.Returns.Error
+}
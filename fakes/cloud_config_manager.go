@@ -4,7 +4,8 @@ import "github.com/cloudfoundry/bosh-bootloader/bosh"
 
 type CloudConfigManager struct {
 	UpdateCall struct {
-		Receives struct {
+		CallCount int
+		Receives  struct {
 			CloudConfigInput bosh.CloudConfigInput
 			BOSHClient       bosh.Client
 		}
@@ -12,10 +13,28 @@ type CloudConfigManager struct {
 			Error error
 		}
 	}
+
+	GenerateCall struct {
+		CallCount int
+		Receives  struct {
+			CloudConfigInput bosh.CloudConfigInput
+		}
+		Returns struct {
+			CloudConfig []byte
+			Error       error
+		}
+	}
 }
 
 func (c *CloudConfigManager) Update(cloudConfigInput bosh.CloudConfigInput, boshClient bosh.Client) error {
+	c.UpdateCall.CallCount++
 	c.UpdateCall.Receives.CloudConfigInput = cloudConfigInput
 	c.UpdateCall.Receives.BOSHClient = boshClient
 	return c.UpdateCall.Returns.Error
 }
+
+func (c *CloudConfigManager) Generate(cloudConfigInput bosh.CloudConfigInput) ([]byte, error) {
+	c.GenerateCall.CallCount++
+	c.GenerateCall.Receives.CloudConfigInput = cloudConfigInput
+	return c.GenerateCall.Returns.CloudConfig, c.GenerateCall.Returns.Error
+}
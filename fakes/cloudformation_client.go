@@ -54,6 +54,39 @@ type CloudFormationClient struct {
 			Error  error
 		}
 	}
+
+	CreateChangeSetCall struct {
+		CallCount int
+		Receives  struct {
+			Input *cloudformation.CreateChangeSetInput
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	DescribeChangeSetCall struct {
+		CallCount int
+		Stub      func(*cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error)
+
+		Receives struct {
+			Input *cloudformation.DescribeChangeSetInput
+		}
+		Returns struct {
+			Output *cloudformation.DescribeChangeSetOutput
+			Error  error
+		}
+	}
+
+	DeleteChangeSetCall struct {
+		CallCount int
+		Receives  struct {
+			Input *cloudformation.DeleteChangeSetInput
+		}
+		Returns struct {
+			Error error
+		}
+	}
 }
 
 func (c *CloudFormationClient) CreateStack(input *cloudformation.CreateStackInput) (*cloudformation.CreateStackOutput, error) {
@@ -88,3 +121,26 @@ func (c *CloudFormationClient) DescribeStackResource(input *cloudformation.Descr
 	return c.DescribeStackResourceCall.Returns.Output, c.DescribeStackResourceCall.Returns.Error
 
 }
+
+func (c *CloudFormationClient) CreateChangeSet(input *cloudformation.CreateChangeSetInput) (*cloudformation.CreateChangeSetOutput, error) {
+	c.CreateChangeSetCall.CallCount++
+	c.CreateChangeSetCall.Receives.Input = input
+	return nil, c.CreateChangeSetCall.Returns.Error
+}
+
+func (c *CloudFormationClient) DescribeChangeSet(input *cloudformation.DescribeChangeSetInput) (*cloudformation.DescribeChangeSetOutput, error) {
+	c.DescribeChangeSetCall.CallCount++
+	c.DescribeChangeSetCall.Receives.Input = input
+
+	if c.DescribeChangeSetCall.Stub != nil {
+		return c.DescribeChangeSetCall.Stub(input)
+	}
+
+	return c.DescribeChangeSetCall.Returns.Output, c.DescribeChangeSetCall.Returns.Error
+}
+
+func (c *CloudFormationClient) DeleteChangeSet(input *cloudformation.DeleteChangeSetInput) (*cloudformation.DeleteChangeSetOutput, error) {
+	c.DeleteChangeSetCall.CallCount++
+	c.DeleteChangeSetCall.Receives.Input = input
+	return nil, c.DeleteChangeSetCall.Returns.Error
+}
@@ -63,6 +63,16 @@ type EC2Client struct {
 			Error  error
 		}
 	}
+
+	DescribeImagesCall struct {
+		Receives struct {
+			Input *awsec2.DescribeImagesInput
+		}
+		Returns struct {
+			Output *awsec2.DescribeImagesOutput
+			Error  error
+		}
+	}
 }
 
 func (c *EC2Client) ImportKeyPair(input *awsec2.ImportKeyPairInput) (*awsec2.ImportKeyPairOutput, error) {
@@ -100,3 +110,9 @@ func (c *EC2Client) DescribeInstances(input *awsec2.DescribeInstancesInput) (*aw
 
 	return c.DescribeInstancesCall.Returns.Output, c.DescribeInstancesCall.Returns.Error
 }
+
+func (c *EC2Client) DescribeImages(input *awsec2.DescribeImagesInput) (*awsec2.DescribeImagesOutput, error) {
+	c.DescribeImagesCall.Receives.Input = input
+
+	return c.DescribeImagesCall.Returns.Output, c.DescribeImagesCall.Returns.Error
+}
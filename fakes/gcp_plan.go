@@ -0,0 +1,21 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/storage"
+
+type GCPPlan struct {
+	ExecuteCall struct {
+		CallCount int
+		Receives  struct {
+			State storage.State
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func (p *GCPPlan) Execute(state storage.State) error {
+	p.ExecuteCall.CallCount++
+	p.ExecuteCall.Receives.State = state
+	return p.ExecuteCall.Returns.Error
+}
@@ -1,6 +1,9 @@
 package fakes
 
-import "github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+import (
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation"
+	"github.com/cloudfoundry/bosh-bootloader/aws/cloudformation/templates"
+)
 
 type InfrastructureManager struct {
 	CreateCall struct {
@@ -13,6 +16,8 @@ type InfrastructureManager struct {
 			LBCertificateARN          string
 			NumberOfAvailabilityZones int
 			EnvID                     string
+			Network                   templates.Network
+			TemplatePatches           []templates.Patch
 		}
 		Returns struct {
 			Stack cloudformation.Stack
@@ -29,6 +34,8 @@ type InfrastructureManager struct {
 			LBType                    string
 			LBCertificateARN          string
 			EnvID                     string
+			Network                   templates.Network
+			TemplatePatches           []templates.Patch
 		}
 		Returns struct {
 			Stack cloudformation.Stack
@@ -57,7 +64,8 @@ type InfrastructureManager struct {
 	}
 
 	DescribeCall struct {
-		Receives struct {
+		CallCount int
+		Receives  struct {
 			StackName string
 		}
 		Returns struct {
@@ -65,9 +73,27 @@ type InfrastructureManager struct {
 			Error error
 		}
 	}
+
+	PlanCall struct {
+		CallCount int
+		Receives  struct {
+			KeyPairName               string
+			NumberOfAvailabilityZones int
+			StackName                 string
+			LBType                    string
+			LBCertificateARN          string
+			EnvID                     string
+			Network                   templates.Network
+			TemplatePatches           []templates.Patch
+		}
+		Returns struct {
+			Changes []cloudformation.ResourceChange
+			Error   error
+		}
+	}
 }
 
-func (m *InfrastructureManager) Create(keyPairName string, numberOfAZs int, stackName, lbType, lbCertificateARN, envID string) (cloudformation.Stack, error) {
+func (m *InfrastructureManager) Create(keyPairName string, numberOfAZs int, stackName, lbType, lbCertificateARN, envID string, network templates.Network, patches []templates.Patch) (cloudformation.Stack, error) {
 	m.CreateCall.CallCount++
 	m.CreateCall.Receives.StackName = stackName
 	m.CreateCall.Receives.LBType = lbType
@@ -75,6 +101,8 @@ func (m *InfrastructureManager) Create(keyPairName string, numberOfAZs int, stac
 	m.CreateCall.Receives.KeyPairName = keyPairName
 	m.CreateCall.Receives.NumberOfAvailabilityZones = numberOfAZs
 	m.CreateCall.Receives.EnvID = envID
+	m.CreateCall.Receives.Network = network
+	m.CreateCall.Receives.TemplatePatches = patches
 
 	if m.CreateCall.Stub != nil {
 		return m.CreateCall.Stub(keyPairName, numberOfAZs, stackName, lbType, envID)
@@ -83,7 +111,7 @@ func (m *InfrastructureManager) Create(keyPairName string, numberOfAZs int, stac
 	return m.CreateCall.Returns.Stack, m.CreateCall.Returns.Error
 }
 
-func (m *InfrastructureManager) Update(keyPairName string, numberOfAZs int, stackName, lbType, lbCertificateARN, envID string) (cloudformation.Stack, error) {
+func (m *InfrastructureManager) Update(keyPairName string, numberOfAZs int, stackName, lbType, lbCertificateARN, envID string, network templates.Network, patches []templates.Patch) (cloudformation.Stack, error) {
 	m.UpdateCall.CallCount++
 	m.UpdateCall.Receives.KeyPairName = keyPairName
 	m.UpdateCall.Receives.NumberOfAvailabilityZones = numberOfAZs
@@ -91,6 +119,8 @@ func (m *InfrastructureManager) Update(keyPairName string, numberOfAZs int, stac
 	m.UpdateCall.Receives.LBType = lbType
 	m.UpdateCall.Receives.LBCertificateARN = lbCertificateARN
 	m.UpdateCall.Receives.EnvID = envID
+	m.UpdateCall.Receives.Network = network
+	m.UpdateCall.Receives.TemplatePatches = patches
 	return m.UpdateCall.Returns.Stack, m.UpdateCall.Returns.Error
 }
 
@@ -108,7 +138,22 @@ func (m *InfrastructureManager) Delete(stackName string) error {
 }
 
 func (m *InfrastructureManager) Describe(stackName string) (cloudformation.Stack, error) {
+	m.DescribeCall.CallCount++
 	m.DescribeCall.Receives.StackName = stackName
 
 	return m.DescribeCall.Returns.Stack, m.DescribeCall.Returns.Error
 }
+
+func (m *InfrastructureManager) Plan(keyPairName string, numberOfAZs int, stackName, lbType, lbCertificateARN, envID string, network templates.Network, patches []templates.Patch) ([]cloudformation.ResourceChange, error) {
+	m.PlanCall.CallCount++
+	m.PlanCall.Receives.KeyPairName = keyPairName
+	m.PlanCall.Receives.NumberOfAvailabilityZones = numberOfAZs
+	m.PlanCall.Receives.StackName = stackName
+	m.PlanCall.Receives.LBType = lbType
+	m.PlanCall.Receives.LBCertificateARN = lbCertificateARN
+	m.PlanCall.Receives.EnvID = envID
+	m.PlanCall.Receives.Network = network
+	m.PlanCall.Receives.TemplatePatches = patches
+
+	return m.PlanCall.Returns.Changes, m.PlanCall.Returns.Error
+}
@@ -0,0 +1,20 @@
+package fakes
+
+type NATAMIResolver struct {
+	ResolveCall struct {
+		CallCount int
+		Receives  struct {
+			Region string
+		}
+		Returns struct {
+			AMI   string
+			Error error
+		}
+	}
+}
+
+func (r *NATAMIResolver) Resolve(region string) (string, error) {
+	r.ResolveCall.CallCount++
+	r.ResolveCall.Receives.Region = region
+	return r.ResolveCall.Returns.AMI, r.ResolveCall.Returns.Error
+}
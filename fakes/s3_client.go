@@ -0,0 +1,164 @@
+package fakes
+
+import (
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+type S3Client struct {
+	HeadBucketCall struct {
+		CallCount int
+		Receives  struct {
+			Input *s3.HeadBucketInput
+		}
+		Returns struct {
+			Output *s3.HeadBucketOutput
+			Error  error
+		}
+	}
+
+	HeadObjectCall struct {
+		CallCount int
+		Receives  struct {
+			Input *s3.HeadObjectInput
+		}
+		Returns struct {
+			Output *s3.HeadObjectOutput
+			Error  error
+		}
+	}
+
+	GetObjectCall struct {
+		CallCount int
+		Receives  struct {
+			Input *s3.GetObjectInput
+		}
+		Returns struct {
+			Output *s3.GetObjectOutput
+			Error  error
+		}
+	}
+
+	PutObjectCall struct {
+		CallCount int
+		Receives  struct {
+			Input *s3.PutObjectInput
+		}
+		Returns struct {
+			Output *s3.PutObjectOutput
+			Error  error
+		}
+	}
+
+	PutObjectWithContextCall struct {
+		CallCount int
+		Receives  struct {
+			Input  *s3.PutObjectInput
+			Header http.Header
+		}
+		Returns struct {
+			Output *s3.PutObjectOutput
+			Error  error
+		}
+	}
+
+	ListObjectsCall struct {
+		CallCount int
+		Receives  struct {
+			Inputs []*s3.ListObjectsInput
+		}
+		Returns struct {
+			Outputs []*s3.ListObjectsOutput
+			Error   error
+		}
+	}
+
+	CopyObjectCall struct {
+		CallCount int
+		Receives  struct {
+			Input *s3.CopyObjectInput
+		}
+		Returns struct {
+			Output *s3.CopyObjectOutput
+			Error  error
+		}
+	}
+
+	DeleteObjectCall struct {
+		CallCount int
+		Receives  struct {
+			Input *s3.DeleteObjectInput
+		}
+		Returns struct {
+			Output *s3.DeleteObjectOutput
+			Error  error
+		}
+	}
+}
+
+func (c *S3Client) HeadBucket(input *s3.HeadBucketInput) (*s3.HeadBucketOutput, error) {
+	c.HeadBucketCall.CallCount++
+	c.HeadBucketCall.Receives.Input = input
+	return c.HeadBucketCall.Returns.Output, c.HeadBucketCall.Returns.Error
+}
+
+func (c *S3Client) HeadObject(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	c.HeadObjectCall.CallCount++
+	c.HeadObjectCall.Receives.Input = input
+	return c.HeadObjectCall.Returns.Output, c.HeadObjectCall.Returns.Error
+}
+
+func (c *S3Client) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	c.GetObjectCall.CallCount++
+	c.GetObjectCall.Receives.Input = input
+	return c.GetObjectCall.Returns.Output, c.GetObjectCall.Returns.Error
+}
+
+func (c *S3Client) PutObject(input *s3.PutObjectInput) (*s3.PutObjectOutput, error) {
+	c.PutObjectCall.CallCount++
+	c.PutObjectCall.Receives.Input = input
+	return c.PutObjectCall.Returns.Output, c.PutObjectCall.Returns.Error
+}
+
+func (c *S3Client) PutObjectWithContext(ctx aws.Context, input *s3.PutObjectInput, opts ...request.Option) (*s3.PutObjectOutput, error) {
+	c.PutObjectWithContextCall.CallCount++
+	c.PutObjectWithContextCall.Receives.Input = input
+
+	r := &request.Request{HTTPRequest: &http.Request{Header: http.Header{}}}
+	r.ApplyOptions(opts...)
+	c.PutObjectWithContextCall.Receives.Header = r.HTTPRequest.Header
+
+	return c.PutObjectWithContextCall.Returns.Output, c.PutObjectWithContextCall.Returns.Error
+}
+
+func (c *S3Client) ListObjects(input *s3.ListObjectsInput) (*s3.ListObjectsOutput, error) {
+	c.ListObjectsCall.CallCount++
+
+	inputCopy := *input
+	c.ListObjectsCall.Receives.Inputs = append(c.ListObjectsCall.Receives.Inputs, &inputCopy)
+
+	if c.ListObjectsCall.Returns.Error != nil {
+		return nil, c.ListObjectsCall.Returns.Error
+	}
+
+	if len(c.ListObjectsCall.Returns.Outputs) < c.ListObjectsCall.CallCount {
+		return &s3.ListObjectsOutput{}, nil
+	}
+
+	return c.ListObjectsCall.Returns.Outputs[c.ListObjectsCall.CallCount-1], nil
+}
+
+func (c *S3Client) CopyObject(input *s3.CopyObjectInput) (*s3.CopyObjectOutput, error) {
+	c.CopyObjectCall.CallCount++
+	c.CopyObjectCall.Receives.Input = input
+	return c.CopyObjectCall.Returns.Output, c.CopyObjectCall.Returns.Error
+}
+
+func (c *S3Client) DeleteObject(input *s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error) {
+	c.DeleteObjectCall.CallCount++
+	c.DeleteObjectCall.Receives.Input = input
+	return c.DeleteObjectCall.Returns.Output, c.DeleteObjectCall.Returns.Error
+}
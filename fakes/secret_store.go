@@ -0,0 +1,42 @@
+package fakes
+
+type SecretStore struct {
+	LocationCall struct {
+		Returns struct {
+			Location string
+		}
+	}
+
+	ReadCall struct {
+		CallCount int
+		Returns   struct {
+			Secrets map[string]string
+			Error   error
+		}
+	}
+
+	WriteCall struct {
+		CallCount int
+		Receives  struct {
+			Secrets map[string]string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func (s *SecretStore) Location() string {
+	return s.LocationCall.Returns.Location
+}
+
+func (s *SecretStore) Read() (map[string]string, error) {
+	s.ReadCall.CallCount++
+	return s.ReadCall.Returns.Secrets, s.ReadCall.Returns.Error
+}
+
+func (s *SecretStore) Write(secrets map[string]string) error {
+	s.WriteCall.CallCount++
+	s.WriteCall.Receives.Secrets = secrets
+	return s.WriteCall.Returns.Error
+}
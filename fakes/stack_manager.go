@@ -71,6 +71,19 @@ type StackManager struct {
 			Error              error
 		}
 	}
+
+	PlanChangesCall struct {
+		Receives struct {
+			StackName     string
+			Template      templates.Template
+			Tags          cloudformation.Tags
+			SleepInterval time.Duration
+		}
+		Returns struct {
+			Changes []cloudformation.ResourceChange
+			Error   error
+		}
+	}
 }
 
 func (m *StackManager) CreateOrUpdate(stackName string, template templates.Template, tags cloudformation.Tags) error {
@@ -119,3 +132,12 @@ func (m *StackManager) GetPhysicalIDForResource(stackName string, logicalResourc
 
 	return m.GetPhysicalIDForResourceCall.Returns.PhysicalResourceID, m.GetPhysicalIDForResourceCall.Returns.Error
 }
+
+func (m *StackManager) PlanChanges(stackName string, template templates.Template, tags cloudformation.Tags, sleepInterval time.Duration) ([]cloudformation.ResourceChange, error) {
+	m.PlanChangesCall.Receives.StackName = stackName
+	m.PlanChangesCall.Receives.Template = template
+	m.PlanChangesCall.Receives.Tags = tags
+	m.PlanChangesCall.Receives.SleepInterval = sleepInterval
+
+	return m.PlanChangesCall.Returns.Changes, m.PlanChangesCall.Returns.Error
+}
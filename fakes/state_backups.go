@@ -0,0 +1,35 @@
+package fakes
+
+import "github.com/cloudfoundry/bosh-bootloader/storage"
+
+type StateBackups struct {
+	BackupsCall struct {
+		CallCount int
+		Returns   struct {
+			Backups []storage.StateBackup
+			Error   error
+		}
+	}
+
+	RestoreCall struct {
+		CallCount int
+		Receives  struct {
+			N int
+		}
+		Returns struct {
+			Backup storage.StateBackup
+			Error  error
+		}
+	}
+}
+
+func (s *StateBackups) Backups() ([]storage.StateBackup, error) {
+	s.BackupsCall.CallCount++
+	return s.BackupsCall.Returns.Backups, s.BackupsCall.Returns.Error
+}
+
+func (s *StateBackups) Restore(n int) (storage.StateBackup, error) {
+	s.RestoreCall.CallCount++
+	s.RestoreCall.Receives.N = n
+	return s.RestoreCall.Returns.Backup, s.RestoreCall.Returns.Error
+}
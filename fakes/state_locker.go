@@ -0,0 +1,53 @@
+package fakes
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+)
+
+type StateLocker struct {
+	LockCall struct {
+		CallCount int
+		Receives  struct {
+			Command string
+			Timeout time.Duration
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	UnlockCall struct {
+		CallCount int
+		Returns   struct {
+			Error error
+		}
+	}
+
+	CurrentCall struct {
+		CallCount int
+		Returns   struct {
+			Lock   storage.Lock
+			Locked bool
+			Error  error
+		}
+	}
+}
+
+func (s *StateLocker) Lock(command string, timeout time.Duration) error {
+	s.LockCall.CallCount++
+	s.LockCall.Receives.Command = command
+	s.LockCall.Receives.Timeout = timeout
+	return s.LockCall.Returns.Error
+}
+
+func (s *StateLocker) Unlock() error {
+	s.UnlockCall.CallCount++
+	return s.UnlockCall.Returns.Error
+}
+
+func (s *StateLocker) Current() (storage.Lock, bool, error) {
+	s.CurrentCall.CallCount++
+	return s.CurrentCall.Returns.Lock, s.CurrentCall.Returns.Locked, s.CurrentCall.Returns.Error
+}
@@ -21,6 +21,13 @@ type StateStore struct {
 			Error error
 		}
 	}
+
+	MigrateCall struct {
+		CallCount int
+		Returns   struct {
+			Error error
+		}
+	}
 }
 
 type SetCallReturn struct {
@@ -36,3 +43,13 @@ func (s *StateStore) Set(state storage.State) error {
 	}
 	return s.SetCall.Returns[s.SetCall.CallCount-1].Error
 }
+
+func (s *StateStore) Get() (storage.State, error) {
+	s.GetCall.CallCount++
+	return s.GetCall.Returns.State, s.GetCall.Returns.Error
+}
+
+func (s *StateStore) Migrate() error {
+	s.MigrateCall.CallCount++
+	return s.MigrateCall.Returns.Error
+}
@@ -11,6 +11,7 @@ type TemplateBuilder struct {
 			LBCertificateARN string
 			IAMUserName      string
 			EnvID            string
+			Network          templates.Network
 		}
 		Returns struct {
 			Template templates.Template
@@ -18,13 +19,14 @@ type TemplateBuilder struct {
 	}
 }
 
-func (b *TemplateBuilder) Build(keyPairName string, numberOfAvailabilityZones int, lbType string, lbCertificateARN string, iamUserName string, envID string) templates.Template {
+func (b *TemplateBuilder) Build(keyPairName string, numberOfAvailabilityZones int, lbType string, lbCertificateARN string, iamUserName string, envID string, network templates.Network) templates.Template {
 	b.BuildCall.Receives.KeyPairName = keyPairName
 	b.BuildCall.Receives.NumberOfAZs = numberOfAvailabilityZones
 	b.BuildCall.Receives.LBType = lbType
 	b.BuildCall.Receives.LBCertificateARN = lbCertificateARN
 	b.BuildCall.Receives.IAMUserName = iamUserName
 	b.BuildCall.Receives.EnvID = envID
+	b.BuildCall.Receives.Network = network
 
 	return b.BuildCall.Returns.Template
 }
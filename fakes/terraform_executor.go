@@ -20,6 +20,25 @@ type TerraformExecutor struct {
 			Error   error
 		}
 	}
+	PlanCall struct {
+		CallCount int
+		Receives  struct {
+			Credentials string
+			EnvID       string
+			ProjectID   string
+			Zone        string
+			Region      string
+			Cert        string
+			Key         string
+			Domain      string
+			Template    string
+			TFState     string
+		}
+		Returns struct {
+			Output string
+			Error  error
+		}
+	}
 	DestroyCall struct {
 		CallCount int
 		Receives  struct {
@@ -53,6 +72,21 @@ func (t *TerraformExecutor) Apply(credentials, envID, projectID, zone, region, c
 	return t.ApplyCall.Returns.TFState, t.ApplyCall.Returns.Error
 }
 
+func (t *TerraformExecutor) Plan(credentials, envID, projectID, zone, region, cert, key, domain, template, tfState string) (string, error) {
+	t.PlanCall.CallCount++
+	t.PlanCall.Receives.Credentials = credentials
+	t.PlanCall.Receives.EnvID = envID
+	t.PlanCall.Receives.ProjectID = projectID
+	t.PlanCall.Receives.Zone = zone
+	t.PlanCall.Receives.Region = region
+	t.PlanCall.Receives.Cert = cert
+	t.PlanCall.Receives.Key = key
+	t.PlanCall.Receives.Domain = domain
+	t.PlanCall.Receives.Template = template
+	t.PlanCall.Receives.TFState = tfState
+	return t.PlanCall.Returns.Output, t.PlanCall.Returns.Error
+}
+
 func (t *TerraformExecutor) Destroy(credentials, envID, projectID, zone, region, template, tfState string) (string, error) {
 	t.DestroyCall.CallCount++
 	t.DestroyCall.Receives.Credentials = credentials
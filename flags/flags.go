@@ -3,6 +3,8 @@ package flags
 import (
 	"flag"
 	"io/ioutil"
+	"strings"
+	"time"
 )
 
 type Flags struct {
@@ -20,14 +22,30 @@ func New(name string) Flags {
 }
 
 func (f Flags) Bool(v *bool, short, long string, value bool) {
-	f.set.BoolVar(v, long, value, "")
-	f.set.BoolVar(v, short, value, "")
+	for _, name := range []string{long, short} {
+		if name != "" {
+			f.set.BoolVar(v, name, value, "")
+		}
+	}
 }
 
 func (f Flags) String(v *string, name string, value string) {
 	f.set.StringVar(v, name, value, "")
 }
 
+func (f Flags) Int(v *int, name string, value int) {
+	f.set.IntVar(v, name, value, "")
+}
+
+func (f Flags) Duration(v *time.Duration, name string, value time.Duration) {
+	f.set.DurationVar(v, name, value, "")
+}
+
+func (f Flags) StringSlice(v *[]string, name string, value []string) {
+	*v = value
+	f.set.Var((*stringSlice)(v), name, "")
+}
+
 func (f Flags) Parse(args []string) error {
 	return f.set.Parse(args)
 }
@@ -35,3 +53,14 @@ func (f Flags) Parse(args []string) error {
 func (f Flags) Args() []string {
 	return f.set.Args()
 }
+
+type stringSlice []string
+
+func (s *stringSlice) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
@@ -1,6 +1,8 @@
 package flags_test
 
 import (
+	"time"
+
 	"github.com/cloudfoundry/bosh-bootloader/flags"
 
 	. "github.com/onsi/ginkgo"
@@ -9,15 +11,21 @@ import (
 
 var _ = Describe("Flags", func() {
 	var (
-		f         flags.Flags
-		boolVal   bool
-		stringVal string
+		f           flags.Flags
+		boolVal     bool
+		stringVal   string
+		intVal      int
+		durationVal time.Duration
+		sliceVal    []string
 	)
 
 	BeforeEach(func() {
 		f = flags.New("test")
 		f.Bool(&boolVal, "b", "bool", false)
 		f.String(&stringVal, "string", "")
+		f.Int(&intVal, "int", 0)
+		f.Duration(&durationVal, "duration", 0)
+		f.StringSlice(&sliceVal, "slice", nil)
 	})
 
 	Describe("Parse", func() {
@@ -33,6 +41,17 @@ var _ = Describe("Flags", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(boolVal).To(BeTrue())
 			})
+
+			It("can define several flags without a short name", func() {
+				var first, second bool
+				f.Bool(&first, "", "first", false)
+				f.Bool(&second, "", "second", false)
+
+				err := f.Parse([]string{"--second"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(first).To(BeFalse())
+				Expect(second).To(BeTrue())
+			})
 		})
 
 		Context("String flags", func() {
@@ -42,6 +61,30 @@ var _ = Describe("Flags", func() {
 				Expect(stringVal).To(Equal("string_value"))
 			})
 		})
+
+		Context("StringSlice flags", func() {
+			It("collects every occurrence of the flag", func() {
+				err := f.Parse([]string{"--slice", "first", "--slice", "second"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sliceVal).To(Equal([]string{"first", "second"}))
+			})
+		})
+
+		Context("Int flags", func() {
+			It("can parse int fields from flags", func() {
+				err := f.Parse([]string{"--int", "42"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(intVal).To(Equal(42))
+			})
+		})
+
+		Context("Duration flags", func() {
+			It("can parse duration fields from flags", func() {
+				err := f.Parse([]string{"--duration", "5m"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(durationVal).To(Equal(5 * time.Minute))
+			})
+		})
 	})
 
 	Describe("Args", func() {
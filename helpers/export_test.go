@@ -0,0 +1,11 @@
+package helpers
+
+import "os"
+
+func SetNotifySignals(f func(chan<- os.Signal)) {
+	notifySignals = f
+}
+
+func ResetNotifySignals() {
+	notifySignals = notifyInterrupts
+}
@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+)
+
+var notifySignals func(chan<- os.Signal) = notifyInterrupts
+
+type InterruptedError struct {
+	Signal  os.Signal
+	Command string
+}
+
+func (e InterruptedError) Error() string {
+	return fmt.Sprintf("bbl received %s, %s was stopped", e.Signal, e.Command)
+}
+
+type InterruptibleCommand struct {
+	cmd *exec.Cmd
+}
+
+func NewInterruptibleCommand(cmd *exec.Cmd) InterruptibleCommand {
+	return InterruptibleCommand{
+		cmd: cmd,
+	}
+}
+
+func (c InterruptibleCommand) Run() error {
+	return RunInterruptible(c.cmd)
+}
+
+// RunInterruptible makes sure that the command sees the interrupts bbl receives,
+// and waits for the command to exit so that the caller can read back any state
+// the command wrote before stopping.
+func RunInterruptible(cmd *exec.Cmd) error {
+	prepareInterruptible(cmd)
+
+	signals := make(chan os.Signal, 1)
+	notifySignals(signals)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	var received os.Signal
+	for {
+		select {
+		case sig := <-signals:
+			received = sig
+			forwardSignal(cmd.Process, sig)
+		case err := <-exited:
+			if received != nil {
+				return InterruptedError{
+					Signal:  received,
+					Command: filepath.Base(cmd.Path),
+				}
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,90 @@
+//go:build !windows
+// +build !windows
+
+package helpers_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	"github.com/cloudfoundry/bosh-bootloader/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RunInterruptible", func() {
+	var (
+		tempDir    string
+		registered chan chan<- os.Signal
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		registered = make(chan chan<- os.Signal, 1)
+		helpers.SetNotifySignals(func(signals chan<- os.Signal) {
+			registered <- signals
+		})
+	})
+
+	AfterEach(func() {
+		helpers.ResetNotifySignals()
+		os.RemoveAll(tempDir)
+	})
+
+	It("runs the command", func() {
+		cmd := exec.Command("sh", "-c", "echo some-output > output")
+		cmd.Dir = tempDir
+
+		err := helpers.RunInterruptible(cmd)
+		Expect(err).NotTo(HaveOccurred())
+
+		output, err := ioutil.ReadFile(filepath.Join(tempDir, "output"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(output)).To(Equal("some-output\n"))
+	})
+
+	It("returns the error when the command fails", func() {
+		err := helpers.RunInterruptible(exec.Command("sh", "-c", "exit 3"))
+		Expect(err).To(MatchError("exit status 3"))
+	})
+
+	It("forwards the signal to the command and waits for it to exit", func() {
+		cmd := exec.Command("sh", "-c", "trap 'echo partial-state > state; exit 2' INT; touch ready; while true; do sleep 0.1; done")
+		cmd.Dir = tempDir
+
+		done := make(chan error, 1)
+		go func() {
+			done <- helpers.RunInterruptible(cmd)
+		}()
+
+		var signals chan<- os.Signal
+		Eventually(registered).Should(Receive(&signals))
+		Eventually(filepath.Join(tempDir, "ready")).Should(BeAnExistingFile())
+
+		signals <- syscall.SIGINT
+
+		var err error
+		Eventually(done, "5s").Should(Receive(&err))
+		Expect(err).To(MatchError("bbl received interrupt, sh was stopped"))
+		Expect(err).To(BeAssignableToTypeOf(helpers.InterruptedError{}))
+
+		state, err := ioutil.ReadFile(filepath.Join(tempDir, "state"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(state)).To(Equal("partial-state\n"))
+	})
+
+	It("runs the command in its own process group", func() {
+		cmd := exec.Command("sh", "-c", "true")
+
+		err := helpers.RunInterruptible(cmd)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cmd.SysProcAttr.Setpgid).To(BeTrue())
+	})
+})
@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package helpers
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+func notifyInterrupts(signals chan<- os.Signal) {
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+}
+
+// prepareInterruptible runs the command in its own process group so that a
+// Ctrl-C only reaches bbl, which then forwards it.
+func prepareInterruptible(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func forwardSignal(process *os.Process, sig os.Signal) {
+	process.Signal(sig)
+}
@@ -0,0 +1,18 @@
+package helpers
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+)
+
+func notifyInterrupts(signals chan<- os.Signal) {
+	signal.Notify(signals, os.Interrupt)
+}
+
+// prepareInterruptible leaves the command in the console of bbl, so a Ctrl-C
+// reaches the command directly. Windows cannot deliver an interrupt to another
+// process, which is why forwardSignal does nothing.
+func prepareInterruptible(cmd *exec.Cmd) {}
+
+func forwardSignal(process *os.Process, sig os.Signal) {}
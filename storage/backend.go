@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const defaultS3Region = "us-east-1"
+
+type Backend interface {
+	Location() string
+	Validate() error
+	Exists(name string) (bool, error)
+	Read(name string) ([]byte, error)
+	Write(name string, data []byte) error
+	WritePrivate(name string, data []byte) error
+	Create(name string, data []byte) error
+	List(prefix string) ([]string, error)
+	Rename(oldName, newName string) error
+	Delete(name string) error
+}
+
+func NewBackend(stateDir, stateBackend string) (Backend, error) {
+	if stateBackend == "" || stateBackend == "local" {
+		return NewLocalBackend(stateDir), nil
+	}
+
+	backendURL, err := url.Parse(stateBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	switch backendURL.Scheme {
+	case "s3":
+		if backendURL.Host == "" {
+			return nil, fmt.Errorf("%q is missing a bucket, the s3 state backend must be provided as s3://bucket/path", stateBackend)
+		}
+
+		query := backendURL.Query()
+
+		region := query.Get("region")
+		if region == "" {
+			region = defaultS3Region
+		}
+
+		config := &goaws.Config{
+			Region: goaws.String(region),
+		}
+
+		if endpoint := query.Get("endpoint"); endpoint != "" {
+			config.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+		}
+
+		client := s3.New(session.New(config))
+
+		return NewS3Backend(client, backendURL.Host, strings.Trim(backendURL.Path, "/")), nil
+	default:
+		return nil, fmt.Errorf("%q is an invalid state backend, supported values are: [local, s3://bucket/path]", stateBackend)
+	}
+}
@@ -0,0 +1,46 @@
+package storage_test
+
+import (
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewBackend", func() {
+	It("returns a local backend when no backend is provided", func() {
+		backend, err := storage.NewBackend("some-state-dir", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backend).To(Equal(storage.NewLocalBackend("some-state-dir")))
+	})
+
+	It("returns a local backend for local", func() {
+		backend, err := storage.NewBackend("some-state-dir", "local")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backend).To(Equal(storage.NewLocalBackend("some-state-dir")))
+	})
+
+	It("returns an s3 backend for s3 urls", func() {
+		backend, err := storage.NewBackend("some-state-dir", "s3://some-bucket/some/prefix?region=some-region")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backend).To(BeAssignableToTypeOf(storage.S3Backend{}))
+		Expect(backend.Location()).To(Equal("s3://some-bucket/some/prefix"))
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when the s3 url has no bucket", func() {
+			_, err := storage.NewBackend("some-state-dir", "s3:///some-prefix")
+			Expect(err).To(MatchError(`"s3:///some-prefix" is missing a bucket, the s3 state backend must be provided as s3://bucket/path`))
+		})
+
+		It("returns an error when the backend is not supported", func() {
+			_, err := storage.NewBackend("some-state-dir", "gcs://some-bucket")
+			Expect(err).To(MatchError(`"gcs://some-bucket" is an invalid state backend, supported values are: [local, s3://bucket/path]`))
+		})
+
+		It("returns an error when the backend cannot be parsed", func() {
+			_, err := storage.NewBackend("some-state-dir", "%%%")
+			Expect(err).To(MatchError(ContainSubstring("invalid URL escape")))
+		})
+	})
+})
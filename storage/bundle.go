@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+const (
+	BundleManifestFileName = "manifest.json"
+
+	bundleStateFileName        = "bbl-state.json"
+	bundleTFStateFileName      = "terraform.tfstate"
+	bundleBOSHStateFileName    = "bosh-state.json"
+	bundleBOSHManifestFileName = "bosh.yml"
+	bundleOverridesPrefix      = TerraformOverridesDir + "/"
+)
+
+var (
+	MissingBundleKey   = errors.New("the bundle is encrypted, please provide the key with --key-file")
+	IncorrectBundleKey = errors.New("the bundle could not be decrypted, please make sure the provided key is correct")
+)
+
+type BundleManifest struct {
+	BBLVersion   string    `json:"bblVersion"`
+	StateVersion int       `json:"stateVersion"`
+	IAAS         string    `json:"iaas"`
+	EnvID        string    `json:"envID"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Files        []string  `json:"files"`
+}
+
+type bundleFile struct {
+	name string
+	data []byte
+}
+
+func WriteBundle(w io.Writer, state State, bblVersion string, encryptionKey string) (BundleManifest, error) {
+	state.Version = CurrentStateVersion
+	state.SecretStore = ""
+
+	files := []bundleFile{}
+
+	if state.TFState != "" {
+		files = append(files, bundleFile{bundleTFStateFileName, []byte(state.TFState)})
+		state.TFState = ""
+	}
+
+	if state.BOSH.State != nil {
+		boshState, err := json.Marshal(state.BOSH.State)
+		if err != nil {
+			return BundleManifest{}, err
+		}
+		files = append(files, bundleFile{bundleBOSHStateFileName, boshState})
+		state.BOSH.State = nil
+	}
+
+	if state.BOSH.Manifest != "" {
+		files = append(files, bundleFile{bundleBOSHManifestFileName, []byte(state.BOSH.Manifest)})
+		state.BOSH.Manifest = ""
+	}
+
+	for _, name := range sortedKeys(state.TerraformOverrides) {
+		files = append(files, bundleFile{bundleOverridesPrefix + name, []byte(state.TerraformOverrides[name])})
+	}
+	state.TerraformOverrides = nil
+
+	stateData, err := json.Marshal(state)
+	if err != nil {
+		return BundleManifest{}, err
+	}
+	files = append([]bundleFile{{bundleStateFileName, stateData}}, files...)
+
+	manifest := BundleManifest{
+		BBLVersion:   bblVersion,
+		StateVersion: state.Version,
+		IAAS:         state.IAAS,
+		EnvID:        state.EnvID,
+		CreatedAt:    now().UTC(),
+	}
+	for _, file := range files {
+		manifest.Files = append(manifest.Files, file.name)
+	}
+
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return BundleManifest{}, err
+	}
+	files = append([]bundleFile{{BundleManifestFileName, manifestData}}, files...)
+
+	var archive bytes.Buffer
+	err = writeArchive(&archive, files)
+	if err != nil {
+		return BundleManifest{}, err
+	}
+
+	data := archive.Bytes()
+	if encryptionKey != "" {
+		data, err = encrypt(data, encryptionKey)
+		if err != nil {
+			return BundleManifest{}, err
+		}
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return BundleManifest{}, err
+	}
+
+	return manifest, nil
+}
+
+func ReadBundle(r io.Reader, encryptionKey string) (State, BundleManifest, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return State{}, BundleManifest{}, err
+	}
+
+	if isEncrypted(data) {
+		if encryptionKey == "" {
+			return State{}, BundleManifest{}, MissingBundleKey
+		}
+
+		data, err = decrypt(data, encryptionKey)
+		if err != nil {
+			if err == IncorrectEncryptionKey {
+				return State{}, BundleManifest{}, IncorrectBundleKey
+			}
+			return State{}, BundleManifest{}, err
+		}
+	}
+
+	files, err := readArchive(bytes.NewReader(data))
+	if err != nil {
+		return State{}, BundleManifest{}, fmt.Errorf("the bundle is not a valid bbl export: %s", err)
+	}
+
+	var manifest BundleManifest
+	if err := unmarshalBundleFile(files, BundleManifestFileName, &manifest); err != nil {
+		return State{}, BundleManifest{}, err
+	}
+
+	for _, name := range manifest.Files {
+		if _, ok := files[name]; !ok {
+			return State{}, BundleManifest{}, fmt.Errorf("the bundle is missing %s", name)
+		}
+	}
+
+	var state State
+	if err := unmarshalBundleFile(files, bundleStateFileName, &state); err != nil {
+		return State{}, BundleManifest{}, err
+	}
+
+	if tfState, ok := files[bundleTFStateFileName]; ok {
+		state.TFState = string(tfState)
+	}
+
+	if _, ok := files[bundleBOSHStateFileName]; ok {
+		if err := unmarshalBundleFile(files, bundleBOSHStateFileName, &state.BOSH.State); err != nil {
+			return State{}, BundleManifest{}, err
+		}
+	}
+
+	if boshManifest, ok := files[bundleBOSHManifestFileName]; ok {
+		state.BOSH.Manifest = string(boshManifest)
+	}
+
+	for _, name := range manifest.Files {
+		if strings.HasPrefix(name, bundleOverridesPrefix) {
+			if state.TerraformOverrides == nil {
+				state.TerraformOverrides = map[string]string{}
+			}
+			state.TerraformOverrides[strings.TrimPrefix(name, bundleOverridesPrefix)] = string(files[name])
+		}
+	}
+
+	state, err = migrate(state)
+	if err != nil {
+		return State{}, BundleManifest{}, err
+	}
+
+	return state, manifest, nil
+}
+
+func unmarshalBundleFile(files map[string][]byte, name string, v interface{}) error {
+	data, ok := files[name]
+	if !ok {
+		return fmt.Errorf("the bundle is missing %s", name)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("the bundle has an invalid %s: %s", name, err)
+	}
+
+	return nil
+}
+
+func writeArchive(w io.Writer, files []bundleFile) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, file := range files {
+		err := tarWriter.WriteHeader(&tar.Header{
+			Name:    file.name,
+			Mode:    int64(OS_PRIVATE_READ_WRITE_MODE),
+			Size:    int64(len(file.data)),
+			ModTime: now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := tarWriter.Write(file.data); err != nil {
+			return err
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+
+	return gzipWriter.Close()
+}
+
+func readArchive(r io.Reader) (map[string][]byte, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	files := map[string][]byte{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		files[header.Name] = data
+	}
+
+	return files, nil
+}
@@ -0,0 +1,223 @@
+package storage_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bundle", func() {
+	var state storage.State
+
+	BeforeEach(func() {
+		storage.SetNow(func() time.Time {
+			return time.Date(2016, time.November, 1, 12, 0, 0, 0, time.UTC)
+		})
+
+		state = storage.State{
+			IAAS:        "gcp",
+			EnvID:       "some-env-id",
+			SecretStore: "https://some-vault/secret/bbl",
+			GCP: storage.GCP{
+				ServiceAccountKey: "some-service-account-key",
+				ProjectID:         "some-project-id",
+			},
+			BOSH: storage.BOSH{
+				DirectorPassword: "some-director-password",
+				State: map[string]interface{}{
+					"director_id": "some-director-id",
+				},
+				Manifest: "name: bosh",
+			},
+			TFState: "some-tf-state",
+		}
+	})
+
+	AfterEach(func() {
+		storage.ResetNow()
+	})
+
+	readArchive := func(data []byte) map[string]string {
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		Expect(err).NotTo(HaveOccurred())
+
+		files := map[string]string{}
+		tarReader := tar.NewReader(gzipReader)
+		for {
+			header, err := tarReader.Next()
+			if err != nil {
+				break
+			}
+			contents, err := ioutil.ReadAll(tarReader)
+			Expect(err).NotTo(HaveOccurred())
+			files[header.Name] = string(contents)
+		}
+
+		return files
+	}
+
+	Describe("WriteBundle", func() {
+		It("writes the state, terraform state, bosh-init state and manifest to a gzipped tarball", func() {
+			var bundle bytes.Buffer
+			manifest, err := storage.WriteBundle(&bundle, state, "1.2.3", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manifest).To(Equal(storage.BundleManifest{
+				BBLVersion:   "1.2.3",
+				StateVersion: 3,
+				IAAS:         "gcp",
+				EnvID:        "some-env-id",
+				CreatedAt:    time.Date(2016, time.November, 1, 12, 0, 0, 0, time.UTC),
+				Files:        []string{"bbl-state.json", "terraform.tfstate", "bosh-state.json", "bosh.yml"},
+			}))
+
+			files := readArchive(bundle.Bytes())
+			Expect(files).To(HaveLen(5))
+			Expect(files["manifest.json"]).To(MatchJSON(`{
+				"bblVersion": "1.2.3",
+				"stateVersion": 3,
+				"iaas": "gcp",
+				"envID": "some-env-id",
+				"createdAt": "2016-11-01T12:00:00Z",
+				"files": ["bbl-state.json", "terraform.tfstate", "bosh-state.json", "bosh.yml"]
+			}`))
+			Expect(files["terraform.tfstate"]).To(Equal("some-tf-state"))
+			Expect(files["bosh-state.json"]).To(MatchJSON(`{"director_id": "some-director-id"}`))
+			Expect(files["bosh.yml"]).To(Equal("name: bosh"))
+			Expect(files["bbl-state.json"]).To(ContainSubstring("some-director-password"))
+			Expect(files["bbl-state.json"]).NotTo(ContainSubstring("some-tf-state"))
+			Expect(files["bbl-state.json"]).NotTo(ContainSubstring("secretStore"))
+		})
+
+		It("writes the terraform overrides to the overrides directory of the bundle", func() {
+			state.TerraformOverrides = map[string]string{
+				"firewall.tf":         "resource \"google_compute_firewall\" \"extra\" {}",
+				"network_override.tf": "resource \"google_compute_network\" \"bbl-network\" {}",
+			}
+
+			var bundle bytes.Buffer
+			manifest, err := storage.WriteBundle(&bundle, state, "1.2.3", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(manifest.Files).To(ContainElement("overrides/firewall.tf"))
+			Expect(manifest.Files).To(ContainElement("overrides/network_override.tf"))
+
+			files := readArchive(bundle.Bytes())
+			Expect(files["overrides/firewall.tf"]).To(Equal(`resource "google_compute_firewall" "extra" {}`))
+			Expect(files["bbl-state.json"]).NotTo(ContainSubstring("google_compute_firewall"))
+		})
+
+		It("encrypts the bundle when a key is provided", func() {
+			var bundle bytes.Buffer
+			_, err := storage.WriteBundle(&bundle, state, "1.2.3", "some-key")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(bundle.String()).To(ContainSubstring(`"cipher":"aes-256-gcm"`))
+			Expect(bundle.String()).NotTo(ContainSubstring("some-director-password"))
+		})
+	})
+
+	Describe("ReadBundle", func() {
+		It("reads the state written by WriteBundle", func() {
+			var bundle bytes.Buffer
+			_, err := storage.WriteBundle(&bundle, state, "1.2.3", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			imported, manifest, err := storage.ReadBundle(&bundle, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			state.Version = 3
+			state.SecretStore = ""
+			Expect(imported).To(Equal(state))
+			Expect(manifest.BBLVersion).To(Equal("1.2.3"))
+		})
+
+		It("reads the terraform overrides written by WriteBundle", func() {
+			state.TerraformOverrides = map[string]string{"firewall.tf": "some-override"}
+
+			var bundle bytes.Buffer
+			_, err := storage.WriteBundle(&bundle, state, "1.2.3", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			imported, _, err := storage.ReadBundle(&bundle, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(imported.TerraformOverrides).To(Equal(map[string]string{"firewall.tf": "some-override"}))
+		})
+
+		It("decrypts the bundle with the key", func() {
+			var bundle bytes.Buffer
+			_, err := storage.WriteBundle(&bundle, state, "1.2.3", "some-key")
+			Expect(err).NotTo(HaveOccurred())
+
+			imported, _, err := storage.ReadBundle(&bundle, "some-key")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(imported.TFState).To(Equal("some-tf-state"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the bundle is encrypted and no key is provided", func() {
+				var bundle bytes.Buffer
+				_, err := storage.WriteBundle(&bundle, state, "1.2.3", "some-key")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, err = storage.ReadBundle(&bundle, "")
+				Expect(err).To(Equal(storage.MissingBundleKey))
+			})
+
+			It("returns an error when the key is incorrect", func() {
+				var bundle bytes.Buffer
+				_, err := storage.WriteBundle(&bundle, state, "1.2.3", "some-key")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, err = storage.ReadBundle(&bundle, "some-other-key")
+				Expect(err).To(Equal(storage.IncorrectBundleKey))
+			})
+
+			It("returns an error when the bundle is not an archive", func() {
+				_, _, err := storage.ReadBundle(bytes.NewBufferString("some-garbage"), "")
+				Expect(err).To(MatchError(ContainSubstring("the bundle is not a valid bbl export")))
+			})
+
+			It("returns an error when the bundle was written with a newer state version", func() {
+				var bundle bytes.Buffer
+				gzipWriter := gzip.NewWriter(&bundle)
+				tarWriter := tar.NewWriter(gzipWriter)
+				for name, contents := range map[string]string{
+					"manifest.json":  `{"files": ["bbl-state.json"]}`,
+					"bbl-state.json": `{"version": 999}`,
+				} {
+					Expect(tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(contents))})).To(Succeed())
+					_, err := tarWriter.Write([]byte(contents))
+					Expect(err).NotTo(HaveOccurred())
+				}
+				Expect(tarWriter.Close()).To(Succeed())
+				Expect(gzipWriter.Close()).To(Succeed())
+
+				_, _, err := storage.ReadBundle(&bundle, "")
+				Expect(err).To(MatchError(ContainSubstring("which is newer than the version this bbl supports")))
+			})
+
+			It("returns an error when a file listed in the manifest is missing", func() {
+				var bundle bytes.Buffer
+				gzipWriter := gzip.NewWriter(&bundle)
+				tarWriter := tar.NewWriter(gzipWriter)
+				contents := `{"files": ["bbl-state.json", "terraform.tfstate"]}`
+				Expect(tarWriter.WriteHeader(&tar.Header{Name: "manifest.json", Mode: 0600, Size: int64(len(contents))})).To(Succeed())
+				_, err := tarWriter.Write([]byte(contents))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tarWriter.Close()).To(Succeed())
+				Expect(gzipWriter.Close()).To(Succeed())
+
+				_, _, err = storage.ReadBundle(&bundle, "")
+				Expect(err).To(MatchError("the bundle is missing bbl-state.json"))
+			})
+		})
+	})
+})
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	encryptionCipher = "aes-256-gcm"
+	encryptionKDF    = "scrypt"
+
+	scryptN      = 32768
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLength   = 32
+)
+
+var (
+	randReader io.Reader = rand.Reader
+
+	MissingEncryptionKey   = errors.New("bbl-state.json is encrypted, please provide the state key with --state-key-file or BBL_STATE_KEY")
+	IncorrectEncryptionKey = errors.New("bbl-state.json could not be decrypted, please make sure the provided state key is correct")
+)
+
+type encryptedState struct {
+	Cipher     string `json:"cipher"`
+	KDF        string `json:"kdf"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type encryptedStateFile struct {
+	Encrypted *encryptedState `json:"encrypted"`
+}
+
+func isEncrypted(data []byte) bool {
+	var file encryptedStateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return false
+	}
+
+	return file.Encrypted != nil
+}
+
+func encrypt(plaintext []byte, key string) ([]byte, error) {
+	salt := make([]byte, saltLength)
+	if _, err := io.ReadFull(randReader, salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(randReader, nonce); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(encryptedStateFile{
+		Encrypted: &encryptedState{
+			Cipher:     encryptionCipher,
+			KDF:        encryptionKDF,
+			Salt:       salt,
+			Nonce:      nonce,
+			Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+		},
+	})
+}
+
+func decrypt(data []byte, key string) ([]byte, error) {
+	if key == "" {
+		return nil, MissingEncryptionKey
+	}
+
+	var file encryptedStateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	if file.Encrypted.Cipher != encryptionCipher || file.Encrypted.KDF != encryptionKDF {
+		return nil, errors.New("bbl-state.json is encrypted with an unsupported cipher")
+	}
+
+	gcm, err := newGCM(key, file.Encrypted.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(file.Encrypted.Nonce) != gcm.NonceSize() {
+		return nil, IncorrectEncryptionKey
+	}
+
+	plaintext, err := gcm.Open(nil, file.Encrypted.Nonce, file.Encrypted.Ciphertext, nil)
+	if err != nil {
+		return nil, IncorrectEncryptionKey
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key string, salt []byte) (cipher.AEAD, error) {
+	derivedKey, err := scrypt.Key([]byte(key), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -2,7 +2,10 @@ package storage
 
 import (
 	"io"
+	"net/http"
 	"os"
+	"os/user"
+	"time"
 )
 
 func SetEncode(f func(io.Writer, interface{}) error) {
@@ -20,3 +23,65 @@ func SetRename(f func(string, string) error) {
 func ResetRename() {
 	rename = os.Rename
 }
+
+func SetNow(f func() time.Time) {
+	now = f
+}
+
+func ResetNow() {
+	now = time.Now
+}
+
+func SetSleep(f func(time.Duration)) {
+	sleep = f
+}
+
+func ResetSleep() {
+	sleep = time.Sleep
+}
+
+func SetHostname(f func() (string, error)) {
+	hostname = f
+}
+
+func ResetHostname() {
+	hostname = os.Hostname
+}
+
+func SetCurrentUser(f func() (*user.User, error)) {
+	currentUser = f
+}
+
+func ResetCurrentUser() {
+	currentUser = user.Current
+}
+
+func Migrate(state State) (State, error) {
+	return migrate(state)
+}
+
+func MigrateV1ToV2(state State) (State, error) {
+	return migrateV1ToV2(state)
+}
+
+func MigrateV2ToV3(state State) (State, error) {
+	return migrateV2ToV3(state)
+}
+
+func SetMigrations(m map[int]func(State) (State, error)) {
+	migrations = map[int]migration{}
+	for version, f := range m {
+		migrations[version] = f
+	}
+}
+
+func ResetMigrations() {
+	migrations = map[int]migration{
+		1: migrateV1ToV2,
+		2: migrateV2ToV3,
+	}
+}
+
+func VaultSecretStoreTimeout(v VaultSecretStore) time.Duration {
+	return v.client.(*http.Client).Timeout
+}
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type LocalBackend struct {
+	dir string
+}
+
+func NewLocalBackend(dir string) LocalBackend {
+	return LocalBackend{
+		dir: dir,
+	}
+}
+
+func (b LocalBackend) Location() string {
+	return b.dir
+}
+
+func (b LocalBackend) Validate() error {
+	_, err := os.Stat(b.dir)
+	return err
+}
+
+func (b LocalBackend) Exists(name string) (bool, error) {
+	_, err := os.Stat(b.path(name))
+	switch {
+	case os.IsNotExist(err):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b LocalBackend) Read(name string) ([]byte, error) {
+	return ioutil.ReadFile(b.path(name))
+}
+
+func (b LocalBackend) Write(name string, data []byte) error {
+	return b.write(name, data, OS_READ_WRITE_MODE)
+}
+
+func (b LocalBackend) WritePrivate(name string, data []byte) error {
+	return b.write(name, data, OS_PRIVATE_READ_WRITE_MODE)
+}
+
+func (b LocalBackend) write(name string, data []byte, mode os.FileMode) error {
+	file, err := ioutil.TempFile(b.dir, fmt.Sprintf(".%s.", name))
+	if err != nil {
+		return err
+	}
+
+	err = writeAndSync(file, data, mode)
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	err = rename(file.Name(), b.path(name))
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return syncDir(b.dir)
+}
+
+func (b LocalBackend) Create(name string, data []byte) error {
+	file, err := os.OpenFile(b.path(name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, OS_READ_WRITE_MODE)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	return err
+}
+
+func (b LocalBackend) List(prefix string) ([]string, error) {
+	files, err := ioutil.ReadDir(b.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, file := range files {
+		if !file.IsDir() && strings.HasPrefix(file.Name(), prefix) {
+			names = append(names, file.Name())
+		}
+	}
+
+	return names, nil
+}
+
+func (b LocalBackend) Rename(oldName, newName string) error {
+	return rename(b.path(oldName), b.path(newName))
+}
+
+func (b LocalBackend) Delete(name string) error {
+	return os.Remove(b.path(name))
+}
+
+func (b LocalBackend) path(name string) string {
+	return filepath.Join(b.dir, name)
+}
+
+func writeAndSync(file *os.File, data []byte, mode os.FileMode) error {
+	_, err := file.Write(data)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Sync()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Chmod(file.Name(), mode)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
@@ -0,0 +1,158 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LocalBackend", func() {
+	var (
+		backend storage.LocalBackend
+		tempDir string
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		backend = storage.NewLocalBackend(tempDir)
+	})
+
+	Describe("Location", func() {
+		It("returns the directory", func() {
+			Expect(backend.Location()).To(Equal(tempDir))
+		})
+	})
+
+	Describe("Validate", func() {
+		It("returns an error when the directory does not exist", func() {
+			err := storage.NewLocalBackend("some-fake-directory").Validate()
+			Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+		})
+	})
+
+	Describe("Write and Read", func() {
+		It("writes and reads back a file in the directory", func() {
+			err := backend.Write("some-file", []byte("some-contents"))
+			Expect(err).NotTo(HaveOccurred())
+
+			contents, err := ioutil.ReadFile(filepath.Join(tempDir, "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-contents"))
+
+			contents, err = backend.Read("some-file")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-contents"))
+		})
+
+		It("replaces the file without leaving temporary files behind", func() {
+			err := backend.Write("some-file", []byte("some-contents"))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = backend.Write("some-file", []byte("some-other-contents"))
+			Expect(err).NotTo(HaveOccurred())
+
+			contents, err := backend.Read("some-file")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-other-contents"))
+
+			files, err := ioutil.ReadDir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+			Expect(files[0].Mode()).To(Equal(os.FileMode(0644)))
+		})
+
+		It("returns a not exist error when the file is missing", func() {
+			_, err := backend.Read("some-missing-file")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	Describe("Exists", func() {
+		It("returns whether the file exists", func() {
+			exists, err := backend.Exists("some-file")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+
+			err = backend.Write("some-file", []byte("some-contents"))
+			Expect(err).NotTo(HaveOccurred())
+
+			exists, err = backend.Exists("some-file")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+		})
+	})
+
+	Describe("Create", func() {
+		It("creates the file", func() {
+			err := backend.Create("some-file", []byte("some-contents"))
+			Expect(err).NotTo(HaveOccurred())
+
+			contents, err := ioutil.ReadFile(filepath.Join(tempDir, "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-contents"))
+		})
+
+		It("returns an exist error when the file already exists", func() {
+			err := backend.Write("some-file", []byte("some-contents"))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = backend.Create("some-file", []byte("some-other-contents"))
+			Expect(os.IsExist(err)).To(BeTrue())
+
+			contents, err := ioutil.ReadFile(filepath.Join(tempDir, "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-contents"))
+		})
+	})
+
+	Describe("List", func() {
+		It("returns the files with the given prefix", func() {
+			for _, name := range []string{"some-prefix-1", "some-prefix-2", "some-other-file"} {
+				err := backend.Write(name, []byte("some-contents"))
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			names, err := backend.List("some-prefix-")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(ConsistOf("some-prefix-1", "some-prefix-2"))
+		})
+
+		It("returns an error when the directory cannot be read", func() {
+			_, err := storage.NewLocalBackend("some-fake-directory").List("some-prefix-")
+			Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+		})
+	})
+
+	Describe("Rename", func() {
+		It("renames the file", func() {
+			err := backend.Write("some-file", []byte("some-contents"))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = backend.Rename("some-file", "some-other-file")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(tempDir, "some-file")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempDir, "some-other-file")).To(BeAnExistingFile())
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes the file", func() {
+			err := backend.Write("some-file", []byte("some-contents"))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = backend.Delete("some-file")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(tempDir, "some-file")).NotTo(BeAnExistingFile())
+		})
+	})
+})
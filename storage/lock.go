@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+)
+
+const LockFileName = "bbl-state.lock"
+
+var (
+	lockRetryInterval = time.Second
+
+	now         func() time.Time           = time.Now
+	sleep       func(time.Duration)        = time.Sleep
+	hostname    func() (string, error)     = os.Hostname
+	currentUser func() (*user.User, error) = user.Current
+)
+
+type Lock struct {
+	Holder    string    `json:"holder"`
+	Host      string    `json:"host"`
+	Command   string    `json:"command"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (l Lock) String() string {
+	return fmt.Sprintf("%s@%s running \"bbl %s\" since %s", l.Holder, l.Host, l.Command, l.Timestamp.Format(time.RFC3339))
+}
+
+type LockedError struct {
+	Lock Lock
+}
+
+func (e LockedError) Error() string {
+	return fmt.Sprintf("bbl-state.json is locked by %s, use --lock-timeout to wait for the lock or \"bbl force-unlock\" to remove a stale lock", e.Lock)
+}
+
+type Locker struct {
+	backend Backend
+}
+
+func NewLocker(backend Backend) Locker {
+	return Locker{
+		backend: backend,
+	}
+}
+
+func (l Locker) Lock(command string, timeout time.Duration) error {
+	data, err := json.Marshal(newLock(command))
+	if err != nil {
+		return err
+	}
+
+	deadline := now().Add(timeout)
+	for {
+		err := l.backend.Create(LockFileName, data)
+		if err == nil {
+			return nil
+		}
+
+		if !os.IsExist(err) {
+			return err
+		}
+
+		if !now().Before(deadline) {
+			break
+		}
+
+		sleep(lockRetryInterval)
+	}
+
+	lock, locked, err := l.Current()
+	if err != nil {
+		return err
+	}
+
+	if !locked {
+		return l.Lock(command, 0)
+	}
+
+	return LockedError{Lock: lock}
+}
+
+func (l Locker) Current() (Lock, bool, error) {
+	data, err := l.backend.Read(LockFileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Lock{}, false, nil
+		}
+		return Lock{}, false, err
+	}
+
+	var lock Lock
+	err = json.Unmarshal(data, &lock)
+	if err != nil {
+		return Lock{}, false, err
+	}
+
+	return lock, true, nil
+}
+
+func (l Locker) Unlock() error {
+	err := l.backend.Delete(LockFileName)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func newLock(command string) Lock {
+	lock := Lock{
+		Holder:    "unknown",
+		Host:      "unknown",
+		Command:   command,
+		Timestamp: now().UTC(),
+	}
+
+	if u, err := currentUser(); err == nil {
+		lock.Holder = u.Username
+	}
+
+	if h, err := hostname(); err == nil {
+		lock.Host = h
+	}
+
+	return lock
+}
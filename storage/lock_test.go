@@ -0,0 +1,173 @@
+package storage_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Locker", func() {
+	var (
+		tempDir     string
+		locker      storage.Locker
+		currentTime time.Time
+		sleeps      []time.Duration
+	)
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		locker = storage.NewLocker(storage.NewLocalBackend(tempDir))
+
+		currentTime = time.Date(2016, time.November, 1, 12, 0, 0, 0, time.UTC)
+		sleeps = []time.Duration{}
+
+		storage.SetNow(func() time.Time {
+			return currentTime
+		})
+		storage.SetSleep(func(duration time.Duration) {
+			sleeps = append(sleeps, duration)
+			currentTime = currentTime.Add(duration)
+		})
+		storage.SetHostname(func() (string, error) {
+			return "some-host", nil
+		})
+		storage.SetCurrentUser(func() (*user.User, error) {
+			return &user.User{Username: "some-user"}, nil
+		})
+	})
+
+	AfterEach(func() {
+		storage.ResetNow()
+		storage.ResetSleep()
+		storage.ResetHostname()
+		storage.ResetCurrentUser()
+	})
+
+	Describe("Lock", func() {
+		It("writes a lock record to the lock file", func() {
+			err := locker.Lock("up", 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := ioutil.ReadFile(filepath.Join(tempDir, "bbl-state.lock"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var lock storage.Lock
+			err = json.Unmarshal(data, &lock)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(lock).To(Equal(storage.Lock{
+				Holder:    "some-user",
+				Host:      "some-host",
+				Command:   "up",
+				Timestamp: currentTime,
+			}))
+		})
+
+		It("records an unknown holder and host when they cannot be determined", func() {
+			storage.SetHostname(func() (string, error) {
+				return "", errors.New("failed to get hostname")
+			})
+			storage.SetCurrentUser(func() (*user.User, error) {
+				return nil, errors.New("failed to get user")
+			})
+
+			err := locker.Lock("up", 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			lock, locked, err := locker.Current()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locked).To(BeTrue())
+			Expect(lock.Holder).To(Equal("unknown"))
+			Expect(lock.Host).To(Equal("unknown"))
+		})
+
+		Context("when the state is already locked", func() {
+			BeforeEach(func() {
+				err := locker.Lock("create-lbs", 0)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an error describing the existing lock", func() {
+				err := locker.Lock("up", 0)
+				Expect(err).To(MatchError(`bbl-state.json is locked by some-user@some-host running "bbl create-lbs" since 2016-11-01T12:00:00Z, use --lock-timeout to wait for the lock or "bbl force-unlock" to remove a stale lock`))
+				Expect(sleeps).To(BeEmpty())
+			})
+
+			It("retries until the timeout expires", func() {
+				err := locker.Lock("up", 3*time.Second)
+				Expect(err).To(MatchError(ContainSubstring("bbl-state.json is locked by some-user@some-host")))
+				Expect(sleeps).To(Equal([]time.Duration{time.Second, time.Second, time.Second}))
+			})
+
+			It("acquires the lock when it is released before the timeout", func() {
+				storage.SetSleep(func(duration time.Duration) {
+					sleeps = append(sleeps, duration)
+					currentTime = currentTime.Add(duration)
+
+					err := locker.Unlock()
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				err := locker.Lock("up", 3*time.Second)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sleeps).To(HaveLen(1))
+
+				lock, _, err := locker.Current()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lock.Command).To(Equal("up"))
+			})
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the lock file cannot be created", func() {
+				locker = storage.NewLocker(storage.NewLocalBackend("some-fake-directory"))
+
+				err := locker.Lock("up", 0)
+				Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+			})
+		})
+	})
+
+	Describe("Current", func() {
+		It("returns false when the state is not locked", func() {
+			_, locked, err := locker.Current()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(locked).To(BeFalse())
+		})
+
+		It("returns an error when the lock file cannot be parsed", func() {
+			err := ioutil.WriteFile(filepath.Join(tempDir, "bbl-state.lock"), []byte("%%%"), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err = locker.Current()
+			Expect(err).To(MatchError(ContainSubstring("invalid character")))
+		})
+	})
+
+	Describe("Unlock", func() {
+		It("removes the lock file", func() {
+			err := locker.Lock("up", 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = locker.Unlock()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(tempDir, "bbl-state.lock")).NotTo(BeAnExistingFile())
+		})
+
+		It("does nothing when the state is not locked", func() {
+			err := locker.Unlock()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+const CurrentStateVersion = 3
+
+type migration func(State) (State, error)
+
+var migrations = map[int]migration{
+	1: migrateV1ToV2,
+	2: migrateV2ToV3,
+}
+
+func migrate(state State) (State, error) {
+	if state.Version > CurrentStateVersion {
+		return state, fmt.Errorf("bbl-state.json has version %d, which is newer than the version this bbl supports (%d), please upgrade bbl", state.Version, CurrentStateVersion)
+	}
+
+	if state.Version == 0 {
+		return state, nil
+	}
+
+	for state.Version < CurrentStateVersion {
+		m, ok := migrations[state.Version]
+		if !ok {
+			return state, fmt.Errorf("bbl-state.json has version %d, which cannot be migrated", state.Version)
+		}
+
+		migrated, err := m(state)
+		if err != nil {
+			return state, fmt.Errorf("failed to migrate bbl-state.json from version %d: %s", state.Version, err)
+		}
+
+		if migrated.Version != state.Version+1 {
+			return state, fmt.Errorf("migration from version %d of bbl-state.json did not produce version %d", state.Version, state.Version+1)
+		}
+
+		state = migrated
+	}
+
+	return state, nil
+}
+
+func migrateV1ToV2(state State) (State, error) {
+	state.Version = 2
+	state.IAAS = "aws"
+	return state, nil
+}
+
+// Version 3 keeps secrets in bbl-secrets.json. The split itself happens
+// when the migrated state is written.
+func migrateV2ToV3(state State) (State, error) {
+	state.Version = 3
+	return state, nil
+}
+
+func migrateStateFileName(backend Backend) error {
+	name, err := stateFileName(backend)
+	if err != nil || name == StateFileName {
+		return err
+	}
+
+	GetStateLogger.Println("renaming state.json to bbl-state.json")
+	return backend.Rename(name, StateFileName)
+}
+
+// Environments created by older versions of bbl keep their state in
+// state.json until it is migrated.
+func stateFileName(backend Backend) (string, error) {
+	stateExists, err := backend.Exists(LegacyStateFileName)
+	if err != nil || !stateExists {
+		return StateFileName, err
+	}
+
+	bblStateExists, err := backend.Exists(StateFileName)
+	if err != nil {
+		return "", err
+	}
+
+	if bblStateExists {
+		return "", errors.New("Cannot proceed with state.json and bbl-state.json present. Please delete one of the files.")
+	}
+
+	return LegacyStateFileName, nil
+}
@@ -0,0 +1,139 @@
+package storage_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migrations", func() {
+	AfterEach(func() {
+		storage.ResetMigrations()
+	})
+
+	Describe("Migrate", func() {
+		It("applies the migrations in order up to the current version", func() {
+			applied := []int{}
+			expected := []int{}
+			migrations := map[int]func(storage.State) (storage.State, error){}
+			for version := 1; version < storage.CurrentStateVersion; version++ {
+				from := version
+				expected = append(expected, from)
+				migrations[from] = func(state storage.State) (storage.State, error) {
+					applied = append(applied, from)
+					state.Version = from + 1
+					state.EnvID = fmt.Sprintf("migrated-from-%d", from)
+					return state, nil
+				}
+			}
+			storage.SetMigrations(migrations)
+
+			state, err := storage.Migrate(storage.State{
+				Version: 1,
+				EnvID:   "some-env-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(applied).To(Equal(expected))
+			Expect(state).To(Equal(storage.State{
+				Version: storage.CurrentStateVersion,
+				EnvID:   fmt.Sprintf("migrated-from-%d", storage.CurrentStateVersion-1),
+			}))
+		})
+
+		It("does not migrate a state at the current version", func() {
+			state, err := storage.Migrate(storage.State{
+				Version: storage.CurrentStateVersion,
+				IAAS:    "gcp",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(storage.State{
+				Version: storage.CurrentStateVersion,
+				IAAS:    "gcp",
+			}))
+		})
+
+		It("does not migrate an unversioned state", func() {
+			state, err := storage.Migrate(storage.State{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(storage.State{}))
+		})
+
+		Context("failure cases", func() {
+			It("refuses a state that is newer than this version of bbl", func() {
+				_, err := storage.Migrate(storage.State{
+					Version: storage.CurrentStateVersion + 1,
+				})
+				Expect(err).To(MatchError(fmt.Sprintf("bbl-state.json has version %d, which is newer than the version this bbl supports (%d), please upgrade bbl", storage.CurrentStateVersion+1, storage.CurrentStateVersion)))
+			})
+
+			It("returns an error when there is no migration for the version", func() {
+				storage.SetMigrations(map[int]func(storage.State) (storage.State, error){})
+
+				_, err := storage.Migrate(storage.State{
+					Version: 1,
+				})
+				Expect(err).To(MatchError("bbl-state.json has version 1, which cannot be migrated"))
+			})
+
+			It("returns an error when a migration fails", func() {
+				storage.SetMigrations(map[int]func(storage.State) (storage.State, error){
+					1: func(state storage.State) (storage.State, error) {
+						return state, errors.New("some migration error")
+					},
+				})
+
+				_, err := storage.Migrate(storage.State{
+					Version: 1,
+				})
+				Expect(err).To(MatchError("failed to migrate bbl-state.json from version 1: some migration error"))
+			})
+
+			It("returns an error when a migration does not bump the version", func() {
+				storage.SetMigrations(map[int]func(storage.State) (storage.State, error){
+					1: func(state storage.State) (storage.State, error) {
+						return state, nil
+					},
+				})
+
+				_, err := storage.Migrate(storage.State{
+					Version: 1,
+				})
+				Expect(err).To(MatchError("migration from version 1 of bbl-state.json did not produce version 2"))
+			})
+		})
+	})
+
+	Describe("MigrateV2ToV3", func() {
+		It("bumps the version", func() {
+			state, err := storage.MigrateV2ToV3(storage.State{
+				Version: 2,
+				EnvID:   "some-env-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(storage.State{
+				Version: 3,
+				EnvID:   "some-env-id",
+			}))
+		})
+	})
+
+	Describe("MigrateV1ToV2", func() {
+		It("defaults the iaas to aws", func() {
+			state, err := storage.MigrateV1ToV2(storage.State{
+				Version: 1,
+				EnvID:   "some-env-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(storage.State{
+				Version: 2,
+				IAAS:    "aws",
+				EnvID:   "some-env-id",
+			}))
+		})
+	})
+})
@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	goaws "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+type s3Client interface {
+	HeadBucket(*s3.HeadBucketInput) (*s3.HeadBucketOutput, error)
+	HeadObject(*s3.HeadObjectInput) (*s3.HeadObjectOutput, error)
+	GetObject(*s3.GetObjectInput) (*s3.GetObjectOutput, error)
+	PutObject(*s3.PutObjectInput) (*s3.PutObjectOutput, error)
+	PutObjectWithContext(goaws.Context, *s3.PutObjectInput, ...request.Option) (*s3.PutObjectOutput, error)
+	ListObjects(*s3.ListObjectsInput) (*s3.ListObjectsOutput, error)
+	CopyObject(*s3.CopyObjectInput) (*s3.CopyObjectOutput, error)
+	DeleteObject(*s3.DeleteObjectInput) (*s3.DeleteObjectOutput, error)
+}
+
+type S3Backend struct {
+	client s3Client
+	bucket string
+	prefix string
+}
+
+func NewS3Backend(client s3Client, bucket, prefix string) S3Backend {
+	return S3Backend{
+		client: client,
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+func (b S3Backend) Location() string {
+	return fmt.Sprintf("s3://%s", path.Join(b.bucket, b.prefix))
+}
+
+func (b S3Backend) Validate() error {
+	_, err := b.client.HeadBucket(&s3.HeadBucketInput{
+		Bucket: goaws.String(b.bucket),
+	})
+	if err != nil {
+		return fmt.Errorf("state backend %s is not reachable: %s", b.Location(), err)
+	}
+
+	return nil
+}
+
+func (b S3Backend) Exists(name string) (bool, error) {
+	_, err := b.client.HeadObject(&s3.HeadObjectInput{
+		Bucket: goaws.String(b.bucket),
+		Key:    goaws.String(b.key(name)),
+	})
+	switch {
+	case isS3NotFound(err):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (b S3Backend) Read(name string) ([]byte, error) {
+	output, err := b.client.GetObject(&s3.GetObjectInput{
+		Bucket: goaws.String(b.bucket),
+		Key:    goaws.String(b.key(name)),
+	})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, &os.PathError{Op: "read", Path: b.url(name), Err: os.ErrNotExist}
+		}
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	return ioutil.ReadAll(output.Body)
+}
+
+func (b S3Backend) Write(name string, data []byte) error {
+	_, err := b.client.PutObject(&s3.PutObjectInput{
+		Bucket: goaws.String(b.bucket),
+		Key:    goaws.String(b.key(name)),
+		Body:   bytes.NewReader(data),
+	})
+	return err
+}
+
+// Objects are only readable by the bucket owner unless the bucket policy
+// says otherwise, so private writes need nothing extra.
+func (b S3Backend) WritePrivate(name string, data []byte) error {
+	return b.Write(name, data)
+}
+
+// The put is conditional on the object not existing yet, so when two
+// writers race only one of them creates it.
+func (b S3Backend) Create(name string, data []byte) error {
+	_, err := b.client.PutObjectWithContext(goaws.BackgroundContext(), &s3.PutObjectInput{
+		Bucket: goaws.String(b.bucket),
+		Key:    goaws.String(b.key(name)),
+		Body:   bytes.NewReader(data),
+	}, ifNoneMatch)
+	if err != nil {
+		if isS3Conflict(err) {
+			return &os.PathError{Op: "create", Path: b.url(name), Err: os.ErrExist}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// List only returns the objects directly under the prefix of the backend,
+// like the local backend only reads the files in its directory.
+func (b S3Backend) List(prefix string) ([]string, error) {
+	input := &s3.ListObjectsInput{
+		Bucket:    goaws.String(b.bucket),
+		Prefix:    goaws.String(b.directory() + prefix),
+		Delimiter: goaws.String("/"),
+	}
+
+	names := []string{}
+	for {
+		output, err := b.client.ListObjects(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range output.Contents {
+			names = append(names, path.Base(goaws.StringValue(object.Key)))
+		}
+
+		if !goaws.BoolValue(output.IsTruncated) {
+			break
+		}
+
+		switch {
+		case output.NextMarker != nil:
+			input.Marker = output.NextMarker
+		case len(output.Contents) > 0:
+			input.Marker = output.Contents[len(output.Contents)-1].Key
+		default:
+			return names, nil
+		}
+	}
+
+	return names, nil
+}
+
+func (b S3Backend) Rename(oldName, newName string) error {
+	_, err := b.client.CopyObject(&s3.CopyObjectInput{
+		Bucket:     goaws.String(b.bucket),
+		Key:        goaws.String(b.key(newName)),
+		CopySource: goaws.String(url.QueryEscape(path.Join(b.bucket, b.key(oldName)))),
+	})
+	if err != nil {
+		return err
+	}
+
+	return b.Delete(oldName)
+}
+
+func (b S3Backend) Delete(name string) error {
+	_, err := b.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: goaws.String(b.bucket),
+		Key:    goaws.String(b.key(name)),
+	})
+	return err
+}
+
+func (b S3Backend) key(name string) string {
+	return path.Join(b.prefix, name)
+}
+
+func (b S3Backend) directory() string {
+	if b.prefix == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(b.prefix, "/") + "/"
+}
+
+func (b S3Backend) url(name string) string {
+	return fmt.Sprintf("s3://%s/%s", b.bucket, b.key(name))
+}
+
+func ifNoneMatch(r *request.Request) {
+	r.HTTPRequest.Header.Set("If-None-Match", "*")
+}
+
+func isS3Conflict(err error) bool {
+	requestFailure, ok := err.(awserr.RequestFailure)
+	if !ok {
+		return false
+	}
+
+	switch requestFailure.StatusCode() {
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		return true
+	}
+
+	return false
+}
+
+func isS3NotFound(err error) bool {
+	requestFailure, ok := err.(awserr.RequestFailure)
+	return ok && requestFailure.StatusCode() == http.StatusNotFound
+}
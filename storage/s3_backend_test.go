@@ -0,0 +1,302 @@
+package storage_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("S3Backend", func() {
+	var (
+		client   *fakes.S3Client
+		backend  storage.S3Backend
+		notFound error
+	)
+
+	BeforeEach(func() {
+		client = &fakes.S3Client{}
+		backend = storage.NewS3Backend(client, "some-bucket", "some/prefix")
+		notFound = awserr.NewRequestFailure(awserr.New("NotFound", "not found", nil), 404, "some-request-id")
+	})
+
+	Describe("Location", func() {
+		It("returns the s3 url of the backend", func() {
+			Expect(backend.Location()).To(Equal("s3://some-bucket/some/prefix"))
+		})
+	})
+
+	Describe("Validate", func() {
+		It("checks that the bucket is reachable", func() {
+			err := backend.Validate()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.HeadBucketCall.Receives.Input).To(Equal(&s3.HeadBucketInput{
+				Bucket: aws.String("some-bucket"),
+			}))
+		})
+
+		It("returns an error when the bucket is not reachable", func() {
+			client.HeadBucketCall.Returns.Error = errors.New("access denied")
+
+			err := backend.Validate()
+			Expect(err).To(MatchError("state backend s3://some-bucket/some/prefix is not reachable: access denied"))
+		})
+	})
+
+	Describe("Exists", func() {
+		It("returns true when the object exists", func() {
+			exists, err := backend.Exists("some-file")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+
+			Expect(client.HeadObjectCall.Receives.Input).To(Equal(&s3.HeadObjectInput{
+				Bucket: aws.String("some-bucket"),
+				Key:    aws.String("some/prefix/some-file"),
+			}))
+		})
+
+		It("returns false when the object is not found", func() {
+			client.HeadObjectCall.Returns.Error = notFound
+
+			exists, err := backend.Exists("some-file")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+		})
+
+		It("returns an error when the object cannot be checked", func() {
+			client.HeadObjectCall.Returns.Error = errors.New("failed to head object")
+
+			_, err := backend.Exists("some-file")
+			Expect(err).To(MatchError("failed to head object"))
+		})
+	})
+
+	Describe("Read", func() {
+		It("returns the contents of the object", func() {
+			client.GetObjectCall.Returns.Output = &s3.GetObjectOutput{
+				Body: ioutil.NopCloser(strings.NewReader("some-contents")),
+			}
+
+			contents, err := backend.Read("some-file")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-contents"))
+
+			Expect(client.GetObjectCall.Receives.Input).To(Equal(&s3.GetObjectInput{
+				Bucket: aws.String("some-bucket"),
+				Key:    aws.String("some/prefix/some-file"),
+			}))
+		})
+
+		It("returns a not exist error when the object is not found", func() {
+			client.GetObjectCall.Returns.Error = notFound
+
+			_, err := backend.Read("some-file")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("returns an error when the object cannot be read", func() {
+			client.GetObjectCall.Returns.Error = errors.New("failed to get object")
+
+			_, err := backend.Read("some-file")
+			Expect(err).To(MatchError("failed to get object"))
+		})
+	})
+
+	Describe("Write", func() {
+		It("puts the object", func() {
+			err := backend.Write("some-file", []byte("some-contents"))
+			Expect(err).NotTo(HaveOccurred())
+
+			input := client.PutObjectCall.Receives.Input
+			Expect(input.Bucket).To(Equal(aws.String("some-bucket")))
+			Expect(input.Key).To(Equal(aws.String("some/prefix/some-file")))
+
+			contents, err := ioutil.ReadAll(input.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-contents"))
+		})
+
+		It("returns an error when the object cannot be written", func() {
+			client.PutObjectCall.Returns.Error = errors.New("failed to put object")
+
+			err := backend.Write("some-file", []byte("some-contents"))
+			Expect(err).To(MatchError("failed to put object"))
+		})
+	})
+
+	Describe("Create", func() {
+		It("puts the object only if it does not exist yet", func() {
+			err := backend.Create("some-file", []byte("some-contents"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.PutObjectWithContextCall.CallCount).To(Equal(1))
+			Expect(client.PutObjectWithContextCall.Receives.Input.Bucket).To(Equal(aws.String("some-bucket")))
+			Expect(client.PutObjectWithContextCall.Receives.Input.Key).To(Equal(aws.String("some/prefix/some-file")))
+			Expect(client.PutObjectWithContextCall.Receives.Header.Get("If-None-Match")).To(Equal("*"))
+
+			body, err := ioutil.ReadAll(client.PutObjectWithContextCall.Receives.Input.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal("some-contents"))
+
+			Expect(client.HeadObjectCall.CallCount).To(Equal(0))
+			Expect(client.PutObjectCall.CallCount).To(Equal(0))
+		})
+
+		It("returns an exist error when the object already exists", func() {
+			client.PutObjectWithContextCall.Returns.Error = awserr.NewRequestFailure(awserr.New("PreconditionFailed", "precondition failed", nil), 412, "some-request-id")
+
+			err := backend.Create("some-file", []byte("some-contents"))
+			Expect(os.IsExist(err)).To(BeTrue())
+		})
+
+		It("returns an exist error when another write to the object is in progress", func() {
+			client.PutObjectWithContextCall.Returns.Error = awserr.NewRequestFailure(awserr.New("ConditionalRequestConflict", "conflict", nil), 409, "some-request-id")
+
+			err := backend.Create("some-file", []byte("some-contents"))
+			Expect(os.IsExist(err)).To(BeTrue())
+		})
+
+		It("returns an error when the object cannot be put", func() {
+			client.PutObjectWithContextCall.Returns.Error = errors.New("failed to put object")
+
+			err := backend.Create("some-file", []byte("some-contents"))
+			Expect(err).To(MatchError("failed to put object"))
+		})
+	})
+
+	Describe("List", func() {
+		It("returns the names of the objects with the given prefix across pages", func() {
+			client.ListObjectsCall.Returns.Outputs = []*s3.ListObjectsOutput{
+				{
+					IsTruncated: aws.Bool(true),
+					Contents: []*s3.Object{
+						{Key: aws.String("some/prefix/some-file-1")},
+					},
+				},
+				{
+					IsTruncated: aws.Bool(false),
+					Contents: []*s3.Object{
+						{Key: aws.String("some/prefix/some-file-2")},
+					},
+				},
+			}
+
+			names, err := backend.List("some-file-")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{"some-file-1", "some-file-2"}))
+
+			Expect(client.ListObjectsCall.Receives.Inputs).To(Equal([]*s3.ListObjectsInput{
+				{
+					Bucket:    aws.String("some-bucket"),
+					Prefix:    aws.String("some/prefix/some-file-"),
+					Delimiter: aws.String("/"),
+				},
+				{
+					Bucket:    aws.String("some-bucket"),
+					Prefix:    aws.String("some/prefix/some-file-"),
+					Delimiter: aws.String("/"),
+					Marker:    aws.String("some/prefix/some-file-1"),
+				},
+			}))
+		})
+
+		It("lists everything directly under the prefix when no name prefix is given", func() {
+			backend = storage.NewS3Backend(client, "some-bucket", "some/prefix/overrides")
+
+			_, err := backend.List("")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.ListObjectsCall.Receives.Inputs[0].Prefix).To(Equal(aws.String("some/prefix/overrides/")))
+		})
+
+		It("lists from the root of the bucket when there is no prefix", func() {
+			backend = storage.NewS3Backend(client, "some-bucket", "")
+
+			_, err := backend.List("some-file-")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.ListObjectsCall.Receives.Inputs[0].Prefix).To(Equal(aws.String("some-file-")))
+		})
+
+		It("continues from the next marker when a page has only common prefixes", func() {
+			client.ListObjectsCall.Returns.Outputs = []*s3.ListObjectsOutput{
+				{
+					IsTruncated: aws.Bool(true),
+					NextMarker:  aws.String("some/prefix/some-dir/"),
+				},
+				{
+					IsTruncated: aws.Bool(false),
+					Contents: []*s3.Object{
+						{Key: aws.String("some/prefix/some-file")},
+					},
+				},
+			}
+
+			names, err := backend.List("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{"some-file"}))
+			Expect(client.ListObjectsCall.Receives.Inputs[1].Marker).To(Equal(aws.String("some/prefix/some-dir/")))
+		})
+
+		It("returns an error when the objects cannot be listed", func() {
+			client.ListObjectsCall.Returns.Error = errors.New("failed to list objects")
+
+			_, err := backend.List("some-file-")
+			Expect(err).To(MatchError("failed to list objects"))
+		})
+	})
+
+	Describe("Rename", func() {
+		It("copies the object and deletes the original", func() {
+			err := backend.Rename("some-file", "some-other-file")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.CopyObjectCall.Receives.Input).To(Equal(&s3.CopyObjectInput{
+				Bucket:     aws.String("some-bucket"),
+				Key:        aws.String("some/prefix/some-other-file"),
+				CopySource: aws.String("some-bucket%2Fsome%2Fprefix%2Fsome-file"),
+			}))
+			Expect(client.DeleteObjectCall.Receives.Input).To(Equal(&s3.DeleteObjectInput{
+				Bucket: aws.String("some-bucket"),
+				Key:    aws.String("some/prefix/some-file"),
+			}))
+		})
+
+		It("returns an error when the object cannot be copied", func() {
+			client.CopyObjectCall.Returns.Error = errors.New("failed to copy object")
+
+			err := backend.Rename("some-file", "some-other-file")
+			Expect(err).To(MatchError("failed to copy object"))
+			Expect(client.DeleteObjectCall.CallCount).To(Equal(0))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("deletes the object", func() {
+			err := backend.Delete("some-file")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.DeleteObjectCall.Receives.Input).To(Equal(&s3.DeleteObjectInput{
+				Bucket: aws.String("some-bucket"),
+				Key:    aws.String("some/prefix/some-file"),
+			}))
+		})
+
+		It("returns an error when the object cannot be deleted", func() {
+			client.DeleteObjectCall.Returns.Error = errors.New("failed to delete object")
+
+			err := backend.Delete("some-file")
+			Expect(err).To(MatchError("failed to delete object"))
+		})
+	})
+})
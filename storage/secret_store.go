@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SecretReferencePrefix = "secret-store:"
+
+	directorUsernameSecret      = "directorUsername"
+	directorPasswordSecret      = "directorPassword"
+	directorSSLPrivateKeySecret = "directorSSLPrivateKey"
+	manifestSecret              = "manifest"
+	credentialsSecretsPrefix    = "credentials/"
+
+	secretStoreTimeout = 30 * time.Second
+)
+
+type SecretStore interface {
+	Location() string
+	Read() (map[string]string, error)
+	Write(secrets map[string]string) error
+}
+
+func NewSecretStore(secretStore, token string) (SecretStore, error) {
+	if secretStore == "" {
+		return nil, nil
+	}
+
+	storeURL, err := url.Parse(secretStore)
+	if err != nil {
+		return nil, err
+	}
+
+	if storeURL.Scheme != "http" && storeURL.Scheme != "https" {
+		return nil, fmt.Errorf("%q is an invalid secret store, the secret store must be provided as https://vault-address/mount/path", secretStore)
+	}
+
+	secretPath := strings.Trim(storeURL.Path, "/")
+	if !strings.Contains(secretPath, "/") {
+		return nil, fmt.Errorf("%q is missing a secret path, the secret store must be provided as https://vault-address/mount/path", secretStore)
+	}
+
+	kvVersion := 1
+	if version := storeURL.Query().Get("kv-version"); version != "" {
+		kvVersion, err = strconv.Atoi(version)
+		if err != nil || (kvVersion != 1 && kvVersion != 2) {
+			return nil, fmt.Errorf("%q has an invalid kv-version, supported values are: [1, 2]", secretStore)
+		}
+	}
+
+	if token == "" {
+		return nil, fmt.Errorf("a token is required to use the secret store at %s, set VAULT_TOKEN", secretStore)
+	}
+
+	address := fmt.Sprintf("%s://%s", storeURL.Scheme, storeURL.Host)
+
+	return NewVaultSecretStore(&http.Client{Timeout: secretStoreTimeout}, address, secretPath, token, kvVersion), nil
+}
+
+func IsSecretReference(value string) bool {
+	return strings.HasPrefix(value, SecretReferencePrefix)
+}
+
+func secretReference(name string) string {
+	return SecretReferencePrefix + name
+}
+
+// secretFields lists the fields of the state that are kept in the secret
+// store. The manifest is included because it embeds the director
+// credentials.
+func secretFields(state *State) map[string]*string {
+	return map[string]*string{
+		directorUsernameSecret:      &state.BOSH.DirectorUsername,
+		directorPasswordSecret:      &state.BOSH.DirectorPassword,
+		directorSSLPrivateKeySecret: &state.BOSH.DirectorSSLPrivateKey,
+		manifestSecret:              &state.BOSH.Manifest,
+	}
+}
+
+func ExternalizeSecrets(state State, secretStore SecretStore) (State, error) {
+	secrets := map[string]string{}
+
+	for name, value := range secretFields(&state) {
+		if *value != "" {
+			secrets[name] = *value
+		}
+	}
+
+	for name, value := range state.BOSH.Credentials {
+		secrets[credentialsSecretsPrefix+name] = value
+	}
+
+	if len(secrets) == 0 {
+		return state, nil
+	}
+
+	if hasPlainSecrets(secrets) {
+		stored, err := secretStore.Read()
+		if err != nil {
+			return state, err
+		}
+
+		changed := len(secrets) != len(stored)
+		for name, value := range secrets {
+			storedValue, ok := stored[name]
+			if IsSecretReference(value) {
+				if !ok {
+					return state, fmt.Errorf("secret store %s has no value for %q", secretStore.Location(), name)
+				}
+				secrets[name] = storedValue
+				continue
+			}
+
+			if !ok || storedValue != value {
+				changed = true
+			}
+		}
+
+		if changed {
+			err = secretStore.Write(secrets)
+			if err != nil {
+				return state, err
+			}
+		}
+	}
+
+	state.SecretStore = secretStore.Location()
+
+	for name, value := range secretFields(&state) {
+		if *value != "" {
+			*value = secretReference(name)
+		}
+	}
+
+	if len(state.BOSH.Credentials) > 0 {
+		credentials := map[string]string{}
+		for name := range state.BOSH.Credentials {
+			credentials[name] = secretReference(credentialsSecretsPrefix + name)
+		}
+		state.BOSH.Credentials = credentials
+	}
+
+	return state, nil
+}
+
+func ResolveSecrets(state State, secretStore SecretStore) (State, error) {
+	if !HasSecretReferences(state) {
+		return state, nil
+	}
+
+	if secretStore == nil {
+		return state, fmt.Errorf("bbl-state.json references secrets in %s, use --secret-store or BBL_SECRET_STORE to provide the secret store", state.SecretStore)
+	}
+
+	secrets, err := secretStore.Read()
+	if err != nil {
+		return state, err
+	}
+
+	resolve := func(value string) (string, error) {
+		if !IsSecretReference(value) {
+			return value, nil
+		}
+
+		name := strings.TrimPrefix(value, SecretReferencePrefix)
+		secret, ok := secrets[name]
+		if !ok {
+			return "", fmt.Errorf("secret store %s has no value for %q", secretStore.Location(), name)
+		}
+
+		return secret, nil
+	}
+
+	fields := secretFields(&state)
+	for _, name := range sortedFieldNames(fields) {
+		*fields[name], err = resolve(*fields[name])
+		if err != nil {
+			return state, err
+		}
+	}
+
+	if len(state.BOSH.Credentials) > 0 {
+		credentials := map[string]string{}
+		for _, name := range sortedKeys(state.BOSH.Credentials) {
+			credentials[name], err = resolve(state.BOSH.Credentials[name])
+			if err != nil {
+				return state, err
+			}
+		}
+		state.BOSH.Credentials = credentials
+	}
+
+	return state, nil
+}
+
+func HasSecretReferences(state State) bool {
+	for _, value := range secretFields(&state) {
+		if IsSecretReference(*value) {
+			return true
+		}
+	}
+
+	for _, value := range state.BOSH.Credentials {
+		if IsSecretReference(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasPlainSecrets(secrets map[string]string) bool {
+	for _, value := range secrets {
+		if !IsSecretReference(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedFieldNames(fields map[string]*string) []string {
+	names := []string{}
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
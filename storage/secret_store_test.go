@@ -0,0 +1,279 @@
+package storage_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SecretStore", func() {
+	Describe("NewSecretStore", func() {
+		It("returns no secret store when none is provided", func() {
+			secretStore, err := storage.NewSecretStore("", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secretStore).To(BeNil())
+		})
+
+		It("returns a vault secret store for http urls", func() {
+			secretStore, err := storage.NewSecretStore("https://vault.example.com:8200/secret/bbl/some-env", "some-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secretStore).To(BeAssignableToTypeOf(storage.VaultSecretStore{}))
+			Expect(secretStore.Location()).To(Equal("https://vault.example.com:8200/secret/bbl/some-env"))
+			Expect(storage.VaultSecretStoreTimeout(secretStore.(storage.VaultSecretStore))).To(Equal(30 * time.Second))
+		})
+
+		It("supports version 2 kv mounts", func() {
+			secretStore, err := storage.NewSecretStore("https://vault.example.com/secret/bbl?kv-version=2", "some-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secretStore.Location()).To(Equal("https://vault.example.com/secret/bbl?kv-version=2"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the scheme is not supported", func() {
+				_, err := storage.NewSecretStore("vault://some-vault/secret/bbl", "some-token")
+				Expect(err).To(MatchError(`"vault://some-vault/secret/bbl" is an invalid secret store, the secret store must be provided as https://vault-address/mount/path`))
+			})
+
+			It("returns an error when the secret path is missing", func() {
+				_, err := storage.NewSecretStore("https://some-vault/secret", "some-token")
+				Expect(err).To(MatchError(`"https://some-vault/secret" is missing a secret path, the secret store must be provided as https://vault-address/mount/path`))
+			})
+
+			It("returns an error when the kv version is not supported", func() {
+				_, err := storage.NewSecretStore("https://some-vault/secret/bbl?kv-version=3", "some-token")
+				Expect(err).To(MatchError(`"https://some-vault/secret/bbl?kv-version=3" has an invalid kv-version, supported values are: [1, 2]`))
+			})
+
+			It("returns an error when the token is missing", func() {
+				_, err := storage.NewSecretStore("https://some-vault/secret/bbl", "")
+				Expect(err).To(MatchError("a token is required to use the secret store at https://some-vault/secret/bbl, set VAULT_TOKEN"))
+			})
+		})
+	})
+
+	Describe("ExternalizeSecrets", func() {
+		var secretStore *fakes.SecretStore
+
+		BeforeEach(func() {
+			secretStore = &fakes.SecretStore{}
+			secretStore.LocationCall.Returns.Location = "https://some-vault/secret/bbl"
+		})
+
+		It("writes the director credentials, key and manifest to the secret store and replaces them with references", func() {
+			state, err := storage.ExternalizeSecrets(storage.State{
+				BOSH: storage.BOSH{
+					DirectorAddress:       "some-director-address",
+					DirectorUsername:      "some-director-username",
+					DirectorPassword:      "some-director-password",
+					DirectorSSLPrivateKey: "some-director-ssl-private-key",
+					Manifest:              "some-manifest-with-some-nats-password",
+					Credentials: map[string]string{
+						"natsPassword": "some-nats-password",
+					},
+				},
+			}, secretStore)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(secretStore.WriteCall.Receives.Secrets).To(Equal(map[string]string{
+				"directorUsername":         "some-director-username",
+				"directorPassword":         "some-director-password",
+				"directorSSLPrivateKey":    "some-director-ssl-private-key",
+				"manifest":                 "some-manifest-with-some-nats-password",
+				"credentials/natsPassword": "some-nats-password",
+			}))
+			Expect(state).To(Equal(storage.State{
+				SecretStore: "https://some-vault/secret/bbl",
+				BOSH: storage.BOSH{
+					DirectorAddress:       "some-director-address",
+					DirectorUsername:      "secret-store:directorUsername",
+					DirectorPassword:      "secret-store:directorPassword",
+					DirectorSSLPrivateKey: "secret-store:directorSSLPrivateKey",
+					Manifest:              "secret-store:manifest",
+					Credentials: map[string]string{
+						"natsPassword": "secret-store:credentials/natsPassword",
+					},
+				},
+			}))
+		})
+
+		It("does not write to the secret store when the stored secrets are unchanged", func() {
+			secretStore.ReadCall.Returns.Secrets = map[string]string{
+				"directorPassword": "some-director-password",
+				"manifest":         "some-manifest",
+			}
+
+			state, err := storage.ExternalizeSecrets(storage.State{
+				BOSH: storage.BOSH{
+					DirectorPassword: "some-director-password",
+					Manifest:         "some-manifest",
+				},
+			}, secretStore)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(secretStore.ReadCall.CallCount).To(Equal(1))
+			Expect(secretStore.WriteCall.CallCount).To(Equal(0))
+			Expect(state.BOSH.DirectorPassword).To(Equal("secret-store:directorPassword"))
+			Expect(state.BOSH.Manifest).To(Equal("secret-store:manifest"))
+		})
+
+		It("writes to the secret store when a stored secret is no longer in the state", func() {
+			secretStore.ReadCall.Returns.Secrets = map[string]string{
+				"directorPassword":         "some-director-password",
+				"credentials/natsPassword": "some-nats-password",
+			}
+
+			_, err := storage.ExternalizeSecrets(storage.State{
+				BOSH: storage.BOSH{
+					DirectorPassword: "some-director-password",
+				},
+			}, secretStore)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(secretStore.WriteCall.Receives.Secrets).To(Equal(map[string]string{
+				"directorPassword": "some-director-password",
+			}))
+		})
+
+		It("does not write to the secret store when there are no director credentials", func() {
+			state, err := storage.ExternalizeSecrets(storage.State{EnvID: "some-env-id"}, secretStore)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(secretStore.WriteCall.CallCount).To(Equal(0))
+			Expect(state).To(Equal(storage.State{EnvID: "some-env-id"}))
+		})
+
+		It("does not write to the secret store when the credentials are already references", func() {
+			_, err := storage.ExternalizeSecrets(storage.State{
+				BOSH: storage.BOSH{
+					DirectorPassword: "secret-store:directorPassword",
+				},
+			}, secretStore)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(secretStore.ReadCall.CallCount).To(Equal(0))
+			Expect(secretStore.WriteCall.CallCount).To(Equal(0))
+		})
+
+		It("keeps stored values for credentials that are already references", func() {
+			secretStore.ReadCall.Returns.Secrets = map[string]string{
+				"directorUsername": "some-director-username",
+			}
+
+			_, err := storage.ExternalizeSecrets(storage.State{
+				BOSH: storage.BOSH{
+					DirectorUsername: "secret-store:directorUsername",
+					DirectorPassword: "some-new-director-password",
+				},
+			}, secretStore)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(secretStore.WriteCall.Receives.Secrets).To(Equal(map[string]string{
+				"directorUsername": "some-director-username",
+				"directorPassword": "some-new-director-password",
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the secret store cannot be written", func() {
+				secretStore.WriteCall.Returns.Error = errors.New("failed to write secrets")
+
+				_, err := storage.ExternalizeSecrets(storage.State{
+					BOSH: storage.BOSH{DirectorPassword: "some-director-password"},
+				}, secretStore)
+				Expect(err).To(MatchError("failed to write secrets"))
+			})
+
+			It("returns an error when the secret store cannot be read", func() {
+				secretStore.ReadCall.Returns.Error = errors.New("failed to read secrets")
+
+				_, err := storage.ExternalizeSecrets(storage.State{
+					BOSH: storage.BOSH{DirectorPassword: "some-director-password"},
+				}, secretStore)
+				Expect(err).To(MatchError("failed to read secrets"))
+			})
+		})
+	})
+
+	Describe("ResolveSecrets", func() {
+		var secretStore *fakes.SecretStore
+
+		BeforeEach(func() {
+			secretStore = &fakes.SecretStore{}
+			secretStore.LocationCall.Returns.Location = "https://some-vault/secret/bbl"
+			secretStore.ReadCall.Returns.Secrets = map[string]string{
+				"directorUsername":         "some-director-username",
+				"directorPassword":         "some-director-password",
+				"directorSSLPrivateKey":    "some-director-ssl-private-key",
+				"manifest":                 "some-manifest",
+				"credentials/natsPassword": "some-nats-password",
+			}
+		})
+
+		It("replaces references with values from the secret store", func() {
+			state, err := storage.ResolveSecrets(storage.State{
+				SecretStore: "https://some-vault/secret/bbl",
+				BOSH: storage.BOSH{
+					DirectorUsername:      "secret-store:directorUsername",
+					DirectorPassword:      "secret-store:directorPassword",
+					DirectorSSLPrivateKey: "secret-store:directorSSLPrivateKey",
+					Manifest:              "secret-store:manifest",
+					Credentials: map[string]string{
+						"natsPassword": "secret-store:credentials/natsPassword",
+					},
+				},
+			}, secretStore)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(state.BOSH).To(Equal(storage.BOSH{
+				DirectorUsername:      "some-director-username",
+				DirectorPassword:      "some-director-password",
+				DirectorSSLPrivateKey: "some-director-ssl-private-key",
+				Manifest:              "some-manifest",
+				Credentials: map[string]string{
+					"natsPassword": "some-nats-password",
+				},
+			}))
+		})
+
+		It("does not read the secret store when there are no references", func() {
+			state, err := storage.ResolveSecrets(storage.State{
+				BOSH: storage.BOSH{DirectorPassword: "some-director-password"},
+			}, secretStore)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(secretStore.ReadCall.CallCount).To(Equal(0))
+			Expect(state.BOSH.DirectorPassword).To(Equal("some-director-password"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when there is no secret store", func() {
+				_, err := storage.ResolveSecrets(storage.State{
+					SecretStore: "https://some-vault/secret/bbl",
+					BOSH:        storage.BOSH{DirectorPassword: "secret-store:directorPassword"},
+				}, nil)
+				Expect(err).To(MatchError("bbl-state.json references secrets in https://some-vault/secret/bbl, use --secret-store or BBL_SECRET_STORE to provide the secret store"))
+			})
+
+			It("returns an error when the secret store cannot be read", func() {
+				secretStore.ReadCall.Returns.Error = errors.New("failed to read secrets")
+
+				_, err := storage.ResolveSecrets(storage.State{
+					BOSH: storage.BOSH{DirectorPassword: "secret-store:directorPassword"},
+				}, secretStore)
+				Expect(err).To(MatchError("failed to read secrets"))
+			})
+
+			It("returns an error when a referenced secret is missing", func() {
+				_, err := storage.ResolveSecrets(storage.State{
+					BOSH: storage.BOSH{DirectorPassword: "secret-store:someMissingSecret"},
+				}, secretStore)
+				Expect(err).To(MatchError(`secret store https://some-vault/secret/bbl has no value for "someMissingSecret"`))
+			})
+		})
+	})
+})
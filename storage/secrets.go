@@ -0,0 +1,94 @@
+package storage
+
+import "os"
+
+const (
+	OS_PRIVATE_READ_WRITE_MODE = os.FileMode(0600)
+	SecretsFileName            = "bbl-secrets.json"
+)
+
+type AWSSecrets struct {
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+}
+
+type GCPSecrets struct {
+	ServiceAccountKey string `json:"serviceAccountKey"`
+}
+
+type KeyPairSecrets struct {
+	PrivateKey string `json:"privateKey"`
+}
+
+type BOSHSecrets struct {
+	DirectorPassword      string            `json:"directorPassword"`
+	DirectorSSLPrivateKey string            `json:"directorSSLPrivateKey"`
+	Credentials           map[string]string `json:"credentials"`
+	Manifest              string            `json:"manifest"`
+}
+
+type LBSecrets struct {
+	Key string `json:"key"`
+}
+
+type Secrets struct {
+	AWS     AWSSecrets     `json:"aws"`
+	GCP     GCPSecrets     `json:"gcp"`
+	KeyPair KeyPairSecrets `json:"keyPair"`
+	BOSH    BOSHSecrets    `json:"bosh"`
+	LB      LBSecrets      `json:"lb"`
+	TFState string         `json:"tfState"`
+}
+
+func splitSecrets(state State) (State, Secrets) {
+	secrets := Secrets{
+		AWS: AWSSecrets{
+			AccessKeyID:     state.AWS.AccessKeyID,
+			SecretAccessKey: state.AWS.SecretAccessKey,
+		},
+		GCP: GCPSecrets{
+			ServiceAccountKey: state.GCP.ServiceAccountKey,
+		},
+		KeyPair: KeyPairSecrets{
+			PrivateKey: state.KeyPair.PrivateKey,
+		},
+		BOSH: BOSHSecrets{
+			DirectorPassword:      state.BOSH.DirectorPassword,
+			DirectorSSLPrivateKey: state.BOSH.DirectorSSLPrivateKey,
+			Credentials:           state.BOSH.Credentials,
+			Manifest:              state.BOSH.Manifest,
+		},
+		LB: LBSecrets{
+			Key: state.LB.Key,
+		},
+		TFState: state.TFState,
+	}
+
+	state.AWS.AccessKeyID = ""
+	state.AWS.SecretAccessKey = ""
+	state.GCP.ServiceAccountKey = ""
+	state.KeyPair.PrivateKey = ""
+	state.BOSH.DirectorPassword = ""
+	state.BOSH.DirectorSSLPrivateKey = ""
+	state.BOSH.Credentials = nil
+	state.BOSH.Manifest = ""
+	state.LB.Key = ""
+	state.TFState = ""
+
+	return state, secrets
+}
+
+func mergeSecrets(state State, secrets Secrets) State {
+	state.AWS.AccessKeyID = secrets.AWS.AccessKeyID
+	state.AWS.SecretAccessKey = secrets.AWS.SecretAccessKey
+	state.GCP.ServiceAccountKey = secrets.GCP.ServiceAccountKey
+	state.KeyPair.PrivateKey = secrets.KeyPair.PrivateKey
+	state.BOSH.DirectorPassword = secrets.BOSH.DirectorPassword
+	state.BOSH.DirectorSSLPrivateKey = secrets.BOSH.DirectorSSLPrivateKey
+	state.BOSH.Credentials = secrets.BOSH.Credentials
+	state.BOSH.Manifest = secrets.BOSH.Manifest
+	state.LB.Key = secrets.LB.Key
+	state.TFState = secrets.TFState
+
+	return state
+}
@@ -1,17 +1,17 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/json"
-	"errors"
 	"io"
 	"os"
-	"path/filepath"
 	"reflect"
 )
 
 const (
-	OS_READ_WRITE_MODE = os.FileMode(0644)
-	StateFileName      = "bbl-state.json"
+	OS_READ_WRITE_MODE  = os.FileMode(0644)
+	StateFileName       = "bbl-state.json"
+	LegacyStateFileName = "state.json"
 )
 
 type logger interface {
@@ -26,6 +26,9 @@ var (
 type AWS struct {
 	AccessKeyID     string `json:"accessKeyId"`
 	SecretAccessKey string `json:"secretAccessKey"`
+	Profile         string `json:"profile,omitempty"`
+	AssumeRoleARN   string `json:"assumeRoleARN,omitempty"`
+	ExternalID      string `json:"externalID,omitempty"`
 	Region          string `json:"region"`
 }
 
@@ -37,9 +40,12 @@ type GCP struct {
 }
 
 type Stack struct {
-	Name            string `json:"name"`
-	LBType          string `json:"lbType"`
-	CertificateName string `json:"certificateName"`
+	Name            string          `json:"name"`
+	LBType          string          `json:"lbType"`
+	CertificateName string          `json:"certificateName"`
+	NATType         string          `json:"natType,omitempty"`
+	NATAMI          string          `json:"natAMI,omitempty"`
+	TemplatePatches []TemplatePatch `json:"templatePatches,omitempty"`
 }
 
 type LB struct {
@@ -49,6 +55,41 @@ type LB struct {
 	Domain string `json:"domain,omitempty"`
 }
 
+type Checkpoint struct {
+	Phase     string `json:"phase"`
+	InputHash string `json:"inputHash"`
+}
+
+type TemplatePatch struct {
+	Name     string `json:"name"`
+	Contents string `json:"contents"`
+}
+
+type VersionOverride struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	SHA1 string `json:"sha1"`
+}
+
+type OpsFile struct {
+	Name     string `json:"name"`
+	Contents string `json:"contents"`
+}
+
+type DirectorSizing struct {
+	InstanceType         string `json:"instanceType,omitempty"`
+	EphemeralDiskSizeGB  int    `json:"ephemeralDiskSizeGB,omitempty"`
+	EphemeralDiskType    string `json:"ephemeralDiskType,omitempty"`
+	PersistentDiskSizeGB int    `json:"persistentDiskSizeGB,omitempty"`
+	PersistentDiskType   string `json:"persistentDiskType,omitempty"`
+}
+
+type Network struct {
+	CIDR       string `json:"cidr,omitempty"`
+	DirectorIP string `json:"directorIP,omitempty"`
+	NATIP      string `json:"natIP,omitempty"`
+}
+
 type State struct {
 	Version int     `json:"version"`
 	IAAS    string  `json:"iaas"`
@@ -60,47 +101,102 @@ type State struct {
 	EnvID   string  `json:"envID"`
 	TFState string  `json:"tfState"`
 	LB      LB      `json:"lb"`
+
+	NoDirector       bool              `json:"noDirector,omitempty"`
+	Outputs          map[string]string `json:"outputs,omitempty"`
+	OpsFiles         []OpsFile         `json:"opsFiles,omitempty"`
+	VersionOverrides []VersionOverride `json:"versionOverrides,omitempty"`
+	AirGapped        bool              `json:"airGapped,omitempty"`
+	DirectorSizing   *DirectorSizing   `json:"directorSizing,omitempty"`
+	Network          *Network          `json:"network,omitempty"`
+	Checkpoints      []Checkpoint      `json:"checkpoints,omitempty"`
+	SecretStore      string            `json:"secretStore,omitempty"`
+
+	// TerraformOverrides is only set while exporting or importing a bundle, the
+	// overrides themselves live in the overrides directory under the state dir.
+	TerraformOverrides map[string]string `json:"-"`
 }
 
 type Store struct {
-	version   int
-	stateFile string
+	backend       Backend
+	encryptionKey string
+	secretStore   SecretStore
+	backupLimit   int
+	backedUp      *bool
 }
 
-func NewStore(dir string) Store {
+func NewStore(backend Backend, encryptionKey string, secretStore SecretStore, backupLimit int) Store {
 	return Store{
-		version:   2,
-		stateFile: filepath.Join(dir, StateFileName),
+		backend:       backend,
+		encryptionKey: encryptionKey,
+		secretStore:   secretStore,
+		backupLimit:   backupLimit,
+		backedUp:      new(bool),
+	}
+}
+
+func (s Store) Get() (State, error) {
+	state, err := GetState(s.backend, s.encryptionKey)
+	if err != nil {
+		return state, err
 	}
+
+	return ResolveSecrets(state, s.secretStore)
 }
 
 func (s Store) Set(state State) error {
-	_, err := os.Stat(filepath.Dir(s.stateFile))
+	err := s.backend.Validate()
 	if err != nil {
 		return err
 	}
 
 	if reflect.DeepEqual(state, State{}) {
-		err := os.Remove(s.stateFile)
-		if err != nil && !os.IsNotExist(err) {
-			return err
+		for _, name := range []string{SecretsFileName, StateFileName} {
+			err := s.backend.Delete(name)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
 		}
 
 		return nil
 	}
 
-	file, err := os.OpenFile(s.stateFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, OS_READ_WRITE_MODE)
+	state.Version = CurrentStateVersion
+
+	if s.secretStore != nil {
+		state, err = ExternalizeSecrets(state, s.secretStore)
+		if err != nil {
+			return err
+		}
+	}
+
+	state, secrets := splitSecrets(state)
+
+	stateData, err := s.marshal(state)
 	if err != nil {
 		return err
 	}
 
-	state.Version = s.version
-	err = encode(file, state)
+	secretsData, err := s.marshal(secrets)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return s.write(stateData, secretsData)
+}
+
+func (s Store) marshal(v interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := encode(&buffer, v)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.encryptionKey == "" {
+		return buffer.Bytes(), nil
+	}
+
+	return encrypt(buffer.Bytes(), s.encryptionKey)
 }
 
 func (g GCP) Empty() bool {
@@ -109,92 +205,89 @@ func (g GCP) Empty() bool {
 
 var GetStateLogger logger
 
-func GetState(dir string) (State, error) {
-	state := State{}
-
-	_, err := os.Stat(dir)
+// GetState only reads, older state is migrated in memory. Migrate writes
+// the migrated state back and should only run while holding the lock.
+func GetState(backend Backend, encryptionKey string) (State, error) {
+	state, err := readState(backend, encryptionKey)
 	if err != nil {
 		return state, err
 	}
 
-	bothExist, err := stateAndBBLStateExist(dir)
+	return migrate(state)
+}
+
+func (s Store) Migrate() error {
+	err := migrateStateFileName(s.backend)
 	if err != nil {
-		return state, err
+		return err
+	}
+
+	state, err := readState(s.backend, s.encryptionKey)
+	if err != nil {
+		return err
 	}
 
-	if bothExist {
-		return state, errors.New("Cannot proceed with state.json and bbl-state.json present. Please delete one of the files.")
+	migratedState, err := migrate(state)
+	if err != nil {
+		return err
+	}
+
+	if migratedState.Version == state.Version {
+		return nil
 	}
 
-	err = renameStateToBBLState(dir)
+	store := s
+	store.secretStore = nil
+	return store.Set(migratedState)
+}
+
+func readState(backend Backend, encryptionKey string) (State, error) {
+	state := State{}
+
+	err := backend.Validate()
 	if err != nil {
 		return state, err
 	}
 
-	file, err := os.Open(filepath.Join(dir, StateFileName))
+	name, err := stateFileName(backend)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return state, nil
-		}
 		return state, err
 	}
 
-	err = json.NewDecoder(file).Decode(&state)
+	err = unmarshal(backend, name, encryptionKey, &state)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
 		return state, err
 	}
 
-	if state.Version == 1 {
-		state = migrateV1ToV2(state)
+	var secrets Secrets
+	err = unmarshal(backend, SecretsFileName, encryptionKey, &secrets)
+	switch {
+	case err == nil:
+		state = mergeSecrets(state, secrets)
+	case !os.IsNotExist(err):
+		return state, err
 	}
 
 	return state, nil
 }
 
-func migrateV1ToV2(state State) State {
-	state.Version = 2
-	state.IAAS = "aws"
-	return state
-}
+func unmarshal(backend Backend, name string, encryptionKey string, v interface{}) error {
+	data, err := backend.Read(name)
+	if err != nil {
+		return err
+	}
 
-func renameStateToBBLState(dir string) error {
-	stateFile := filepath.Join(dir, "state.json")
-	_, err := os.Stat(stateFile)
-	switch {
-	case os.IsNotExist(err):
-		return nil
-	case err == nil:
-		GetStateLogger.Println("renaming state.json to bbl-state.json")
-		err := rename(stateFile, filepath.Join(dir, StateFileName))
+	if isEncrypted(data) {
+		data, err = decrypt(data, encryptionKey)
 		if err != nil {
 			return err
 		}
-		return nil
-	default:
-		return err
-	}
-}
-
-func stateAndBBLStateExist(dir string) (bool, error) {
-	stateFile := filepath.Join(dir, "state.json")
-	_, err := os.Stat(stateFile)
-	switch {
-	case os.IsNotExist(err):
-		return false, nil
-	case err != nil:
-		return false, err
-	}
-
-	bblStateFile := filepath.Join(dir, StateFileName)
-	_, err = os.Stat(bblStateFile)
-	switch {
-	case os.IsNotExist(err):
-		return false, nil
-	case err != nil:
-		return false, err
 	}
-	return true, nil
 
+	return json.Unmarshal(data, v)
 }
 
 func encodeFile(w io.Writer, v interface{}) error {
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	StateBackupPrefix   = StateFileName + ".backup-"
+	SecretsBackupPrefix = SecretsFileName + ".backup-"
+
+	DefaultStateBackups = 10
+
+	stateBackupTimeFormat = "20060102T150405.000000000Z"
+)
+
+type StateBackup struct {
+	Name      string
+	Timestamp time.Time
+}
+
+type stateBackupsByTimestamp []StateBackup
+
+func (b stateBackupsByTimestamp) Len() int           { return len(b) }
+func (b stateBackupsByTimestamp) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b stateBackupsByTimestamp) Less(i, j int) bool { return b[i].Timestamp.After(b[j].Timestamp) }
+
+func (s Store) Backups() ([]StateBackup, error) {
+	names, err := s.backend.List(StateBackupPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []StateBackup{}
+	for _, name := range names {
+		timestamp, err := time.Parse(stateBackupTimeFormat, strings.TrimPrefix(name, StateBackupPrefix))
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, StateBackup{
+			Name:      name,
+			Timestamp: timestamp,
+		})
+	}
+
+	sort.Sort(stateBackupsByTimestamp(backups))
+
+	return backups, nil
+}
+
+func (s Store) Restore(n int) (StateBackup, error) {
+	backups, err := s.Backups()
+	if err != nil {
+		return StateBackup{}, err
+	}
+
+	if n < 1 || n > len(backups) {
+		return StateBackup{}, fmt.Errorf("State backup %d does not exist, there are %d state backups", n, len(backups))
+	}
+
+	backup := backups[n-1]
+	stateData, err := s.backend.Read(backup.Name)
+	if err != nil {
+		return StateBackup{}, err
+	}
+
+	secretsData, err := s.backend.Read(secretsBackupName(backup))
+	if err != nil && !os.IsNotExist(err) {
+		return StateBackup{}, err
+	}
+
+	err = s.write(stateData, secretsData)
+	if err != nil {
+		return StateBackup{}, err
+	}
+
+	return backup, nil
+}
+
+func (s Store) write(stateData, secretsData []byte) error {
+	err := s.backup()
+	if err != nil {
+		return err
+	}
+
+	if secretsData == nil {
+		err = s.backend.Delete(SecretsFileName)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else {
+		err = s.backend.WritePrivate(SecretsFileName, secretsData)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.backend.Write(StateFileName, stateData)
+	if err != nil {
+		return err
+	}
+
+	return s.pruneBackups()
+}
+
+// Only the first write of a bbl invocation is backed up, so that each backup
+// is the state from before a command rather than from one of its steps.
+func (s Store) backup() error {
+	if *s.backedUp {
+		return nil
+	}
+
+	timestamp := now().UTC().Format(stateBackupTimeFormat)
+
+	for _, name := range []string{SecretsFileName, StateFileName} {
+		current, err := s.backend.Read(name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		err = s.backend.WritePrivate(name+".backup-"+timestamp, current)
+		if err != nil {
+			return err
+		}
+	}
+
+	*s.backedUp = true
+	return nil
+}
+
+func (s Store) pruneBackups() error {
+	backups, err := s.Backups()
+	if err != nil {
+		return err
+	}
+
+	if len(backups) <= s.backupLimit {
+		return nil
+	}
+
+	for _, backup := range backups[s.backupLimit:] {
+		for _, name := range []string{secretsBackupName(backup), backup.Name} {
+			err := s.backend.Delete(name)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func secretsBackupName(backup StateBackup) string {
+	return SecretsBackupPrefix + strings.TrimPrefix(backup.Name, StateBackupPrefix)
+}
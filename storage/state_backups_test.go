@@ -0,0 +1,273 @@
+package storage_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("State backups", func() {
+	var (
+		store       storage.Store
+		tempDir     string
+		currentTime time.Time
+	)
+
+	// Each call is a separate bbl invocation with its own store.
+	setState := func(envID string) {
+		currentTime = currentTime.Add(time.Minute)
+
+		store = storage.NewStore(storage.NewLocalBackend(tempDir), "", nil, storage.DefaultStateBackups)
+		err := store.Set(storage.State{
+			EnvID: envID,
+		})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		store = storage.NewStore(storage.NewLocalBackend(tempDir), "", nil, storage.DefaultStateBackups)
+
+		currentTime = time.Date(2016, time.November, 1, 12, 0, 0, 0, time.UTC)
+		storage.SetNow(func() time.Time {
+			return currentTime
+		})
+	})
+
+	AfterEach(func() {
+		storage.ResetNow()
+		storage.ResetRename()
+	})
+
+	Describe("Set", func() {
+		It("backs up the previous state before writing", func() {
+			setState("some-env-id")
+			setState("some-other-env-id")
+
+			backup, err := ioutil.ReadFile(filepath.Join(tempDir, "bbl-state.json.backup-20161101T120200.000000000Z"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(backup)).To(ContainSubstring(`"envID":"some-env-id"`))
+
+			state, err := store.Get()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.EnvID).To(Equal("some-other-env-id"))
+		})
+
+		It("backs up the previous secrets with private permissions", func() {
+			currentTime = currentTime.Add(time.Minute)
+			err := store.Set(storage.State{
+				KeyPair: storage.KeyPair{
+					PrivateKey: "some-private-key",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			setState("some-env-id")
+
+			for _, name := range []string{"bbl-state.json.backup-20161101T120200.000000000Z", "bbl-secrets.json.backup-20161101T120200.000000000Z"} {
+				fileInfo, err := os.Stat(filepath.Join(tempDir, name))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fileInfo.Mode()).To(Equal(os.FileMode(0600)))
+			}
+
+			backup, err := ioutil.ReadFile(filepath.Join(tempDir, "bbl-secrets.json.backup-20161101T120200.000000000Z"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(backup)).To(ContainSubstring("some-private-key"))
+		})
+
+		It("backs up only the state from before the first write of an invocation", func() {
+			setState("some-env-id")
+
+			store = storage.NewStore(storage.NewLocalBackend(tempDir), "", nil, storage.DefaultStateBackups)
+			for _, envID := range []string{"some-other-env-id", "some-final-env-id"} {
+				currentTime = currentTime.Add(time.Minute)
+				err := store.Set(storage.State{
+					EnvID: envID,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			backups, err := store.Backups()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backups).To(HaveLen(1))
+
+			backup, err := ioutil.ReadFile(filepath.Join(tempDir, backups[0].Name))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(backup)).To(ContainSubstring(`"envID":"some-env-id"`))
+		})
+
+		It("does not back up when there is no previous state", func() {
+			setState("some-env-id")
+
+			backups, err := store.Backups()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backups).To(BeEmpty())
+		})
+
+		It("keeps only the ten most recent backups", func() {
+			for i := 0; i < 15; i++ {
+				setState("some-env-id")
+			}
+
+			backups, err := store.Backups()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backups).To(HaveLen(10))
+			Expect(backups[0].Timestamp).To(Equal(currentTime))
+			Expect(backups[9].Timestamp).To(Equal(currentTime.Add(-9 * time.Minute)))
+		})
+
+		It("keeps only the configured number of backups", func() {
+			for i := 0; i < 5; i++ {
+				setState("some-env-id")
+			}
+
+			currentTime = currentTime.Add(time.Minute)
+			store = storage.NewStore(storage.NewLocalBackend(tempDir), "", nil, 2)
+			err := store.Set(storage.State{
+				EnvID: "some-env-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			backups, err := store.Backups()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backups).To(HaveLen(2))
+			Expect(backups[0].Timestamp).To(Equal(currentTime))
+		})
+
+		It("does not leave a partially written state behind when the write fails", func() {
+			setState("some-env-id")
+
+			storage.SetRename(func(string, string) error {
+				return errors.New("failed to rename")
+			})
+
+			err := store.Set(storage.State{
+				EnvID: "some-other-env-id",
+			})
+			Expect(err).To(MatchError("failed to rename"))
+
+			storage.ResetRename()
+
+			state, err := store.Get()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.EnvID).To(Equal("some-env-id"))
+
+			files, err := ioutil.ReadDir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+			for _, file := range files {
+				Expect(file.Name()).NotTo(HavePrefix("."))
+			}
+		})
+	})
+
+	Describe("Backups", func() {
+		It("returns the backups from newest to oldest", func() {
+			setState("some-env-id-1")
+			setState("some-env-id-2")
+			setState("some-env-id-3")
+
+			backups, err := store.Backups()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backups).To(Equal([]storage.StateBackup{
+				{
+					Name:      "bbl-state.json.backup-20161101T120300.000000000Z",
+					Timestamp: time.Date(2016, time.November, 1, 12, 3, 0, 0, time.UTC),
+				},
+				{
+					Name:      "bbl-state.json.backup-20161101T120200.000000000Z",
+					Timestamp: time.Date(2016, time.November, 1, 12, 2, 0, 0, time.UTC),
+				},
+			}))
+		})
+
+		It("ignores files that are not backups", func() {
+			err := ioutil.WriteFile(filepath.Join(tempDir, "bbl-state.json.backup-something"), []byte{}, os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			backups, err := store.Backups()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backups).To(BeEmpty())
+		})
+
+		It("returns an error when the backups cannot be listed", func() {
+			store = storage.NewStore(storage.NewLocalBackend("some-fake-directory"), "", nil, storage.DefaultStateBackups)
+
+			_, err := store.Backups()
+			Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+		})
+	})
+
+	Describe("Restore", func() {
+		BeforeEach(func() {
+			setState("some-env-id-1")
+			setState("some-env-id-2")
+			setState("some-env-id-3")
+
+			store = storage.NewStore(storage.NewLocalBackend(tempDir), "", nil, storage.DefaultStateBackups)
+		})
+
+		It("restores the nth most recent backup", func() {
+			backup, err := store.Restore(2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backup.Timestamp).To(Equal(time.Date(2016, time.November, 1, 12, 2, 0, 0, time.UTC)))
+
+			state, err := store.Get()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.EnvID).To(Equal("some-env-id-1"))
+		})
+
+		It("backs up the state it replaces", func() {
+			currentTime = currentTime.Add(time.Minute)
+
+			_, err := store.Restore(2)
+			Expect(err).NotTo(HaveOccurred())
+
+			backups, err := store.Backups()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backups).To(HaveLen(3))
+
+			backup, err := ioutil.ReadFile(filepath.Join(tempDir, backups[0].Name))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(backup)).To(ContainSubstring(`"envID":"some-env-id-3"`))
+		})
+
+		It("restores a backup that has the secrets inline", func() {
+			err := ioutil.WriteFile(filepath.Join(tempDir, "bbl-state.json.backup-20161101T110000.000000000Z"), []byte(`{
+				"version": 2,
+				"envID": "some-old-env-id",
+				"keyPair": {
+					"privateKey": "some-old-private-key"
+				}
+			}`), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = store.Restore(3)
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err := store.Get()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.EnvID).To(Equal("some-old-env-id"))
+			Expect(state.KeyPair.PrivateKey).To(Equal("some-old-private-key"))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when the backup does not exist", func() {
+				_, err := store.Restore(3)
+				Expect(err).To(MatchError("State backup 3 does not exist, there are 2 state backups"))
+
+				_, err = store.Restore(0)
+				Expect(err).To(MatchError("State backup 0 does not exist, there are 2 state backups"))
+			})
+		})
+	})
+})
@@ -26,7 +26,7 @@ var _ = Describe("Store", func() {
 		var err error
 		tempDir, err = ioutil.TempDir("", "")
 
-		store = storage.NewStore(tempDir)
+		store = storage.NewStore(storage.NewLocalBackend(tempDir), "", nil, storage.DefaultStateBackups)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -101,36 +101,74 @@ var _ = Describe("Store", func() {
 			data, err := ioutil.ReadFile(filepath.Join(tempDir, "bbl-state.json"))
 			Expect(err).NotTo(HaveOccurred())
 			Expect(data).To(MatchJSON(`{
-				"version": 2,
+				"version": 3,
 				"iaas": "aws",
 				"aws": {
-					"accessKeyId": "some-aws-access-key-id",
-					"secretAccessKey": "some-aws-secret-access-key",
+					"accessKeyId": "",
+					"secretAccessKey": "",
 					"region": "some-region"
 				},
 				"gcp": {
-					"serviceAccountKey": "some-service-account-key",
+					"serviceAccountKey": "",
 					"projectID": "some-project-id",
 					"zone": "some-zone",
 					"region": "some-region"
 				},
 				"keyPair": {
 					"name": "some-name",
-					"privateKey": "some-private",
+					"privateKey": "",
 					"publicKey": "some-public"
 				},
 				"lb": {
 					"type": "some-type",
 					"cert": "some-cert",
-					"key": "some-key"
+					"key": ""
 				},
 				"bosh":{
 					"directorName": "some-director-name",
 					"directorUsername": "some-director-username",
-					"directorPassword": "some-director-password",
+					"directorPassword": "",
 					"directorAddress": "some-director-address",
 					"directorSSLCA": "some-bosh-ssl-ca",
 					"directorSSLCertificate": "some-bosh-ssl-certificate",
+					"directorSSLPrivateKey": "",
+					"credentials": null,
+					"manifest": "",
+					"state": {
+						"key": "value"
+					}
+				},
+				"stack": {
+					"name": "some-stack-name",
+					"lbType": "some-lb-type",
+					"certificateName": "some-certificate-name"
+				},
+				"envID": "some-env-id",
+				"tfState": ""
+			}`))
+
+			fileInfo, err := os.Stat(filepath.Join(tempDir, "bbl-state.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fileInfo.Mode()).To(Equal(os.FileMode(0644)))
+
+			data, err = ioutil.ReadFile(filepath.Join(tempDir, "bbl-secrets.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{
+				"aws": {
+					"accessKeyId": "some-aws-access-key-id",
+					"secretAccessKey": "some-aws-secret-access-key"
+				},
+				"gcp": {
+					"serviceAccountKey": "some-service-account-key"
+				},
+				"keyPair": {
+					"privateKey": "some-private"
+				},
+				"lb": {
+					"key": "some-key"
+				},
+				"bosh":{
+					"directorPassword": "some-director-password",
 					"directorSSLPrivateKey": "some-bosh-ssl-private-key",
 					"credentials": {
 						"mbusUsername": "some-mbus-username",
@@ -148,35 +186,60 @@ var _ = Describe("Store", func() {
 						"blobstoreAgentPassword": "some-blobstore-agent-password",
 						"hmPassword": "some-hm-password"
 					},
-					"manifest": "name: bosh",
-					"state": {
-						"key": "value"
-					}
+					"manifest": "name: bosh"
 				},
-				"stack": {
-					"name": "some-stack-name",
-					"lbType": "some-lb-type",
-					"certificateName": "some-certificate-name"
-				},
-				"envID": "some-env-id",
 				"tfState": "some-tf-state"
 			}`))
 
-			fileInfo, err := os.Stat(filepath.Join(tempDir, "bbl-state.json"))
+			fileInfo, err = os.Stat(filepath.Join(tempDir, "bbl-secrets.json"))
 			Expect(err).NotTo(HaveOccurred())
-			Expect(fileInfo.Mode()).To(Equal(os.FileMode(0644)))
+			Expect(fileInfo.Mode()).To(Equal(os.FileMode(0600)))
+		})
+
+		It("reads back the state combined with its secrets", func() {
+			state := storage.State{
+				IAAS: "gcp",
+				GCP: storage.GCP{
+					ServiceAccountKey: "some-service-account-key",
+					ProjectID:         "some-project-id",
+				},
+				KeyPair: storage.KeyPair{
+					Name:       "some-name",
+					PrivateKey: "some-private",
+				},
+				BOSH: storage.BOSH{
+					DirectorAddress:  "some-director-address",
+					DirectorPassword: "some-director-password",
+					Credentials: map[string]string{
+						"mbusPassword": "some-mbus-password",
+					},
+				},
+				TFState: "some-tf-state",
+			}
+
+			err := store.Set(state)
+			Expect(err).NotTo(HaveOccurred())
+
+			state.Version = 3
+			Expect(store.Get()).To(Equal(state))
 		})
 
 		Context("when the state is empty", func() {
-			It("removes the bbl-state.json file", func() {
+			It("removes the bbl-state.json and bbl-secrets.json files", func() {
 				err := ioutil.WriteFile(filepath.Join(tempDir, "bbl-state.json"), []byte("{}"), os.ModePerm)
 				Expect(err).NotTo(HaveOccurred())
 
+				err = ioutil.WriteFile(filepath.Join(tempDir, "bbl-secrets.json"), []byte("{}"), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+
 				err = store.Set(storage.State{})
 				Expect(err).NotTo(HaveOccurred())
 
 				_, err = os.Stat(filepath.Join(tempDir, "bbl-state.json"))
 				Expect(os.IsNotExist(err)).To(BeTrue())
+
+				_, err = os.Stat(filepath.Join(tempDir, "bbl-secrets.json"))
+				Expect(os.IsNotExist(err)).To(BeTrue())
 			})
 
 			Context("when the bbl-state.json file does not exist", func() {
@@ -204,7 +267,7 @@ var _ = Describe("Store", func() {
 
 		Context("failure cases", func() {
 			It("fails when the directory does not exist", func() {
-				store = storage.NewStore("non-valid-dir")
+				store = storage.NewStore(storage.NewLocalBackend("non-valid-dir"), "", nil, storage.DefaultStateBackups)
 				err := store.Set(storage.State{})
 				Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
 			})
@@ -234,6 +297,137 @@ var _ = Describe("Store", func() {
 				Expect(err).To(MatchError("failed to encode"))
 			})
 		})
+
+		Context("when an encryption key is provided", func() {
+			BeforeEach(func() {
+				store = storage.NewStore(storage.NewLocalBackend(tempDir), "some-encryption-key", nil, storage.DefaultStateBackups)
+			})
+
+			It("stores the state encrypted with the key", func() {
+				err := store.Set(storage.State{
+					IAAS: "gcp",
+					KeyPair: storage.KeyPair{
+						PrivateKey: "some-private-key",
+					},
+					BOSH: storage.BOSH{
+						DirectorPassword: "some-director-password",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				data, err := ioutil.ReadFile(filepath.Join(tempDir, "bbl-state.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(ContainSubstring(`"cipher":"aes-256-gcm"`))
+				Expect(string(data)).To(ContainSubstring(`"kdf":"scrypt"`))
+				Expect(string(data)).NotTo(ContainSubstring("some-private-key"))
+				Expect(string(data)).NotTo(ContainSubstring("some-director-password"))
+
+				secrets, err := ioutil.ReadFile(filepath.Join(tempDir, "bbl-secrets.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(secrets)).To(ContainSubstring(`"cipher":"aes-256-gcm"`))
+				Expect(string(secrets)).NotTo(ContainSubstring("some-private-key"))
+
+				state, err := storage.GetState(storage.NewLocalBackend(tempDir), "some-encryption-key")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state).To(Equal(storage.State{
+					Version: 3,
+					IAAS:    "gcp",
+					KeyPair: storage.KeyPair{
+						PrivateKey: "some-private-key",
+					},
+					BOSH: storage.BOSH{
+						DirectorPassword: "some-director-password",
+					},
+				}))
+			})
+
+			It("fails to write the bbl-state.json file", func() {
+				storage.SetEncode(func(io.Writer, interface{}) error {
+					return errors.New("failed to encode")
+				})
+
+				err := store.Set(storage.State{
+					Stack: storage.Stack{
+						Name: "some-stack-name",
+					},
+				})
+				Expect(err).To(MatchError("failed to encode"))
+			})
+		})
+
+		Context("when a secret store is provided", func() {
+			var secretStore *fakes.SecretStore
+
+			BeforeEach(func() {
+				secretStore = &fakes.SecretStore{}
+				secretStore.LocationCall.Returns.Location = "https://some-vault/secret/bbl"
+				store = storage.NewStore(storage.NewLocalBackend(tempDir), "", secretStore, storage.DefaultStateBackups)
+			})
+
+			It("stores references to the director credentials in the state files", func() {
+				err := store.Set(storage.State{
+					IAAS: "gcp",
+					BOSH: storage.BOSH{
+						DirectorUsername: "some-director-username",
+						DirectorPassword: "some-director-password",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(secretStore.WriteCall.Receives.Secrets).To(Equal(map[string]string{
+					"directorUsername": "some-director-username",
+					"directorPassword": "some-director-password",
+				}))
+
+				state, err := storage.GetState(storage.NewLocalBackend(tempDir), "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.SecretStore).To(Equal("https://some-vault/secret/bbl"))
+				Expect(state.BOSH.DirectorUsername).To(Equal("secret-store:directorUsername"))
+				Expect(state.BOSH.DirectorPassword).To(Equal("secret-store:directorPassword"))
+			})
+
+			It("returns an error when the secret store cannot be written", func() {
+				secretStore.WriteCall.Returns.Error = errors.New("failed to write secrets")
+
+				err := store.Set(storage.State{
+					BOSH: storage.BOSH{DirectorPassword: "some-director-password"},
+				})
+				Expect(err).To(MatchError("failed to write secrets"))
+			})
+		})
+	})
+
+	Describe("Get", func() {
+		It("returns the state stored in the backend", func() {
+			err := store.Set(storage.State{
+				IAAS: "gcp",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err := store.Get()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(storage.State{
+				Version: 3,
+				IAAS:    "gcp",
+			}))
+		})
+
+		It("resolves references to the secret store", func() {
+			secretStore := &fakes.SecretStore{}
+			secretStore.ReadCall.Returns.Secrets = map[string]string{
+				"directorPassword": "some-director-password",
+			}
+
+			err := store.Set(storage.State{
+				IAAS: "gcp",
+				BOSH: storage.BOSH{DirectorPassword: "secret-store:directorPassword"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			state, err := storage.NewStore(storage.NewLocalBackend(tempDir), "", secretStore, storage.DefaultStateBackups).Get()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.BOSH.DirectorPassword).To(Equal("some-director-password"))
+		})
 	})
 
 	Describe("GCP", func() {
@@ -292,11 +486,11 @@ var _ = Describe("Store", func() {
 			})
 
 			It("returns the stored state information", func() {
-				state, err := storage.GetState(tempDir)
+				state, err := storage.GetState(storage.NewLocalBackend(tempDir), "")
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(state).To(Equal(storage.State{
-					Version: 2,
+					Version: 3,
 					IAAS:    "aws",
 					AWS: storage.AWS{
 						AccessKeyID:     "some-aws-access-key-id",
@@ -355,11 +549,11 @@ var _ = Describe("Store", func() {
 			})
 
 			It("migrates the state file with a default of iaas: aws", func() {
-				state, err := storage.GetState(tempDir)
+				state, err := storage.GetState(storage.NewLocalBackend(tempDir), "")
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(state).To(Equal(storage.State{
-					Version: 2,
+					Version: 3,
 					IAAS:    "aws",
 					AWS: storage.AWS{
 						AccessKeyID:     "some-aws-access-key-id",
@@ -385,11 +579,101 @@ var _ = Describe("Store", func() {
 					},
 				}))
 			})
+
+			It("does not write the migrated state", func() {
+				original, err := ioutil.ReadFile(filepath.Join(tempDir, "bbl-state.json"))
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = storage.GetState(storage.NewLocalBackend(tempDir), "")
+				Expect(err).NotTo(HaveOccurred())
+
+				contents, err := ioutil.ReadFile(filepath.Join(tempDir, "bbl-state.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(contents).To(Equal(original))
+
+				backups, err := filepath.Glob(filepath.Join(tempDir, "bbl-state.json.backup-*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(backups).To(BeEmpty())
+			})
+		})
+
+		Context("when there is a state file newer than this version of bbl", func() {
+			It("returns an error", func() {
+				err := ioutil.WriteFile(filepath.Join(tempDir, "bbl-state.json"), []byte(`{"version": 999}`), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = storage.GetState(storage.NewLocalBackend(tempDir), "")
+				Expect(err).To(MatchError(ContainSubstring("bbl-state.json has version 999, which is newer than the version this bbl supports")))
+			})
+		})
+
+		Context("when there is an encrypted state file", func() {
+			BeforeEach(func() {
+				err := storage.NewStore(storage.NewLocalBackend(tempDir), "some-encryption-key", nil, storage.DefaultStateBackups).Set(storage.State{
+					IAAS:  "aws",
+					EnvID: "some-env-id",
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("decrypts the state with the provided key", func() {
+				state, err := storage.GetState(storage.NewLocalBackend(tempDir), "some-encryption-key")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(state).To(Equal(storage.State{
+					Version: 3,
+					IAAS:    "aws",
+					EnvID:   "some-env-id",
+				}))
+			})
+
+			Context("failure cases", func() {
+				It("returns an error when no key is provided", func() {
+					_, err := storage.GetState(storage.NewLocalBackend(tempDir), "")
+					Expect(err).To(MatchError("bbl-state.json is encrypted, please provide the state key with --state-key-file or BBL_STATE_KEY"))
+				})
+
+				It("returns an error when the key is incorrect", func() {
+					_, err := storage.GetState(storage.NewLocalBackend(tempDir), "some-other-encryption-key")
+					Expect(err).To(MatchError("bbl-state.json could not be decrypted, please make sure the provided state key is correct"))
+				})
+
+				It("returns an error when the cipher is not supported", func() {
+					err := ioutil.WriteFile(filepath.Join(tempDir, "bbl-state.json"), []byte(`{
+						"encrypted": {
+							"cipher": "some-cipher",
+							"kdf": "scrypt"
+						}
+					}`), os.ModePerm)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = storage.GetState(storage.NewLocalBackend(tempDir), "some-encryption-key")
+					Expect(err).To(MatchError("bbl-state.json is encrypted with an unsupported cipher"))
+				})
+			})
+		})
+
+		Context("when there is a plaintext state file and an encryption key is provided", func() {
+			It("returns the stored state information", func() {
+				err := ioutil.WriteFile(filepath.Join(tempDir, "bbl-state.json"), []byte(`{
+					"version": 2,
+					"iaas": "gcp"
+				}`), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+
+				state, err := storage.GetState(storage.NewLocalBackend(tempDir), "some-encryption-key")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(state).To(Equal(storage.State{
+					Version: 3,
+					IAAS:    "gcp",
+				}))
+			})
 		})
 
 		Context("when the bbl-state.json file doesn't exist", func() {
 			It("returns an empty state object", func() {
-				state, err := storage.GetState(tempDir)
+				state, err := storage.GetState(storage.NewLocalBackend(tempDir), "")
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(state).To(Equal(storage.State{}))
@@ -412,26 +696,25 @@ var _ = Describe("Store", func() {
 					storage.ResetRename()
 				})
 
-				It("renames state.json to bbl-state.json and returns its state", func() {
-					state, err := storage.GetState(tempDir)
+				It("returns its state without renaming it", func() {
+					state, err := storage.GetState(storage.NewLocalBackend(tempDir), "")
 					Expect(err).NotTo(HaveOccurred())
 
 					_, err = os.Stat(filepath.Join(tempDir, "state.json"))
-					Expect(err).To(gomegamatchers.BeAnOsIsNotExistError())
+					Expect(err).NotTo(HaveOccurred())
 
 					_, err = os.Stat(filepath.Join(tempDir, "bbl-state.json"))
-					Expect(err).NotTo(HaveOccurred())
+					Expect(err).To(gomegamatchers.BeAnOsIsNotExistError())
 
 					Expect(state).To(Equal(storage.State{
-						Version: 2,
+						Version: 3,
 						AWS: storage.AWS{
 							AccessKeyID:     "some-aws-access-key-id",
 							SecretAccessKey: "some-aws-secret-access-key",
 							Region:          "some-aws-region",
 						},
 					}))
-					Expect(logger.PrintlnCall.CallCount).To(Equal(1))
-					Expect(logger.PrintlnCall.Receives.Message).To(Equal("renaming state.json to bbl-state.json"))
+					Expect(logger.PrintlnCall.CallCount).To(Equal(0))
 				})
 
 				Context("failure cases", func() {
@@ -440,28 +723,17 @@ var _ = Describe("Store", func() {
 							err := os.Chmod(tempDir, os.FileMode(0000))
 							Expect(err).NotTo(HaveOccurred())
 
-							_, err = storage.GetState(tempDir)
+							_, err = storage.GetState(storage.NewLocalBackend(tempDir), "")
 							Expect(err).To(MatchError(ContainSubstring("permission denied")))
 						})
 					})
-
-					Context("when renaming the file fails", func() {
-						It("returns an error", func() {
-							storage.SetRename(func(src, dst string) error {
-								return errors.New("renaming failed")
-							})
-
-							_, err := storage.GetState(tempDir)
-							Expect(err).To(MatchError("renaming failed"))
-						})
-					})
 				})
 			})
 		})
 
 		Context("failure cases", func() {
 			It("fails when the directory does not exist", func() {
-				_, err := storage.GetState("some-fake-directory")
+				_, err := storage.GetState(storage.NewLocalBackend("some-fake-directory"), "")
 				Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
 			})
 
@@ -469,7 +741,7 @@ var _ = Describe("Store", func() {
 				err := os.Chmod(tempDir, 0000)
 				Expect(err).NotTo(HaveOccurred())
 
-				_, err = storage.GetState(tempDir)
+				_, err = storage.GetState(storage.NewLocalBackend(tempDir), "")
 				Expect(err).To(MatchError(ContainSubstring("permission denied")))
 			})
 
@@ -477,7 +749,7 @@ var _ = Describe("Store", func() {
 				err := ioutil.WriteFile(filepath.Join(tempDir, "bbl-state.json"), []byte(`%%%%`), os.ModePerm)
 				Expect(err).NotTo(HaveOccurred())
 
-				_, err = storage.GetState(tempDir)
+				_, err = storage.GetState(storage.NewLocalBackend(tempDir), "")
 				Expect(err).To(MatchError(ContainSubstring("invalid character")))
 			})
 
@@ -488,10 +760,116 @@ var _ = Describe("Store", func() {
 				err = ioutil.WriteFile(filepath.Join(tempDir, "bbl-state.json"), []byte(`{}`), os.ModePerm)
 				Expect(err).NotTo(HaveOccurred())
 
-				_, err = storage.GetState(tempDir)
+				_, err = storage.GetState(storage.NewLocalBackend(tempDir), "")
 				Expect(err).To(MatchError(ContainSubstring("Cannot proceed with state.json and bbl-state.json present. Please delete one of the files.")))
 
 			})
 		})
 	})
+
+	Describe("Migrate", func() {
+		var logger *fakes.Logger
+
+		BeforeEach(func() {
+			logger = &fakes.Logger{}
+			storage.GetStateLogger = logger
+		})
+
+		Context("when there is a v1 state file", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(filepath.Join(tempDir, "bbl-state.json"), []byte(`{
+					"version": 1,
+					"aws": {
+						"region": "some-aws-region"
+					}
+				}`), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("backs up the v1 state file and persists the migrated state", func() {
+				original, err := ioutil.ReadFile(filepath.Join(tempDir, "bbl-state.json"))
+				Expect(err).NotTo(HaveOccurred())
+
+				err = store.Migrate()
+				Expect(err).NotTo(HaveOccurred())
+
+				backups, err := filepath.Glob(filepath.Join(tempDir, "bbl-state.json.backup-*"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(backups).To(HaveLen(1))
+
+				backup, err := ioutil.ReadFile(backups[0])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(backup).To(Equal(original))
+
+				migrated, err := ioutil.ReadFile(filepath.Join(tempDir, "bbl-state.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(migrated)).To(ContainSubstring(`"version":3`))
+				Expect(string(migrated)).To(ContainSubstring(`"iaas":"aws"`))
+			})
+		})
+
+		Context("when the state file is current", func() {
+			It("does not write it", func() {
+				err := ioutil.WriteFile(filepath.Join(tempDir, "bbl-state.json"), []byte(`{"version": 3, "iaas": "gcp"}`), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = store.Migrate()
+				Expect(err).NotTo(HaveOccurred())
+
+				contents, err := ioutil.ReadFile(filepath.Join(tempDir, "bbl-state.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal(`{"version": 3, "iaas": "gcp"}`))
+			})
+		})
+
+		Context("when there is no state file", func() {
+			It("does nothing", func() {
+				err := store.Migrate()
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = os.Stat(filepath.Join(tempDir, "bbl-state.json"))
+				Expect(err).To(gomegamatchers.BeAnOsIsNotExistError())
+			})
+		})
+
+		Context("when state.json exists", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(filepath.Join(tempDir, "state.json"), []byte(`{
+					"version": 2,
+					"aws": {
+						"region": "some-aws-region"
+					}
+				}`), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				storage.ResetRename()
+			})
+
+			It("renames state.json to bbl-state.json and migrates it", func() {
+				err := store.Migrate()
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = os.Stat(filepath.Join(tempDir, "state.json"))
+				Expect(err).To(gomegamatchers.BeAnOsIsNotExistError())
+
+				migrated, err := ioutil.ReadFile(filepath.Join(tempDir, "bbl-state.json"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(migrated)).To(ContainSubstring(`"version":3`))
+
+				Expect(logger.PrintlnCall.CallCount).To(Equal(1))
+				Expect(logger.PrintlnCall.Receives.Message).To(Equal("renaming state.json to bbl-state.json"))
+			})
+
+			It("returns an error when renaming the file fails", func() {
+				storage.SetRename(func(src, dst string) error {
+					return errors.New("renaming failed")
+				})
+
+				err := store.Migrate()
+				Expect(err).To(MatchError("renaming failed"))
+			})
+		})
+	})
 })
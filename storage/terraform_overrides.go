@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const TerraformOverridesDir = "overrides"
+
+// NewTerraformOverridesBackend returns the backend that holds the terraform
+// overrides, which live in the overrides directory next to the state.
+func NewTerraformOverridesBackend(stateDir, stateBackend string) (Backend, error) {
+	if stateBackend == "" || stateBackend == "local" {
+		return NewLocalBackend(filepath.Join(stateDir, TerraformOverridesDir)), nil
+	}
+
+	backendURL, err := url.Parse(stateBackend)
+	if err != nil {
+		return nil, err
+	}
+	backendURL.Path = path.Join(backendURL.Path, TerraformOverridesDir)
+
+	return NewBackend(stateDir, backendURL.String())
+}
+
+func ReadTerraformOverrides(backend Backend) (map[string]string, error) {
+	names, err := backend.List("")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var overrides map[string]string
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".tf") {
+			continue
+		}
+
+		contents, err := backend.Read(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if overrides == nil {
+			overrides = map[string]string{}
+		}
+		overrides[name] = string(contents)
+	}
+
+	return overrides, nil
+}
+
+func WriteTerraformOverrides(backend Backend, overrides map[string]string) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	if localBackend, ok := backend.(LocalBackend); ok {
+		if err := os.MkdirAll(localBackend.dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range sortedKeys(overrides) {
+		if err := validateTerraformOverrideName(name); err != nil {
+			return err
+		}
+
+		if err := backend.Write(name, []byte(overrides[name])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateTerraformOverrideName(name string) error {
+	if filepath.Base(name) != name || !strings.HasSuffix(name, ".tf") {
+		return fmt.Errorf("%q is an invalid terraform override, overrides must be *.tf files", name)
+	}
+
+	return nil
+}
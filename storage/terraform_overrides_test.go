@@ -0,0 +1,116 @@
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TerraformOverrides", func() {
+	var tempDir string
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	Describe("NewTerraformOverridesBackend", func() {
+		It("returns the overrides directory in the state dir for the local backend", func() {
+			backend, err := storage.NewTerraformOverridesBackend("some-state-dir", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend).To(Equal(storage.NewLocalBackend(filepath.Join("some-state-dir", "overrides"))))
+		})
+
+		It("returns the overrides prefix next to the state for the s3 backend", func() {
+			backend, err := storage.NewTerraformOverridesBackend("some-state-dir", "s3://some-bucket/some/prefix?region=some-region")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend).To(BeAssignableToTypeOf(storage.S3Backend{}))
+			Expect(backend.Location()).To(Equal("s3://some-bucket/some/prefix/overrides"))
+
+			backend, err = storage.NewTerraformOverridesBackend("some-state-dir", "s3://some-bucket")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backend.Location()).To(Equal("s3://some-bucket/overrides"))
+		})
+	})
+
+	Describe("ReadTerraformOverrides", func() {
+		It("reads the *.tf files in the directory", func() {
+			err := ioutil.WriteFile(filepath.Join(tempDir, "firewall.tf"), []byte("some-override"), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(filepath.Join(tempDir, "README.md"), []byte("some-readme"), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			overrides, err := storage.ReadTerraformOverrides(storage.NewLocalBackend(tempDir))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(overrides).To(Equal(map[string]string{"firewall.tf": "some-override"}))
+		})
+
+		It("returns no overrides when the directory does not exist", func() {
+			overrides, err := storage.ReadTerraformOverrides(storage.NewLocalBackend(filepath.Join(tempDir, "missing")))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(overrides).To(BeNil())
+		})
+
+		It("reads the overrides from the s3 backend", func() {
+			client := &fakes.S3Client{}
+			client.ListObjectsCall.Returns.Outputs = []*s3.ListObjectsOutput{{
+				Contents: []*s3.Object{{Key: aws.String("some/prefix/overrides/firewall.tf")}},
+			}}
+			client.GetObjectCall.Returns.Output = &s3.GetObjectOutput{
+				Body: ioutil.NopCloser(strings.NewReader("some-override")),
+			}
+
+			overrides, err := storage.ReadTerraformOverrides(storage.NewS3Backend(client, "some-bucket", "some/prefix/overrides"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(overrides).To(Equal(map[string]string{"firewall.tf": "some-override"}))
+
+			Expect(client.GetObjectCall.Receives.Input.Key).To(Equal(aws.String("some/prefix/overrides/firewall.tf")))
+		})
+
+		It("does not read objects next to the overrides prefix in the s3 backend", func() {
+			client := &fakes.S3Client{}
+
+			_, err := storage.ReadTerraformOverrides(storage.NewS3Backend(client, "some-bucket", "some/prefix/overrides"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.ListObjectsCall.Receives.Inputs[0].Prefix).To(Equal(aws.String("some/prefix/overrides/")))
+			Expect(client.ListObjectsCall.Receives.Inputs[0].Delimiter).To(Equal(aws.String("/")))
+		})
+	})
+
+	Describe("WriteTerraformOverrides", func() {
+		It("creates the directory and writes the overrides", func() {
+			dir := filepath.Join(tempDir, "overrides")
+
+			err := storage.WriteTerraformOverrides(storage.NewLocalBackend(dir), map[string]string{"firewall.tf": "some-override"})
+			Expect(err).NotTo(HaveOccurred())
+
+			contents, err := ioutil.ReadFile(filepath.Join(dir, "firewall.tf"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-override"))
+		})
+
+		It("returns an error for overrides that are not *.tf files", func() {
+			err := storage.WriteTerraformOverrides(storage.NewLocalBackend(tempDir), map[string]string{"../firewall.tf": "some-override"})
+			Expect(err).To(MatchError(`"../firewall.tf" is an invalid terraform override, overrides must be *.tf files`))
+
+			err = storage.WriteTerraformOverrides(storage.NewLocalBackend(tempDir), map[string]string{"firewall.sh": "some-override"})
+			Expect(err).To(MatchError(`"firewall.sh" is an invalid terraform override, overrides must be *.tf files`))
+		})
+	})
+})
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+type httpClient interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+type VaultSecretStore struct {
+	client    httpClient
+	address   string
+	path      string
+	token     string
+	kvVersion int
+}
+
+type vaultSecret struct {
+	Data map[string]string `json:"data"`
+}
+
+type vaultSecretV2 struct {
+	Data vaultSecret `json:"data"`
+}
+
+type vaultErrors struct {
+	Errors []string `json:"errors"`
+}
+
+func NewVaultSecretStore(client httpClient, address, path, token string, kvVersion int) VaultSecretStore {
+	return VaultSecretStore{
+		client:    client,
+		address:   strings.TrimRight(address, "/"),
+		path:      strings.Trim(path, "/"),
+		token:     token,
+		kvVersion: kvVersion,
+	}
+}
+
+func (v VaultSecretStore) Location() string {
+	location := fmt.Sprintf("%s/%s", v.address, v.path)
+	if v.kvVersion == 2 {
+		location += "?kv-version=2"
+	}
+
+	return location
+}
+
+func (v VaultSecretStore) Read() (map[string]string, error) {
+	body, status, err := v.do("GET", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == http.StatusNotFound {
+		return map[string]string{}, nil
+	}
+
+	if v.kvVersion == 2 {
+		var secret vaultSecretV2
+		if err := json.Unmarshal(body, &secret); err != nil {
+			return nil, fmt.Errorf("failed to parse secret from %s: %s", v.Location(), err)
+		}
+		return secret.Data.Data, nil
+	}
+
+	var secret vaultSecret
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return nil, fmt.Errorf("failed to parse secret from %s: %s", v.Location(), err)
+	}
+
+	return secret.Data, nil
+}
+
+func (v VaultSecretStore) Write(secrets map[string]string) error {
+	var payload interface{} = secrets
+	if v.kvVersion == 2 {
+		payload = vaultSecret{Data: secrets}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, status, err := v.do("PUT", data)
+	if err != nil {
+		return err
+	}
+
+	if status == http.StatusNotFound {
+		return fmt.Errorf("secret store %s returned 404 Not Found", v.Location())
+	}
+
+	return nil
+}
+
+func (v VaultSecretStore) do(method string, data []byte) ([]byte, int, error) {
+	request, err := http.NewRequest(method, v.url(), bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	request.Header.Set("X-Vault-Token", v.token)
+	if data != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := v.client.Do(request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("secret store %s is not reachable: %s", v.Location(), err)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if response.StatusCode >= 300 && response.StatusCode != http.StatusNotFound {
+		var errs vaultErrors
+		json.Unmarshal(body, &errs)
+
+		return nil, response.StatusCode, fmt.Errorf("secret store %s returned %s: %s", v.Location(), response.Status, strings.Join(errs.Errors, ", "))
+	}
+
+	return body, response.StatusCode, nil
+}
+
+func (v VaultSecretStore) url() string {
+	if v.kvVersion == 2 {
+		parts := strings.SplitN(v.path, "/", 2)
+		return fmt.Sprintf("%s/v1/%s/data/%s", v.address, parts[0], parts[1])
+	}
+
+	return fmt.Sprintf("%s/v1/%s", v.address, v.path)
+}
@@ -0,0 +1,158 @@
+package storage_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry/bosh-bootloader/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("VaultSecretStore", func() {
+	var (
+		server *httptest.Server
+
+		requestMethod string
+		requestPath   string
+		requestToken  string
+		requestBody   map[string]interface{}
+
+		responseStatus int
+		responseBody   string
+	)
+
+	BeforeEach(func() {
+		requestBody = nil
+		responseStatus = http.StatusOK
+		responseBody = ""
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestMethod = r.Method
+			requestPath = r.URL.Path
+			requestToken = r.Header.Get("X-Vault-Token")
+
+			body, err := ioutil.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			if len(body) > 0 {
+				Expect(json.Unmarshal(body, &requestBody)).To(Succeed())
+			}
+
+			w.WriteHeader(responseStatus)
+			w.Write([]byte(responseBody))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("with a version 1 kv mount", func() {
+		var secretStore storage.VaultSecretStore
+
+		BeforeEach(func() {
+			secretStore = storage.NewVaultSecretStore(http.DefaultClient, server.URL, "secret/bbl/some-env", "some-token", 1)
+		})
+
+		It("reads the secret", func() {
+			responseBody = `{"data": {"directorPassword": "some-password"}}`
+
+			secrets, err := secretStore.Read()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(requestMethod).To(Equal("GET"))
+			Expect(requestPath).To(Equal("/v1/secret/bbl/some-env"))
+			Expect(requestToken).To(Equal("some-token"))
+			Expect(secrets).To(Equal(map[string]string{"directorPassword": "some-password"}))
+		})
+
+		It("returns no secrets when the secret does not exist", func() {
+			responseStatus = http.StatusNotFound
+			responseBody = `{"errors": []}`
+
+			secrets, err := secretStore.Read()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secrets).To(BeEmpty())
+		})
+
+		It("writes the secret", func() {
+			responseStatus = http.StatusNoContent
+
+			err := secretStore.Write(map[string]string{"directorPassword": "some-password"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(requestMethod).To(Equal("PUT"))
+			Expect(requestPath).To(Equal("/v1/secret/bbl/some-env"))
+			Expect(requestToken).To(Equal("some-token"))
+			Expect(requestBody).To(Equal(map[string]interface{}{"directorPassword": "some-password"}))
+		})
+	})
+
+	Context("with a version 2 kv mount", func() {
+		var secretStore storage.VaultSecretStore
+
+		BeforeEach(func() {
+			secretStore = storage.NewVaultSecretStore(http.DefaultClient, server.URL, "secret/bbl/some-env", "some-token", 2)
+		})
+
+		It("reads the secret from the data path", func() {
+			responseBody = `{"data": {"data": {"directorPassword": "some-password"}, "metadata": {"version": 1}}}`
+
+			secrets, err := secretStore.Read()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(requestPath).To(Equal("/v1/secret/data/bbl/some-env"))
+			Expect(secrets).To(Equal(map[string]string{"directorPassword": "some-password"}))
+		})
+
+		It("writes the secret wrapped in data", func() {
+			err := secretStore.Write(map[string]string{"directorPassword": "some-password"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(requestPath).To(Equal("/v1/secret/data/bbl/some-env"))
+			Expect(requestBody).To(Equal(map[string]interface{}{
+				"data": map[string]interface{}{"directorPassword": "some-password"},
+			}))
+		})
+	})
+
+	Context("failure cases", func() {
+		var secretStore storage.VaultSecretStore
+
+		BeforeEach(func() {
+			secretStore = storage.NewVaultSecretStore(http.DefaultClient, server.URL, "secret/bbl", "some-token", 1)
+		})
+
+		It("returns the errors from the secret store", func() {
+			responseStatus = http.StatusForbidden
+			responseBody = `{"errors": ["permission denied"]}`
+
+			_, err := secretStore.Read()
+			Expect(err).To(MatchError(ContainSubstring("returned 403 Forbidden: permission denied")))
+		})
+
+		It("returns an error when the secret cannot be parsed", func() {
+			responseBody = "%%%"
+
+			_, err := secretStore.Read()
+			Expect(err).To(MatchError(ContainSubstring("failed to parse secret from")))
+		})
+
+		It("returns an error when writing to a missing mount", func() {
+			responseStatus = http.StatusNotFound
+
+			err := secretStore.Write(map[string]string{})
+			Expect(err).To(MatchError(ContainSubstring("returned 404 Not Found")))
+		})
+
+		It("returns an error when the secret store is not reachable", func() {
+			server.Close()
+
+			_, err := secretStore.Read()
+			Expect(err).To(MatchError(ContainSubstring("is not reachable")))
+		})
+	})
+})
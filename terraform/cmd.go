@@ -3,6 +3,8 @@ package terraform
 import (
 	"io"
 	"os/exec"
+
+	"github.com/cloudfoundry/bosh-bootloader/helpers"
 )
 
 type Cmd struct {
@@ -21,5 +23,5 @@ func (cmd Cmd) Run(stdout io.Writer, workingDirectory string, args []string) err
 	runCommand.Stdout = stdout
 	runCommand.Stderr = cmd.stderr
 
-	return runCommand.Run()
+	return helpers.RunInterruptible(runCommand)
 }
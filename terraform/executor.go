@@ -1,38 +1,114 @@
 package terraform
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 
 	"github.com/cloudfoundry/bosh-bootloader/helpers"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
 )
 
 var tempDir func(dir, prefix string) (string, error) = ioutil.TempDir
 var writeFile func(file string, data []byte, perm os.FileMode) error = ioutil.WriteFile
 var readFile func(filename string) ([]byte, error) = ioutil.ReadFile
 
-type Executor struct{ cmd terraformCmd }
+var planDestroyCount = regexp.MustCompile(`(\d+) to destroy`)
+
+type Executor struct {
+	cmd       terraformCmd
+	overrides storage.Backend
+}
+
 type terraformCmd interface {
 	Run(stdout io.Writer, workingDirectory string, args []string) error
 }
 
-func NewExecutor(cmd terraformCmd) Executor {
-	return Executor{cmd: cmd}
+func NewExecutor(cmd terraformCmd, overrides storage.Backend) Executor {
+	return Executor{
+		cmd:       cmd,
+		overrides: overrides,
+	}
 }
 
 func (e Executor) Apply(credentials, envID, projectID, zone, region, cert, key, domain, template, prevTFState string) (string, error) {
-	tempDir, err := tempDir("", "")
+	tempDir, vars, err := writeApplyInputs(credentials, envID, projectID, zone, region, cert, key, domain, template, prevTFState)
 	if err != nil {
 		return "", err
 	}
 
+	err = e.writeOverrides(tempDir)
+	if err != nil {
+		return "", err
+	}
+
+	args := append([]string{"apply"}, vars...)
+	err = e.cmd.Run(os.Stdout, tempDir, args)
+	if err != nil {
+		tfState, readErr := readFile(filepath.Join(tempDir, "terraform.tfstate"))
+		if readErr != nil {
+			errorList := helpers.Errors{}
+			errorList.Add(err)
+			errorList.Add(readErr)
+			return "", errorList
+		}
+		return string(tfState), NewTerraformApplyError(string(tfState), err)
+	}
+
+	tfState, err := readFile(filepath.Join(tempDir, "terraform.tfstate"))
+	if err != nil {
+		return "", err
+	}
+
+	return string(tfState), nil
+}
+
+func (e Executor) Plan(credentials, envID, projectID, zone, region, cert, key, domain, template, prevTFState string) (string, error) {
+	tempDir, vars, err := writeApplyInputs(credentials, envID, projectID, zone, region, cert, key, domain, template, prevTFState)
+	if err != nil {
+		return "", err
+	}
+
+	err = e.writeOverrides(tempDir)
+	if err != nil {
+		return "", err
+	}
+
+	var output bytes.Buffer
+	args := append([]string{"plan"}, vars...)
+	err = e.cmd.Run(&output, tempDir, args)
+	if err != nil {
+		return "", err
+	}
+
+	return output.String(), nil
+}
+
+func PlanDestroyCount(planOutput string) int {
+	matches := planDestroyCount.FindStringSubmatch(planOutput)
+	if matches == nil {
+		return 0
+	}
+
+	count, _ := strconv.Atoi(matches[1])
+	return count
+}
+
+func writeApplyInputs(credentials, envID, projectID, zone, region, cert, key, domain, template, prevTFState string) (string, []string, error) {
+	tempDir, err := tempDir("", "")
+	if err != nil {
+		return "", nil, err
+	}
+
 	credentialsPath := filepath.Join(tempDir, "credentials.json")
 	err = writeFile(credentialsPath, []byte(credentials), os.ModePerm)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	var certPath string
@@ -40,7 +116,7 @@ func (e Executor) Apply(credentials, envID, projectID, zone, region, cert, key,
 		certPath = filepath.Join(tempDir, "cert")
 		err = writeFile(certPath, []byte(cert), os.ModePerm)
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 	}
 
@@ -49,23 +125,23 @@ func (e Executor) Apply(credentials, envID, projectID, zone, region, cert, key,
 		keyPath = filepath.Join(tempDir, "key")
 		err = writeFile(keyPath, []byte(key), os.ModePerm)
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 	}
 
 	err = writeFile(filepath.Join(tempDir, "template.tf"), []byte(template), os.ModePerm)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	if prevTFState != "" {
 		err = writeFile(filepath.Join(tempDir, "terraform.tfstate"), []byte(prevTFState), os.ModePerm)
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 	}
 
-	args := []string{"apply"}
+	args := []string{}
 	args = append(args, makeVar("project_id", projectID)...)
 	args = append(args, makeVar("env_id", envID)...)
 	args = append(args, makeVar("region", region)...)
@@ -78,24 +154,8 @@ func (e Executor) Apply(credentials, envID, projectID, zone, region, cert, key,
 	}
 	args = append(args, makeVar("credentials", credentialsPath)...)
 	args = append(args, makeVar("system_domain", domain)...)
-	err = e.cmd.Run(os.Stdout, tempDir, args)
-	if err != nil {
-		tfState, readErr := readFile(filepath.Join(tempDir, "terraform.tfstate"))
-		if readErr != nil {
-			errorList := helpers.Errors{}
-			errorList.Add(err)
-			errorList.Add(readErr)
-			return "", errorList
-		}
-		return string(tfState), NewTerraformApplyError(string(tfState), err)
-	}
 
-	tfState, err := readFile(filepath.Join(tempDir, "terraform.tfstate"))
-	if err != nil {
-		return "", err
-	}
-
-	return string(tfState), nil
+	return tempDir, args, nil
 }
 
 func (e Executor) Destroy(credentials, envID, projectID, zone, region, template, prevTFState string) (string, error) {
@@ -120,6 +180,11 @@ func (e Executor) Destroy(credentials, envID, projectID, zone, region, template,
 		return "", err
 	}
 
+	err = e.writeOverrides(tempDir)
+	if err != nil {
+		return "", err
+	}
+
 	args := []string{"destroy", "-force"}
 	args = append(args, makeVar("project_id", projectID)...)
 	args = append(args, makeVar("env_id", envID)...)
@@ -146,6 +211,26 @@ func (e Executor) Destroy(credentials, envID, projectID, zone, region, template,
 	return string(tfState), nil
 }
 
+func (e Executor) writeOverrides(tempDir string) error {
+	overrides, err := storage.ReadTerraformOverrides(e.overrides)
+	if err != nil {
+		return err
+	}
+
+	for name, contents := range overrides {
+		if name == "template.tf" {
+			return fmt.Errorf("terraform override %q conflicts with the template generated by bbl", name)
+		}
+
+		err = writeFile(filepath.Join(tempDir, name), []byte(contents), os.ModePerm)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func makeVar(name string, value string) []string {
 	return []string{"-var", fmt.Sprintf("%s=%s", name, value)}
 }
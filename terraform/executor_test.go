@@ -3,12 +3,14 @@ package terraform_test
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/cloudfoundry/bosh-bootloader/fakes"
+	"github.com/cloudfoundry/bosh-bootloader/storage"
 	"github.com/cloudfoundry/bosh-bootloader/terraform"
 
 	. "github.com/onsi/ginkgo"
@@ -17,15 +19,20 @@ import (
 
 var _ = Describe("Executor", func() {
 	var (
-		cmd      *fakes.TerraformCmd
-		executor terraform.Executor
-		tempDir  string
+		cmd          *fakes.TerraformCmd
+		executor     terraform.Executor
+		tempDir      string
+		overridesDir string
 	)
 
 	BeforeEach(func() {
 		cmd = &fakes.TerraformCmd{}
 
-		executor = terraform.NewExecutor(cmd)
+		var err error
+		overridesDir, err = ioutil.TempDir("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		executor = terraform.NewExecutor(cmd, storage.NewLocalBackend(overridesDir))
 
 		terraform.SetTempDir(func(dir, prefix string) (string, error) {
 			var err error
@@ -44,6 +51,8 @@ var _ = Describe("Executor", func() {
 		terraform.ResetTempDir()
 		terraform.ResetReadFile()
 		terraform.ResetWriteFile()
+
+		os.RemoveAll(overridesDir)
 	})
 
 	Describe("Apply", func() {
@@ -58,6 +67,29 @@ var _ = Describe("Executor", func() {
 			Expect(string(fileContents)).To(Equal("some-template"))
 		})
 
+		It("writes the terraform overrides next to the template", func() {
+			err := ioutil.WriteFile(filepath.Join(overridesDir, "network_override.tf"), []byte("some-override"), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = executor.Apply("some-credentials-json", "some-env-id", "some-project-id", "some-zone", "some-region",
+				"some-cert", "some-key", "some-domain", "some-template", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			fileContents, err := ioutil.ReadFile(filepath.Join(tempDir, "network_override.tf"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(string(fileContents)).To(Equal("some-override"))
+		})
+
+		It("returns an error when an override would replace the template", func() {
+			err := ioutil.WriteFile(filepath.Join(overridesDir, "template.tf"), []byte("some-override"), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = executor.Apply("some-credentials-json", "some-env-id", "some-project-id", "some-zone", "some-region",
+				"some-cert", "some-key", "some-domain", "some-template", "")
+			Expect(err).To(MatchError(`terraform override "template.tf" conflicts with the template generated by bbl`))
+		})
+
 		It("writes the cert when cert is provided", func() {
 			_, err := executor.Apply("some-credentials-json", "some-env-id", "some-project-id", "some-zone", "some-region",
 				"some-cert", "some-key", "some-domain", "some-template", "")
@@ -295,6 +327,74 @@ var _ = Describe("Executor", func() {
 		})
 	})
 
+	Describe("Plan", func() {
+		It("runs terraform plan with the same inputs as apply and returns its output", func() {
+			cmd.RunCall.Stub = func(stdout io.Writer) {
+				fmt.Fprint(stdout, "Plan: 1 to add, 0 to change, 0 to destroy.")
+			}
+
+			output, err := executor.Plan("some-credentials-json", "some-env-id", "some-project-id", "some-zone", "some-region",
+				"some-cert", "some-key", "some-domain", "some-template", "some-tf-state")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(Equal("Plan: 1 to add, 0 to change, 0 to destroy."))
+
+			fileContents, err := ioutil.ReadFile(filepath.Join(tempDir, "template.tf"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(fileContents)).To(Equal("some-template"))
+
+			fileContents, err = ioutil.ReadFile(filepath.Join(tempDir, "terraform.tfstate"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(fileContents)).To(Equal("some-tf-state"))
+
+			Expect(cmd.RunCall.Receives.WorkingDirectory).To(Equal(tempDir))
+			Expect(cmd.RunCall.Receives.Args).To(Equal([]string{
+				"plan",
+				"-var", "project_id=some-project-id",
+				"-var", "env_id=some-env-id",
+				"-var", "region=some-region",
+				"-var", "zone=some-zone",
+				"-var", fmt.Sprintf("ssl_certificate=%s/cert", tempDir),
+				"-var", fmt.Sprintf("ssl_certificate_private_key=%s/key", tempDir),
+				"-var", fmt.Sprintf("credentials=%s/credentials.json", tempDir),
+				"-var", "system_domain=some-domain",
+			}))
+		})
+
+		Context("failure cases", func() {
+			It("returns an error when it fails to write the template file", func() {
+				terraform.SetWriteFile(func(file string, data []byte, perm os.FileMode) error {
+					if file == filepath.Join(tempDir, "template.tf") {
+						return errors.New("failed to write template file")
+					}
+
+					return nil
+				})
+
+				_, err := executor.Plan("some-credentials-json", "some-env-id", "some-project-id", "some-zone", "some-region",
+					"some-cert", "some-key", "some-domain", "some-template", "")
+				Expect(err).To(MatchError("failed to write template file"))
+			})
+
+			It("returns an error when terraform plan fails", func() {
+				cmd.RunCall.Returns.Error = errors.New("failed to run terraform command")
+
+				_, err := executor.Plan("some-credentials-json", "some-env-id", "some-project-id", "some-zone", "some-region",
+					"some-cert", "some-key", "some-domain", "some-template", "")
+				Expect(err).To(MatchError("failed to run terraform command"))
+			})
+		})
+	})
+
+	Describe("PlanDestroyCount", func() {
+		It("returns the number of resources the plan destroys", func() {
+			Expect(terraform.PlanDestroyCount("Plan: 2 to add, 1 to change, 3 to destroy.")).To(Equal(3))
+		})
+
+		It("returns zero when the plan has no changes", func() {
+			Expect(terraform.PlanDestroyCount("No changes. Infrastructure is up-to-date.")).To(Equal(0))
+		})
+	})
+
 	Describe("Destroy", func() {
 		It("writes the template and tf state to a temp dir", func() {
 			_, err := executor.Destroy("some-credentials-json", "some-env-id", "some-project-id", "some-zone", "some-region",
@@ -310,6 +410,19 @@ var _ = Describe("Executor", func() {
 			Expect(string(tfStateContents)).To(Equal("some-tf-state"))
 		})
 
+		It("writes the terraform overrides next to the template", func() {
+			err := ioutil.WriteFile(filepath.Join(overridesDir, "network_override.tf"), []byte("some-override"), os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = executor.Destroy("some-credentials-json", "some-env-id", "some-project-id", "some-zone", "some-region",
+				"some-template", "some-tf-state")
+			Expect(err).NotTo(HaveOccurred())
+
+			fileContents, err := ioutil.ReadFile(filepath.Join(tempDir, "network_override.tf"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(fileContents)).To(Equal("some-override"))
+		})
+
 		It("writes credentials to a file", func() {
 			_, err := executor.Destroy("some-credentials-json", "some-env-id", "some-project-id", "some-zone", "some-region",
 				"some-template", "some-tf-state")